@@ -0,0 +1,461 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PatternBruteforce marks a stretch of an Analysis that no Matcher explained, guessed by trying
+// every character.
+const PatternBruteforce MatchKind = "bruteforce"
+
+// MaxAnalyzeRunes bounds the runes Analyze segments, since the search grows with the cube of the
+// length. The rest of a longer password is counted as bruteforce.
+const MaxAnalyzeRunes = 100
+
+// Guess model constants, following zxcvbn.
+const (
+	bruteforceCardinality = 10    // Guesses per rune of a bruteforce stretch
+	minSegmentGuesses     = 10000 // Extra guesses charged for each segment after the first
+	minSingleRuneGuesses  = 10    // Floor of a one-rune match shorter than the password
+	minMultiRuneGuesses   = 50    // Floor of a longer match shorter than the password
+	defaultDictionaryRank = 10000 // Rank assumed for words of a DictionaryChecker that does not rank them
+	spatialStartingKeys   = 94    // Keys a keyboard walk may start on
+	spatialAverageDegree  = 4.6   // Average neighbours of a key on a QWERTY keyboard
+	minYearSpace          = 20    // Years guessed around the current one at the least
+	dateDaysPerYear       = 365   // Month and day combinations of a date
+	maxDictionaryWordRune = 32    // Longest substring the dictionary matcher looks up
+	minDictionaryWordRune = 3     // Shortest substring the dictionary matcher looks up
+	maxGuesses            = 1e300 // Cap keeping Analysis.Guesses finite
+	dateSeparatorGuesses  = 4     // Variants of a date written with separators
+	sequenceObviousStarts = "aAzZ019"
+)
+
+// Matcher finds weak structures in a password, like the detectors behind Result.Patterns. Match
+// returns every candidate it sees, overlapping or not, with Start and End in runes; Analyze picks
+// the cheapest combination. A Match may set Guesses, the number of guesses an attacker needs for
+// its Token; when zero, Analyze estimates it from the Kind, or as bruteforce for unknown kinds.
+// Matchers must be safe for concurrent use.
+type Matcher interface {
+	Match(password []rune) []Match
+}
+
+// MatcherFunc adapts an ordinary function to the Matcher interface.
+type MatcherFunc func(password []rune) []Match
+
+// Match calls f(password).
+func (f MatcherFunc) Match(password []rune) []Match {
+	return f(password)
+}
+
+var (
+	matchersMu sync.RWMutex
+	matchers   []namedMatcher
+)
+
+// namedMatcher is an entry of the matcher registry.
+type namedMatcher struct {
+	name    string
+	matcher Matcher
+}
+
+// Names of the built-in matchers.
+const (
+	MatcherDictionary = "dictionary"
+	MatcherSequence   = "sequence"
+	MatcherRepeat     = "repeat"
+	MatcherSpatial    = "spatial"
+	MatcherDate       = "date"
+)
+
+func init() {
+	matchers = []namedMatcher{
+		{MatcherDictionary, NewDictionaryMatcher(CommonPasswords)},
+		{MatcherSequence, MatcherFunc(func(runes []rune) []Match { return detectSequences(runes, 2) })},
+		{MatcherRepeat, MatcherFunc(func(runes []rune) []Match {
+			return append(detectRepeats(runes, 1), detectRepeatedBlocks(runes)...)
+		})},
+		{MatcherSpatial, MatcherFunc(func(runes []rune) []Match { return detectKeyboardWalks(runes, 3) })},
+		{MatcherDate, MatcherFunc(func(runes []rune) []Match { return detectDates(string(runes), DefaultYearRange) })},
+	}
+}
+
+// RegisterMatcher adds a matcher used by Analyze when it is given none, or replaces the one
+// registered under name, built-in ones included.
+func RegisterMatcher(name string, m Matcher) error {
+	if name == "" {
+		return errors.New("matcher name must not be empty")
+	}
+	if m == nil {
+		return errors.New("matcher must not be nil")
+	}
+	matchersMu.Lock()
+	defer matchersMu.Unlock()
+	for i := range matchers {
+		if matchers[i].name == name {
+			matchers[i].matcher = m
+			return nil
+		}
+	}
+	matchers = append(matchers, namedMatcher{name, m})
+	return nil
+}
+
+// Matchers returns the registered matchers in registration order, the built-in ones first.
+func Matchers() []Matcher {
+	matchersMu.RLock()
+	defer matchersMu.RUnlock()
+	list := make([]Matcher, len(matchers))
+	for i, m := range matchers {
+		list[i] = m.matcher
+	}
+	return list
+}
+
+// dictionaryMatcher reports every substring of the password a DictionaryChecker contains.
+type dictionaryMatcher struct {
+	dictionary DictionaryChecker
+}
+
+// NewDictionaryMatcher returns a Matcher reporting, as PatternDictionary, every substring of 3
+// to 32 runes that d contains, compared in lowercase. Words of the embedded list and of a WordList
+// are guessed by rank; other checkers' words are assumed to rank 10000.
+func NewDictionaryMatcher(d DictionaryChecker) Matcher {
+	return dictionaryMatcher{dictionary: d}
+}
+
+func (m dictionaryMatcher) Match(runes []rune) []Match {
+	ranked, _ := m.dictionary.(interface{ rank(string) int })
+	var patterns []Match
+	for i := range runes {
+		for j := i + minDictionaryWordRune; j <= len(runes) && j-i <= maxDictionaryWordRune; j++ {
+			token := string(runes[i:j])
+			word := strings.ToLower(token)
+			if !m.dictionary.Contains(word) {
+				continue
+			}
+			rank := defaultDictionaryRank
+			if ranked != nil {
+				rank = max(ranked.rank(word), 1)
+			}
+			patterns = append(patterns, Match{Kind: PatternDictionary, Token: token, Start: i, End: j, Base: word,
+				Guesses: float64(rank) * uppercaseVariations(token)})
+		}
+	}
+	return patterns
+}
+
+// Analysis is the result of Analyze: the cheapest way an attacker could guess a password by
+// combining the structures the matchers found.
+type Analysis struct {
+	Guesses    float64    `json:"guesses"`     // Estimated guesses to crack the password, capped at 1e300
+	Entropy    float64    `json:"entropy"`     // Guesses in bits, log2(Guesses)
+	Sequence   []Match    `json:"sequence"`    // The segmentation behind Guesses, in order, bruteforce stretches included
+	Score      int        `json:"score"`       // Strength from 0 to 100 with Entropy in place of EffectiveEntropy
+	Rating     Rating     `json:"rating"`      // Qualitative bucket of Score
+	CrackTimes CrackTimes `json:"crack_times"` // Estimated seconds to guess the password for each attacker profile
+}
+
+// Feedback returns suggestions for the structures in the Analysis, as Result.Feedback does for
+// Result.Patterns.
+func (a Analysis) Feedback() []string {
+	return a.FeedbackLocale(DefaultLanguage)
+}
+
+// FeedbackLocale is like Feedback but uses the messages registered for lang. Dictionary words
+// come first.
+func (a Analysis) FeedbackLocale(lang string) []string {
+	var words []string
+	var patterns []Match
+	for _, m := range a.Sequence {
+		switch m.Kind {
+		case PatternBruteforce:
+		case PatternDictionary:
+			if line := message(lang, "feedback.analysis.dictionary", "token", m.Token); !slices.Contains(words, line) {
+				words = append(words, line)
+			}
+		default:
+			patterns = append(patterns, m)
+		}
+	}
+	return append(words, Result{Patterns: patterns}.FeedbackLocale(lang)...)
+}
+
+// Analyze estimates how many guesses password takes, zxcvbn style. Every matcher, the registered
+// ones when none are given, reports candidate structures; Analyze then finds by dynamic
+// programming the segmentation of the password into matches and bruteforce stretches that needs
+// the fewest guesses. A sequence of n segments costs n! times the product of their guesses, plus
+// 10000 guesses for each segment after the first, so splitting into many small pieces does not
+// pay. Bruteforce stretches are charged the bits per rune of the password's charset, as
+// CharsetEntropy is. Only the first MaxAnalyzeRunes runes are segmented.
+func Analyze(password string, matchers ...Matcher) Analysis {
+	if len(matchers) == 0 {
+		matchers = Matchers()
+	}
+	res := Audit(password, Options{})
+	bitsPerRune := 0.0
+	if res.Length > 0 {
+		bitsPerRune = res.CharsetEntropy / float64(res.Length)
+	}
+	analysis := analyze(password, bitsPerRune, matchers)
+	compromised := len(analysis.Sequence) == 1 && analysis.Sequence[0].Kind == PatternDictionary
+	analysis.Score = scoreOf(analysis.Entropy, res.Length, res.Classes, compromised)
+	analysis.Rating = RatingOf(analysis.Score)
+	analysis.CrackTimes = crackTimesOf(analysis.Entropy)
+	return analysis
+}
+
+// analyzer holds what the guess estimates of one Analyze call share.
+type analyzer struct {
+	matchers    []Matcher
+	bitsPerRune float64 // Bits of a bruteforce rune
+}
+
+// analyze fills in the Guesses, Entropy, and Sequence of an Analysis of password.
+func analyze(password string, bitsPerRune float64, matchers []Matcher) Analysis {
+	if bitsPerRune <= 0 {
+		bitsPerRune = math.Log2(bruteforceCardinality)
+	}
+	a := analyzer{matchers: matchers, bitsPerRune: bitsPerRune}
+	runes := []rune(password)
+	var rest []rune
+	if len(runes) > MaxAnalyzeRunes {
+		runes, rest = runes[:MaxAnalyzeRunes], runes[MaxAnalyzeRunes:]
+	}
+
+	var candidates []Match
+	for _, m := range matchers {
+		for _, match := range m.Match(runes) {
+			if match.Start >= 0 && match.Start < match.End && match.End <= len(runes) {
+				candidates = append(candidates, match)
+			}
+		}
+	}
+	guesses, sequence := a.segment(runes, candidates)
+
+	bits := math.Log2(guesses)
+	if len(rest) > 0 {
+		tail := a.bruteforce(rest, 0, len(rest))
+		tail.Start, tail.End = MaxAnalyzeRunes, MaxAnalyzeRunes+len(rest)
+		sequence = append(sequence, tail)
+		bits += float64(len(rest)) * bitsPerRune
+		guesses = math.Min(math.Exp2(bits), maxGuesses)
+	}
+	return Analysis{Guesses: guesses, Entropy: bits, Sequence: sequence}
+}
+
+// segmentStep is the cheapest way found to cover a prefix of the password with l segments.
+type segmentStep struct {
+	match   Match   // Last segment
+	product float64 // Product of the segments' guesses
+	total   float64 // Guesses of the whole sequence, zero when no sequence was found
+}
+
+// segment returns the fewest guesses of any sequence of candidates and bruteforce stretches
+// covering runes, and that sequence.
+func (a analyzer) segment(runes []rune, candidates []Match) (float64, []Match) {
+	n := len(runes)
+	if n == 0 {
+		return 1, nil
+	}
+	byEnd := make([][]Match, n+1)
+	for _, m := range candidates {
+		m.Guesses = a.guesses(m, n)
+		byEnd[m.End] = append(byEnd[m.End], m)
+	}
+
+	// optimal[k][l] covers runes[:k] with l segments.
+	optimal := make([][]segmentStep, n+1)
+	for k := range optimal {
+		optimal[k] = make([]segmentStep, k+1)
+	}
+	update := func(m Match, l int) {
+		product := m.Guesses
+		if l > 1 {
+			product *= optimal[m.Start][l-1].product
+		}
+		total := math.Min(factorial(l)*product+math.Pow(minSegmentGuesses, float64(l-1)), maxGuesses)
+		// Keep the step only if no sequence with as few segments is as cheap.
+		for _, step := range optimal[m.End][:l+1] {
+			if step.total > 0 && step.total <= total {
+				return
+			}
+		}
+		optimal[m.End][l] = segmentStep{match: m, product: product, total: total}
+	}
+	extend := func(m Match, bruteforce bool) {
+		if m.Start == 0 {
+			update(m, 1)
+			return
+		}
+		for l, step := range optimal[m.Start] {
+			// Adjacent bruteforce stretches are one longer stretch, found on its own.
+			if step.total > 0 && !(bruteforce && step.match.Kind == PatternBruteforce) {
+				update(m, l+1)
+			}
+		}
+	}
+
+	for k := 1; k <= n; k++ {
+		for _, m := range byEnd[k] {
+			extend(m, false)
+		}
+		for i := 0; i < k; i++ {
+			extend(a.bruteforce(runes, i, k), true)
+		}
+	}
+
+	best, bestL := math.Inf(1), 0
+	for l, step := range optimal[n] {
+		if step.total > 0 && step.total < best {
+			best, bestL = step.total, l
+		}
+	}
+	sequence := make([]Match, bestL)
+	for k, l := n, bestL; l > 0; l-- {
+		m := optimal[k][l].match
+		sequence[l-1] = m
+		k = m.Start
+	}
+	return math.Min(best, maxGuesses), sequence
+}
+
+// bruteforce is the bruteforce stretch runes[start:end].
+func (a analyzer) bruteforce(runes []rune, start, end int) Match {
+	guesses := math.Min(math.Exp2(a.bitsPerRune*float64(end-start)), maxGuesses)
+	return Match{Kind: PatternBruteforce, Token: string(runes[start:end]), Start: start, End: end, Guesses: math.Max(guesses, 1)}
+}
+
+// guesses returns the guesses of m within a password of length runes: m.Guesses when its
+// Matcher set them, else an estimate for its Kind, never below the floor for a part of the password.
+func (a analyzer) guesses(m Match, length int) float64 {
+	guesses := m.Guesses
+	if guesses <= 0 {
+		guesses = a.estimate(m)
+	}
+	if m.Len() < length {
+		floor := float64(minMultiRuneGuesses)
+		if m.Len() == 1 {
+			floor = minSingleRuneGuesses
+		}
+		guesses = math.Max(guesses, floor)
+	}
+	return math.Min(math.Max(guesses, 1), maxGuesses)
+}
+
+// estimate estimates the guesses of a Match whose Matcher left Guesses zero.
+func (a analyzer) estimate(m Match) float64 {
+	runes := []rune(m.Token)
+	switch m.Kind {
+	case PatternSequence:
+		if len(runes) == 0 {
+			break
+		}
+		base := 26.0
+		if strings.ContainsRune(sequenceObviousStarts, runes[0]) {
+			base = 4
+		} else if unicode.IsDigit(runes[0]) {
+			base = 10
+		}
+		if len(runes) > 1 && sequenceStep(runes[0], runes[1]) < 0 {
+			base *= 2
+		}
+		return base * float64(len(runes))
+	case PatternRepeat, PatternRepeatedBlock:
+		if base := utf8.RuneCountInString(m.Base); base > 0 {
+			return analyze(m.Base, a.bitsPerRune, a.matchers).Guesses * float64(len(runes)/base)
+		}
+	case PatternKeyboardWalk:
+		return spatialStartingKeys * spatialAverageDegree * float64(max(len(runes)-1, 1)) * uppercaseVariations(m.Token)
+	case PatternYear:
+		return yearSpace(m.Token)
+	case PatternDate:
+		guesses := yearSpace(m.Base[:min(4, len(m.Base))]) * dateDaysPerYear
+		if strings.ContainsAny(m.Token, dateSeparators) {
+			guesses *= dateSeparatorGuesses
+		}
+		return guesses
+	case PatternDictionary:
+		return defaultDictionaryRank * uppercaseVariations(m.Token)
+	}
+	return math.Exp2(a.bitsPerRune * float64(len(runes)))
+}
+
+// yearSpace returns the years an attacker tries before year: its distance from the current year,
+// at least minYearSpace.
+func yearSpace(year string) float64 {
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return minYearSpace
+	}
+	return math.Max(math.Abs(float64(y-time.Now().Year())), minYearSpace)
+}
+
+// uppercaseVariations returns the ways of capitalizing token an attacker tries: one when it is
+// lowercase, two when only its first or last letter or all of them are uppercase, and otherwise
+// every way of choosing that many uppercase letters.
+func uppercaseVariations(token string) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	runes := []rune(token)
+	first, last := unicode.IsUpper(runes[0]), unicode.IsUpper(runes[len(runes)-1])
+	if lower == 0 || (upper == 1 && (first || last)) {
+		return 2
+	}
+	variations := 0.0
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+// factorial returns n!.
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// binomial returns n choose k.
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
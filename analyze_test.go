@@ -0,0 +1,167 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// kinds returns the kinds of the segments of a.
+func kinds(a Analysis) []string {
+	var list []string
+	for _, m := range a.Sequence {
+		list = append(list, m.Kind.String()+":"+m.Token)
+	}
+	return list
+}
+
+func TestAnalyzePicksCheaperSegmentation(t *testing.T) {
+	a := Analyze("passwordpassword")
+	if got := strings.Join(kinds(a), " "); got != "repeated_block:passwordpassword" {
+		t.Fatalf("Sequence = %s, want one repeated block", got)
+	}
+
+	// The other segmentation, the same dictionary word twice, is found by the matchers but costs more.
+	words := Analyze("passwordpassword", NewDictionaryMatcher(CommonPasswords))
+	if got := strings.Join(kinds(words), " "); got != "dictionary:password dictionary:password" {
+		t.Fatalf("Sequence with only the dictionary = %s, want the word twice", got)
+	}
+	if a.Guesses >= words.Guesses {
+		t.Errorf("repeat costs %v guesses, two words %v, want the repeat cheaper", a.Guesses, words.Guesses)
+	}
+	// Twice the 2 guesses of "password" plus one for the repeat, against 2! * 50 * 50 + 10000 for
+	// two words held at the floor of a partial match
+	if a.Guesses != 5 || words.Guesses != 15000 {
+		t.Errorf("Guesses = %v and %v, want 5 and 15000", a.Guesses, words.Guesses)
+	}
+}
+
+func TestAnalyzeSegments(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"", ""},
+		{"password", "dictionary:password"},
+		{"abcdef2024", "sequence:abcdef year:2024"},
+		{"qwertyuiop", "dictionary:qwertyuiop"},
+		{"zxcvbnm,./", "keyboard_walk:zxcvbnm,./"},
+		{"aaaaaaaaaa", "repeat:aaaaaaaaaa"},
+		{"Xq7#mB2vLp9!w4Z", "bruteforce:Xq7#mB2vLp9!w4Z"},
+		{"mike1990-07-04", "bruteforce:mike date:1990-07-04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if got := strings.Join(kinds(Analyze(tt.password)), " "); got != tt.want {
+				t.Errorf("Analyze(%q).Sequence = %s, want %s", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCoversPassword(t *testing.T) {
+	for _, password := range []string{"correcthorsebatterystaple", "Tr0ub4dor&3", "iloveyou2024!!!", "日本語のパスワード123"} {
+		a := Analyze(password)
+		end := 0
+		for _, m := range a.Sequence {
+			if m.Start != end || m.Guesses < 1 {
+				t.Errorf("Analyze(%q) segment %+v does not follow %d", password, m, end)
+			}
+			end = m.End
+		}
+		if n := len([]rune(password)); end != n {
+			t.Errorf("Analyze(%q) covers %d of %d runes", password, end, n)
+		}
+		if math.Abs(a.Entropy-math.Log2(a.Guesses)) > 1e-9 || a.Score != scoreOf(a.Entropy, int64(end), Audit(password, Options{}).Classes, false) {
+			t.Errorf("Analyze(%q) = %+v, want Entropy log2(Guesses) and Score from it", password, a)
+		}
+	}
+}
+
+func TestAnalyzeLongPassword(t *testing.T) {
+	long := strings.Repeat("Xq7#mB2vLp9!w4Z", 10)
+	a := Analyze(long)
+	if math.IsInf(a.Entropy, 0) || math.IsInf(a.Guesses, 0) || a.Guesses > 1e300 {
+		t.Errorf("Analyze() of %d runes = %v guesses, %v bits, want finite values", len(long), a.Guesses, a.Entropy)
+	}
+	if last := a.Sequence[len(a.Sequence)-1]; last.End != len(long) {
+		t.Errorf("last segment ends at %d, want %d", last.End, len(long))
+	}
+}
+
+func TestRegisterMatcher(t *testing.T) {
+	if err := RegisterMatcher("", MatcherFunc(func([]rune) []Match { return nil })); err == nil {
+		t.Error("RegisterMatcher() with no name succeeded")
+	}
+	if err := RegisterMatcher("test", nil); err == nil {
+		t.Error("RegisterMatcher() with a nil matcher succeeded")
+	}
+
+	// A company name is a cheap guess, reported with its own Guesses.
+	company := MatcherFunc(func(runes []rune) []Match {
+		s := strings.ToLower(string(runes))
+		if i := strings.Index(s, "acme"); i >= 0 {
+			start := len([]rune(s[:i]))
+			return []Match{{Kind: "company", Token: string(runes[start : start+4]), Start: start, End: start + 4, Guesses: 1}}
+		}
+		return nil
+	})
+	before := Analyze("Acme-Xq7#mB2v")
+	if err := RegisterMatcher("test-company", company); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		matchersMu.Lock()
+		matchers = matchers[:len(matchers)-1]
+		matchersMu.Unlock()
+	}()
+	if err := RegisterMatcher("test-company", company); err != nil || len(Matchers()) != 6 {
+		t.Fatalf("replacing a matcher left %d matchers, error %v", len(Matchers()), err)
+	}
+	after := Analyze("Acme-Xq7#mB2v")
+	if kinds(after)[0] != "company:Acme" || after.Guesses >= before.Guesses {
+		t.Errorf("Sequence = %v with %v guesses, want the company first and fewer than %v", kinds(after), after.Guesses, before.Guesses)
+	}
+}
+
+func TestAnalyzeFeedback(t *testing.T) {
+	got := Analyze("horse2024").Feedback()
+	want := []string{"Avoid common words and passwords such as 'horse'.", "Avoid years such as '2024'."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Feedback() = %q, want %q", got, want)
+	}
+}
+
+func TestOptionsMatchersCapEntropy(t *testing.T) {
+	plain := Audit("iloveyoupassword", Options{})
+	capped := Audit("iloveyoupassword", Options{Matchers: Matchers()})
+	if capped.EffectiveEntropy >= plain.EffectiveEntropy || capped.EffectiveEntropy != Analyze("iloveyoupassword").Entropy {
+		t.Errorf("EffectiveEntropy = %v with Matchers, %v without, want the Analyze entropy", capped.EffectiveEntropy, plain.EffectiveEntropy)
+	}
+	if capped.Score >= plain.Score {
+		t.Errorf("Score = %d with Matchers, %d without, want lower", capped.Score, plain.Score)
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	password := strings.Repeat("Tr0ub4dor&3", 9)
+	for b.Loop() {
+		Analyze(password)
+	}
+}
@@ -0,0 +1,176 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params are the Argon2id cost parameters encoded with each hash.
+type Argon2Params struct {
+	Memory      uint32 // Memory in KiB
+	Iterations  uint32 // Number of passes over the memory
+	Parallelism uint8  // Number of lanes
+	SaltLength  uint32 // Random salt length in bytes
+	KeyLength   uint32 // Derived key length in bytes
+}
+
+// DefaultArgon2Params follow the OWASP recommendation of 19 MiB, two iterations, and one lane.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Highest Argon2id costs hashing and verification accept. A stored hash above them is rejected
+// with ErrMalformedHash rather than run, so a crafted or corrupted record cannot allocate
+// gigabytes or loop for hours on every login.
+const (
+	MaxArgon2Memory     = 4 << 20 // KiB, 4 GiB
+	MaxArgon2Iterations = 64
+)
+
+// checkCost reports an error when the memory, iterations, or parallelism of p are out of range.
+func (p Argon2Params) checkCost() error {
+	if p.Parallelism < 1 || p.Memory < 8*uint32(p.Parallelism) || p.Memory > MaxArgon2Memory ||
+		p.Iterations < 1 || p.Iterations > MaxArgon2Iterations {
+		return fmt.Errorf("invalid argon2id parameters m=%d, t=%d, p=%d", p.Memory, p.Iterations, p.Parallelism)
+	}
+	return nil
+}
+
+// argon2Prefix starts every encoding produced by HashArgon2id.
+const argon2Prefix = "$argon2id$"
+
+// HashArgon2id derives an Argon2id key from password with a salt from crypto/rand and returns it
+// in the PHC string format, "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>".
+func HashArgon2id(password string, p Argon2Params) (string, error) {
+	if err := p.checkCost(); err != nil {
+		return "", err
+	}
+	if p.SaltLength < 8 || p.KeyLength < 4 {
+		return "", fmt.Errorf("invalid argon2id lengths: salt %d bytes, key %d bytes", p.SaltLength, p.KeyLength)
+	}
+
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	pw := []byte(password)
+	defer wipe(pw)
+	key := argon2.IDKey(pw, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyArgon2id compares password with a PHC-encoded Argon2id hash in constant time. It returns
+// nil on a match, ErrPasswordMismatch when the password is wrong, ErrMalformedHash when encoded
+// cannot be parsed, and ErrUnsupportedVersion for Argon2 versions other than 19.
+func VerifyArgon2id(password, encoded string) error {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+	pw := []byte(password)
+	defer wipe(pw)
+	other := argon2.IDKey(pw, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// NeedsRehash reports whether encoded should be replaced by a fresh HashArgon2id with p. It is
+// true when encoded is not a valid Argon2id hash or was produced with different parameters, so it
+// can be called after every successful login to upgrade stored hashes.
+func NeedsRehash(encoded string, p Argon2Params) bool {
+	current, _, _, err := decodeArgon2id(encoded)
+	return err != nil || current != p
+}
+
+// decodeArgon2id parses a PHC Argon2id string. Parameters other than m, t, and p, such as keyid
+// or data, are ignored.
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	if !strings.HasPrefix(encoded, argon2Prefix) {
+		return p, nil, nil, fmt.Errorf("%w: not an argon2id hash", ErrMalformedHash)
+	}
+	phc, err := ParsePHC(encoded)
+	if err != nil {
+		return p, nil, nil, err
+	}
+	version := phc.Version
+	if version == 0 {
+		version = 0x10 // PHC strings without v= predate version 19
+	}
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("%w: argon2 version %d", ErrUnsupportedVersion, version)
+	}
+
+	var seen int
+	for name, value := range phc.Params {
+		var bits int
+		switch name {
+		case "m", "t":
+			bits = 32
+		case "p":
+			bits = 8
+		default:
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, bits)
+		if err != nil {
+			return p, nil, nil, fmt.Errorf("%w: bad parameter %s=%q", ErrMalformedHash, name, value)
+		}
+		switch name {
+		case "m":
+			p.Memory = uint32(n)
+		case "t":
+			p.Iterations = uint32(n)
+		case "p":
+			p.Parallelism = uint8(n)
+		}
+		seen++
+	}
+	if seen != 3 {
+		return p, nil, nil, fmt.Errorf("%w: want m, t, and p parameters", ErrMalformedHash)
+	}
+	if err := p.checkCost(); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+	p.SaltLength, p.KeyLength = uint32(len(phc.Salt)), uint32(len(phc.Hash))
+	return p, phc.Salt, phc.Hash, nil
+}
+
+// decodePHCBase64 decodes the unpadded standard base64 used by PHC strings, accepting padding.
+func decodePHCBase64(s string) ([]byte, error) {
+	b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, errors.New("invalid base64")
+	}
+	return b, nil
+}
@@ -0,0 +1,188 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Argon2id vectors from the test suite of the reference implementation (phc-winner-argon2).
+var argon2ReferenceVectors = []struct {
+	password string
+	encoded  string
+}{
+	{"password", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+	{"password", "$argon2id$v=19$m=256,t=2,p=1$c29tZXNhbHQ$nf65EOgLrQMR/uIPnA4rEsF5h7TKyQwu9U1bMCHGi/4"},
+	{"password", "$argon2id$v=19$m=256,t=2,p=2$c29tZXNhbHQ$bQk8UB/VmZZF4Oo79iDXuL5/0ttZwg2f/5U52iv1cDc"},
+	{"password", "$argon2id$v=19$m=65536,t=1,p=1$c29tZXNhbHQ$9qWtwbpyPd3vm1rB1GThgPzZ3/ydHL92zKL+15XZypg"},
+	{"differentpassword", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$C4TWUs9rDEvq7w3+J4umqA32aWKB1+DSiRuBfYxFj94"},
+	{"password", "$argon2id$v=19$m=65536,t=2,p=1$ZGlmZnNhbHQ$vfMrBczELrFdWP0ZsfhWsRPaHppYdP3MVEMIVlqoFBw"},
+}
+
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestVerifyArgon2idReferenceVectors(t *testing.T) {
+	for _, tt := range argon2ReferenceVectors {
+		t.Run(tt.encoded, func(t *testing.T) {
+			if err := VerifyArgon2id(tt.password, tt.encoded); err != nil {
+				t.Errorf("VerifyArgon2id() error = %v, want nil", err)
+			}
+			if err := VerifyArgon2id(tt.password+"x", tt.encoded); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("VerifyArgon2id() with wrong password error = %v, want %v", err, ErrPasswordMismatch)
+			}
+		})
+	}
+}
+
+func TestHashArgon2idRoundTrip(t *testing.T) {
+	encoded, err := HashArgon2id("Pässwørd-密码", testArgon2Params)
+	if err != nil {
+		t.Fatalf("HashArgon2id() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("HashArgon2id() = %q, want PHC argon2id encoding", encoded)
+	}
+	if err := VerifyArgon2id("Pässwørd-密码", encoded); err != nil {
+		t.Errorf("VerifyArgon2id() error = %v, want nil", err)
+	}
+
+	again, err := HashArgon2id("Pässwørd-密码", testArgon2Params)
+	if err != nil {
+		t.Fatalf("HashArgon2id() error = %v", err)
+	}
+	if again == encoded {
+		t.Error("HashArgon2id() returned the same encoding twice, want a fresh salt")
+	}
+}
+
+func TestHashArgon2idInvalidParams(t *testing.T) {
+	for _, p := range []Argon2Params{
+		{},
+		{Memory: 64, Iterations: 0, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+		{Memory: 8, Iterations: 1, Parallelism: 4, SaltLength: 16, KeyLength: 32},
+		{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 4, KeyLength: 32},
+	} {
+		if _, err := HashArgon2id("password", p); err == nil {
+			t.Errorf("HashArgon2id(%+v) error = nil, want error", p)
+		}
+	}
+}
+
+func TestVerifyArgon2idUnknownParameters(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=2,p=1,keyid=abc,data=ZGF0YQ$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+	if err := VerifyArgon2id("password", encoded); err != nil {
+		t.Errorf("VerifyArgon2id() error = %v, want nil", err)
+	}
+	reordered := "$argon2id$v=19$p=1,t=2,m=65536$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+	if err := VerifyArgon2id("password", reordered); err != nil {
+		t.Errorf("VerifyArgon2id() reordered error = %v, want nil", err)
+	}
+}
+
+func TestVerifyArgon2idMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    error
+	}{
+		{"Empty", "", ErrMalformedHash},
+		{"bcrypt", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", ErrMalformedHash},
+		{"Argon2i", "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"Old version", "$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrUnsupportedVersion},
+		{"No version", "$argon2id$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrUnsupportedVersion},
+		{"Missing hash", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ", ErrMalformedHash},
+		{"Missing parameter", "$argon2id$v=19$m=65536,t=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"Parallelism overflow", "$argon2id$v=19$m=65536,t=2,p=256$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"Memory over the limit", "$argon2id$v=19$m=4194305,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"Memory at uint32 max", "$argon2id$v=19$m=4294967295,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"Iterations over the limit", "$argon2id$v=19$m=65536,t=65,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"Iterations at uint32 max", "$argon2id$v=19$m=65536,t=4294967295,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+		{"Bad base64", "$argon2id$v=19$m=65536,t=2,p=1$c29t!!$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", ErrMalformedHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyArgon2id("password", tt.encoded); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyArgon2id() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgon2idCostLimits(t *testing.T) {
+	// Decoding alone checks the limits, so the boundary can be tested without running Argon2.
+	for _, tt := range []struct {
+		params string
+		ok     bool
+	}{
+		{"m=4194304,t=64,p=1", true},
+		{"m=4194305,t=64,p=1", false},
+		{"m=4194304,t=65,p=1", false},
+	} {
+		_, _, _, err := decodeArgon2id("$argon2id$v=19$" + tt.params + "$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+		if (err == nil) != tt.ok || (err != nil && !errors.Is(err, ErrMalformedHash)) {
+			t.Errorf("decodeArgon2id(%s) error = %v, want ok %v", tt.params, err, tt.ok)
+		}
+	}
+
+	over := testArgon2Params
+	over.Iterations = MaxArgon2Iterations + 1
+	if _, err := HashArgon2id("password", over); err == nil {
+		t.Errorf("HashArgon2id() with t=%d succeeded", over.Iterations)
+	}
+	over = testArgon2Params
+	over.Memory = MaxArgon2Memory + 1
+	if _, err := DeriveKeyWithParams([]byte("password"), make([]byte, MinSaltBytes), "files", 32, over); err == nil {
+		t.Errorf("DeriveKeyWithParams() with m=%d succeeded", over.Memory)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	encoded, err := HashArgon2id("password", testArgon2Params)
+	if err != nil {
+		t.Fatalf("HashArgon2id() error = %v", err)
+	}
+
+	stronger := testArgon2Params
+	stronger.Iterations = 3
+	tests := []struct {
+		name    string
+		encoded string
+		params  Argon2Params
+		want    bool
+	}{
+		{"Same parameters", encoded, testArgon2Params, false},
+		{"More iterations", encoded, stronger, true},
+		{"Reference vector", argon2ReferenceVectors[0].encoded, DefaultArgon2Params, true},
+		{"Not argon2id", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", DefaultArgon2Params, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsRehash(tt.encoded, tt.params); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkHashArgon2id(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = HashArgon2id("correct horse battery staple", DefaultArgon2Params)
+	}
+}
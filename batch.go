@@ -0,0 +1,140 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// AuditAll audits passwords against opts on a pool of workers goroutines, GOMAXPROCS when zero or
+// negative. Results are in the same order as passwords. When ctx is done before every password is
+// audited, AuditAll returns the results of the leading passwords it finished with ctx.Err(). ctx
+// also bounds the breach and history checks of each audit. Options that fail Validate return the
+// validation error and no results.
+func AuditAll(ctx context.Context, passwords []string, opts Options, workers int) ([]Result, error) {
+	required, forbidden, err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+	v := newValidator(opts, required, forbidden)
+	return v.AuditAll(ctx, passwords, workers)
+}
+
+// AuditAll is like the package-level AuditAll but audits against the Validator's Options.
+func (v *Validator) AuditAll(ctx context.Context, passwords []string, workers int) ([]Result, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(passwords))
+	results := make([]Result, len(passwords))
+
+	// Workers claim indices in order and finish each claimed audit, so every index below next is
+	// done once they return.
+	var (
+		wg   sync.WaitGroup
+		next atomic.Int64
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= len(passwords) {
+					return
+				}
+				results[i] = v.auditContext(ctx, passwords[i])
+				if v.opts.Report != nil {
+					v.opts.Report.Add(results[i])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if done := int(next.Load()); done < len(passwords) {
+		return results[:done], ctx.Err()
+	}
+	return results, nil
+}
+
+// AuditReader audits newline-delimited passwords read from r and calls fn with the line number,
+// starting at 1, the password, and its Result. Lines may be any length and end in "\n" or "\r\n",
+// and a final line without a newline is audited too. It streams r with a reused buffer and stops
+// at the first error from fn, ctx, or r, returning that error. fn may be nil when only
+// Options.Report is wanted. Options that fail Validate return the validation error before
+// anything is read.
+func AuditReader(ctx context.Context, r io.Reader, opts Options, fn func(line int, password string, res Result) error) error {
+	required, forbidden, err := opts.validate()
+	if err != nil {
+		return err
+	}
+	v := newValidator(opts, required, forbidden)
+	return v.AuditReader(ctx, r, fn)
+}
+
+// AuditReader is like the package-level AuditReader but audits against the Validator's Options.
+func (v *Validator) AuditReader(ctx context.Context, r io.Reader, fn func(line int, password string, res Result) error) error {
+	br := bufio.NewReader(r)
+	var buf []byte
+	defer func() { wipe(buf) }()
+
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// ReadSlice returns at most a buffer at a time, so longer lines are collected in buf.
+		buf = buf[:0]
+		var err error
+		for {
+			var chunk []byte
+			chunk, err = br.ReadSlice('\n')
+			buf = append(buf, chunk...)
+			if !errors.Is(err, bufio.ErrBufferFull) {
+				break
+			}
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF && len(buf) == 0 {
+			return nil
+		}
+
+		password := string(bytes.TrimSuffix(bytes.TrimSuffix(buf, []byte("\n")), []byte("\r")))
+		res := v.auditContext(ctx, password)
+		if v.opts.Report != nil {
+			v.opts.Report.Add(res)
+		}
+		if fn != nil {
+			if ferr := fn(line, password, res); ferr != nil {
+				return ferr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syntheticPasswords returns n deterministic passwords mixing weak and strong shapes.
+func syntheticPasswords(n int) []string {
+	shapes := []string{"password%d", "Tr0ub4dor&%d", "qwerty%d", "%d-Correct-Horse-Battery", "abc%dXYZ!"}
+	passwords := make([]string, n)
+	for i := range passwords {
+		passwords[i] = fmt.Sprintf(shapes[i%len(shapes)], i)
+	}
+	return passwords
+}
+
+var batchOptions = Options{
+	MinLength:         10,
+	UseDigits:         true,
+	UseLower:          true,
+	RejectCommon:      true,
+	MaxSequenceLength: 3,
+	MaxRepeatRun:      3,
+}
+
+func TestAuditAll(t *testing.T) {
+	passwords := syntheticPasswords(2000)
+
+	for _, workers := range []int{0, 1, 3, 64, 5000} {
+		t.Run(strconv.Itoa(workers), func(t *testing.T) {
+			results, err := AuditAll(context.Background(), passwords, batchOptions, workers)
+			if err != nil {
+				t.Fatalf("AuditAll() error = %v", err)
+			}
+			if len(results) != len(passwords) {
+				t.Fatalf("len(results) = %d, want %d", len(results), len(passwords))
+			}
+			for i, pass := range passwords {
+				want := Audit(pass, batchOptions)
+				if results[i].Entropy != want.Entropy || results[i].Score != want.Score || fmt.Sprint(results[i].Err) != fmt.Sprint(want.Err) {
+					t.Fatalf("results[%d] for %q = %+v, want %+v", i, pass, results[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditAllEmpty(t *testing.T) {
+	results, err := AuditAll(context.Background(), nil, Options{}, 0)
+	if err != nil || len(results) != 0 {
+		t.Errorf("AuditAll(nil) = %v, %v, want no results", results, err)
+	}
+}
+
+func TestAuditAllInvalidOptions(t *testing.T) {
+	options := Options{MinLength: 16, MaxLength: 8}
+	if results, err := AuditAll(context.Background(), []string{"password"}, options, 0); !errors.Is(err, ErrInvalidOptions) || results != nil {
+		t.Errorf("AuditAll() = %v, %v, want %v", results, err, ErrInvalidOptions)
+	}
+	err := AuditReader(context.Background(), strings.NewReader("password\n"), options, func(int, string, Result) error {
+		t.Error("AuditReader() called fn with invalid options")
+		return nil
+	})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("AuditReader() error = %v, want %v", err, ErrInvalidOptions)
+	}
+}
+
+// cancellingChecker cancels a context after it has been consulted a number of times.
+type cancellingChecker struct {
+	calls  atomic.Int64
+	after  int64
+	cancel context.CancelFunc
+}
+
+func (c *cancellingChecker) Contains(string) bool {
+	if c.calls.Add(1) == c.after {
+		c.cancel()
+	}
+	return false
+}
+
+func TestAuditAllCancel(t *testing.T) {
+	passwords := syntheticPasswords(10000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	checker := &cancellingChecker{after: 100, cancel: cancel}
+	options := Options{Dictionary: checker}
+
+	results, err := AuditAll(ctx, passwords, options, 4)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("AuditAll() error = %v, want %v", err, context.Canceled)
+	}
+	if len(results) == 0 || len(results) >= len(passwords) {
+		t.Fatalf("len(results) = %d, want partial results", len(results))
+	}
+	for i, result := range results {
+		if result.Length != int64(len([]rune(passwords[i]))) {
+			t.Fatalf("results[%d] was not audited: %+v", i, result)
+		}
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	results, err = AuditAll(ctx, passwords, Options{}, 0)
+	if !errors.Is(err, context.Canceled) || len(results) != 0 {
+		t.Errorf("AuditAll() with a done context = %d results, %v, want none and %v", len(results), err, context.Canceled)
+	}
+}
+
+// blockingChecker answers no breach lookup until its context is done.
+func blockingChecker(ctx context.Context, _ string) (bool, int, error) {
+	<-ctx.Done()
+	return false, 0, ctx.Err()
+}
+
+func TestBatchCancelsBreachChecks(t *testing.T) {
+	options := Options{BreachChecker: BreachCheckerFunc(blockingChecker)}
+	tests := []struct {
+		name  string
+		audit func(ctx context.Context) error
+	}{
+		{"AuditAll", func(ctx context.Context) error {
+			_, err := AuditAll(ctx, []string{"Xq7#mB2vLp9!", "Correct-Horse-9"}, options, 1)
+			return err
+		}},
+		{"AuditReader", func(ctx context.Context) error {
+			return AuditReader(ctx, strings.NewReader("Xq7#mB2vLp9!\nCorrect-Horse-9\n"), options, nil)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() { done <- tt.audit(ctx) }()
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("%s() error = %v, want %v", tt.name, err, context.DeadlineExceeded)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s() still blocked in the breach check after its context was done", tt.name)
+			}
+		})
+	}
+}
+
+func TestAuditReader(t *testing.T) {
+	long := strings.Repeat("Ab1!", 1500) // longer than bufio's default buffer
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "LF", input: "alpha\nbravo\n", want: []string{"alpha", "bravo"}},
+		{name: "CRLF", input: "alpha\r\nbravo\r\n", want: []string{"alpha", "bravo"}},
+		{name: "No trailing newline", input: "alpha\nbravo", want: []string{"alpha", "bravo"}},
+		{name: "Blank lines", input: "alpha\n\nbravo\n", want: []string{"alpha", "", "bravo"}},
+		{name: "Lone carriage return kept", input: "al\rpha\n", want: []string{"al\rpha"}},
+		{name: "Long line", input: "alpha\n" + long + "\r\nbravo", want: []string{"alpha", long, "bravo"}},
+		{name: "Empty", input: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := AuditReader(context.Background(), strings.NewReader(tt.input), batchOptions, func(line int, password string, res Result) error {
+				if line != len(got)+1 {
+					t.Errorf("line = %d, want %d", line, len(got)+1)
+				}
+				if want := Audit(password, batchOptions); res.Entropy != want.Entropy || fmt.Sprint(res.Err) != fmt.Sprint(want.Err) {
+					t.Errorf("result for %q = %+v, want %+v", password, res, want)
+				}
+				got = append(got, password)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("AuditReader() error = %v", err)
+			}
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("AuditReader() passwords = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditReaderStops(t *testing.T) {
+	errStop := errors.New("stop")
+	input := strings.Repeat("password\n", 100)
+
+	lines := 0
+	err := AuditReader(context.Background(), strings.NewReader(input), Options{}, func(line int, password string, res Result) error {
+		lines = line
+		if line == 10 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || lines != 10 {
+		t.Errorf("AuditReader() stopped at line %d with %v, want line 10 and %v", lines, err, errStop)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lines = 0
+	err = AuditReader(ctx, strings.NewReader(input), Options{}, func(line int, password string, res Result) error {
+		lines = line
+		if line == 5 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || lines != 5 {
+		t.Errorf("AuditReader() stopped at line %d with %v, want line 5 and %v", lines, err, context.Canceled)
+	}
+
+	errRead := errors.New("read failed")
+	err = AuditReader(context.Background(), io.MultiReader(strings.NewReader("alpha\n"), &failingReader{err: errRead}), Options{}, func(int, string, Result) error { return nil })
+	if !errors.Is(err, errRead) {
+		t.Errorf("AuditReader() error = %v, want %v", err, errRead)
+	}
+}
+
+// lineSource generates n newline-terminated passwords without holding them in memory.
+type lineSource struct {
+	n, i    int
+	pending []byte
+}
+
+func (s *lineSource) Read(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		if len(s.pending) == 0 {
+			if s.i == s.n {
+				if written == 0 {
+					return 0, io.EOF
+				}
+				break
+			}
+			s.pending = strconv.AppendInt(append(s.pending[:0], "Passw0rd-"...), int64(s.i), 10)
+			s.pending = append(s.pending, '\r', '\n')
+			s.i++
+		}
+		n := copy(p[written:], s.pending)
+		s.pending = s.pending[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func TestAuditReaderConstantMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("audits a million lines")
+	}
+	const lines = 1000000
+
+	var before, peak runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	count := 0
+	err := AuditReader(context.Background(), &lineSource{n: lines}, Options{MinLength: 8}, func(line int, password string, res Result) error {
+		count++
+		if line%100000 == 0 {
+			runtime.GC()
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			if stats.HeapInuse > peak.HeapInuse {
+				peak = stats
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("AuditReader() error = %v", err)
+	}
+	if count != lines {
+		t.Fatalf("AuditReader() read %d lines, want %d", count, lines)
+	}
+	// The input is about 17 MB; streaming it should keep the live heap far below that.
+	if grown := int64(peak.HeapInuse) - int64(before.HeapInuse); grown > 4<<20 {
+		t.Errorf("heap grew by %d bytes while streaming %d lines", grown, lines)
+	}
+}
+
+func BenchmarkAuditAll(b *testing.B) {
+	passwords := syntheticPasswords(100000)
+	v, err := NewValidator(batchOptions)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, pass := range passwords {
+				_ = v.Audit(pass)
+			}
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = v.AuditAll(context.Background(), passwords, 0)
+		}
+	})
+}
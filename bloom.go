@@ -0,0 +1,232 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+)
+
+// bloomMagic starts every serialized BloomChecker, followed by bloomVersion.
+const bloomMagic = "GPBF"
+
+// bloomVersion is the serialization format BloomChecker writes. Version 1 is the magic, a version
+// byte, the number of hash functions as a uint32, the number of bits and of entries as uint64s,
+// and the bits as uint64 words, all little-endian.
+const bloomVersion = 1
+
+// maxBloomBits bounds the filter size, 16 GiB of bits. NewBloomFromReader grows a filter as its
+// bits arrive, bloomReadChunk bytes at a time, so a corrupt header over a short stream cannot
+// demand that much memory up front.
+const (
+	maxBloomBits   = 1 << 37
+	bloomReadChunk = 1 << 20
+)
+
+// maxBloomHashes bounds the hash functions per lookup, enough for a false-positive rate of
+// 2^-64, so a corrupt header cannot make every IsBreached loop billions of times.
+const maxBloomHashes = 64
+
+// BloomChecker is a BreachChecker backed by a Bloom filter of SHA-1 password hashes, for
+// deployments that cannot reach Have I Been Pwned. It never misses a password in the corpus it
+// was built from, and wrongly reports others as breached at about the false-positive rate it was
+// built for. A filter has no counts, so IsBreached reports a count of 1. Build one with BuildBloom,
+// save it with WriteTo, and load it with NewBloomFromReader; it is safe for concurrent use once
+// built.
+type BloomChecker struct {
+	bits    []uint64
+	m       uint64 // Number of bits
+	k       uint32 // Number of hash functions
+	entries uint64 // Hashes added
+}
+
+// BuildBloom streams the SHA-1 hashes in hashes into a new BloomChecker sized for n entries at
+// false-positive rate fpRate. It reads the Have I Been Pwned ordered-by-hash dump, one
+// "HASH:count" line at a time, and accepts lines without a count; only the filter is held in
+// memory. Adding far more than n hashes raises the false-positive rate.
+func BuildBloom(hashes io.Reader, n uint64, fpRate float64) (*BloomChecker, error) {
+	b, err := newBloom(n, fpRate)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(hashes)
+	var sum [sha1.Size]byte
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		hash, _, _ := bytes.Cut(text, []byte(":"))
+		if len(hash) != hex.EncodedLen(sha1.Size) {
+			return nil, fmt.Errorf("line %d: %q is not a SHA-1 hash", line, hash)
+		}
+		if _, err := hex.Decode(sum[:], hash); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		b.add(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// newBloom returns an empty filter with the optimal number of bits and hash functions for n
+// entries at false-positive rate fpRate.
+func newBloom(n uint64, fpRate float64) (*BloomChecker, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("bloom filter needs a positive number of entries")
+	}
+	if !(fpRate > 0 && fpRate < 1) {
+		return nil, fmt.Errorf("bloom filter false-positive rate %v is outside 0 to 1", fpRate)
+	}
+	m := math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	if m > maxBloomBits {
+		return nil, fmt.Errorf("bloom filter for %d entries at rate %v needs %.0f bits, maximum is %d", n, fpRate, m, uint64(maxBloomBits))
+	}
+	bits := (uint64(m) + 63) / 64 * 64
+	k := min(max(1, uint32(math.Round(float64(bits)/float64(n)*math.Ln2))), maxBloomHashes)
+	return &BloomChecker{bits: make([]uint64, bits/64), m: bits, k: k}, nil
+}
+
+// indexes calls f with the bit index of each hash function for sum. The SHA-1 digest is already
+// uniform, so its first two words seed the double hashing of Kirsch and Mitzenmacher.
+func (b *BloomChecker) indexes(sum [sha1.Size]byte, f func(uint64) bool) {
+	h1 := binary.LittleEndian.Uint64(sum[0:8])
+	h2 := binary.LittleEndian.Uint64(sum[8:16]) | 1
+	for i := uint64(0); i < uint64(b.k); i++ {
+		if !f((h1 + i*h2) % b.m) {
+			return
+		}
+	}
+}
+
+// add sets the bits of sum.
+func (b *BloomChecker) add(sum [sha1.Size]byte) {
+	b.indexes(sum, func(i uint64) bool {
+		b.bits[i/64] |= 1 << (i % 64)
+		return true
+	})
+	b.entries++
+}
+
+// contains reports whether every bit of sum is set.
+func (b *BloomChecker) contains(sum [sha1.Size]byte) bool {
+	found := true
+	b.indexes(sum, func(i uint64) bool {
+		found = b.bits[i/64]&(1<<(i%64)) != 0
+		return found
+	})
+	return found
+}
+
+// IsBreached implements BreachChecker. It reports a count of 1 for a password the filter holds,
+// since the filter keeps no counts.
+func (b *BloomChecker) IsBreached(ctx context.Context, password string) (bool, int, error) {
+	if err := ctx.Err(); err != nil {
+		return false, 0, err
+	}
+	p := []byte(password)
+	sum := sha1.Sum(p)
+	wipe(p)
+	if b.contains(sum) {
+		return true, 1, nil
+	}
+	return false, 0, nil
+}
+
+// Len returns the number of hashes added to the filter.
+func (b *BloomChecker) Len() uint64 {
+	return b.entries
+}
+
+// FalsePositiveRate estimates the rate at which the filter reports passwords it does not hold
+// as breached, from its size and the number of hashes added.
+func (b *BloomChecker) FalsePositiveRate() float64 {
+	return math.Pow(1-math.Exp(-float64(b.k)*float64(b.entries)/float64(b.m)), float64(b.k))
+}
+
+// WriteTo writes the filter to w in the versioned format NewBloomFromReader reads.
+func (b *BloomChecker) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var header [len(bloomMagic) + 1 + 4 + 8 + 8]byte
+	copy(header[:], bloomMagic)
+	header[4] = bloomVersion
+	binary.LittleEndian.PutUint32(header[5:], b.k)
+	binary.LittleEndian.PutUint64(header[9:], b.m)
+	binary.LittleEndian.PutUint64(header[17:], b.entries)
+	written, err := bw.Write(header[:])
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+	var word [8]byte
+	for _, bits := range b.bits {
+		binary.LittleEndian.PutUint64(word[:], bits)
+		written, err := bw.Write(word[:])
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, bw.Flush()
+}
+
+// NewBloomFromReader loads a filter written by BloomChecker.WriteTo. It fails with
+// ErrMalformedBloom for data that is not a filter and ErrUnsupportedBloomVersion for a format
+// this release cannot read.
+func NewBloomFromReader(r io.Reader) (*BloomChecker, error) {
+	br := bufio.NewReader(r)
+	var header [len(bloomMagic) + 1 + 4 + 8 + 8]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformedBloom, err)
+	}
+	if string(header[:4]) != bloomMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrMalformedBloom, header[:4])
+	}
+	if header[4] != bloomVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedBloomVersion, header[4])
+	}
+	b := &BloomChecker{
+		k:       binary.LittleEndian.Uint32(header[5:]),
+		m:       binary.LittleEndian.Uint64(header[9:]),
+		entries: binary.LittleEndian.Uint64(header[17:]),
+	}
+	if b.k == 0 || b.k > maxBloomHashes || b.m == 0 || b.m%64 != 0 || b.m > maxBloomBits {
+		return nil, fmt.Errorf("%w: %d hash functions over %d bits", ErrMalformedBloom, b.k, b.m)
+	}
+	words := b.m / 64
+	b.bits = make([]uint64, 0, min(words, bloomReadChunk/8))
+	chunk := make([]byte, min(words*8, bloomReadChunk))
+	for uint64(len(b.bits)) < words {
+		n := min(words-uint64(len(b.bits)), bloomReadChunk/8)
+		if _, err := io.ReadFull(br, chunk[:n*8]); err != nil {
+			return nil, fmt.Errorf("%w: bits: %v", ErrMalformedBloom, err)
+		}
+		for i := uint64(0); i < n; i++ {
+			b.bits = append(b.bits, binary.LittleEndian.Uint64(chunk[i*8:]))
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,189 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// hibpLine returns the line of the Have I Been Pwned dump for password.
+func hibpLine(password string, count int) string {
+	return fmt.Sprintf("%X:%d\r\n", sha1.Sum([]byte(password)), count)
+}
+
+func TestBloomBuildThenQuery(t *testing.T) {
+	breached := []string{"password", "123456", "qwerty", "Tr0ub4dor&3", "пароль"}
+	var dump strings.Builder
+	for i, password := range breached {
+		dump.WriteString(hibpLine(password, 1000-i))
+	}
+	dump.WriteString(fmt.Sprintf("%x\n\n", sha1.Sum([]byte("lowercase-without-count"))))
+	breached = append(breached, "lowercase-without-count")
+
+	built, err := BuildBloom(strings.NewReader(dump.String()), 100, 1e-6)
+	if err != nil {
+		t.Fatalf("BuildBloom() error = %v", err)
+	}
+	if built.Len() != uint64(len(breached)) {
+		t.Errorf("Len() = %d, want %d", built.Len(), len(breached))
+	}
+
+	var buf bytes.Buffer
+	n, err := built.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo() = %d, %v, want %d bytes", n, err, buf.Len())
+	}
+	loaded, err := NewBloomFromReader(&buf)
+	if err != nil {
+		t.Fatalf("NewBloomFromReader() error = %v", err)
+	}
+
+	for _, b := range []*BloomChecker{built, loaded} {
+		for _, password := range breached {
+			if ok, count, err := b.IsBreached(context.Background(), password); !ok || count != 1 || err != nil {
+				t.Errorf("IsBreached(%q) = %v, %d, %v, want true, 1, nil", password, ok, count, err)
+			}
+		}
+		if ok, _, _ := b.IsBreached(context.Background(), "correct horse battery staple"); ok {
+			t.Errorf("IsBreached() = true for a password outside the filter")
+		}
+	}
+
+	result := Audit("qwerty", Options{BreachChecker: loaded})
+	if !errors.Is(result.Err, ErrPwnedPassword) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrPwnedPassword)
+	}
+}
+
+func TestBloomFalsePositiveRate(t *testing.T) {
+	const n = 10000
+	for _, rate := range []float64{0.01, 0.001} {
+		t.Run(fmt.Sprint(rate), func(t *testing.T) {
+			var dump strings.Builder
+			for i := range n {
+				dump.WriteString(hibpLine(fmt.Sprintf("breached-%d", i), 1))
+			}
+			b, err := BuildBloom(strings.NewReader(dump.String()), n, rate)
+			if err != nil {
+				t.Fatalf("BuildBloom() error = %v", err)
+			}
+			if estimate := b.FalsePositiveRate(); estimate > rate*1.01 {
+				t.Errorf("FalsePositiveRate() = %v, want at most %v", estimate, rate)
+			}
+
+			const trials = 100000
+			falsePositives := 0
+			for i := range trials {
+				if ok, _, _ := b.IsBreached(context.Background(), fmt.Sprintf("unseen-%d", i)); ok {
+					falsePositives++
+				}
+			}
+			if observed := float64(falsePositives) / trials; observed > 2*rate {
+				t.Errorf("observed false-positive rate %v, want at most %v", observed, 2*rate)
+			}
+		})
+	}
+}
+
+func TestBuildBloomErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		dump   string
+		n      uint64
+		fpRate float64
+	}{
+		{"No entries", "", 0, 0.01},
+		{"Rate of zero", "", 10, 0},
+		{"Rate of one", "", 10, 1},
+		{"Truncated hash", "7C4A8D09CA3762AF61E59520943DC26494F8941:1\n", 10, 0.01},
+		{"Not hexadecimal", "ZZ4A8D09CA3762AF61E59520943DC26494F8941B:1\n", 10, 0.01},
+		{"Too large", "", 1 << 40, 1e-9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildBloom(strings.NewReader(tt.dump), tt.n, tt.fpRate); err == nil {
+				t.Errorf("BuildBloom() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestNewBloomFromReaderErrors(t *testing.T) {
+	b, err := BuildBloom(strings.NewReader(hibpLine("password", 1)), 10, 0.01)
+	if err != nil {
+		t.Fatalf("BuildBloom() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	valid := buf.Bytes()
+
+	corrupt := func(i int, c byte) []byte {
+		data := bytes.Clone(valid)
+		data[i] = c
+		return data
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"Empty", nil, ErrMalformedBloom},
+		{"Bad magic", corrupt(0, 'X'), ErrMalformedBloom},
+		{"Future version", corrupt(4, bloomVersion+1), ErrUnsupportedBloomVersion},
+		{"No hash functions", bytes.Join([][]byte{valid[:5], make([]byte, 4), valid[9:]}, nil), ErrMalformedBloom},
+		{"Too many hash functions", bytes.Join([][]byte{valid[:5], binary.LittleEndian.AppendUint32(nil, maxBloomHashes+1), valid[9:]}, nil), ErrMalformedBloom},
+		{"Billions of hash functions", bytes.Join([][]byte{valid[:5], {0xff, 0xff, 0xff, 0xff}, valid[9:]}, nil), ErrMalformedBloom},
+		{"Truncated bits", valid[:len(valid)-1], ErrMalformedBloom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBloomFromReader(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("NewBloomFromReader() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBloomFromReaderShortStream(t *testing.T) {
+	// A header claiming the largest filter, followed by a single word of bits.
+	header := append([]byte(bloomMagic), bloomVersion)
+	header = binary.LittleEndian.AppendUint32(header, 7)
+	header = binary.LittleEndian.AppendUint64(header, maxBloomBits)
+	header = binary.LittleEndian.AppendUint64(header, 1)
+	data := append(header, make([]byte, 8)...)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := NewBloomFromReader(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrMalformedBloom) {
+		t.Errorf("NewBloomFromReader() error = %v, want %v", err, ErrMalformedBloom)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+		t.Errorf("NewBloomFromReader() allocated %d bytes for a %d-byte stream", allocated, len(data))
+	}
+}
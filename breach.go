@@ -0,0 +1,123 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BreachCheckMode is how Audit treats the BreachChecker, and what happens when it errors or does
+// not answer within Options.BreachCheckTimeout.
+type BreachCheckMode int
+
+const (
+	BreachEnforce  BreachCheckMode = iota // Reject the password with ErrBreachCheckUnavailable (fail closed)
+	BreachAdvisory                        // Accept the password and record the failure in Result.Warnings (fail open)
+	BreachSkip                            // Do not consult the BreachChecker at all
+)
+
+var breachModeNames = [...]string{"enforce", "advisory", "skip"}
+
+// String returns the lowercase name of the mode, such as "advisory".
+func (m BreachCheckMode) String() string {
+	if m < BreachEnforce || m > BreachSkip {
+		return fmt.Sprintf("BreachCheckMode(%d)", int(m))
+	}
+	return breachModeNames[m]
+}
+
+// MarshalText encodes the mode by name, so it appears as "advisory" in JSON.
+func (m BreachCheckMode) MarshalText() ([]byte, error) {
+	if m < BreachEnforce || m > BreachSkip {
+		return nil, fmt.Errorf("unknown breach check mode %d", int(m))
+	}
+	return []byte(breachModeNames[m]), nil
+}
+
+// UnmarshalText decodes a mode written by MarshalText.
+func (m *BreachCheckMode) UnmarshalText(text []byte) error {
+	for i, name := range breachModeNames {
+		if name == string(text) {
+			*m = BreachCheckMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown breach check mode %q", text)
+}
+
+// breachMode returns the BreachCheckMode Audit applies, honouring the deprecated BreachFailOpen.
+func (opts *Options) breachMode() BreachCheckMode {
+	if opts.BreachCheckMode == BreachEnforce && opts.BreachFailOpen {
+		return BreachAdvisory
+	}
+	return opts.BreachCheckMode
+}
+
+// breachOutcome is what consulting a BreachChecker about a password found.
+type breachOutcome struct {
+	breached bool
+	count    int
+	pattern  Match // The confusable skeleton or leet spelling that was breached, if not the password
+	err      error
+}
+
+// checkBreach asks checker about pass and, unless it is breached, its confusable skeleton and,
+// with leet set, its decoded spellings. With a positive timeout the lookups share one deadline
+// and checkBreach returns once it passes, even when checker ignores its context; the abandoned
+// lookup finishes in the background.
+func checkBreach(ctx context.Context, checker BreachChecker, timeout time.Duration, pass, skeleton string, leet bool) breachOutcome {
+	if timeout <= 0 {
+		return breachLookups(ctx, checker, pass, skeleton, leet)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan breachOutcome, 1)
+	go func() {
+		done <- breachLookups(ctx, checker, pass, skeleton, leet)
+	}()
+	select {
+	case out := <-done:
+		return out
+	case <-ctx.Done():
+		select {
+		case out := <-done:
+			return out
+		default:
+			return breachOutcome{err: fmt.Errorf("no answer within %v: %w", timeout, ctx.Err())}
+		}
+	}
+}
+
+// breachLookups runs the lookups of checkBreach one after another.
+func breachLookups(ctx context.Context, checker BreachChecker, pass, skeleton string, leet bool) breachOutcome {
+	breached, count, err := checker.IsBreached(ctx, pass)
+	if err != nil || breached {
+		return breachOutcome{breached: breached, count: count, err: err}
+	}
+	if skeleton != "" {
+		if breached, count, err = checker.IsBreached(ctx, skeleton); err != nil || breached {
+			return breachOutcome{breached: breached, count: count, pattern: confusablePattern(pass, skeleton), err: err}
+		}
+	}
+	if leet {
+		p, count, err := leetBreachMatch(ctx, checker, pass)
+		return breachOutcome{breached: p.Kind != "", count: count, pattern: p, err: err}
+	}
+	return breachOutcome{}
+}
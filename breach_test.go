@@ -0,0 +1,162 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSlowPwnedServer returns a range API that answers only after delay, counting its requests.
+func newSlowPwnedServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-time.After(delay):
+		case <-release:
+		}
+		w.Write([]byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n"))
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return server, &requests
+}
+
+func TestAuditBreachCheckModes(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	slow, _ := newSlowPwnedServer(t, time.Minute)
+	fast, _ := newSlowPwnedServer(t, 0)
+
+	tests := []struct {
+		name        string
+		server      *httptest.Server
+		mode        BreachCheckMode
+		wantErr     bool
+		wantWarning bool
+		wantChecked bool
+	}{
+		{"Enforce rejects on timeout", slow, BreachEnforce, true, false, false},
+		{"Advisory warns on timeout", slow, BreachAdvisory, false, true, false},
+		{"Skip never waits", slow, BreachSkip, false, false, false},
+		{"Enforce accepts an unseen password", fast, BreachEnforce, false, false, true},
+		{"Advisory accepts an unseen password", fast, BreachAdvisory, false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{
+				BreachChecker:      &PwnedClient{BaseURL: tt.server.URL + "/"},
+				BreachCheckMode:    tt.mode,
+				BreachCheckTimeout: timeout,
+			}
+			start := time.Now()
+			result := Audit("Xq7#mB2vLp9!", opts)
+			if elapsed := time.Since(start); elapsed > 10*timeout {
+				t.Errorf("Audit() took %v, want about the %v timeout", elapsed, timeout)
+			}
+			if got := errors.Is(result.Err, ErrBreachCheckUnavailable); got != tt.wantErr || (result.Err != nil) != tt.wantErr {
+				t.Errorf("Audit() error = %v, want ErrBreachCheckUnavailable %v", result.Err, tt.wantErr)
+			}
+			var warning *Warning
+			gotWarning := len(result.Warnings) == 1 && errors.As(result.Warnings[0], &warning) &&
+				errors.Is(warning, ErrBreachCheckUnavailable) && errors.Is(warning, context.DeadlineExceeded)
+			if gotWarning != tt.wantWarning || (len(result.Warnings) > 0) != tt.wantWarning {
+				t.Errorf("Audit() warnings = %v, want a breach warning %v", result.Warnings, tt.wantWarning)
+			}
+			if result.BreachChecked != tt.wantChecked {
+				t.Errorf("Audit() BreachChecked = %v, want %v", result.BreachChecked, tt.wantChecked)
+			}
+		})
+	}
+}
+
+func TestAuditBreachCheckSkipMakesNoRequest(t *testing.T) {
+	server, requests := newSlowPwnedServer(t, 0)
+	result := Audit("Xq7#mB2vLp9!", Options{BreachChecker: &PwnedClient{BaseURL: server.URL + "/"}, BreachCheckMode: BreachSkip})
+	if n := requests.Load(); n != 0 || result.BreachChecked {
+		t.Errorf("Audit() made %d requests and BreachChecked = %v, want none in BreachSkip", n, result.BreachChecked)
+	}
+}
+
+func TestAuditBreachCheckTimeoutIgnoredContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	hung := BreachCheckerFunc(func(context.Context, string) (bool, int, error) {
+		<-release
+		return true, 1, nil
+	})
+
+	start := time.Now()
+	result := Audit("Xq7#mB2vLp9!", Options{BreachChecker: hung, BreachCheckTimeout: 20 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Audit() took %v, want it to stop waiting at the deadline", elapsed)
+	}
+	if !errors.Is(result.Err, ErrBreachCheckUnavailable) {
+		t.Errorf("Audit() error = %v, want ErrBreachCheckUnavailable", result.Err)
+	}
+}
+
+func TestAuditBreachFailOpenIsAdvisory(t *testing.T) {
+	failing := BreachCheckerFunc(func(context.Context, string) (bool, int, error) {
+		return false, 0, errors.New("network unreachable")
+	})
+	result := Audit("Xq7#mB2vLp9!", Options{BreachChecker: failing, BreachFailOpen: true})
+	if result.Err != nil || len(result.Warnings) != 1 || !errors.Is(result.Warnings[0], ErrBreachCheckUnavailable) {
+		t.Errorf("Audit() error = %v, warnings = %v, want a breach warning", result.Err, result.Warnings)
+	}
+	if !errors.Is(result.Warnings[0], ErrBreachCheckFailed) {
+		t.Errorf("Audit() warning = %v, want it to match the deprecated ErrBreachCheckFailed", result.Warnings[0])
+	}
+}
+
+func TestBreachCheckModeText(t *testing.T) {
+	for _, mode := range []BreachCheckMode{BreachEnforce, BreachAdvisory, BreachSkip} {
+		data, err := json.Marshal(mode)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error = %v", mode, err)
+		}
+		var got BreachCheckMode
+		if err := json.Unmarshal(data, &got); err != nil || got != mode {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", data, got, err, mode)
+		}
+	}
+	if _, err := json.Marshal(BreachCheckMode(7)); err == nil {
+		t.Error("Marshal(BreachCheckMode(7)) error = nil, want an error")
+	}
+	var mode BreachCheckMode
+	if err := json.Unmarshal([]byte(`"lenient"`), &mode); err == nil {
+		t.Error(`Unmarshal("lenient") error = nil, want an error`)
+	}
+}
+
+func TestValidateBreachCheck(t *testing.T) {
+	for _, opts := range []Options{{BreachCheckMode: BreachCheckMode(3)}, {BreachCheckTimeout: -time.Second}} {
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidOptions", opts, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheTTL is how long CachedChecker keeps a result when TTL is zero.
+const DefaultCacheTTL = time.Hour
+
+// DefaultCacheEntries is the number of results CachedChecker keeps when MaxEntries is zero.
+const DefaultCacheEntries = 10000
+
+// CachedChecker decorates a BreachChecker with an in-memory LRU cache, so signup retries do not
+// query the breach source again. A PwnedClient is cached by range: its responses are kept by
+// SHA-1 prefix and answer every password in that bucket. Other checkers are cached by the
+// password's SHA-1 hash. Plaintext passwords are never stored, errors are not cached, and
+// concurrent lookups of the same key share one call to Checker. The zero value of each setting
+// uses its default; a CachedChecker is safe for concurrent use and must not be copied.
+type CachedChecker struct {
+	Checker    BreachChecker
+	TTL        time.Duration // How long a result is reused, DefaultCacheTTL when zero
+	MaxEntries int           // Results kept before the least recently used is evicted, DefaultCacheEntries when zero
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // Values are *cacheEntry
+	lru     list.List                // Most recently used first
+	calls   map[string]*cacheCall    // Lookups in flight
+	now     func() time.Time         // Clock, time.Now when nil
+
+	hits, misses, shared atomic.Uint64
+}
+
+// CacheStats counts the lookups of a CachedChecker.
+type CacheStats struct {
+	Hits    uint64 // Lookups answered from the cache
+	Misses  uint64 // Lookups passed on to Checker
+	Shared  uint64 // Lookups that waited for an identical one in flight
+	Entries int    // Results currently cached
+}
+
+// cacheEntry is a cached range response or result.
+type cacheEntry struct {
+	key      string
+	body     []byte // Range response of a PwnedClient
+	breached bool
+	count    int
+	expires  time.Time
+}
+
+// cacheCall is a lookup in flight, which identical lookups wait for.
+type cacheCall struct {
+	done  chan struct{}
+	entry *cacheEntry
+	err   error
+}
+
+// IsBreached implements BreachChecker, consulting the cache before Checker.
+func (c *CachedChecker) IsBreached(ctx context.Context, password string) (bool, int, error) {
+	if client, ok := c.Checker.(*PwnedClient); ok {
+		prefix, suffix := pwnedHash(password)
+		entry, err := c.lookup(ctx, "range:"+prefix, func(ctx context.Context) (*cacheEntry, error) {
+			body, err := client.fetchRange(ctx, prefix)
+			return &cacheEntry{body: body}, err
+		})
+		if err != nil {
+			return false, 0, err
+		}
+		count, err := rangeCount(entry.body, suffix)
+		return count > 0, count, err
+	}
+
+	p := []byte(password)
+	sum := sha1.Sum(p)
+	wipe(p)
+	entry, err := c.lookup(ctx, "sha1:"+hex.EncodeToString(sum[:]), func(ctx context.Context) (*cacheEntry, error) {
+		breached, count, err := c.Checker.IsBreached(ctx, password)
+		return &cacheEntry{breached: breached, count: count}, err
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return entry.breached, entry.count, nil
+}
+
+// Stats returns the lookup counters and the number of cached results.
+func (c *CachedChecker) Stats() CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Shared: c.shared.Load(), Entries: entries}
+}
+
+// lookup returns the live entry for key, calling fetch once for concurrent lookups that miss.
+func (c *CachedChecker) lookup(ctx context.Context, key string, fetch func(context.Context) (*cacheEntry, error)) (*cacheEntry, error) {
+	for {
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = make(map[string]*list.Element)
+			c.calls = make(map[string]*cacheCall)
+		}
+		if el, ok := c.entries[key]; ok {
+			entry := el.Value.(*cacheEntry)
+			if c.clock().Before(entry.expires) {
+				c.lru.MoveToFront(el)
+				c.mu.Unlock()
+				c.hits.Add(1)
+				return entry, nil
+			}
+			c.lru.Remove(el)
+			delete(c.entries, key)
+		}
+
+		if call, ok := c.calls[key]; ok {
+			c.mu.Unlock()
+			c.shared.Add(1)
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue // The caller that fetched gave up; this one has not
+			}
+			return call.entry, call.err
+		}
+
+		call := &cacheCall{done: make(chan struct{})}
+		c.calls[key] = call
+		c.mu.Unlock()
+		c.misses.Add(1)
+
+		call.entry, call.err = fetch(ctx)
+		c.mu.Lock()
+		delete(c.calls, key)
+		if call.err == nil {
+			c.insert(key, call.entry)
+		}
+		c.mu.Unlock()
+		close(call.done)
+		return call.entry, call.err
+	}
+}
+
+// insert caches entry under key, evicting the least recently used entries beyond MaxEntries. The
+// caller holds c.mu.
+func (c *CachedChecker) insert(key string, entry *cacheEntry) {
+	ttl := c.TTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	limit := c.MaxEntries
+	if limit == 0 {
+		limit = DefaultCacheEntries
+	}
+	entry.key, entry.expires = key, c.clock().Add(ttl)
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > limit {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// clock returns the current time.
+func (c *CachedChecker) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
+// isContextError reports whether err comes from a cancelled or expired context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
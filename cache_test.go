@@ -0,0 +1,207 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingChecker reports every password as breached once and counts its calls.
+type countingChecker struct {
+	calls atomic.Int64
+	err   error
+}
+
+func (c *countingChecker) IsBreached(ctx context.Context, password string) (bool, int, error) {
+	c.calls.Add(1)
+	return c.err == nil, 1, c.err
+}
+
+func TestCachedCheckerHitsAndMisses(t *testing.T) {
+	inner := &countingChecker{}
+	c := &CachedChecker{Checker: inner}
+	for range 3 {
+		if ok, count, err := c.IsBreached(context.Background(), "password"); !ok || count != 1 || err != nil {
+			t.Fatalf("IsBreached() = %v, %d, %v, want true, 1, nil", ok, count, err)
+		}
+	}
+	c.IsBreached(context.Background(), "letmein")
+	if calls := inner.calls.Load(); calls != 2 {
+		t.Errorf("Checker called %d times, want 2", calls)
+	}
+	if stats := c.Stats(); stats != (CacheStats{Hits: 2, Misses: 2, Entries: 2}) {
+		t.Errorf("Stats() = %+v, want 2 hits, 2 misses, 2 entries", stats)
+	}
+	for key := range c.entries {
+		if strings.Contains(key, "password") || strings.Contains(key, "letmein") {
+			t.Errorf("cache key %q holds the plaintext password", key)
+		}
+	}
+}
+
+func TestCachedCheckerPwnedRanges(t *testing.T) {
+	server, paths := newPwnedServer(t)
+	c := &CachedChecker{Checker: &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}}
+
+	if ok, count, err := c.IsBreached(context.Background(), "password"); !ok || count != 9545824 || err != nil {
+		t.Fatalf("IsBreached() = %v, %d, %v, want true, 9545824, nil", ok, count, err)
+	}
+	// bucket-543162 shares the SHA-1 prefix 5BAA6 of "password", so its range is already cached.
+	if ok, count, err := c.IsBreached(context.Background(), "bucket-543162"); ok || count != 0 || err != nil {
+		t.Errorf("IsBreached() = %v, %d, %v, want false, 0, nil", ok, count, err)
+	}
+	if len(*paths) != 1 {
+		t.Errorf("requested paths = %v, want one request for the shared prefix", *paths)
+	}
+	for key := range c.entries {
+		if key != "range:5BAA6" {
+			t.Errorf("cache key = %q, want only the range prefix", key)
+		}
+	}
+}
+
+func TestCachedCheckerExpiry(t *testing.T) {
+	inner := &countingChecker{}
+	now := time.Unix(1700000000, 0)
+	c := &CachedChecker{Checker: inner, TTL: time.Minute, now: func() time.Time { return now }}
+
+	c.IsBreached(context.Background(), "password")
+	now = now.Add(59 * time.Second)
+	c.IsBreached(context.Background(), "password")
+	if calls := inner.calls.Load(); calls != 1 {
+		t.Errorf("Checker called %d times within the TTL, want 1", calls)
+	}
+	now = now.Add(time.Second)
+	c.IsBreached(context.Background(), "password")
+	if calls := inner.calls.Load(); calls != 2 {
+		t.Errorf("Checker called %d times after the TTL, want the expired entry fetched again", calls)
+	}
+}
+
+func TestCachedCheckerEviction(t *testing.T) {
+	inner := &countingChecker{}
+	c := &CachedChecker{Checker: inner, MaxEntries: 2}
+	for _, password := range []string{"one", "two", "one", "three", "one", "two"} {
+		c.IsBreached(context.Background(), password)
+	}
+	// "two" was the least recently used when "three" arrived, so only it is fetched again.
+	if calls := inner.calls.Load(); calls != 4 {
+		t.Errorf("Checker called %d times, want 4", calls)
+	}
+	if entries := c.Stats().Entries; entries != 2 {
+		t.Errorf("Stats().Entries = %d, want 2", entries)
+	}
+}
+
+func TestCachedCheckerErrorsNotCached(t *testing.T) {
+	inner := &countingChecker{err: errors.New("network unreachable")}
+	c := &CachedChecker{Checker: inner}
+	for range 2 {
+		if _, _, err := c.IsBreached(context.Background(), "password"); err == nil {
+			t.Fatal("IsBreached() error = nil, want the checker's error")
+		}
+	}
+	if calls := inner.calls.Load(); calls != 2 {
+		t.Errorf("Checker called %d times, want errors retried", calls)
+	}
+}
+
+func TestCachedCheckerConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int64
+	inner := BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+		calls.Add(1)
+		<-release
+		return true, 42, nil
+	})
+	c := &CachedChecker{Checker: inner}
+
+	const callers = 50
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Go(func() {
+			if ok, count, err := c.IsBreached(context.Background(), "password"); !ok || count != 42 || err != nil {
+				t.Errorf("IsBreached() = %v, %d, %v, want true, 42, nil", ok, count, err)
+			}
+		})
+	}
+	for c.shared.Load() < callers-1 {
+		time.Sleep(time.Millisecond) // Wait until every other caller joins the lookup in flight
+	}
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Checker called %d times for concurrent identical lookups, want 1", n)
+	}
+	if stats := c.Stats(); stats.Misses != 1 || stats.Shared != callers-1 {
+		t.Errorf("Stats() = %+v, want 1 miss and %d shared", stats, callers-1)
+	}
+
+	// Lookups of different passwords still run in parallel across the race detector.
+	wg = sync.WaitGroup{}
+	for i := range 20 {
+		wg.Go(func() {
+			c.IsBreached(context.Background(), strings.Repeat("x", i+1))
+			c.Stats()
+		})
+	}
+	wg.Wait()
+}
+
+func TestCachedCheckerCancelledLeader(t *testing.T) {
+	started := make(chan struct{})
+	var calls atomic.Int64
+	inner := BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return false, 0, ctx.Err()
+		}
+		return true, 7, nil
+	})
+	c := &CachedChecker{Checker: inner}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, _, err := c.IsBreached(ctx, "password")
+		done <- err
+	}()
+	<-started
+	waiter := make(chan int)
+	go func() {
+		_, count, _ := c.IsBreached(context.Background(), "password")
+		waiter <- count
+	}()
+	for c.shared.Load() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("IsBreached() error = %v, want %v for the cancelled caller", err, context.Canceled)
+	}
+	if count := <-waiter; count != 7 {
+		t.Errorf("IsBreached() count = %d, want 7 fetched again for the waiting caller", count)
+	}
+}
@@ -0,0 +1,117 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// calibrationTrials is how many times each candidate is hashed; the median of the trials is used
+// so one slow run does not skew the result.
+const calibrationTrials = 5
+
+// CalibrateArgon2 stops once the median is within calibrationTolerance of the target, or after
+// calibrationRounds candidates.
+const (
+	calibrationTolerance = 0.1
+	calibrationRounds    = 8
+)
+
+// measureArgon2 times one candidate; tests replace it with a model of the host.
+var measureArgon2 = MeasureArgon2
+
+// CalibrateArgon2 chooses Argon2id parameters that take about target to hash on this host without
+// using more than maxMemoryMiB, and returns them with the median time they took. Parallelism
+// follows GOMAXPROCS. Memory grows from the OWASP 19 MiB (or less when target is small) up to the
+// ceiling before iterations are added, since memory is what makes the hash expensive to attack on
+// GPUs. Each candidate is measured and the next one scaled from it, so a host that slows down once
+// the memory outgrows its caches still converges. Run it once at deployment rather than per request.
+func CalibrateArgon2(target time.Duration, maxMemoryMiB uint32) (Argon2Params, time.Duration, error) {
+	if target <= 0 {
+		return Argon2Params{}, 0, errors.New("argon2 calibration target must be positive")
+	}
+	if maxMemoryMiB == 0 || maxMemoryMiB > MaxArgon2Memory/1024 {
+		return Argon2Params{}, 0, fmt.Errorf("argon2 calibration memory ceiling must be between 1 and %d MiB", MaxArgon2Memory/1024)
+	}
+	p := DefaultArgon2Params
+	p.Parallelism = uint8(min(runtime.GOMAXPROCS(0), math.MaxUint8))
+	ceiling := uint64(maxMemoryMiB) * 1024
+	floor := uint64(8 * uint32(p.Parallelism))
+	p.Memory = uint32(max(min(uint64(DefaultArgon2Params.Memory), ceiling), floor))
+	p.Iterations = 1
+
+	for round := 1; ; round++ {
+		took, err := measureArgon2(p)
+		if err != nil {
+			return Argon2Params{}, 0, err
+		}
+		if math.Abs(float64(took-target)) <= calibrationTolerance*float64(target) || round == calibrationRounds {
+			return p, took, nil
+		}
+		next := scaleArgon2(p, float64(target)/float64(max(took, 1)), floor, ceiling)
+		if next == p {
+			return p, took, nil
+		}
+		p = next
+	}
+}
+
+// scaleArgon2 returns p with its memory times iterations multiplied by scale, filling memory up to
+// ceiling before adding iterations.
+func scaleArgon2(p Argon2Params, scale float64, floor, ceiling uint64) Argon2Params {
+	cost := float64(p.Memory) * float64(p.Iterations) * scale
+	switch {
+	case cost <= float64(ceiling):
+		p.Memory = uint32(max(roundMiB(uint64(cost)), floor))
+		p.Iterations = 1
+	default:
+		p.Memory = uint32(ceiling)
+		p.Iterations = uint32(min(max(math.Round(cost/float64(ceiling)), 1), MaxArgon2Iterations))
+	}
+	return p
+}
+
+// roundMiB rounds KiB down to a whole number of MiB once there is at least one.
+func roundMiB(kib uint64) uint64 {
+	if kib < 1024 {
+		return kib
+	}
+	return kib / 1024 * 1024
+}
+
+// MeasureArgon2 returns the median time of several Argon2id hashes with p on this host.
+func MeasureArgon2(p Argon2Params) (time.Duration, error) {
+	if p.Memory < 8*uint32(p.Parallelism) || p.Iterations < 1 || p.Parallelism < 1 || p.KeyLength < 4 {
+		return 0, errors.New("invalid argon2id parameters")
+	}
+	salt := make([]byte, max(p.SaltLength, 8))
+	times := make([]time.Duration, calibrationTrials)
+	for i := range times {
+		start := time.Now()
+		argon2.IDKey([]byte("calibration"), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+		times[i] = time.Since(start)
+	}
+	slices.Sort(times)
+	return times[len(times)/2], nil
+}
@@ -0,0 +1,137 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fakeArgon2Host models a host where hashing takes one nanosecond per KiB per iteration.
+func fakeArgon2Host(t *testing.T) {
+	t.Helper()
+	fakeArgon2Model(t, func(p Argon2Params) time.Duration {
+		return time.Duration(p.Memory) * time.Duration(p.Iterations)
+	})
+}
+
+// fakeArgon2Model replaces the measurement of a candidate with model for the rest of the test.
+func fakeArgon2Model(t *testing.T, model func(Argon2Params) time.Duration) {
+	t.Helper()
+	saved := measureArgon2
+	measureArgon2 = func(p Argon2Params) (time.Duration, error) {
+		return model(p), nil
+	}
+	t.Cleanup(func() { measureArgon2 = saved })
+}
+
+func TestCalibrateArgon2Model(t *testing.T) {
+	fakeArgon2Host(t)
+	tests := []struct {
+		name           string
+		target         time.Duration
+		maxMemoryMiB   uint32
+		wantMemory     uint32
+		wantIterations uint32
+	}{
+		{"memory within ceiling", 64 * 1024 * time.Nanosecond, 256, 64 * 1024, 1},
+		{"rounds down to whole MiB", 40*1024*time.Nanosecond + 500, 256, 40 * 1024, 1},
+		{"iterations past ceiling", 256 * 1024 * time.Nanosecond, 64, 64 * 1024, 4},
+		{"small target", 2*1024*time.Nanosecond + 100, 64, 2 * 1024, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, took, err := CalibrateArgon2(tt.target, tt.maxMemoryMiB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Memory != tt.wantMemory || p.Iterations != tt.wantIterations {
+				t.Errorf("CalibrateArgon2() = m=%d, t=%d, want m=%d, t=%d", p.Memory, p.Iterations, tt.wantMemory, tt.wantIterations)
+			}
+			if want := time.Duration(p.Memory) * time.Duration(p.Iterations); took != want {
+				t.Errorf("CalibrateArgon2() took = %v, want the %v measured for the chosen parameters", took, want)
+			}
+			if want := min(runtime.GOMAXPROCS(0), 255); int(p.Parallelism) != want {
+				t.Errorf("Parallelism = %d, want GOMAXPROCS %d", p.Parallelism, want)
+			}
+		})
+	}
+}
+
+func TestCalibrateArgon2Converges(t *testing.T) {
+	// Past 16 MiB the host falls out of its caches and each KiB costs four times as much, so
+	// extrapolating linearly from the first candidate would overshoot.
+	var candidates int
+	fakeArgon2Model(t, func(p Argon2Params) time.Duration {
+		candidates++
+		kib := time.Duration(p.Memory)
+		if kib > 16*1024 {
+			kib = 16*1024 + 4*(kib-16*1024)
+		}
+		return kib * time.Duration(p.Iterations)
+	})
+	target := 100 * 1024 * time.Nanosecond
+	p, took, err := CalibrateArgon2(target, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := took - target; diff < -target/10 || diff > target/10 {
+		t.Errorf("CalibrateArgon2() = m=%d, t=%d taking %v, want within 10%% of %v", p.Memory, p.Iterations, took, target)
+	}
+	if candidates < 2 || candidates > calibrationRounds {
+		t.Errorf("CalibrateArgon2() measured %d candidates, want between 2 and %d", candidates, calibrationRounds)
+	}
+}
+
+func TestCalibrateArgon2Host(t *testing.T) {
+	p, took, err := CalibrateArgon2(time.Millisecond, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if took <= 0 {
+		t.Errorf("CalibrateArgon2() took = %v, want the measured duration", took)
+	}
+	if p.Memory > 4*1024 || p.Memory < 8*uint32(p.Parallelism) {
+		t.Errorf("Memory = %d KiB, want between %d and 4096", p.Memory, 8*p.Parallelism)
+	}
+	if _, err := MeasureArgon2(p); err != nil {
+		t.Errorf("MeasureArgon2() = %v", err)
+	}
+	encoded, err := HashArgon2id("hunter2", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArgon2id("hunter2", encoded); err != nil {
+		t.Errorf("VerifyArgon2id() = %v", err)
+	}
+}
+
+func TestCalibrateArgon2Errors(t *testing.T) {
+	if _, _, err := CalibrateArgon2(0, 64); err == nil {
+		t.Error("CalibrateArgon2() with a zero target succeeded")
+	}
+	if _, _, err := CalibrateArgon2(time.Millisecond, 0); err == nil {
+		t.Error("CalibrateArgon2() with no memory succeeded")
+	}
+	if _, _, err := CalibrateArgon2(time.Millisecond, MaxArgon2Memory/1024+1); err == nil {
+		t.Error("CalibrateArgon2() with a ceiling above MaxArgon2Memory succeeded")
+	}
+	if _, err := MeasureArgon2(Argon2Params{}); err == nil {
+		t.Error("MeasureArgon2() with zero parameters succeeded")
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Command passwd audits and generates passwords with github.com/andreimerlescu/go-passwd.
+//
+//	passwd audit [flags] [-]
+//	passwd generate [flags]
+//
+// audit reads passwords from standard input, one per line, or prompts for one with echo off when
+// standard input is a terminal and no "-" argument is given. It exits 1 when any password is not
+// strong and 2 on usage errors. generate prints --count random passwords.
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	go_passwd "github.com/andreimerlescu/go-passwd"
+	"golang.org/x/term"
+)
+
+// Exit codes returned by run.
+const (
+	exitOK    = 0
+	exitWeak  = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: passwd audit|generate [flags]")
+		return exitUsage
+	}
+	switch args[0] {
+	case "audit":
+		return runAudit(args[1:], stdin, stdout, stderr)
+	case "generate":
+		return runGenerate(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "passwd: unknown command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+// runAudit implements passwd audit.
+func runAudit(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("passwd audit", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	config := fs.String("config", "", "read Options from a JSON `file`; other flags override it")
+	minLength := fs.Uint("min-length", 0, "minimum length in characters")
+	maxLength := fs.Uint("max-length", 0, "maximum length in characters, zero for none")
+	require := fs.String("require", "", "comma-separated `classes` to require: digit, lower, upper, symbol, extended, emoji")
+	minEntropy := fs.Float64("min-entropy", 0, "minimum entropy in `bits`")
+	rejectCommon := fs.Bool("reject-common", false, "reject common passwords")
+	pwned := fs.Bool("pwned", false, "reject passwords found by the Have I Been Pwned range API")
+	asJSON := fs.Bool("json", false, "print one JSON object per password")
+	summarize := fs.Bool("report", false, "print a summary of all passwords instead of a line for each")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 1 || (fs.NArg() == 1 && fs.Arg(0) != "-") {
+		fmt.Fprintln(stderr, `passwd audit: the only argument accepted is "-" for standard input`)
+		return exitUsage
+	}
+
+	var opts go_passwd.Options
+	if *config != "" {
+		data, err := os.ReadFile(*config)
+		if err == nil {
+			opts, err = go_passwd.ParseOptionsJSON(data)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "passwd audit: %v\n", err)
+			return exitUsage
+		}
+	}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "min-length":
+			opts.MinLength = *minLength
+		case "max-length":
+			opts.MaxLength = *maxLength
+		case "require":
+			err = setClasses(&opts, *require)
+		case "min-entropy":
+			opts.MinEntropy = *minEntropy
+		case "reject-common":
+			opts.RejectCommon = *rejectCommon
+		case "pwned":
+			if *pwned {
+				opts.BreachChecker = go_passwd.DefaultPwnedClient
+			}
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "passwd audit: %v\n", err)
+		return exitUsage
+	}
+	if *summarize {
+		opts.Report = &go_passwd.Report{}
+	}
+	v, err := go_passwd.NewValidator(opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "passwd audit: %v\n", err)
+		return exitUsage
+	}
+
+	weak := false
+	report := func(line int, _ string, res go_passwd.Result) error {
+		if !res.Strong {
+			weak = true
+		}
+		if *summarize {
+			return nil
+		}
+		if *asJSON {
+			return json.NewEncoder(stdout).Encode(struct {
+				Line   int              `json:"line"`
+				Result go_passwd.Result `json:"result"`
+			}{line, res.Redacted(go_passwd.DefaultLanguage)})
+		}
+		verdict := "strong"
+		if !res.Strong {
+			verdict = "weak"
+		}
+		fmt.Fprintf(stdout, "line %d: %s, score %d (%s)\n", line, verdict, res.Score, res.Rating)
+		for _, violation := range res.Violations {
+			fmt.Fprintf(stdout, "\t%s\n", go_passwd.Translate(violation, go_passwd.DefaultLanguage))
+		}
+		return nil
+	}
+
+	if f, ok := stdin.(*os.File); ok && fs.NArg() == 0 && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(stderr, "Password: ")
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(stderr)
+		if err != nil {
+			fmt.Fprintf(stderr, "passwd audit: %v\n", err)
+			return exitUsage
+		}
+		secret := go_passwd.NewSecretBytes(password)
+		res := v.AuditSecret(secret)
+		secret.Zero()
+		if opts.Report != nil {
+			opts.Report.Add(res)
+		}
+		err = report(1, "", res)
+		if err != nil {
+			fmt.Fprintf(stderr, "passwd audit: %v\n", err)
+			return exitUsage
+		}
+	} else if err := v.AuditReader(context.Background(), stdin, report); err != nil {
+		fmt.Fprintf(stderr, "passwd audit: %v\n", err)
+		return exitUsage
+	}
+
+	if opts.Report != nil {
+		summary := opts.Report.Summary()
+		if *asJSON {
+			err = json.NewEncoder(stdout).Encode(summary)
+		} else {
+			_, err = summary.WriteTo(stdout)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "passwd audit: %v\n", err)
+			return exitUsage
+		}
+	}
+	if weak {
+		return exitWeak
+	}
+	return exitOK
+}
+
+// runGenerate implements passwd generate.
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("passwd generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	length := fs.Uint("length", go_passwd.DefaultGenerateLength, "password length in characters")
+	classes := fs.String("classes", "all", `comma-separated classes to include: digit, lower, upper, symbol, extended, emoji, or "all" for the first four`)
+	count := fs.Int("count", 1, "number of passwords to print")
+	entropy := fs.Float64("entropy", 0, "pick the shortest length carrying at least this many `bits` instead of --length")
+	unambiguous := fs.Bool("exclude-ambiguous", false, "leave out look-alike characters such as 0, O, 1, l and I")
+	asJSON := fs.Bool("json", false, "print one JSON object per password")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "passwd generate: no arguments accepted")
+		return exitUsage
+	}
+	if *count < 1 {
+		fmt.Fprintln(stderr, "passwd generate: --count must be at least 1")
+		return exitUsage
+	}
+
+	var opts go_passwd.Options
+	if err := setClasses(&opts, *classes); err != nil {
+		fmt.Fprintf(stderr, "passwd generate: %v\n", err)
+		return exitUsage
+	}
+	g := go_passwd.Generator{ExcludeAmbiguous: *unambiguous}
+	for i := 0; i < *count; i++ {
+		var (
+			password string
+			bits     float64
+			err      error
+		)
+		if *entropy > 0 {
+			password, bits, err = g.GenerateWithEntropy(*entropy, opts)
+		} else {
+			o := opts
+			o.MinLength, o.MaxLength = *length, *length
+			if password, err = g.Generate(o); err == nil {
+				bits = go_passwd.Audit(password, o).Entropy
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "passwd generate: %v\n", err)
+			return exitUsage
+		}
+		if *asJSON {
+			err = json.NewEncoder(stdout).Encode(struct {
+				Password string  `json:"password"`
+				Entropy  float64 `json:"entropy"`
+			}{password, bits})
+		} else {
+			_, err = fmt.Fprintln(stdout, password)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "passwd generate: %v\n", err)
+			return exitUsage
+		}
+	}
+	return exitOK
+}
+
+// setClasses sets the Use* fields of opts named in the comma-separated list.
+func setClasses(opts *go_passwd.Options, list string) error {
+	for _, name := range strings.Split(list, ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "all":
+			opts.UseDigits, opts.UseLower, opts.UseUpper, opts.UseSymbols = true, true, true, true
+		case "digit", "digits":
+			opts.UseDigits = true
+		case "lower":
+			opts.UseLower = true
+		case "upper":
+			opts.UseUpper = true
+		case "symbol", "symbols":
+			opts.UseSymbols = true
+		case "extended":
+			opts.UseExtended = true
+		case "emoji":
+			opts.UseEmoji = true
+		case "":
+		default:
+			return fmt.Errorf("unknown character class %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	go_passwd "github.com/andreimerlescu/go-passwd"
+)
+
+func TestRunAudit(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(config, []byte(`{"min_length": 20}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout []string
+	}{
+		{"Strong", []string{"audit", "--min-length", "12", "-"}, "Xq7mB2vLp9!w4Z\n", exitOK, []string{"line 1: strong"}},
+		{"One weak line", []string{"audit", "--min-length", "12", "--require", "upper,digit,symbol"}, "Xq7mB2vLp9!w4Z\nshort\n", exitWeak, []string{"line 1: strong", "line 2: weak", "Password must be at least 12 characters long."}},
+		{"Common password", []string{"audit", "--reject-common"}, "password\n", exitWeak, []string{"Password is too common."}},
+		{"Config file", []string{"audit", "--config", config}, "Xq7mB2vLp9!w4Z\n", exitWeak, []string{"at least 20 characters"}},
+		{"Flags override config", []string{"audit", "--config", config, "--min-length", "8"}, "Xq7mB2vLp9!w4Z\n", exitOK, nil},
+		{"Empty input", []string{"audit"}, "", exitOK, nil},
+		{"Report", []string{"audit", "--report", "--min-length", "12"}, "Xq7mB2vLp9!w4Z\nshort\n", exitWeak, []string{"passwords: 2, strong: 1 (50.0%)", "too_short"}},
+		{"Unknown class", []string{"audit", "--require", "glyphs"}, "", exitUsage, nil},
+		{"Unknown flag", []string{"audit", "--nope"}, "", exitUsage, nil},
+		{"Extra argument", []string{"audit", "file.txt"}, "", exitUsage, nil},
+		{"Contradictory options", []string{"audit", "--min-length", "12", "--max-length", "8"}, "", exitUsage, nil},
+		{"Missing config", []string{"audit", "--config", filepath.Join(t.TempDir(), "missing.json")}, "", exitUsage, nil},
+		{"No command", nil, "", exitUsage, nil},
+		{"Unknown command", []string{"crack"}, "", exitUsage, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, tt.code, stderr.String())
+			}
+			for _, want := range tt.stdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout = %q, want it to contain %q", stdout.String(), want)
+				}
+			}
+			if strings.Contains(stdout.String(), "Xq7mB2vLp9") || strings.Contains(stdout.String(), "short\n") {
+				t.Errorf("stdout = %q, echoes a password", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunAuditJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"audit", "--json", "--min-length", "8"}, strings.NewReader("Xq7mB2vLp9!\nshort\n"), &stdout, &stderr)
+	if code != exitWeak {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitWeak, stderr.String())
+	}
+
+	var lines []struct {
+		Line   int              `json:"line"`
+		Result go_passwd.Result `json:"result"`
+	}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		var line struct {
+			Line   int              `json:"line"`
+			Result go_passwd.Result `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0].Line != 1 || !lines[0].Result.Strong || lines[1].Result.Strong || len(lines[1].Result.Violations) != 1 {
+		t.Errorf("JSON output = %+v, want a strong line 1 and a weak line 2", lines)
+	}
+}
+
+func TestRunAuditJSONRedacted(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "policy.json")
+	policy := `{"max_repeat_run": 2, "max_sequence_length": 3, "reject_sequences": true, "reject_common": true}`
+	if err := os.WriteFile(config, []byte(policy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"audit", "--json", "--config", config}, strings.NewReader("qqqqwxyz\npassword1\n"), &stdout, &stderr)
+	if code != exitWeak {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitWeak, stderr.String())
+	}
+	for _, fragment := range []string{"qqq", "wxyz", "password"} {
+		if strings.Contains(stdout.String(), fragment) {
+			t.Errorf("JSON output contains %q of a password: %s", fragment, stdout.String())
+		}
+	}
+}
+
+func TestRunGenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		count  int
+		length int
+		check  func(rune) bool
+	}{
+		{"Defaults", []string{"generate"}, exitOK, 1, go_passwd.DefaultGenerateLength, nil},
+		{"Length and count", []string{"generate", "--length", "20", "--count", "5"}, exitOK, 5, 20, nil},
+		{"Digits only", []string{"generate", "--classes", "digit", "--length", "6"}, exitOK, 1, 6, unicode.IsDigit},
+		{"Unambiguous", []string{"generate", "--exclude-ambiguous", "--count", "20"}, exitOK, 20, go_passwd.DefaultGenerateLength, func(r rune) bool { return !strings.ContainsRune(go_passwd.DefaultAmbiguousSet, r) }},
+		{"Entropy", []string{"generate", "--entropy", "64", "--classes", "lower"}, exitOK, 1, 14, unicode.IsLower},
+		{"Unknown class", []string{"generate", "--classes", "glyphs"}, exitUsage, 0, 0, nil},
+		{"Zero count", []string{"generate", "--count", "0"}, exitUsage, 0, 0, nil},
+		{"Too short for classes", []string{"generate", "--length", "2"}, exitUsage, 0, 0, nil},
+		{"Stray argument", []string{"generate", "extra"}, exitUsage, 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, strings.NewReader(""), &stdout, &stderr); code != tt.code {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, tt.code, stderr.String())
+			}
+			passwords := strings.Fields(stdout.String())
+			if len(passwords) != tt.count {
+				t.Fatalf("printed %d passwords, want %d: %q", len(passwords), tt.count, passwords)
+			}
+			for _, password := range passwords {
+				if n := len([]rune(password)); n != tt.length {
+					t.Errorf("password %q has %d characters, want %d", password, n, tt.length)
+				}
+				if tt.check != nil && strings.IndexFunc(password, func(r rune) bool { return !tt.check(r) }) >= 0 {
+					t.Errorf("password %q has a character outside the requested classes", password)
+				}
+			}
+		})
+	}
+}
+
+func TestRunGenerateJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"generate", "--json", "--count", "2"}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitOK, stderr.String())
+	}
+	decoder := json.NewDecoder(&stdout)
+	for i := 0; i < 2; i++ {
+		var out struct {
+			Password string  `json:"password"`
+			Entropy  float64 `json:"entropy"`
+		}
+		if err := decoder.Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		if len(out.Password) != go_passwd.DefaultGenerateLength || out.Entropy <= 0 {
+			t.Errorf("JSON output = %+v, want a %d-character password and its entropy", out, go_passwd.DefaultGenerateLength)
+		}
+	}
+}
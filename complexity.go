@@ -0,0 +1,152 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Complexity is a legacy complexity level naming the character classes a password mixes. The
+// numeric values are stable but not ordered by strength; compare levels with ComplexityStrength.
+type Complexity int64
+
+const (
+	PwComplexityDigitsOnly Complexity = iota
+	PwComplexityLowerOnly
+	PwComplexityUpperOnly
+	PwComplexityLowerDigits
+	PwComplexityUpperDigits
+	PwComplexityMixedOnly
+	PwComplexityDigitsMixed
+	PwComplexitySymbolsOnly
+	PwComplexitySymbolsDigits
+	PwComplexitySymbolsUpper
+	PwComplexitySymbolsLower
+	PwComplexitySymbolsMixed
+	PwComplexitySymbolsDigitsMixed
+	PwComplexityExtendedOnly
+	PwComplexityExtendedMixed // Includes extended characters and other types
+	PwComplexitySymbolsDigitsUpper
+	PwComplexitySymbolsDigitsLower
+)
+
+// complexityNames are the names String returns, indexed by level.
+var complexityNames = [...]string{
+	PwComplexityDigitsOnly:         "DigitsOnly",
+	PwComplexityLowerOnly:          "LowerOnly",
+	PwComplexityUpperOnly:          "UpperOnly",
+	PwComplexityLowerDigits:        "LowerDigits",
+	PwComplexityUpperDigits:        "UpperDigits",
+	PwComplexityMixedOnly:          "MixedOnly",
+	PwComplexityDigitsMixed:        "DigitsMixed",
+	PwComplexitySymbolsOnly:        "SymbolsOnly",
+	PwComplexitySymbolsDigits:      "SymbolsDigits",
+	PwComplexitySymbolsUpper:       "SymbolsUpper",
+	PwComplexitySymbolsLower:       "SymbolsLower",
+	PwComplexitySymbolsMixed:       "SymbolsMixed",
+	PwComplexitySymbolsDigitsMixed: "SymbolsDigitsMixed",
+	PwComplexityExtendedOnly:       "ExtendedOnly",
+	PwComplexityExtendedMixed:      "ExtendedMixed",
+	PwComplexitySymbolsDigitsUpper: "SymbolsDigitsUpper",
+	PwComplexitySymbolsDigitsLower: "SymbolsDigitsLower",
+}
+
+// complexityStrength orders the complexity levels by the size of the charset they imply, weakest first.
+var complexityStrength = []Complexity{
+	PwComplexityDigitsOnly,
+	PwComplexityLowerOnly,
+	PwComplexityUpperOnly,
+	PwComplexitySymbolsOnly,
+	PwComplexityLowerDigits,
+	PwComplexityUpperDigits,
+	PwComplexitySymbolsDigits,
+	PwComplexityMixedOnly,
+	PwComplexitySymbolsLower,
+	PwComplexitySymbolsUpper,
+	PwComplexityDigitsMixed,
+	PwComplexitySymbolsDigitsLower,
+	PwComplexitySymbolsDigitsUpper,
+	PwComplexitySymbolsMixed,
+	PwComplexitySymbolsDigitsMixed,
+	PwComplexityExtendedOnly,
+	PwComplexityExtendedMixed,
+}
+
+// ComplexityStrength returns the rank of a complexity level on a scale ordered by strength, or -1
+// if the level is unknown. Unlike the constants themselves, a higher rank is always stronger.
+func ComplexityStrength(complexity Complexity) int {
+	for rank, c := range complexityStrength {
+		if c == complexity {
+			return rank
+		}
+	}
+	return -1
+}
+
+// Complexities returns every defined complexity level in numeric order.
+func Complexities() []Complexity {
+	levels := make([]Complexity, len(complexityNames))
+	for i := range levels {
+		levels[i] = Complexity(i)
+	}
+	return levels
+}
+
+// String returns the name of the level, such as "SymbolsDigitsMixed", or "Complexity(n)" when
+// the level is unknown.
+func (c Complexity) String() string {
+	if c < 0 || int(c) >= len(complexityNames) {
+		return "Complexity(" + strconv.FormatInt(int64(c), 10) + ")"
+	}
+	return complexityNames[c]
+}
+
+// snakeName returns the name of the level in snake_case, such as "symbols_digits_mixed", as used
+// in serialized output, or "" when the level is unknown.
+func (c Complexity) snakeName() string {
+	if c < 0 || int(c) >= len(complexityNames) {
+		return ""
+	}
+	var b strings.Builder
+	for i, r := range complexityNames[c] {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ParseComplexity returns the level named s in CamelCase or snake_case, such as
+// "SymbolsDigitsMixed" or "symbols_digits_mixed", ignoring case and an optional "PwComplexity"
+// prefix.
+func ParseComplexity(s string) (Complexity, error) {
+	key := strings.ToLower(strings.ReplaceAll(s, "_", ""))
+	key = strings.TrimPrefix(key, "pwcomplexity")
+	for level, name := range complexityNames {
+		if key != "" && strings.ToLower(name) == key {
+			return Complexity(level), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown complexity %q", s)
+}
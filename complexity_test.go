@@ -0,0 +1,108 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestComplexityRoundTrip(t *testing.T) {
+	for _, c := range Complexities() {
+		name := c.String()
+		if got, err := ParseComplexity(name); err != nil || got != c {
+			t.Errorf("ParseComplexity(%q) = %v, %v, want %v", name, got, err, c)
+		}
+		snake := c.snakeName()
+		if got, err := ParseComplexity(snake); err != nil || got != c {
+			t.Errorf("ParseComplexity(%q) = %v, %v, want %v", snake, got, err, c)
+		}
+		if ComplexityStrength(c) < 0 {
+			t.Errorf("ComplexityStrength(%v) = -1", c)
+		}
+	}
+}
+
+func TestComplexityString(t *testing.T) {
+	tests := []struct {
+		complexity Complexity
+		want       string
+		snake      string
+	}{
+		{PwComplexityDigitsOnly, "DigitsOnly", "digits_only"},
+		{PwComplexitySymbolsDigitsMixed, "SymbolsDigitsMixed", "symbols_digits_mixed"},
+		{PwComplexityExtendedMixed, "ExtendedMixed", "extended_mixed"},
+		{PwComplexitySymbolsDigitsLower, "SymbolsDigitsLower", "symbols_digits_lower"},
+		{Complexity(17), "Complexity(17)", ""},
+		{Complexity(-1), "Complexity(-1)", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.complexity.String(); got != tt.want {
+			t.Errorf("Complexity(%d).String() = %q, want %q", int64(tt.complexity), got, tt.want)
+		}
+		if got := tt.complexity.snakeName(); got != tt.snake {
+			t.Errorf("Complexity(%d).snakeName() = %q, want %q", int64(tt.complexity), got, tt.snake)
+		}
+	}
+}
+
+func TestParseComplexity(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Complexity
+		wantErr bool
+	}{
+		{"SymbolsDigitsMixed", PwComplexitySymbolsDigitsMixed, false},
+		{"symbols_digits_mixed", PwComplexitySymbolsDigitsMixed, false},
+		{"SYMBOLS_DIGITS_MIXED", PwComplexitySymbolsDigitsMixed, false},
+		{"PwComplexityLowerDigits", PwComplexityLowerDigits, false},
+		{"digits_only", PwComplexityDigitsOnly, false},
+		{"", 0, true},
+		{"PwComplexity", 0, true},
+		{"symbols", 0, true},
+		{"digits only", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseComplexity(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseComplexity(%q) error = %v, wantErr %t", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseComplexity(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestComplexities(t *testing.T) {
+	levels := Complexities()
+	if len(levels) != int(PwComplexitySymbolsDigitsLower)+1 {
+		t.Fatalf("Complexities() returned %d levels, want %d", len(levels), PwComplexitySymbolsDigitsLower+1)
+	}
+	for i, c := range levels {
+		if c != Complexity(i) {
+			t.Errorf("Complexities()[%d] = %v, want %v", i, c, Complexity(i))
+		}
+	}
+	if len(complexityStrength) != len(levels) {
+		t.Errorf("complexityStrength ranks %d levels, want %d", len(complexityStrength), len(levels))
+	}
+	levels[0] = PwComplexityExtendedMixed
+	if !slices.Equal(Complexities()[:1], []Complexity{PwComplexityDigitsOnly}) {
+		t.Error("Complexities() shares its backing array between calls")
+	}
+}
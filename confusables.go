@@ -0,0 +1,88 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PatternConfusable is the Pattern kind of a dictionary or breached password disguised with
+// lookalike letters from another script, such as "pаssword" with a Cyrillic "а". Base holds the
+// skeleton, the password with every lookalike replaced by the ASCII letter it imitates.
+const PatternConfusable MatchKind = "confusable"
+
+// Confusables maps letters of other scripts to the ASCII letters they are commonly mistaken for.
+// Extend or replace it before auditing; it is read without locking.
+var Confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'к': 'k',
+	'м': 'm', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'т': 't', 'у': 'y', 'ԝ': 'w', 'х': 'x',
+	'А': 'A', 'В': 'B', 'С': 'C', 'Е': 'E', 'Н': 'H', 'І': 'I', 'Ј': 'J', 'К': 'K', 'М': 'M',
+	'О': 'O', 'Р': 'P', 'Ѕ': 'S', 'Т': 'T', 'Х': 'X', 'Ү': 'Y',
+	// Greek
+	'α': 'a', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N',
+	'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+	// Latin letters outside ASCII
+	'ı': 'i', 'ȷ': 'j', 'ɑ': 'a', 'ɡ': 'g',
+}
+
+// ConfusableRune is a letter of another script in a password that looks like an ASCII letter.
+type ConfusableRune struct {
+	Char      string `json:"char"`       // The letter as written
+	LooksLike string `json:"looks_like"` // The ASCII letter it imitates
+	Index     int    `json:"index"`      // Position of the letter, counted in runes
+}
+
+// confusablesOf returns the lookalike letters of pass and its skeleton. A password written in
+// another script, such as the Russian "пароль", only reports them when it also has ASCII letters
+// or when every letter is a lookalike, so ordinary words of other scripts are left alone.
+func confusablesOf(pass string) ([]ConfusableRune, string) {
+	var found []ConfusableRune
+	latin, others := false, false
+	i := 0
+	for _, r := range pass {
+		switch {
+		case r < utf8.RuneSelf:
+			latin = latin || unicode.IsLetter(r)
+		case unicode.IsLetter(r):
+			if looks, ok := Confusables[r]; ok {
+				found = append(found, ConfusableRune{Char: string(r), LooksLike: string(looks), Index: i})
+			} else {
+				others = true
+			}
+		}
+		i++
+	}
+	if len(found) == 0 || (!latin && others) {
+		return nil, ""
+	}
+	skeleton := strings.Map(func(r rune) rune {
+		if looks, ok := Confusables[r]; ok {
+			return looks
+		}
+		return r
+	}, pass)
+	return found, skeleton
+}
+
+// confusablePattern describes pass as a disguised spelling of skeleton.
+func confusablePattern(pass, skeleton string) Match {
+	return Match{Kind: PatternConfusable, Token: pass, Start: 0, End: utf8.RuneCountInString(pass), Base: skeleton}
+}
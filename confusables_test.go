@@ -0,0 +1,89 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfusablesOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		pass     string
+		want     []ConfusableRune
+		skeleton string
+	}{
+		{"Cyrillic a", "pаssword", []ConfusableRune{{"а", "a", 1}}, "password"},
+		{"Greek omicron and Cyrillic e", "dοnkеy-42", []ConfusableRune{{"ο", "o", 1}, {"е", "e", 4}}, "donkey-42"},
+		{"Every letter a lookalike", "раѕѕ", []ConfusableRune{{"р", "p", 0}, {"а", "a", 1}, {"ѕ", "s", 2}, {"ѕ", "s", 3}}, "pass"},
+		{"Russian word", "пароль2024", nil, ""},
+		{"Greek word", "καλημέρα", nil, ""},
+		{"Latin accents", "Café-Noir", nil, ""},
+		{"ASCII", "password", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skeleton := confusablesOf(tt.pass)
+			if !reflect.DeepEqual(got, tt.want) || skeleton != tt.skeleton {
+				t.Errorf("confusablesOf(%q) = %v, %q, want %v, %q", tt.pass, got, skeleton, tt.want, tt.skeleton)
+			}
+		})
+	}
+}
+
+func TestAuditConfusables(t *testing.T) {
+	disguised := "pаssword"
+	result := Audit(disguised, Options{RejectCommon: true})
+	if !errors.Is(result.Err, ErrCommonPassword) {
+		t.Fatalf("Audit(%q) error = %v, want %v", disguised, result.Err, ErrCommonPassword)
+	}
+	if len(result.Patterns) == 0 || result.Patterns[0].Kind != PatternConfusable || result.Patterns[0].Base != "password" {
+		t.Errorf("Audit(%q) patterns = %+v, want a %s pattern of %q", disguised, result.Patterns, PatternConfusable, "password")
+	}
+	if !result.HasExtended || len(result.ConfusableRunes) != 1 {
+		t.Errorf("Audit(%q) HasExtended = %v, ConfusableRunes = %v, want the Cyrillic letter reported", disguised, result.HasExtended, result.ConfusableRunes)
+	}
+
+	result = Audit(disguised, Options{RejectConfusables: true})
+	var verr *ValidationError
+	if !errors.As(result.Err, &verr) || verr.Code != CodeConfusable || verr.Params["position"] != 1 || verr.Params["looks_like"] != "a" {
+		t.Errorf("Audit(%q) with RejectConfusables error = %#v, want %s at position 1", disguised, result.Err, CodeConfusable)
+	}
+
+	if result := Audit("пароль2024", Options{RejectConfusables: true}); result.Err != nil {
+		t.Errorf("Audit(%q) with RejectConfusables error = %v, want nil", "пароль2024", result.Err)
+	}
+}
+
+func TestConfusablesNoStronger(t *testing.T) {
+	for _, tt := range []struct{ ascii, disguised string }{
+		{"correct-Horse-42", "cоrrеct-Horse-42"},
+		{"Sunflower.Rising", "Sunflοwer.Rising"},
+		{"tiger-MOUNTAIN", "tigеr-MОUNTAIN"},
+	} {
+		ascii, disguised := Audit(tt.ascii, Options{}), Audit(tt.disguised, Options{})
+		if disguised.Score > ascii.Score || disguised.Complexity != ascii.Complexity {
+			t.Errorf("Audit(%q) score %d complexity %v, Audit(%q) score %d complexity %v, want the disguise no stronger",
+				tt.disguised, disguised.Score, disguised.Complexity, tt.ascii, ascii.Score, ascii.Complexity)
+		}
+		if disguised.CharsetEntropy != ascii.CharsetEntropy {
+			t.Errorf("Audit(%q) charset entropy = %v, want %v like %q", tt.disguised, disguised.CharsetEntropy, ascii.CharsetEntropy, tt.ascii)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// isControl reports whether r is a control character or a line or paragraph separator, which end
+// a line in logs as surely as a newline does.
+func isControl(r rune) bool {
+	return unicode.IsControl(r) || r == '\u2028' || r == '\u2029'
+}
+
+// isInvisible reports whether r is a format character such as a zero-width space, a joiner, or a
+// bidi override, which changes a password without changing how it looks.
+func isInvisible(r rune) bool {
+	return unicode.Is(unicode.Cf, r)
+}
+
+// firstRune returns the first rune of pass matching f and its position in runes, or -1 when none
+// does.
+func firstRune(pass string, f func(rune) bool) (rune, int) {
+	i := 0
+	for _, r := range pass {
+		if f(r) {
+			return r, i
+		}
+		i++
+	}
+	return 0, -1
+}
+
+// invalidUTF8 returns the first byte of pass that is not part of a valid UTF-8 sequence and its
+// position in runes, counting each invalid byte as one rune, or -1 when pass is valid.
+func invalidUTF8(pass string) (byte, int) {
+	i := 0
+	for offset := 0; offset < len(pass); i++ {
+		r, size := utf8.DecodeRuneInString(pass[offset:])
+		if r == utf8.RuneError && size == 1 {
+			return pass[offset], i
+		}
+		offset += size
+	}
+	return 0, -1
+}
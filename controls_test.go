@@ -0,0 +1,119 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAuditControlChars(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		want     error
+		detail   string
+	}{
+		{"Null byte", "pass\x00word", Options{}, ErrControlChar, "password contains a control character: U+0000 at position 4"},
+		{"Tab", "pass\tword", Options{}, ErrControlChar, "password contains a control character: U+0009 at position 4"},
+		{"Escape", "\x1b[31mred", Options{}, ErrControlChar, "password contains a control character: U+001B at position 0"},
+		{"C1 control", "ab\u0085cd", Options{}, ErrControlChar, "password contains a control character: U+0085 at position 2"},
+		{"Line separator", "ab\u2028cd", Options{}, ErrControlChar, "password contains a control character: U+2028 at position 2"},
+		{"Control allowed", "pass\tword", Options{AllowControlChars: true}, nil, ""},
+		{"Zero-width space allowed by default", "pass\u200bword", Options{}, nil, ""},
+		{"Zero-width space", "pass\u200bword", Options{RejectInvisibleChars: true}, ErrInvisibleChar, "password contains an invisible character: U+200B at position 4"},
+		{"Bidi override", "abc\u202edcba", Options{RejectInvisibleChars: true}, ErrInvisibleChar, "password contains an invisible character: U+202E at position 3"},
+		{"Emoji joiner", "👩‍💻", Options{RejectInvisibleChars: true}, ErrInvisibleChar, "password contains an invisible character: U+200D at position 1"},
+		{"Visible Unicode", "Pässwörd€", Options{RejectInvisibleChars: true}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if !errors.Is(result.Err, tt.want) || (tt.want == nil) != (result.Err == nil) {
+				t.Fatalf("Audit(%q) error = %v, want %v", tt.password, result.Err, tt.want)
+			}
+			if tt.detail != "" && result.Err.Error() != tt.detail {
+				t.Errorf("Audit(%q) error = %q, want %q", tt.password, result.Err, tt.detail)
+			}
+		})
+	}
+}
+
+func TestAuditInvalidUTF8(t *testing.T) {
+	password := "ab\xffcd"
+	result := Audit(password, Options{})
+	if !errors.Is(result.Err, ErrInvalidUTF8) {
+		t.Fatalf("Audit(%q) error = %v, want ErrInvalidUTF8", password, result.Err)
+	}
+	var verr *ValidationError
+	if !errors.As(result.Err, &verr) || verr.Code != CodeInvalidUTF8 || verr.Params["position"] != 2 || verr.Params["byte"] != "0xff" {
+		t.Errorf("Audit(%q) error = %#v, want code %s at position 2", password, verr, CodeInvalidUTF8)
+	}
+
+	result = Audit(password, Options{ReplaceInvalidUTF8: true})
+	if result.Err != nil {
+		t.Fatalf("Audit(%q) error = %v, want nil with ReplaceInvalidUTF8", password, result.Err)
+	}
+	if result.Length != 5 || result.LengthBytes != 5 || result.Counts.Lower != 4 {
+		t.Errorf("Audit(%q) Length = %d, LengthBytes = %d, Counts = %+v, want U+FFFD in place of the byte", password, result.Length, result.LengthBytes, result.Counts)
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		s        string
+		wantByte byte
+		wantPos  int
+	}{
+		{"", 0, -1},
+		{"valid €", 0, -1},
+		{"\xff", 0xff, 0},
+		{"€\xe2\x82", 0xe2, 1},
+		{"a\xc0\xafb", 0xc0, 1},
+	}
+	for _, tt := range tests {
+		if b, i := invalidUTF8(tt.s); b != tt.wantByte || i != tt.wantPos {
+			t.Errorf("invalidUTF8(%q) = %#x, %d, want %#x, %d", tt.s, b, i, tt.wantByte, tt.wantPos)
+		}
+	}
+}
+
+func FuzzAudit(f *testing.F) {
+	for _, seed := range []string{"", "Password1!", "pass\x00word", "\xff\xfe", "👩‍👩‍👧", "🇫🇷🇩", "a\u202eb", "\xe2\x82"} {
+		f.Add([]byte(seed))
+	}
+	options := Options{MinLength: 8, UseDigits: true, MaxRepeatRun: 3, MaxSequenceLength: 3, KeyboardWalkLength: 4, RejectInvisibleChars: true}
+	replace := options
+	replace.ReplaceInvalidUTF8 = true
+	f.Fuzz(func(t *testing.T, password []byte) {
+		result := Audit(string(password), options)
+		if !utf8.Valid(password) && !errors.Is(result.Err, ErrInvalidUTF8) {
+			t.Fatalf("Audit(%q) error = %v, want ErrInvalidUTF8", password, result.Err)
+		}
+		if result.Length < 0 || result.Length > int64(len(password)) || result.LengthBytes != int64(len(password)) {
+			t.Fatalf("Audit(%q) Length = %d, LengthBytes = %d", password, result.Length, result.LengthBytes)
+		}
+		if errors.Is(Audit(string(password), replace).Err, ErrInvalidUTF8) {
+			t.Fatalf("Audit(%q) failed with ErrInvalidUTF8 despite ReplaceInvalidUTF8", password)
+		}
+		if got := AuditBytes(password, options); got.Err == nil != (result.Err == nil) {
+			t.Fatalf("AuditBytes(%q) error = %v, Audit error = %v", password, got.Err, result.Err)
+		}
+	})
+}
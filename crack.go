@@ -0,0 +1,121 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Guess rates of the standard attacker profiles reported in Result.CrackTimes.
+const (
+	GuessesOnlineThrottled   = 10   // Online attack against a rate-limited login
+	GuessesOnlineUnthrottled = 1e3  // Online attack without rate limiting
+	GuessesOfflineSlowHash   = 1e4  // Offline attack against a slow hash such as bcrypt
+	GuessesOfflineFastHash   = 1e10 // Offline attack against a fast hash such as SHA-1
+)
+
+// CrackTimes holds the estimated seconds needed to guess a password for each attacker profile.
+// Seconds are reported as floats because they routinely exceed what time.Duration can hold.
+type CrackTimes struct {
+	OnlineThrottled   float64 `json:"online_throttled"`
+	OnlineUnthrottled float64 `json:"online_unthrottled"`
+	OfflineSlowHash   float64 `json:"offline_slow_hash"`
+	OfflineFastHash   float64 `json:"offline_fast_hash"`
+}
+
+// crackTimesOf estimates CrackTimes for a password with the given entropy.
+func crackTimesOf(entropy float64) CrackTimes {
+	return CrackTimes{
+		OnlineThrottled:   CrackSeconds(entropy, GuessesOnlineThrottled),
+		OnlineUnthrottled: CrackSeconds(entropy, GuessesOnlineUnthrottled),
+		OfflineSlowHash:   CrackSeconds(entropy, GuessesOfflineSlowHash),
+		OfflineFastHash:   CrackSeconds(entropy, GuessesOfflineFastHash),
+	}
+}
+
+// CrackSeconds returns the average seconds an attacker making guessesPerSecond guesses needs to
+// find a password with the given entropy: half of the 2^entropy search space, and at least one
+// guess. Results too large for a float64 are capped at math.MaxFloat64.
+func CrackSeconds(entropy, guessesPerSecond float64) float64 {
+	if guessesPerSecond <= 0 {
+		return math.MaxFloat64
+	}
+	guesses := math.Max(math.Exp2(math.Max(entropy, 0)-1), 1)
+	seconds := guesses / guessesPerSecond
+	if math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return math.MaxFloat64
+	}
+	return seconds
+}
+
+// CrackTime is CrackSeconds as a time.Duration, capped at the largest representable duration
+// (about 292 years).
+func CrackTime(entropy, guessesPerSecond float64) time.Duration {
+	seconds := CrackSeconds(entropy, guessesPerSecond)
+	if seconds >= float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// FormatDuration renders d for people, e.g. "less than a second", "4 days", or "centuries".
+func FormatDuration(d time.Duration) string {
+	return FormatSeconds(d.Seconds())
+}
+
+// FormatSeconds renders a number of seconds for people, e.g. "less than a second", "4 days", or
+// "centuries".
+func FormatSeconds(seconds float64) string {
+	const (
+		minute  = 60
+		hour    = 60 * minute
+		day     = 24 * hour
+		year    = 365.25 * day // Julian years, so months average 30.44 days
+		month   = year / 12
+		century = 100 * year
+	)
+
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds < minute:
+		return plural(seconds, "second")
+	case seconds < hour:
+		return plural(seconds/minute, "minute")
+	case seconds < day:
+		return plural(seconds/hour, "hour")
+	case seconds < month:
+		return plural(seconds/day, "day")
+	case seconds < year:
+		return plural(seconds/month, "month")
+	case seconds < century:
+		return plural(seconds/year, "year")
+	default:
+		return "centuries"
+	}
+}
+
+// plural rounds n down and pairs it with unit, pluralized when needed.
+func plural(n float64, unit string) string {
+	whole := int64(n)
+	if whole == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", whole, unit)
+}
@@ -0,0 +1,107 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCrackSeconds(t *testing.T) {
+	tests := []struct {
+		name    string
+		entropy float64
+		rate    float64
+		want    float64
+	}{
+		{"Zero entropy takes one guess", 0, 10, 0.1},
+		{"Negative entropy takes one guess", -5, 10, 0.1},
+		{"Half the search space on average", 11, 1e3, 1.024},
+		{"Overflowing search space is capped", 5000, 1, math.MaxFloat64},
+		{"No guesses never finishes", 40, 0, math.MaxFloat64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CrackSeconds(tt.entropy, tt.rate); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CrackSeconds(%v, %v) = %v, want %v", tt.entropy, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrackTime(t *testing.T) {
+	if got := CrackTime(0, 10); got != 100*time.Millisecond {
+		t.Errorf("CrackTime(0, 10) = %v, want 100ms", got)
+	}
+	if got := CrackTime(128, GuessesOfflineFastHash); got != time.Duration(math.MaxInt64) {
+		t.Errorf("CrackTime(128, fast hash) = %v, want the maximum duration", got)
+	}
+	if got := CrackTime(5000, 1); got != time.Duration(math.MaxInt64) {
+		t.Errorf("CrackTime(5000, 1) = %v, want the maximum duration", got)
+	}
+}
+
+func TestFormatSeconds(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0, "less than a second"},
+		{0.5, "less than a second"},
+		{1, "1 second"},
+		{42, "42 seconds"},
+		{90, "1 minute"},
+		{7200, "2 hours"},
+		{4 * 86400, "4 days"},
+		{30 * 86400, "30 days"},
+		{31 * 86400, "1 month"},
+		{45 * 86400, "1 month"},
+		{365 * 86400, "11 months"},
+		{366 * 86400, "1 year"},
+		{3 * 365.25 * 86400, "3 years"},
+		{99 * 366 * 86400, "99 years"},
+		{100 * 365.25 * 86400, "centuries"},
+		{math.MaxFloat64, "centuries"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSeconds(tt.seconds); got != tt.want {
+			t.Errorf("FormatSeconds(%v) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := FormatDuration(time.Duration(math.MaxInt64)); got != "centuries" {
+		t.Errorf("FormatDuration(max) = %q, want %q", got, "centuries")
+	}
+	if got := FormatDuration(4 * 24 * time.Hour); got != "4 days" {
+		t.Errorf("FormatDuration(96h) = %q, want %q", got, "4 days")
+	}
+}
+
+func TestAuditCrackTimes(t *testing.T) {
+	result := Audit("Xq7#mB2vLp9!", Options{})
+	if result.CrackTimes != crackTimesOf(result.Entropy) {
+		t.Errorf("Audit() crack times = %+v, want %+v", result.CrackTimes, crackTimesOf(result.Entropy))
+	}
+	if result.CrackTimes.OnlineThrottled <= result.CrackTimes.OfflineFastHash {
+		t.Errorf("Audit() crack times = %+v, want slower attackers to take longer", result.CrackTimes)
+	}
+}
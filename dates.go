@@ -0,0 +1,230 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Match kinds of the dates and numbers reported in Result.Patterns.
+const (
+	PatternYear  MatchKind = "year"  // A four-digit year within Options.YearRange such as "2024"
+	PatternDate  MatchKind = "date"  // A calendar date such as "07041776" or "2024-01-15"; Base holds it as YYYY-MM-DD
+	PatternPhone MatchKind = "phone" // A run of 7 to 11 digits such as a phone number, optionally grouped with separators
+)
+
+// Years is an inclusive range of years.
+type Years struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// DefaultYearRange is the range of years detected when Options.YearRange is zero.
+var DefaultYearRange = Years{Min: 1900, Max: 2049}
+
+// contains reports whether year falls within y.
+func (y Years) contains(year int) bool {
+	return year >= y.Min && year <= y.Max
+}
+
+// yearRangeOf returns y, or DefaultYearRange when y is zero.
+func yearRangeOf(y Years) Years {
+	if y == (Years{}) {
+		return DefaultYearRange
+	}
+	return y
+}
+
+// dateSeparators are the runes that may split the fields of a date or the groups of a number.
+const dateSeparators = "-./ "
+
+// Digit runs of these lengths are read as phone numbers unless they are dates.
+const (
+	minPhoneDigits = 7
+	maxPhoneDigits = 11
+)
+
+// detectDates returns the dates, years within years, and phone-number-like digit runs in pass.
+// Only numbers that read as a plausible calendar date are dates, so "12451999" with its month 45
+// is left to the phone check, and card and SSN-like numbers are skipped. Nothing is allocated when pass has no such numbers.
+func detectDates(pass string, years Years) []Match {
+	var patterns []Match
+	index := 0 // Position of pass[i], counted in runes
+	for i := 0; i < len(pass); {
+		if !isASCIIDigit(pass[i]) {
+			if pass[i] < 0x80 || pass[i] >= 0xC0 {
+				index++
+			}
+			i++
+			continue
+		}
+		end := numberEnd(pass, i)
+		// Tokens are cloned, as pass may be the caller's buffer under AuditBytes.
+		token := pass[i:end]
+		if _, ok := piiKind(token); ok {
+			// Left to detectPII, which keeps the digits out of the match.
+		} else if date, ok := parseDate(token, years); ok {
+			patterns = append(patterns, Match{Kind: PatternDate, Token: strings.Clone(token), Start: index, End: index + len(token), Base: date})
+		} else if digits := digitCount(token); digits >= minPhoneDigits && digits <= maxPhoneDigits {
+			patterns = append(patterns, Match{Kind: PatternPhone, Token: strings.Clone(token), Start: index, End: index + len(token)})
+		} else {
+			patterns = appendYears(patterns, token, index, years)
+		}
+		index += end - i
+		i = end
+	}
+	return patterns
+}
+
+// numberEnd returns the end of the number starting at pass[start]: digits, possibly split into
+// groups by single separators.
+func numberEnd(pass string, start int) int {
+	end := start
+	for end < len(pass) {
+		switch {
+		case isASCIIDigit(pass[end]):
+			end++
+		case strings.IndexByte(dateSeparators, pass[end]) >= 0 && end+1 < len(pass) && isASCIIDigit(pass[end+1]):
+			end += 2
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// digitCount returns the number of digits in a number.
+func digitCount(token string) int {
+	n := 0
+	for i := 0; i < len(token); i++ {
+		if isASCIIDigit(token[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// appendYears appends a year pattern for every group of exactly four digits of token within years.
+func appendYears(patterns []Match, token string, index int, years Years) []Match {
+	for start := 0; start < len(token); {
+		end := start
+		for end < len(token) && isASCIIDigit(token[end]) {
+			end++
+		}
+		if end-start == 4 {
+			if year, _ := strconv.Atoi(token[start:end]); years.contains(year) {
+				digits := strings.Clone(token[start:end])
+				patterns = append(patterns, Match{Kind: PatternYear, Token: digits, Start: index + start, End: index + end, Base: digits})
+			}
+		}
+		start = end + 1
+	}
+	return patterns
+}
+
+// dateLayouts are the positions of the year, month, and day among the fields of a date, most
+// common first.
+var dateLayouts = [...]struct{ year, month, day int }{
+	{2, 0, 1}, // MM/DD/YYYY
+	{2, 1, 0}, // DD/MM/YYYY
+	{0, 1, 2}, // YYYY-MM-DD
+}
+
+// parseDate reports whether token is a plausible date in one of dateLayouts, written as three
+// fields split by the same separator or as six or eight digits, and returns it as YYYY-MM-DD.
+// Four-digit years must fall within years; two-digit years are read as 1950 to 2049.
+func parseDate(token string, years Years) (string, bool) {
+	var splits [len(dateLayouts)][3]string
+	if i := strings.IndexAny(token, dateSeparators); i >= 0 {
+		sep := token[i : i+1]
+		first, rest, _ := strings.Cut(token, sep)
+		second, third, ok := strings.Cut(rest, sep)
+		if !ok || strings.ContainsAny(second, dateSeparators) || strings.ContainsAny(third, dateSeparators) {
+			return "", false
+		}
+		fields := [3]string{first, second, third}
+		splits = [len(dateLayouts)][3]string{fields, fields, fields}
+	} else {
+		switch len(token) {
+		case 8:
+			yearLast := [3]string{token[:2], token[2:4], token[4:]}
+			splits = [len(dateLayouts)][3]string{yearLast, yearLast, {token[:4], token[4:6], token[6:]}}
+		case 6:
+			fields := [3]string{token[:2], token[2:4], token[4:]}
+			splits = [len(dateLayouts)][3]string{fields, fields, fields}
+		default:
+			return "", false
+		}
+	}
+	for i, layout := range dateLayouts {
+		f := splits[i]
+		if date, ok := calendarDate(f[layout.year], f[layout.month], f[layout.day], years); ok {
+			return date, true
+		}
+	}
+	return "", false
+}
+
+// calendarDate reports whether the fields form a real date and returns it as YYYY-MM-DD.
+func calendarDate(y, m, d string, years Years) (string, bool) {
+	if len(y) != 2 && len(y) != 4 || len(m) > 2 || len(d) > 2 {
+		return "", false
+	}
+	year, _ := strconv.Atoi(y)
+	month, _ := strconv.Atoi(m)
+	day, _ := strconv.Atoi(d)
+	switch {
+	case len(y) == 4 && !years.contains(year):
+		return "", false
+	case len(y) == 2 && year < 50:
+		year += 2000
+	case len(y) == 2:
+		year += 1900
+	}
+	if month < 1 || month > 12 || day < 1 || day > daysIn(year, month) {
+		return "", false
+	}
+	return strconv.Itoa(year) + "-" + twoDigits(month) + "-" + twoDigits(day), true
+}
+
+// daysIn returns the number of days in month of year.
+func daysIn(year, month int) int {
+	switch month {
+	case 2:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+// twoDigits formats n with a leading zero below ten.
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// isASCIIDigit reports whether b is an ASCII digit.
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,76 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDetectDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		pass  string
+		years Years
+		want  []Match
+	}{
+		{"Year", "Summer2024!", DefaultYearRange, []Match{{Kind: PatternYear, Token: "2024", Start: 6, End: 10, Base: "2024"}}},
+		{"Year outside the range", "Summer2077!", DefaultYearRange, nil},
+		{"Custom range", "Summer2077!", Years{Min: 2000, Max: 2099}, []Match{{Kind: PatternYear, Token: "2077", Start: 6, End: 10, Base: "2077"}}},
+		{"MMDDYYYY", "07041776", Years{Min: 1700, Max: 2049}, []Match{{Kind: PatternDate, Token: "07041776", Start: 0, End: 8, Base: "1776-07-04"}}},
+		{"DDMMYYYY", "x25121990", DefaultYearRange, []Match{{Kind: PatternDate, Token: "25121990", Start: 1, End: 9, Base: "1990-12-25"}}},
+		{"YYYYMMDD", "20240115", DefaultYearRange, []Match{{Kind: PatternDate, Token: "20240115", Start: 0, End: 8, Base: "2024-01-15"}}},
+		{"YYYY-MM-DD", "born:2024-01-15", DefaultYearRange, []Match{{Kind: PatternDate, Token: "2024-01-15", Start: 5, End: 15, Base: "2024-01-15"}}},
+		{"DD/MM/YY", "ñ31/12/99", DefaultYearRange, []Match{{Kind: PatternDate, Token: "31/12/99", Start: 1, End: 9, Base: "1999-12-31"}}},
+		{"MM.DD.YY", "Kid4.7.05", DefaultYearRange, []Match{{Kind: PatternDate, Token: "4.7.05", Start: 3, End: 9, Base: "2005-04-07"}}},
+		{"Leap day", "29021996", DefaultYearRange, []Match{{Kind: PatternDate, Token: "29021996", Start: 0, End: 8, Base: "1996-02-29"}}},
+		{"No leap day", "29021997", DefaultYearRange, []Match{{Kind: PatternPhone, Token: "29021997", Start: 0, End: 8}}},
+		{"Implausible month", "12451999", DefaultYearRange, []Match{{Kind: PatternPhone, Token: "12451999", Start: 0, End: 8}}},
+		{"Mixed separators", "2024-01.15", DefaultYearRange, []Match{{Kind: PatternPhone, Token: "2024-01.15", Start: 0, End: 10}}},
+		{"Phone number", "call555-123-4567", DefaultYearRange, []Match{{Kind: PatternPhone, Token: "555-123-4567", Start: 4, End: 16}}},
+		{"Too many digits for a phone", "123456789012", DefaultYearRange, nil},
+		{"Short numbers", "P@sswørd12345!", DefaultYearRange, nil},
+		{"Version", "v1.2.3", DefaultYearRange, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDates(tt.pass, tt.years); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectDates(%q) = %+v, want %+v", tt.pass, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditDates(t *testing.T) {
+	for _, pass := range []string{"Summer2024!", "Jane07041990", "Call5551234567"} {
+		result := Audit(pass, Options{RejectDates: true})
+		var verr *ValidationError
+		if !errors.Is(result.Err, ErrDate) || !errors.As(result.Err, &verr) || verr.Code != CodeDate {
+			t.Errorf("Audit(%q) with RejectDates error = %v, want %v", pass, result.Err, ErrDate)
+		}
+		if result := Audit(pass, Options{}); result.Err != nil || result.EffectiveEntropy >= result.CharsetEntropy {
+			t.Errorf("Audit(%q) error = %v, EffectiveEntropy = %.2f, want nil and below %.2f", pass, result.Err, result.EffectiveEntropy, result.CharsetEntropy)
+		}
+	}
+	if result := Audit("Xq7mB2vLp9!w4Z", Options{RejectDates: true}); result.Err != nil {
+		t.Errorf("Audit() with RejectDates error = %v, want nil", result.Err)
+	}
+	if err := (Options{YearRange: Years{Min: 2050, Max: 1900}}).Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() with an inverted YearRange = %v, want %v", err, ErrInvalidOptions)
+	}
+}
@@ -0,0 +1,146 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// MinSaltBytes is the shortest salt DeriveKey and GenerateSalt accept.
+const MinSaltBytes = 16
+
+// MaxDerivedKeyBytes is the longest key DeriveKey returns, the HKDF-SHA-256 limit of 255 blocks.
+const MaxDerivedKeyBytes = 255 * sha256.Size
+
+// DeriveKey derives a length-byte encryption key from password with DefaultArgon2Params. See
+// DeriveKeyWithParams.
+func DeriveKey(password, salt []byte, info string, length int) ([]byte, error) {
+	return DeriveKeyWithParams(password, salt, info, length, DefaultArgon2Params)
+}
+
+// DeriveKeyWithParams stretches password and salt with Argon2id at p into a 32-byte secret and
+// expands it with HKDF-SHA-256 and info into a length-byte key, so each info string, such as
+// "files" or "backups", gives an independent key from the one password. p.SaltLength and
+// p.KeyLength are ignored. Store salt with the user, and never reuse the login hash or its salt
+// as a key. Salts shorter than MinSaltBytes fail with ErrSaltTooShort and lengths outside 1 to
+// MaxDerivedKeyBytes with ErrInvalidKeyLength.
+func DeriveKeyWithParams(password, salt []byte, info string, length int, p Argon2Params) ([]byte, error) {
+	if len(salt) < MinSaltBytes {
+		return nil, fmt.Errorf("%w: %d bytes, want at least %d", ErrSaltTooShort, len(salt), MinSaltBytes)
+	}
+	if length < 1 || length > MaxDerivedKeyBytes {
+		return nil, fmt.Errorf("%w: %d bytes, want 1 to %d", ErrInvalidKeyLength, length, MaxDerivedKeyBytes)
+	}
+	if err := p.checkCost(); err != nil {
+		return nil, err
+	}
+	secret := argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, sha256.Size)
+	defer wipe(secret)
+	return hkdf.Expand(sha256.New, secret, info, length)
+}
+
+// GenerateSalt returns n random bytes from crypto/rand for DeriveKey. n below MinSaltBytes fails
+// with ErrSaltTooShort.
+func GenerateSalt(n int) ([]byte, error) {
+	if n < MinSaltBytes {
+		return nil, fmt.Errorf("%w: %d bytes, want at least %d", ErrSaltTooShort, n, MinSaltBytes)
+	}
+	return randomSalt(n)
+}
+
+// derivePasswordDomain separates the inputs of DerivePassword from every other use of the same
+// master secret. Changing it, or derivePasswordParams, changes every derived password.
+const derivePasswordDomain = "go-passwd DerivePassword v1"
+
+// derivePasswordParams are the Argon2id costs of DerivePassword, fixed so a password derived
+// today is derived again by every later version.
+var derivePasswordParams = Argon2Params{Memory: 64 * 1024, Iterations: 3, Parallelism: 1}
+
+// DerivePassword derives the password for username at site from master, shaped like template as
+// GenerateFromTemplate reads it, for a stateless password manager: the same inputs always yield
+// the same password and nothing needs storing. Incrementing counter rotates the password.
+//
+// Argon2id (64 MiB, 3 passes, 1 lane) stretches master with a salt hashing the site and username,
+// and HKDF-SHA-256 expands the result with the counter into a byte stream. Each placeholder takes
+// bytes from the stream by rejection sampling, so every character of its set is equally likely.
+// The scheme is fixed and pinned by tests. An empty master or site fails with ErrEmptyMaster or
+// ErrEmptySite, and an invalid template with a *TemplateError.
+func DerivePassword(master Secret, site, username string, counter uint32, template string) (string, error) {
+	if master.Len() == 0 {
+		return "", ErrEmptyMaster
+	}
+	if site == "" {
+		return "", ErrEmptySite
+	}
+	var g Generator
+	parts, err := g.parseTemplate(template)
+	if err != nil {
+		return "", err
+	}
+
+	// Length prefixes keep ("ab", "c") and ("a", "bc") apart.
+	salt := sha256.New()
+	for _, field := range []string{derivePasswordDomain, site, username} {
+		salt.Write(binary.BigEndian.AppendUint32(nil, uint32(len(field))))
+		salt.Write([]byte(field))
+	}
+	info := string(binary.BigEndian.AppendUint32([]byte(derivePasswordDomain), counter))
+	stream, err := DeriveKeyWithParams(master.bytes(), salt.Sum(nil), info, MaxDerivedKeyBytes, derivePasswordParams)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(stream)
+
+	out, err := sampleTemplate(parts, stream)
+	defer zeroRunes(out)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// sampleTemplate fills parts from stream, rejecting any byte at or above the largest multiple of
+// a set's size so the modulo that follows is unbiased. Template sets are far smaller than 256.
+func sampleTemplate(parts []templatePart, stream []byte) ([]rune, error) {
+	out := make([]rune, len(parts))
+	next := 0
+	for i, part := range parts {
+		if part.set == nil {
+			out[i] = part.literal
+			continue
+		}
+		limit := 256 - 256%len(part.set)
+		for {
+			if next == len(stream) {
+				return out, errors.New("derived byte stream exhausted")
+			}
+			b := int(stream[next])
+			next++
+			if b < limit {
+				out[i] = part.set[b%len(part.set)]
+				break
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,168 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// deriveVectorParams are spelled out rather than reusing testArgon2Params so the pinned keys
+// below only change if DeriveKey itself does.
+var deriveVectorParams = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestDeriveKeyVectors(t *testing.T) {
+	tests := []struct {
+		password string
+		salt     string
+		info     string
+		length   int
+		want     string
+	}{
+		{"password", "somesaltsomesalt", "files", 32, "aac996d60c42838d69b0147b52d8fcae3d3d943b6f6e42e32fa2c9fc01f98dc4"},
+		{"password", "somesaltsomesalt", "backups", 32, "a60ca422396875d7be4984d989f306cb2e209ad635f60d3c227b2fec8464ceaa"},
+		{"password", "somesaltsomesalt", "files", 64, "aac996d60c42838d69b0147b52d8fcae3d3d943b6f6e42e32fa2c9fc01f98dc4e0c5c544be0bf58b18e26263e5765c634eaabdaa419c303ff97ae6a74a6b5f7c"},
+		{"Pässwørd-密码", "0123456789abcdef", "", 16, "a6311e3a972cf4e24527cdb290eeaed5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.info, func(t *testing.T) {
+			key, err := DeriveKeyWithParams([]byte(tt.password), []byte(tt.salt), tt.info, tt.length, deriveVectorParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(key); got != tt.want {
+				t.Errorf("DeriveKeyWithParams() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	key, err := DeriveKey([]byte("password"), []byte("somesaltsomesalt"), "files", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(key), "989f7110566f2de3e87a0ed25834b9c006baa3a90bd41ebf6ba5c3a9a74d4676"; got != want {
+		t.Errorf("DeriveKey() at DefaultArgon2Params = %s, want %s", got, want)
+	}
+}
+
+func TestDeriveKeyErrors(t *testing.T) {
+	salt := bytes.Repeat([]byte{1}, MinSaltBytes)
+	tests := []struct {
+		name   string
+		salt   []byte
+		length int
+		params Argon2Params
+		want   error
+	}{
+		{"Short salt", salt[:MinSaltBytes-1], 32, deriveVectorParams, ErrSaltTooShort},
+		{"Zero length", salt, 0, deriveVectorParams, ErrInvalidKeyLength},
+		{"Absurd length", salt, MaxDerivedKeyBytes + 1, deriveVectorParams, ErrInvalidKeyLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DeriveKeyWithParams([]byte("password"), tt.salt, "files", tt.length, tt.params); !errors.Is(err, tt.want) {
+				t.Errorf("DeriveKeyWithParams() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := DeriveKeyWithParams([]byte("password"), salt, "files", 32, Argon2Params{}); err == nil {
+		t.Error("DeriveKeyWithParams() with zero parameters succeeded")
+	}
+	if key, err := DeriveKeyWithParams([]byte("password"), salt, "files", MaxDerivedKeyBytes, deriveVectorParams); err != nil || len(key) != MaxDerivedKeyBytes {
+		t.Errorf("DeriveKeyWithParams() at MaxDerivedKeyBytes = %d bytes, %v", len(key), err)
+	}
+}
+
+func TestGenerateSalt(t *testing.T) {
+	a, err := GenerateSalt(MinSaltBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := GenerateSalt(MinSaltBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != MinSaltBytes || bytes.Equal(a, b) {
+		t.Errorf("GenerateSalt() = %x then %x, want two different %d-byte salts", a, b, MinSaltBytes)
+	}
+	if _, err := GenerateSalt(MinSaltBytes - 1); !errors.Is(err, ErrSaltTooShort) {
+		t.Errorf("GenerateSalt(%d) error = %v, want ErrSaltTooShort", MinSaltBytes-1, err)
+	}
+}
+
+func TestDerivePassword(t *testing.T) {
+	master := NewSecret("correct horse battery staple")
+	tests := []struct {
+		site     string
+		username string
+		counter  uint32
+		template string
+		want     string
+	}{
+		{"example.com", "alice", 1, `Ulllllldd\-s`, "Fgircup44-$"},
+		{"example.com", "alice", 2, `Ulllllldd\-s`, "Qqfyfgq60-~"},
+		{"example.com", "bob", 1, `Ulllllldd\-s`, `Qietlzn15-"`},
+		{"github.com", "alice", 1, "xxxxxxxxxxxxxxxxxxxx", "U/d4Qej^SHu}6Ctm!]C4"},
+		{"bank.example", "", 0, "dddddd", "744787"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.site+"/"+tt.username, func(t *testing.T) {
+			got, err := DerivePassword(master, tt.site, tt.username, tt.counter, tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DerivePassword() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDerivePasswordErrors(t *testing.T) {
+	master := NewSecret("correct horse battery staple")
+	if _, err := DerivePassword(Secret{}, "example.com", "alice", 1, "xxxx"); !errors.Is(err, ErrEmptyMaster) {
+		t.Errorf("DerivePassword() with no master error = %v, want ErrEmptyMaster", err)
+	}
+	if _, err := DerivePassword(master, "", "alice", 1, "xxxx"); !errors.Is(err, ErrEmptySite) {
+		t.Errorf("DerivePassword() with no site error = %v, want ErrEmptySite", err)
+	}
+	var te *TemplateError
+	if _, err := DerivePassword(master, "example.com", "alice", 1, "q"); !errors.As(err, &te) {
+		t.Errorf("DerivePassword() with a bad template error = %v, want *TemplateError", err)
+	}
+}
+
+func TestSampleTemplateRejectsBias(t *testing.T) {
+	var g Generator
+	parts, err := g.parseTemplate(`dd\-d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 256 % 10 leaves 250 as the limit: 250 and 255 are rejected, never folded onto 0 and 5.
+	got, err := sampleTemplate(parts, []byte{250, 3, 255, 249, 17})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "39-7" {
+		t.Errorf("sampleTemplate() = %q, want %q", string(got), "39-7")
+	}
+	if _, err := sampleTemplate(parts, []byte{1, 250}); err == nil {
+		t.Error("sampleTemplate() on an exhausted stream succeeded")
+	}
+}
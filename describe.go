@@ -0,0 +1,198 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Describe returns one English sentence per requirement Audit enforces with opts, in the order
+// Audit checks them, for showing the policy to users.
+func (opts Options) Describe() []string {
+	return opts.DescribeLocale(DefaultLanguage)
+}
+
+// DescribeLocale is like Describe but uses the messages registered for lang with RegisterMessages,
+// falling back to English.
+func (opts Options) DescribeLocale(lang string) []string {
+	var lines []string
+	add := func(key string, args ...any) {
+		lines = append(lines, message(lang, key, args...))
+	}
+
+	switch {
+	case opts.MinLength > 0 && opts.MaxLength > 0:
+		add("describe.length_range", "min_length", opts.MinLength, "max_length", opts.MaxLength)
+	case opts.MinLength > 0:
+		add("describe.min_length", "min_length", opts.MinLength)
+	case opts.MaxLength > 0:
+		add("describe.max_length", "max_length", opts.MaxLength)
+	}
+
+	if opts.AllowedChars != "" {
+		add("describe.allowed_chars", "chars", opts.AllowedChars)
+	}
+	if opts.DisallowedChars != "" {
+		add("describe.disallowed_chars", "chars", opts.DisallowedChars)
+	}
+	if opts.RejectInvisibleChars {
+		add("describe.invisible_chars")
+	}
+	if opts.MaxBytes > 0 {
+		add("describe.max_bytes", "max_bytes", opts.MaxBytes)
+	}
+	if opts.RejectWhitespace {
+		add("describe.whitespace")
+	} else if opts.ForbidLeadingTrailingSpace {
+		add("describe.edge_whitespace")
+	}
+	if opts.FirstCharClasses != 0 {
+		add("describe.first_char", "classes", classList(lang, opts.FirstCharClasses))
+	}
+	if opts.LastCharClasses != 0 {
+		add("describe.last_char", "classes", classList(lang, opts.LastCharClasses))
+	}
+	if opts.Stringprep {
+		add("describe.stringprep")
+	}
+	if opts.RejectConfusables {
+		add("describe.confusables")
+	}
+
+	for _, pattern := range opts.RequiredPatterns {
+		add("describe.required_pattern", "pattern", pattern)
+	}
+	for _, pattern := range opts.ForbiddenPatterns {
+		add("describe.forbidden_pattern", "pattern", pattern)
+	}
+
+	symbolOne, symbolMany := "describe.symbol", "describe.symbols"
+	if opts.SymbolSet != "" {
+		symbolOne, symbolMany = "describe.symbol_from", "describe.symbols_from"
+	}
+	classes := []struct {
+		one, many string
+		count     uint
+	}{
+		{"describe.digit", "describe.digits", minimumCount(opts.UseDigits, opts.MinDigits)},
+		{"describe.lower", "describe.lowers", minimumCount(opts.UseLower, opts.MinLower)},
+		{"describe.upper", "describe.uppers", minimumCount(opts.UseUpper, opts.MinUpper)},
+		{symbolOne, symbolMany, minimumCount(opts.UseSymbols, opts.MinSymbols)},
+		{"describe.extended", "describe.extendeds", minimumCount(opts.UseExtended, opts.MinExtended)},
+		{"describe.emoji", "describe.emoji", minimumCount(opts.UseEmoji, 0)},
+	}
+	if opts.MinClasses > 0 {
+		enabled := 0
+		for _, class := range classes {
+			if class.count > 0 {
+				enabled++
+			}
+		}
+		if enabled == 0 {
+			for i := range classes[:defaultClassCount] {
+				classes[i].count = 1
+			}
+			enabled = defaultClassCount
+		}
+		add("describe.min_classes", "min_classes", opts.MinClasses, "count", enabled)
+	}
+	for _, class := range classes {
+		switch {
+		case class.count == 1:
+			add(class.one, "symbols", opts.SymbolSet)
+		case class.count > 1:
+			add(class.many, "count", class.count, "symbols", opts.SymbolSet)
+		}
+	}
+
+	if opts.MinUniqueChars > 0 {
+		add("describe.min_unique_chars", "min_unique_chars", opts.MinUniqueChars)
+	}
+	if opts.MaxClassRatio > 0 {
+		add("describe.max_class_ratio", "max_percent", strconv.FormatFloat(math.Round(opts.MaxClassRatio*100), 'f', -1, 64))
+	}
+
+	if opts.MaxSequenceLength > 0 && opts.RejectSequences {
+		add("describe.sequences", "max", opts.MaxSequenceLength)
+	}
+	if opts.MaxRepeatRun > 0 {
+		add("describe.repeats", "max", opts.MaxRepeatRun)
+	}
+	if opts.KeyboardWalkLength > 0 && opts.RejectKeyboardWalks {
+		add("describe.keyboard_walks", "length", opts.KeyboardWalkLength)
+	}
+	if opts.RejectDates {
+		years := yearRangeOf(opts.YearRange)
+		add("describe.dates", "min_year", years.Min, "max_year", years.Max)
+	}
+	if opts.RejectPII {
+		add("describe.pii")
+	}
+	if len(opts.UserInputs) > 0 {
+		add("describe.user_inputs")
+	}
+	if len(opts.PreviousPasswords) > 0 {
+		limit := opts.MaxSimilarity
+		if limit == 0 {
+			limit = DefaultMaxSimilarity
+		}
+		add("describe.previous_passwords", "percent", strconv.FormatFloat(math.Round(limit*100), 'f', -1, 64))
+	}
+	if opts.RejectCommon {
+		add("describe.reject_common")
+		if len(opts.ExtraDictionary) > 0 {
+			add("describe.extra_dictionary", "count", len(opts.ExtraDictionary))
+		}
+	}
+	if opts.Dictionary != nil {
+		add("describe.dictionary")
+	}
+	if opts.NormalizeLeet && (opts.RejectCommon || opts.Dictionary != nil || opts.BreachChecker != nil) {
+		add("describe.normalize_leet")
+	}
+	if opts.BreachChecker != nil && opts.BreachCheckMode != BreachSkip {
+		add("describe.breach")
+	}
+	if opts.History != nil {
+		switch n := len(opts.History.Hashes); {
+		case n == 1:
+			add("describe.history_one")
+		case n > 1:
+			add("describe.history", "count", n)
+		}
+	}
+	if opts.MinEntropy > 0 {
+		add("describe.min_entropy", "bits", strconv.FormatFloat(math.Ceil(opts.MinEntropy), 'f', -1, 64))
+	}
+	for _, rule := range opts.CustomRules {
+		add("describe.custom_rule", "name", rule.Name())
+	}
+	return lines
+}
+
+// classList names the classes in the bitmask for a sentence in lang, such as "digit, symbol".
+func classList(lang string, classes Class) string {
+	var names []string
+	for i, name := range classNames {
+		if classes&(1<<i) != 0 {
+			names = append(names, message(lang, "class."+name))
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,199 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		want    []string
+	}{
+		{
+			name:    "No requirements",
+			options: Options{},
+			want:    nil,
+		},
+		{
+			name:    "Length range and classes",
+			options: Options{MinLength: 12, MaxLength: 64, UseUpper: true, UseDigits: true, MinSymbols: 2},
+			want: []string{
+				"Must be between 12 and 64 characters long.",
+				"Must include a digit.",
+				"Must include an uppercase letter.",
+				"Must include at least 2 symbols.",
+			},
+		},
+		{
+			name:    "Minimum length only",
+			options: Options{MinLength: 8},
+			want:    []string{"Must be at least 8 characters long."},
+		},
+		{
+			name:    "Maximum length only",
+			options: Options{MaxLength: 20},
+			want:    []string{"Must be at most 20 characters long."},
+		},
+		{
+			name:    "Sequences are described only when rejected",
+			options: Options{MaxSequenceLength: 3},
+			want:    nil,
+		},
+		{
+			name: "NIST preset",
+			options: func() Options {
+				o := NISTOptions()
+				o.UserInputs = []string{"alice"}
+				return o
+			}(),
+			want: []string{
+				"Must be between 8 and 64 characters long.",
+				"Must not contain more than 3 sequential characters, such as abcd or 4321.",
+				"Must not repeat a character more than 3 times in a row.",
+				"Must not contain your name, username, or email address.",
+				"Must not be a commonly used password.",
+				"Swapping letters for look-alikes, as in p@ssw0rd, does not disguise a banned word.",
+				"Must not appear in known data breaches.",
+			},
+		},
+		{
+			name: "Rotation and entropy",
+			options: Options{
+				PreviousPasswords: []string{"Winter2023!"},
+				History:           &HistoryChecker{Hashes: []string{"a", "b", "c", "d"}},
+				MinEntropy:        59.5,
+			},
+			want: []string{
+				"Must be less than 70% similar to your previous passwords.",
+				"Must not reuse any of your last 4 passwords.",
+				"Must carry at least 60 bits of entropy.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.Describe(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// describeExempt lists the Options fields that do not make Audit reject passwords.
+var describeExempt = map[string]string{
+	"MinimumComplexity":  "only affects Result.Strong",
+	"BreachFailOpen":     "only matters when the breach checker fails",
+	"BreachCheckMode":    "only loosens the breach check",
+	"BreachCheckTimeout": "only matters when the breach checker is slow",
+	"PolicyName":         "a label",
+	"FailFast":           "changes how violations are reported, not which passwords pass",
+	"AllowControlChars":  "relaxes a check every policy enforces",
+	"ReplaceInvalidUTF8": "changes how invalid input is read",
+	"Normalize":          "changes how the password is read",
+	"UnicodeClasses":     "changes how characters are classified",
+	"UseMarkov":          "changes how entropy is estimated",
+	"CountGraphemes":     "changes how length is counted",
+	"MarkovModel":        "changes how entropy is estimated",
+	"Report":             "collects statistics",
+	"Metrics":            "exports counters",
+	"Matchers":           "caps entropy",
+	"Logger":             "logs outcomes",
+	"LogLevel":           "logs outcomes",
+}
+
+// describePrerequisites sets the fields another field needs before Audit enforces it.
+var describePrerequisites = map[string]func(*Options){
+	"ExtraDictionary":     func(o *Options) { o.RejectCommon = true },
+	"NormalizeLeet":       func(o *Options) { o.RejectCommon = true },
+	"MaxSequenceLength":   func(o *Options) { o.RejectSequences = true },
+	"RejectSequences":     func(o *Options) { o.MaxSequenceLength = 3 },
+	"KeyboardWalkLength":  func(o *Options) { o.RejectKeyboardWalks = true },
+	"RejectKeyboardWalks": func(o *Options) { o.KeyboardWalkLength = 4 },
+	"MaxSimilarity":       func(o *Options) { o.PreviousPasswords = []string{"Winter2023!"} },
+	"SymbolSet":           func(o *Options) { o.UseSymbols = true },
+	"YearRange":           func(o *Options) { o.RejectDates = true },
+}
+
+// optionTestValues returns a value that enables an Options field, by the type of the field.
+func optionTestValues() map[reflect.Type]reflect.Value {
+	checker := BreachCheckerFunc(func(context.Context, string) (bool, int, error) { return false, 0, nil })
+	return map[reflect.Type]reflect.Value{
+		reflect.TypeOf(uint(0)):                          reflect.ValueOf(uint(3)),
+		reflect.TypeOf(""):                               reflect.ValueOf("!#"),
+		reflect.TypeOf(true):                             reflect.ValueOf(true),
+		reflect.TypeOf(0.5):                              reflect.ValueOf(0.5),
+		reflect.TypeOf([]string(nil)):                    reflect.ValueOf([]string{"acmecorp"}),
+		reflect.TypeOf((*DictionaryChecker)(nil)).Elem(): reflect.ValueOf(NewWordList("acmecorp")),
+		reflect.TypeOf((*BreachChecker)(nil)).Elem():     reflect.ValueOf(checker),
+		reflect.TypeOf((*HistoryChecker)(nil)):           reflect.ValueOf(&HistoryChecker{Hashes: []string{"a"}}),
+		reflect.TypeOf(Class(0)):                         reflect.ValueOf(ClassLower | ClassUpper),
+		reflect.TypeOf(Years{}):                          reflect.ValueOf(Years{Min: 1950, Max: 2030}),
+		reflect.TypeOf([]Rule(nil)):                      reflect.ValueOf([]Rule{NewRule("quarter", func(string) error { return nil })}),
+	}
+}
+
+func TestDescribeCoversEveryOption(t *testing.T) {
+	values := optionTestValues()
+
+	fields := reflect.TypeOf(Options{})
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		t.Run(field.Name, func(t *testing.T) {
+			if reason, ok := describeExempt[field.Name]; ok {
+				t.Skip(reason)
+			}
+			value, ok := values[field.Type]
+			if !ok {
+				t.Fatalf("no test value for %s of type %s; add one or exempt the field", field.Name, field.Type)
+			}
+
+			var base Options
+			if prepare, ok := describePrerequisites[field.Name]; ok {
+				prepare(&base)
+			}
+			set := base
+			reflect.ValueOf(&set).Elem().Field(i).Set(value)
+
+			if before, after := base.Describe(), set.Describe(); reflect.DeepEqual(before, after) {
+				t.Errorf("Describe() does not mention %s: %q", field.Name, after)
+			}
+		})
+	}
+}
+
+func TestDescribeLocale(t *testing.T) {
+	RegisterMessages("xx", map[string]string{
+		"describe.min_length": "Au moins {min_length} caractères.",
+	})
+	options := Options{MinLength: 10, UseDigits: true}
+
+	want := []string{"Au moins 10 caractères.", "Must include a digit."}
+	for _, lang := range []string{"xx", "xx-YY", "xx_YY"} {
+		if got := options.DescribeLocale(lang); !reflect.DeepEqual(got, want) {
+			t.Errorf("DescribeLocale(%q) = %q, want %q", lang, got, want)
+		}
+	}
+	if got, want := options.DescribeLocale("zz"), options.Describe(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DescribeLocale(%q) = %q, want English %q", "zz", got, want)
+	}
+}
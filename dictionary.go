@@ -0,0 +1,217 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	_ "embed"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed data/common_passwords.txt
+var commonPasswordsData string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]int // Rank in the embedded list, most common first, from 1
+)
+
+// loadCommonPasswords builds the lookup table for the embedded list on first use.
+func loadCommonPasswords() map[string]int {
+	commonPasswordsOnce.Do(func() {
+		lines := strings.Split(commonPasswordsData, "\n")
+		commonPasswords = make(map[string]int, len(lines))
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" {
+				if _, dup := commonPasswords[line]; !dup {
+					commonPasswords[line] = len(commonPasswords) + 1
+				}
+			}
+		}
+	})
+	return commonPasswords
+}
+
+// DictionaryChecker reports whether a word is in a list of banned passwords. Audit calls Contains
+// with the lowercased password and, when different, its form with trailing digits and symbols
+// stripped.
+type DictionaryChecker interface {
+	Contains(word string) bool
+}
+
+// commonPasswordList is the DictionaryChecker backed by the embedded common password list.
+type commonPasswordList struct{}
+
+func (commonPasswordList) Contains(word string) bool {
+	_, ok := loadCommonPasswords()[strings.ToLower(word)]
+	return ok
+}
+
+// rank returns the position of word in the embedded list, most common first, or zero.
+func (commonPasswordList) rank(word string) int {
+	return loadCommonPasswords()[strings.ToLower(word)]
+}
+
+// CommonPasswords is a DictionaryChecker over the embedded list of common leaked passwords.
+var CommonPasswords DictionaryChecker = commonPasswordList{}
+
+// WordList is a DictionaryChecker over a caller-supplied set of lowercased words.
+type WordList map[string]struct{}
+
+// NewWordList returns a WordList containing words, compared case-insensitively.
+func NewWordList(words ...string) WordList {
+	list := make(WordList, len(words))
+	for _, word := range words {
+		list[strings.ToLower(word)] = struct{}{}
+	}
+	return list
+}
+
+// Contains reports whether word is in the list, ignoring case.
+func (list WordList) Contains(word string) bool {
+	_, ok := list[strings.ToLower(word)]
+	return ok
+}
+
+// rank returns the size of the list for a word it contains, as every word is equally likely, or
+// zero.
+func (list WordList) rank(word string) int {
+	if !list.Contains(word) {
+		return 0
+	}
+	return len(list)
+}
+
+// IsCommonPassword reports whether pass, compared case-insensitively and with any trailing digits
+// and symbols removed, appears in the embedded list of common passwords or in extra.
+func IsCommonPassword(pass string, extra ...string) bool {
+	v := newValidator(Options{RejectCommon: true, ExtraDictionary: extra}, nil, nil)
+	_, ok := v.dictionaryWord(pass)
+	return ok
+}
+
+// dictionaryWord returns the word of a dictionary enabled by the Validator that the password is,
+// and whether there is one. The empty string is never a word.
+func (v *Validator) dictionaryWord(pass string) (string, bool) {
+	if pass == "" || !v.opts.RejectCommon && v.opts.Dictionary == nil {
+		return "", false
+	}
+	for _, candidate := range dictionaryCandidates(pass) {
+		if v.containsWord(candidate) {
+			return strings.Clone(candidate), true // candidate may share pass's memory under AuditBytes
+		}
+	}
+	return "", false
+}
+
+// containsWord reports whether any dictionary enabled by the Validator contains word as given.
+func (v *Validator) containsWord(word string) bool {
+	if v.opts.RejectCommon && (CommonPasswords.Contains(word) || v.isBanned(word)) {
+		return true
+	}
+	return v.opts.Dictionary != nil && v.opts.Dictionary.Contains(word)
+}
+
+// bannedWords is an immutable snapshot of a Validator's ExtraDictionary. Changes build a new
+// snapshot and swap it in, so lookups never lock.
+type bannedWords struct {
+	list  WordList
+	words []string // In the order they were added, as Options reports them
+}
+
+// newBannedWords returns a snapshot holding words, then add, less remove, compared
+// case-insensitively. Empty words are skipped.
+func newBannedWords(words, add, remove []string) *bannedWords {
+	removed := NewWordList(remove...)
+	next := &bannedWords{list: make(WordList, len(words)+len(add))}
+	for _, word := range slices.Concat(words, add) {
+		key := strings.ToLower(word)
+		if _, dup := next.list[key]; word == "" || dup || removed.Contains(key) {
+			continue
+		}
+		next.list[key] = struct{}{}
+		next.words = append(next.words, word)
+	}
+	return next
+}
+
+// bannedWords returns the current snapshot, or nil when the banned words never changed and
+// ExtraDictionary applies as compiled.
+func (v *Validator) bannedWords() *bannedWords {
+	if v.banned == nil {
+		return nil
+	}
+	return v.banned.Load()
+}
+
+// isBanned reports whether word, ignoring case, is in the Validator's ExtraDictionary.
+func (v *Validator) isBanned(word string) bool {
+	if b := v.bannedWords(); b != nil {
+		return b.list.Contains(word)
+	}
+	return v.extra.Contains(word)
+}
+
+// AddBannedWords adds words to the Validator's ExtraDictionary without rebuilding it. Like
+// ExtraDictionary, they are only rejected when RejectCommon is set. It is safe to call while other
+// goroutines audit: the list is copied, changed, and swapped in atomically, so Audit never waits
+// on a lock. Audits already running may still see the old list, and one running across a change
+// may check some candidates against each. Words are compared case-insensitively. It fails with
+// ErrValidatorNotBuilt when v does not come from NewValidator or New.
+func (v *Validator) AddBannedWords(words ...string) error {
+	return v.updateBannedWords(words, nil)
+}
+
+// RemoveBannedWords removes words, compared case-insensitively, from the Validator's
+// ExtraDictionary, with the same consistency and errors as AddBannedWords. The embedded common
+// passwords cannot be removed.
+func (v *Validator) RemoveBannedWords(words ...string) error {
+	return v.updateBannedWords(nil, words)
+}
+
+// updateBannedWords swaps in a snapshot with add and without remove, retrying when another
+// change lands first so concurrent updates are never lost.
+func (v *Validator) updateBannedWords(add, remove []string) error {
+	if v.banned == nil {
+		return ErrValidatorNotBuilt
+	}
+	for {
+		old := v.banned.Load()
+		words := v.opts.ExtraDictionary
+		if old != nil {
+			words = old.words
+		}
+		if v.banned.CompareAndSwap(old, newBannedWords(words, add, remove)) {
+			return nil
+		}
+	}
+}
+
+// dictionaryCandidates returns the lowercased password and, when different, the same value with
+// trailing digits and symbols stripped so "password1!" is matched as "password".
+func dictionaryCandidates(pass string) []string {
+	lower := strings.ToLower(pass)
+	stripped := strings.TrimRightFunc(lower, func(r rune) bool {
+		return unicode.IsDigit(r) || (r <= unicode.MaxASCII && strings.ContainsRune(symbolChars, r))
+	})
+	if stripped == "" || stripped == lower {
+		return []string{lower}
+	}
+	return []string{lower, stripped}
+}
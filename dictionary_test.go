@@ -0,0 +1,236 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestIsCommonPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		extra    []string
+		want     bool
+	}{
+		{"password", nil, true},
+		{"PASSWORD", nil, true},
+		{"Password123!", nil, true},
+		{"password1!", nil, true},
+		{"dragon", nil, true},
+		{"123456", nil, true},
+		{"Xq7#mB2vLp9!", nil, false},
+		{"AcmeCorp2024!", []string{"acmecorp"}, true},
+		{"AcmeCorp2024!", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCommonPassword(tt.password, tt.extra...); got != tt.want {
+			t.Errorf("IsCommonPassword(%q, %v) = %v, want %v", tt.password, tt.extra, got, tt.want)
+		}
+	}
+}
+
+func TestCommonPasswordsSize(t *testing.T) {
+	// The README and data/README.md document this size.
+	if got := len(loadCommonPasswords()); got != 7141 {
+		t.Errorf("embedded list holds %d passwords, want 7141", got)
+	}
+}
+
+func TestAuditRejectCommon(t *testing.T) {
+	options := Options{MinLength: 8, UseDigits: true, UseUpper: true, UseSymbols: true, RejectCommon: true}
+
+	if result := Audit("Password123!", options); !errors.Is(result.Err, ErrCommonPassword) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrCommonPassword)
+	}
+	if result := Audit("Xq7#mB2vLp9!", options); result.Err != nil {
+		t.Errorf("Audit() error = %v, want nil", result.Err)
+	}
+
+	options.RejectCommon = false
+	if result := Audit("Password123!", options); result.Err != nil {
+		t.Errorf("Audit() error = %v, want nil when RejectCommon is unset", result.Err)
+	}
+}
+
+// recordingDictionary is a DictionaryChecker that records every word it is asked about.
+type recordingDictionary struct {
+	words map[string]bool
+	asked []string
+}
+
+func (d *recordingDictionary) Contains(word string) bool {
+	d.asked = append(d.asked, word)
+	return d.words[word]
+}
+
+func TestAuditDictionaryChecker(t *testing.T) {
+	dictionary := &recordingDictionary{words: map[string]bool{"acmecorp": true}}
+	breachCalled := false
+	breach := BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+		breachCalled = true
+		return false, 0, nil
+	})
+	options := Options{MinLength: 8, Dictionary: dictionary, BreachChecker: breach}
+
+	result := Audit("AcmeCorp2024!", options)
+	if !errors.Is(result.Err, ErrCommonPassword) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrCommonPassword)
+	}
+	if want := []string{"acmecorp2024!", "acmecorp"}; !reflect.DeepEqual(dictionary.asked, want) {
+		t.Errorf("Dictionary asked %v, want %v", dictionary.asked, want)
+	}
+	if breachCalled {
+		t.Errorf("BreachChecker called after the dictionary rejected the password")
+	}
+
+	// The local dictionary still runs so Patterns and Score are right; the network check does not.
+	dictionary.asked = nil
+	Audit("short", options)
+	if len(dictionary.asked) == 0 || dictionary.asked[0] != "short" {
+		t.Errorf("Dictionary asked %v for a password that failed the cheap checks, want it asked", dictionary.asked)
+	}
+	if breachCalled {
+		t.Errorf("BreachChecker called for a password that failed the cheap checks")
+	}
+
+	Audit("Xq7#mB2vLp9!", options)
+	if !breachCalled {
+		t.Errorf("BreachChecker not called after the dictionary checks passed")
+	}
+}
+
+func TestWordList(t *testing.T) {
+	list := NewWordList("Acme", "Widget")
+	if !list.Contains("ACME") || !list.Contains("widget") || list.Contains("gadget") {
+		t.Errorf("WordList.Contains() mismatch for %v", list)
+	}
+}
+
+func TestValidatorBannedWords(t *testing.T) {
+	v, err := NewValidator(Options{RejectCommon: true, ExtraDictionary: []string{"Acme", "Widget"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	banned := func(password string) bool { return errors.Is(v.Audit(password).Err, ErrCommonPassword) }
+
+	if !banned("acme") || banned("qzvortex") {
+		t.Fatal("ExtraDictionary not applied before any change")
+	}
+	if err := v.AddBannedWords("Qzvortex", "", "ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if !banned("qzvortex99") || !banned("Acme") {
+		t.Error("AddBannedWords() words not rejected")
+	}
+	if err := v.RemoveBannedWords("acme", "password"); err != nil {
+		t.Fatal(err)
+	}
+	if banned("acme") || !banned("widget") || !banned("password") {
+		t.Error("RemoveBannedWords() removed the wrong words")
+	}
+	if got, want := v.Options().ExtraDictionary, []string{"Widget", "Qzvortex"}; !slices.Equal(got, want) {
+		t.Errorf("Options().ExtraDictionary = %v, want %v", got, want)
+	}
+
+	// Without RejectCommon the list is kept but not checked, as for ExtraDictionary
+	plain, _ := NewValidator(Options{})
+	if err := plain.AddBannedWords("qzvortex"); err != nil {
+		t.Fatal(err)
+	}
+	if plain.Audit("qzvortex").Err != nil {
+		t.Error("banned words checked without RejectCommon")
+	}
+}
+
+func TestValidatorBannedWordsNotBuilt(t *testing.T) {
+	var zero Validator
+	if err := zero.AddBannedWords("qzvortex"); !errors.Is(err, ErrValidatorNotBuilt) {
+		t.Errorf("AddBannedWords() error = %v, want %v", err, ErrValidatorNotBuilt)
+	}
+	if err := zero.RemoveBannedWords("qzvortex"); !errors.Is(err, ErrValidatorNotBuilt) {
+		t.Errorf("RemoveBannedWords() error = %v, want %v", err, ErrValidatorNotBuilt)
+	}
+	if res := zero.Audit("qzvortex"); res.Length != 8 {
+		t.Errorf("Audit() on a zero Validator = %+v, want the password audited", res)
+	}
+}
+
+func TestValidatorBannedWordsConcurrent(t *testing.T) {
+	v, err := NewValidator(Options{RejectCommon: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const writers, words = 4, 50
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if res := v.Audit("password"); !errors.Is(res.Err, ErrCommonPassword) {
+					t.Errorf("Audit() = %v during an update, want the embedded list kept", res.Err)
+					return
+				}
+				v.Audit("feedword-1-1")
+			}
+		}()
+	}
+
+	var writersWg sync.WaitGroup
+	for w := range writers {
+		writersWg.Add(1)
+		go func() {
+			defer writersWg.Done()
+			for i := range words {
+				v.AddBannedWords(fmt.Sprintf("feedword-%d-%d", w, i), fmt.Sprintf("stale-%d-%d", w, i))
+				v.RemoveBannedWords(fmt.Sprintf("stale-%d-%d", w, i))
+			}
+		}()
+	}
+	writersWg.Wait()
+	close(stop)
+	wg.Wait()
+
+	// Every concurrent update must land: none may be lost to a racing swap
+	if got := len(v.Options().ExtraDictionary); got != writers*words {
+		t.Errorf("%d banned words after the updates, want %d", got, writers*words)
+	}
+	if !errors.Is(v.Audit("FeedWord-3-49").Err, ErrCommonPassword) {
+		t.Error("word added concurrently not rejected")
+	}
+}
+
+func BenchmarkIsCommonPassword(b *testing.B) {
+	loadCommonPasswords()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		IsCommonPassword("Password123!")
+	}
+}
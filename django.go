@@ -0,0 +1,82 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// djangoPrefix starts the hashes of Django's default PBKDF2PasswordHasher.
+const djangoPrefix = "pbkdf2_sha256$"
+
+// DjangoHash is a parsed Django "pbkdf2_sha256$<iterations>$<salt>$<hash>" password.
+type DjangoHash struct {
+	Iterations int
+	Salt       string // Django salts are text and are used as is, not decoded
+	Hash       []byte
+}
+
+// ParseDjango parses a hash from Django's default password hasher, as stored in the password
+// column of auth_user. Other Django hashers, such as "argon2$" or "bcrypt_sha256$", fail with
+// ErrUnknownHashScheme and malformed hashes with ErrMalformedHash, as do iterations above
+// MaxPBKDF2Iterations and hashes that are not 32 bytes.
+func ParseDjango(encoded string) (DjangoHash, error) {
+	if !strings.HasPrefix(encoded, djangoPrefix) {
+		return DjangoHash{}, fmt.Errorf("%w: want prefix %q", ErrUnknownHashScheme, djangoPrefix)
+	}
+	fields := strings.Split(strings.TrimPrefix(encoded, djangoPrefix), "$")
+	if len(fields) != 3 {
+		return DjangoHash{}, fmt.Errorf("%w: want iterations, salt, and hash", ErrMalformedHash)
+	}
+	iterations, err := strconv.Atoi(fields[0])
+	if err != nil || iterations < 1 || iterations > MaxPBKDF2Iterations {
+		return DjangoHash{}, fmt.Errorf("%w: bad iterations %q", ErrMalformedHash, fields[0])
+	}
+	if fields[1] == "" {
+		return DjangoHash{}, fmt.Errorf("%w: empty salt", ErrMalformedHash)
+	}
+	hash, err := base64.StdEncoding.DecodeString(fields[2])
+	if err != nil || len(hash) != sha256.Size {
+		return DjangoHash{}, fmt.Errorf("%w: want a %d-byte hash", ErrMalformedHash, sha256.Size)
+	}
+	return DjangoHash{Iterations: iterations, Salt: fields[1], Hash: hash}, nil
+}
+
+// VerifyDjango compares password with a Django "pbkdf2_sha256$" hash in constant time. It returns
+// nil on a match, ErrPasswordMismatch for a wrong password, and ErrMalformedHash when encoded
+// cannot be parsed. VerifyAny and Hasher dispatch to it under SchemeDjango, so migrated users can
+// be rehashed on their next login.
+func VerifyDjango(password, encoded string) error {
+	h, err := ParseDjango(encoded)
+	if err != nil {
+		return err
+	}
+	other, err := pbkdf2.Key(sha256.New, password, []byte(h.Salt), h.Iterations, len(h.Hash))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+	if subtle.ConstantTimeCompare(h.Hash, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
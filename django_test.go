@@ -0,0 +1,136 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// interopHash is a hash another framework wrote, read from testdata/interop.txt.
+type interopHash struct {
+	framework string
+	password  string
+	hash      string
+}
+
+func readInteropHashes(t *testing.T) []interopHash {
+	t.Helper()
+	data, err := os.ReadFile("testdata/interop.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hashes []interopHash
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			t.Fatalf("testdata/interop.txt: bad line %q", line)
+		}
+		hashes = append(hashes, interopHash{fields[0], fields[1], fields[2]})
+	}
+	return hashes
+}
+
+func TestVerifyAnyInterop(t *testing.T) {
+	for _, h := range readInteropHashes(t) {
+		t.Run(h.framework+" "+h.hash[:4], func(t *testing.T) {
+			want := SchemeBcrypt
+			if h.framework == "django" {
+				want = SchemeDjango
+			}
+			scheme, err := VerifyAny(h.password, h.hash)
+			if scheme != want || err != nil {
+				t.Errorf("VerifyAny() = %q, %v, want %q, nil", scheme, err, want)
+			}
+			if _, err := VerifyAny(h.password+"x", h.hash); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("VerifyAny(wrong) = %v, want ErrPasswordMismatch", err)
+			}
+		})
+	}
+}
+
+func TestVerifyBcryptPrefixVariants(t *testing.T) {
+	// $2a$, $2b$, and $2y$ differ only in bugs of old implementations that do not affect these
+	// passwords, so each hash verifies under all three prefixes.
+	for _, h := range readInteropHashes(t) {
+		if h.framework == "django" {
+			continue
+		}
+		for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+			if err := Verify(h.password, prefix+h.hash[4:]); err != nil {
+				t.Errorf("Verify(%s) = %v", prefix+h.hash[4:], err)
+			}
+		}
+	}
+}
+
+func TestParseDjango(t *testing.T) {
+	h, err := ParseDjango("pbkdf2_sha256$10000$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Iterations != 10000 || h.Salt != "seasalt" || len(h.Hash) != 32 {
+		t.Errorf("ParseDjango() = %d iterations, salt %q, %d-byte hash", h.Iterations, h.Salt, len(h.Hash))
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    error
+	}{
+		{"Other Django hasher", "argon2$argon2id$v=19$m=102400,t=2,p=8$c2FsdA$aGFzaA", ErrUnknownHashScheme},
+		{"Missing hash", "pbkdf2_sha256$10000$seasalt", ErrMalformedHash},
+		{"Bad iterations", "pbkdf2_sha256$many$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY=", ErrMalformedHash},
+		{"Iterations over the limit", "pbkdf2_sha256$10000001$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY=", ErrMalformedHash},
+		{"Iterations near int64 max", "pbkdf2_sha256$9223372036854775807$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY=", ErrMalformedHash},
+		{"Empty salt", "pbkdf2_sha256$10000$$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY=", ErrMalformedHash},
+		{"Bad base64", "pbkdf2_sha256$10000$seasalt$not base64", ErrMalformedHash},
+		{"Empty hash", "pbkdf2_sha256$10000$seasalt$", ErrMalformedHash},
+		{"Short hash", "pbkdf2_sha256$10000$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvAr", ErrMalformedHash},
+		{"Long hash", "pbkdf2_sha256$10000$seasalt$" + base64.StdEncoding.EncodeToString(make([]byte, 1<<20)), ErrMalformedHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseDjango(tt.encoded); !errors.Is(err, tt.want) {
+				t.Errorf("ParseDjango() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasherConvertsInteropHashes(t *testing.T) {
+	h := &Hasher{Argon2: testArgon2Params, Legacy: []string{SchemeDjango, SchemeBcrypt}}
+	for _, legacy := range readInteropHashes(t) {
+		var stored string
+		ok, err := h.VerifyAndUpgrade(legacy.password, legacy.hash, func(encoded string) error {
+			stored = encoded
+			return nil
+		})
+		if !ok || err != nil {
+			t.Fatalf("VerifyAndUpgrade(%s) = %v, %v", legacy.hash, ok, err)
+		}
+		if err := h.Verify(legacy.password, stored); err != nil || h.NeedsUpgrade(stored) {
+			t.Errorf("%s hash converted to %q, which fails or needs another upgrade: %v", legacy.framework, stored, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// emojiCharsetSize is the charset size credited to emoji and symbols beyond ASCII, roughly the
+// number of emoji on a phone keyboard.
+const emojiCharsetSize = 1400
+
+// emojiChars are the emoji Generate draws from when UseEmoji is set. Each is a single code point
+// that renders as an emoji without a variation selector.
+const emojiChars = "😀😃😄😁😆😅😂🙂😉😊😇😍😎🤓🤔🤗🐶🐱🐭🐹🐰🦊🐻🐼🐨🐯🦁🐮🐷🐸🐵🐔🐧🐦🍎🍐🍊🍋🍌🍉🍇🍓🍒🍑🍍🥝🥑🍅🌽🥕🚀🚗🚲🎲🎸🎹🔑🔒💡📦🌙🌈🔥🌊"
+
+// emojiRanges are the blocks holding emoji, including the code points not yet assigned there.
+var emojiRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1}, // Miscellaneous Symbols and Dingbats
+		{Lo: 0x2b00, Hi: 0x2bff, Stride: 1}, // Miscellaneous Symbols and Arrows
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1}, // Mahjong Tiles through Symbols and Pictographs Extended-A
+	},
+}
+
+// emojiModifiers extend the emoji before them rather than starting a character of their own.
+var emojiModifiers = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x20e3, Hi: 0x20e3, Stride: 1}, // Combining enclosing keycap
+		{Lo: 0xfe0e, Hi: 0xfe0f, Stride: 1}, // Text and emoji variation selectors
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f3fb, Hi: 0x1f3ff, Stride: 1}, // Skin tone modifiers
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1}, // Tags of subdivision flags
+	},
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// isEmoji reports whether r is an emoji or a symbol or punctuation mark beyond ASCII.
+func isEmoji(r rune) bool {
+	return r >= utf8.RuneSelf && (unicode.IsSymbol(r) || unicode.IsPunct(r) || unicode.Is(emojiRanges, r))
+}
+
+// isRegionalIndicator reports whether r is one of the letters that pair up into a flag.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// emojiJoiner tracks emoji sequences so a ZWJ sequence such as "👩‍👩‍👧", a skin tone, or a flag
+// counts as a single character.
+type emojiJoiner struct {
+	afterEmoji bool // The previous character is an emoji
+	joinNext   bool // The previous rune is a ZWJ following an emoji
+	openFlag   bool // The previous character is an unpaired regional indicator
+}
+
+// extends reports whether r continues the character before it, and records r otherwise. class is
+// the class r would be counted in.
+func (j *emojiJoiner) extends(r rune, class Class) bool {
+	switch {
+	case j.joinNext && class == ClassEmoji:
+		j.joinNext = false
+		return true
+	case j.afterEmoji && (r == zeroWidthJoiner || unicode.Is(emojiModifiers, r)):
+		j.joinNext = r == zeroWidthJoiner
+		return true
+	case j.openFlag && isRegionalIndicator(r):
+		j.openFlag = false
+		return true
+	}
+	j.afterEmoji = class == ClassEmoji
+	j.joinNext = false
+	j.openFlag = isRegionalIndicator(r)
+	return false
+}
+
+// graphemeBoundary reports whether s splits at byte i between whole runes without cutting through
+// an emoji sequence, so both halves classify the same as the whole.
+func graphemeBoundary(s string, i int) bool {
+	if !runeBoundary(s, i) {
+		return false
+	}
+	if i > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(s[:i]); r == zeroWidthJoiner || isRegionalIndicator(r) {
+			return false
+		}
+	}
+	if i < len(s) {
+		if r, _ := utf8.DecodeRuneInString(s[i:]); r == zeroWidthJoiner || isRegionalIndicator(r) || unicode.Is(emojiModifiers, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAuditEmoji(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		length   int64
+		emoji    int
+		other    int
+	}{
+		{"Single emoji", "🔒", 1, 1, 0},
+		{"ZWJ family", "👩‍👩‍👧‍👦", 1, 1, 0},
+		{"Skin tone", "👍🏽", 1, 1, 0},
+		{"Variation selector", "❤️", 1, 1, 0},
+		{"Flag", "🇫🇷", 1, 1, 0},
+		{"Two flags", "🇫🇷🇩🇪", 2, 2, 0},
+		{"Odd regional indicators", "🇫🇷🇩", 2, 2, 0},
+		{"Symbol beyond ASCII", "€§", 2, 2, 0},
+		{"Mixed", "ab👩‍💻1", 4, 1, 0},
+		{"Joiner after a letter", "a\u200db", 3, 0, 1},
+		{"Modifier without an emoji", "\ufe0f", 1, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, Options{})
+			if result.Length != tt.length {
+				t.Errorf("Audit(%q) Length = %d, want %d", tt.password, result.Length, tt.length)
+			}
+			if result.LengthBytes != int64(len(tt.password)) {
+				t.Errorf("Audit(%q) LengthBytes = %d, want %d", tt.password, result.LengthBytes, len(tt.password))
+			}
+			if result.Counts.Emoji != tt.emoji || result.Counts.Other != tt.other {
+				t.Errorf("Audit(%q) Counts = %+v, want %d emoji and %d other", tt.password, result.Counts, tt.emoji, tt.other)
+			}
+			if result.HasEmoji != (tt.emoji > 0) || result.Classes.Has(ClassEmoji) != (tt.emoji > 0) {
+				t.Errorf("Audit(%q) HasEmoji = %t, Classes = %07b", tt.password, result.HasEmoji, result.Classes)
+			}
+		})
+	}
+}
+
+func TestAuditEmojiEntropy(t *testing.T) {
+	// Eight emoji draw from a far larger alphabet than eight letters.
+	emoji := Audit("🔒🔑🚀🎲🎸🎹💡📦", Options{}).Entropy
+	letters := Audit("qzmvkxwj", Options{}).Entropy
+	if emoji < 2*letters {
+		t.Errorf("entropy emoji = %.2f, letters = %.2f, want emoji at least twice as strong", emoji, letters)
+	}
+}
+
+func TestAuditUseEmoji(t *testing.T) {
+	result := Audit("Password1!", Options{UseEmoji: true})
+	if !errors.Is(result.Err, ErrMissingEmoji) {
+		t.Errorf("Audit() error = %v, want ErrMissingEmoji", result.Err)
+	}
+	var verr *ValidationError
+	if !errors.As(result.Err, &verr) || verr.Code != CodeMissingEmoji || verr.Field != "UseEmoji" {
+		t.Errorf("Audit() error = %#v, want code %s on UseEmoji", verr, CodeMissingEmoji)
+	}
+	if result := Audit("Password1!🔑", Options{UseEmoji: true}); result.Err != nil {
+		t.Errorf("Audit() error = %v, want nil with an emoji", result.Err)
+	}
+}
+
+func TestGenerateEmoji(t *testing.T) {
+	opts := Options{MinLength: 12, MaxLength: 12, UseLower: true, UseEmoji: true}
+	for i := 0; i < 20; i++ {
+		password, err := Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := utf8.RuneCountInString(password); n != 12 {
+			t.Fatalf("Generate() = %q with %d runes, want 12", password, n)
+		}
+		if result := Audit(password, opts); result.Err != nil || result.Counts.Emoji == 0 {
+			t.Fatalf("Audit(%q) error = %v, counts = %+v, want an emoji", password, result.Err, result.Counts)
+		}
+	}
+}
+
+func TestGraphemeBoundary(t *testing.T) {
+	tests := []struct {
+		s    string
+		i    int
+		want bool
+	}{
+		{"ab", 1, true},
+		{"a🔒", 1, true},
+		{"🔒a", 4, true},
+		{"👩‍💻", 4, false},
+		{"👩‍💻", 7, false},
+		{"👍🏽", 4, false},
+		{"🇫🇷", 4, false},
+		{"€", 1, false},
+	}
+	for _, tt := range tests {
+		if got := graphemeBoundary(tt.s, tt.i); got != tt.want {
+			t.Errorf("graphemeBoundary(%q, %d) = %t, want %t", tt.s, tt.i, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import "math"
+
+// compromisedEntropy is the EffectiveEntropy of a password found in a dictionary or breach source.
+// Attackers try those lists first, so such a password falls within the first thousand or so guesses.
+const compromisedEntropy = 10
+
+// shannonEntropy returns the Shannon entropy of the rune frequencies of pass, in bits per rune,
+// multiplied by its length in runes. Runes below U+0100 are counted without allocating.
+func shannonEntropy(pass string, length int) float64 {
+	if length < 2 {
+		return 0
+	}
+	var latin [256]int
+	var others map[rune]int
+	for _, r := range pass {
+		if r < 256 {
+			latin[r]++
+			continue
+		}
+		if others == nil {
+			others = make(map[rune]int)
+		}
+		others[r]++
+	}
+
+	n := float64(length)
+	bits := 0.0
+	add := func(count int) {
+		if count > 0 {
+			p := float64(count) / n
+			bits -= p * math.Log2(p)
+		}
+	}
+	for _, count := range latin {
+		add(count)
+	}
+	for _, count := range others {
+		add(count)
+	}
+	return bits * n
+}
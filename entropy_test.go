@@ -0,0 +1,93 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAuditEntropyModels(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		options   Options
+		charset   float64
+		shannon   float64
+		effective float64
+	}{
+		{"Empty", "", Options{}, 0, 0, 0},
+		{"Single rune", "a", Options{}, math.Log2(26), 0, math.Log2(26)},
+		{"Random", "Xq7#mB2vLp9!", Options{}, 12 * math.Log2(float64(10+26+26+len(symbolChars))), 12 * math.Log2(12), 12 * math.Log2(float64(10+26+26+len(symbolChars)))},
+		{"Repeat", "aaaaaaaa", Options{MaxRepeatRun: 3}, 8 * math.Log2(26), 0, math.Log2(26)},
+		{"Sequence", "abcdefgh", Options{MaxSequenceLength: 3}, 8 * math.Log2(26), 24, math.Log2(26)},
+		{"Common password", "password", Options{RejectCommon: true}, 8 * math.Log2(26), 22, compromisedEntropy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			for _, got := range []struct {
+				field     string
+				got, want float64
+			}{
+				{"CharsetEntropy", result.CharsetEntropy, tt.charset},
+				{"Entropy", result.Entropy, tt.charset},
+				{"ShannonEntropy", result.ShannonEntropy, tt.shannon},
+				{"EffectiveEntropy", result.EffectiveEntropy, tt.effective},
+			} {
+				if math.Abs(got.got-got.want) > 1e-9 {
+					t.Errorf("Audit(%q) %s = %v, want %v", tt.password, got.field, got.got, got.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditScoreUsesEffectiveEntropy(t *testing.T) {
+	plain := Audit("abcdefgh", Options{})
+	sequence := Audit("abcdefgh", Options{MaxSequenceLength: 3})
+	if sequence.CharsetEntropy != plain.CharsetEntropy {
+		t.Errorf("CharsetEntropy = %v, want %v regardless of patterns", sequence.CharsetEntropy, plain.CharsetEntropy)
+	}
+	if sequence.Score >= plain.Score {
+		t.Errorf("Score = %d with the sequence detected, want less than %d", sequence.Score, plain.Score)
+	}
+	if sequence.CrackTimes.OfflineFastHash >= plain.CrackTimes.OfflineFastHash {
+		t.Errorf("CrackTimes = %v with the sequence detected, want less than %v", sequence.CrackTimes, plain.CrackTimes)
+	}
+}
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		password string
+		want     float64
+	}{
+		{"", 0},
+		{"zzzz", 0},
+		{"abab", 4},
+		{"abcd", 8},
+		{"aabc", 6},
+		{"пароль", 6 * math.Log2(6)},
+		{"密密码码", 4},
+	}
+	for _, tt := range tests {
+		length := len([]rune(tt.password))
+		if got := shannonEntropy(tt.password, length); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("shannonEntropy(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
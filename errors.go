@@ -0,0 +1,220 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Sentinel errors wrapped by Result.Err. Compare against them with errors.Is.
+var (
+	ErrTooShort               = errors.New("password too short")
+	ErrTooLong                = errors.New("password too long")
+	ErrTooManyBytes           = errors.New("password too long in bytes")
+	ErrMissingDigits          = errors.New("password must contain digits")
+	ErrMissingLower           = errors.New("password must contain lowercase letters")
+	ErrMissingUpper           = errors.New("password must contain uppercase letters")
+	ErrMissingSymbols         = errors.New("password must contain symbols")
+	ErrMissingExtended        = errors.New("password must contain extended Unicode characters")
+	ErrMissingEmoji           = errors.New("password must contain an emoji")
+	ErrTooFewClasses          = errors.New("password must contain more kinds of characters")
+	ErrEntropyTooLow          = errors.New("password entropy too low")
+	ErrTooFewUnique           = errors.New("password has too few unique characters")
+	ErrClassRatio             = errors.New("password is mostly one kind of character")
+	ErrCommonPassword         = errors.New("password is too common")
+	ErrPwnedPassword          = errors.New("password has appeared in a data breach")
+	ErrBreachCheckUnavailable = errors.New("password breach check unavailable")
+	ErrSequentialChars        = errors.New("password contains sequential characters")
+	ErrRepeatedChars          = errors.New("password contains repeated characters")
+	ErrKeyboardWalk           = errors.New("password contains a keyboard pattern")
+	ErrDate                   = errors.New("password contains a date, year, or phone number")
+	ErrLooksLikePII           = errors.New("password looks like a card or social security number")
+	ErrContainsUserInput      = errors.New("password contains personal information")
+	ErrTooSimilar             = errors.New("password is too similar to a previous password")
+	ErrPasswordReused         = errors.New("password was used before")
+	ErrHistoryCheckFailed     = errors.New("password history check failed")
+	ErrPINNotDigits           = errors.New("pin must contain only digits")
+	ErrPINRepeated            = errors.New("pin repeats the same digits")
+	ErrPINSequence            = errors.New("pin is a sequence of digits")
+	ErrCommonPIN              = errors.New("pin is too common")
+	ErrPINYear                = errors.New("pin looks like a year")
+	ErrDisallowedChar         = errors.New("password contains a character that is not allowed")
+	ErrControlChar            = errors.New("password contains a control character")
+	ErrInvisibleChar          = errors.New("password contains an invisible character")
+	ErrInvalidUTF8            = errors.New("password is not valid UTF-8")
+	ErrProhibitedChar         = errors.New("password contains a character prohibited by RFC 8265")
+	ErrConfusable             = errors.New("password mixes in lookalike letters from another script")
+	ErrWhitespace             = errors.New("password contains whitespace")
+	ErrEdgeWhitespace         = errors.New("password starts or ends with whitespace")
+	ErrFirstChar              = errors.New("password starts with a kind of character that is not allowed")
+	ErrLastChar               = errors.New("password ends with a kind of character that is not allowed")
+	ErrMissingPattern         = errors.New("password does not match a required pattern")
+	ErrForbiddenPattern       = errors.New("password matches a forbidden pattern")
+	ErrInvalidOptions         = errors.New("invalid options")
+	ErrRulePanicked           = errors.New("custom rule panicked")
+)
+
+// Codes of the ValidationError values Audit and AuditPIN record. They are stable across releases,
+// so services can map them to their own error codes and translations.
+const (
+	CodeTooShort           = "too_short"
+	CodeTooLong            = "too_long"
+	CodeTooManyBytes       = "too_many_bytes"
+	CodeTooFewUnique       = "too_few_unique"
+	CodeTooFewClasses      = "too_few_classes"
+	CodeClassRatio         = "class_ratio"
+	CodeDisallowedChar     = "disallowed_char"
+	CodeControlChar        = "control_char"
+	CodeInvisibleChar      = "invisible_char"
+	CodeInvalidUTF8        = "invalid_utf8"
+	CodeProhibitedChar     = "prohibited_char"
+	CodeConfusable         = "confusable"
+	CodeWhitespace         = "whitespace"
+	CodeEdgeWhitespace     = "edge_whitespace"
+	CodeFirstChar          = "first_char"
+	CodeLastChar           = "last_char"
+	CodeMissingPattern     = "missing_pattern"
+	CodeForbiddenPattern   = "forbidden_pattern"
+	CodeMissingDigit       = "missing_digit"
+	CodeMissingLower       = "missing_lower"
+	CodeMissingUpper       = "missing_upper"
+	CodeMissingSymbol      = "missing_symbol"
+	CodeMissingExtended    = "missing_extended"
+	CodeMissingEmoji       = "missing_emoji"
+	CodeSequence           = "sequence"
+	CodeRepeatedChars      = "repeated_chars"
+	CodeKeyboardWalk       = "keyboard_walk"
+	CodeDate               = "date"
+	CodePII                = "pii"
+	CodeUserInput          = "user_input"
+	CodeTooSimilar         = "too_similar"
+	CodeCommonPassword     = "common_password"
+	CodeBreachCheckFailed  = "breach_check_failed"
+	CodePwned              = "pwned"
+	CodeHistoryCheckFailed = "history_check_failed"
+	CodeReused             = "reused"
+	CodeLowEntropy         = "low_entropy"
+	CodeCustomRule         = "custom_rule"
+	CodeInvalidOptions     = "invalid_options"
+	CodePINNotDigits       = "pin_not_digits"
+	CodePINRepeated        = "pin_repeated"
+	CodePINSequence        = "pin_sequence"
+	CodeCommonPIN          = "common_pin"
+	CodePINYear            = "pin_year"
+)
+
+// ValidationError is the type of every violation in Result.Violations. Code identifies the failed
+// requirement, Field names the Options field that set it, and Params holds the values a message
+// needs, such as min_length or pwned_count. It wraps the detailed error, so errors.Is still
+// matches the sentinel errors above.
+type ValidationError struct {
+	Code   string
+	Field  string
+	Params map[string]any
+	Err    error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// violationCodes returns the distinct Codes of violations in order of first appearance.
+func violationCodes(violations []error) []string {
+	var codes []string
+	for _, violation := range violations {
+		var verr *ValidationError
+		if errors.As(violation, &verr) && !slices.Contains(codes, verr.Code) {
+			codes = append(codes, verr.Code)
+		}
+	}
+	return codes
+}
+
+// validationError returns a *ValidationError wrapping err, with params given as key/value pairs.
+func validationError(code, field string, err error, params ...any) *ValidationError {
+	e := &ValidationError{Code: code, Field: field, Err: err}
+	if len(params) > 0 {
+		e.Params = make(map[string]any, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			e.Params[fmt.Sprint(params[i])] = params[i+1]
+		}
+	}
+	return e
+}
+
+// Errors returned by the hashing helpers.
+var (
+	ErrBcryptTooLong      = errors.New("password exceeds the 72-byte bcrypt limit")
+	ErrPasswordMismatch   = errors.New("password does not match the hash")
+	ErrMalformedHash      = errors.New("malformed password hash")
+	ErrUnsupportedVersion = errors.New("unsupported password hash version")
+	ErrUnknownHashScheme  = errors.New("unknown password hash scheme")
+	ErrUnknownPepper      = errors.New("unknown pepper key")
+	ErrPepperClosed       = errors.New("pepper is closed")
+)
+
+// Errors returned by NewBloomFromReader.
+var (
+	ErrMalformedBloom          = errors.New("malformed bloom filter")
+	ErrUnsupportedBloomVersion = errors.New("unsupported bloom filter version")
+)
+
+// ErrMalformedPwnedFile is returned by NewFileChecker and FileChecker lookups for a file that is not
+// a pwned-passwords file ordered by hash.
+var ErrMalformedPwnedFile = errors.New("malformed pwned passwords file")
+
+// ErrBreachCheckFailed is the former name of ErrBreachCheckUnavailable.
+//
+// Deprecated: use ErrBreachCheckUnavailable.
+var ErrBreachCheckFailed = ErrBreachCheckUnavailable
+
+// ErrInvalidWordlist is returned by LoadWordlist for each line that is not an acceptable word.
+var ErrInvalidWordlist = errors.New("invalid wordlist")
+
+// ErrValidatorNotBuilt is returned by AddBannedWords and RemoveBannedWords on a Validator that
+// NewValidator or New did not build, such as a zero Validator.
+var ErrValidatorNotBuilt = errors.New("validator not built by NewValidator")
+
+// Errors returned by Registry.
+var (
+	ErrUnknownPolicy = errors.New("unknown password policy")
+	ErrPolicyExists  = errors.New("password policy already registered")
+)
+
+// Errors returned by NewMarkovFromReader.
+var (
+	ErrMalformedMarkov          = errors.New("malformed markov model")
+	ErrUnsupportedMarkovVersion = errors.New("unsupported markov model version")
+)
+
+// Errors returned by ParsePHC, alongside ErrMalformedHash, naming what is wrong with the string.
+var (
+	ErrPHCMissingSegment = errors.New("missing PHC segment")
+	ErrPHCInvalidField   = errors.New("invalid PHC field")
+	ErrPHCBadBase64      = errors.New("invalid PHC base64")
+	ErrPHCFieldTooLong   = errors.New("PHC field too long")
+)
+
+// Errors returned by DeriveKey, GenerateSalt, and DerivePassword.
+var (
+	ErrSaltTooShort     = errors.New("salt is too short")
+	ErrInvalidKeyLength = errors.New("invalid derived key length")
+	ErrEmptyMaster      = errors.New("master secret is empty")
+	ErrEmptySite        = errors.New("site is empty")
+)
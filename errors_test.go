@@ -0,0 +1,180 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAuditValidationErrors(t *testing.T) {
+	breached := BreachCheckerFunc(func(context.Context, string) (bool, int, error) { return true, 42, nil })
+
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		sentinel error
+		code     string
+		field    string
+		params   map[string]any
+	}{
+		{
+			name:     "Too short",
+			password: "abc",
+			options:  Options{MinLength: 8},
+			sentinel: ErrTooShort,
+			code:     CodeTooShort,
+			field:    "MinLength",
+			params:   map[string]any{"length": 3, "min_length": uint(8)},
+		},
+		{
+			name:     "Too long",
+			password: "abcdefghij",
+			options:  Options{MaxLength: 4},
+			sentinel: ErrTooLong,
+			code:     CodeTooLong,
+			field:    "MaxLength",
+			params:   map[string]any{"length": 10, "max_length": uint(4)},
+		},
+		{
+			name:     "Outside allowed characters",
+			password: "abcd",
+			options:  Options{AllowedChars: "abc"},
+			sentinel: ErrDisallowedChar,
+			code:     CodeDisallowedChar,
+			field:    "AllowedChars",
+			params:   map[string]any{"char": "d", "position": 3},
+		},
+		{
+			name:     "Missing digit",
+			password: "password",
+			options:  Options{MinDigits: 2},
+			sentinel: ErrMissingDigits,
+			code:     CodeMissingDigit,
+			field:    "MinDigits",
+			params:   map[string]any{"count": 0, "min": uint(2)},
+		},
+		{
+			name:     "Missing symbol",
+			password: "password",
+			options:  Options{UseSymbols: true},
+			sentinel: ErrMissingSymbols,
+			code:     CodeMissingSymbol,
+			field:    "MinSymbols",
+			params:   map[string]any{"count": 0, "min": uint(1)},
+		},
+		{
+			name:     "Common password",
+			password: "password",
+			options:  Options{RejectCommon: true},
+			sentinel: ErrCommonPassword,
+			code:     CodeCommonPassword,
+			field:    "RejectCommon",
+		},
+		{
+			name:     "Pwned",
+			password: "Xq7mB2vLp9!",
+			options:  Options{BreachChecker: breached},
+			sentinel: ErrPwnedPassword,
+			code:     CodePwned,
+			field:    "BreachChecker",
+			params:   map[string]any{"pwned_count": 42},
+		},
+		{
+			name:     "Low entropy",
+			password: "aaaa",
+			options:  Options{MinEntropy: 50},
+			sentinel: ErrEntropyTooLow,
+			code:     CodeLowEntropy,
+			field:    "MinEntropy",
+			params:   map[string]any{"entropy": 4 * math.Log2(26), "min_entropy": 50.0},
+		},
+		{
+			name:     "Forbidden pattern",
+			password: "acme2026",
+			options:  Options{ForbiddenPatterns: []string{`acme`}},
+			sentinel: ErrForbiddenPattern,
+			code:     CodeForbiddenPattern,
+			field:    "ForbiddenPatterns",
+			params:   map[string]any{"pattern": "acme"},
+		},
+		{
+			name:     "Custom rule",
+			password: "Acme-Q3-2026!",
+			options:  Options{CustomRules: []Rule{quarterRule}},
+			sentinel: errQuarter,
+			code:     CodeCustomRule,
+			field:    "CustomRules",
+			params:   map[string]any{"rule": "quarter"},
+		},
+		{
+			name:     "Invalid options",
+			password: "Xq7mB2vLp9!",
+			options:  Options{MinLength: 9, MaxLength: 8},
+			sentinel: ErrInvalidOptions,
+			code:     CodeInvalidOptions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if len(result.Violations) != 1 {
+				t.Fatalf("Audit() violations = %v, want one", result.Violations)
+			}
+			var verr *ValidationError
+			if !errors.As(result.Violations[0], &verr) {
+				t.Fatalf("Audit() violations[0] = %T, want *ValidationError", result.Violations[0])
+			}
+			if !errors.Is(verr, tt.sentinel) || !errors.Is(result.Err, tt.sentinel) {
+				t.Errorf("Audit() error = %v, want it to match %v", result.Err, tt.sentinel)
+			}
+			if verr.Code != tt.code || verr.Field != tt.field {
+				t.Errorf("ValidationError code = %q field = %q, want %q and %q", verr.Code, verr.Field, tt.code, tt.field)
+			}
+			if !reflect.DeepEqual(verr.Params, tt.params) {
+				t.Errorf("ValidationError params = %#v, want %#v", verr.Params, tt.params)
+			}
+		})
+	}
+}
+
+func TestAuditPINValidationErrors(t *testing.T) {
+	tests := []struct {
+		pin  string
+		code string
+	}{
+		{"12a4", CodePINNotDigits},
+		{"123", CodeTooShort},
+		{"1111", CodePINRepeated},
+		{"1357", CodeCommonPIN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pin, func(t *testing.T) {
+			result := AuditPIN(tt.pin, PINOptions{FailFast: true})
+			var verr *ValidationError
+			if len(result.Violations) == 0 || !errors.As(result.Violations[0], &verr) || verr.Code != tt.code {
+				t.Errorf("AuditPIN() violations = %v, want a *ValidationError with code %q", result.Violations, tt.code)
+			}
+		})
+	}
+}
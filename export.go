@@ -0,0 +1,172 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ResultColumns are the header and column order of WriteResultsCSV, and the keys of each
+// WriteResultsJSONL object. New columns are only ever appended.
+var ResultColumns = []string{"id", "length", "entropy", "score", "complexity", "strong", "violations", "pwned_count"}
+
+// RowResult pairs an audit Result with an opaque identifier of the row it came from, such as a
+// line number or an account name. The identifier is written as is, so it must never be the
+// password itself.
+type RowResult struct {
+	ID     string
+	Result Result
+}
+
+// rowRecord is the exported subset of a RowResult, in ResultColumns order.
+type rowRecord struct {
+	ID         string  `json:"id"`
+	Length     int64   `json:"length"`
+	Entropy    float64 `json:"entropy"`
+	Score      int     `json:"score"`
+	Complexity string  `json:"complexity"`
+	Strong     bool    `json:"strong"`
+	Violations string  `json:"violations"`
+	PwnedCount int     `json:"pwned_count"`
+}
+
+// newRowRecord flattens row. Entropy is EffectiveEntropy rounded to two decimals and the
+// violation codes are joined with ";".
+func newRowRecord(row RowResult) rowRecord {
+	res := &row.Result
+	return rowRecord{
+		ID:         row.ID,
+		Length:     res.Length,
+		Entropy:    math.Round(res.EffectiveEntropy*100) / 100,
+		Score:      res.Score,
+		Complexity: res.Complexity.snakeName(),
+		Strong:     res.Strong,
+		Violations: strings.Join(violationCodes(res.Violations), ";"),
+		PwnedCount: res.PwnedCount,
+	}
+}
+
+// strings returns the record as CSV fields.
+func (rec rowRecord) strings() []string {
+	return []string{
+		rec.ID,
+		strconv.FormatInt(rec.Length, 10),
+		strconv.FormatFloat(rec.Entropy, 'f', 2, 64),
+		strconv.Itoa(rec.Score),
+		rec.Complexity,
+		strconv.FormatBool(rec.Strong),
+		rec.Violations,
+		strconv.Itoa(rec.PwnedCount),
+	}
+}
+
+// ResultWriter streams RowResults to an io.Writer as CSV or JSON Lines. Its Line method has the
+// signature AuditReader expects, so a dump can be exported without holding its results:
+//
+//	out := NewResultCSVWriter(os.Stdout)
+//	err := AuditReader(ctx, r, opts, out.Line)
+//	if err == nil {
+//		err = out.Flush()
+//	}
+//
+// It is safe for concurrent use. Call Flush when done.
+type ResultWriter struct {
+	mu     sync.Mutex
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool
+}
+
+// NewResultCSVWriter returns a ResultWriter that writes CSV to w, starting with a header row of
+// ResultColumns. Fields are quoted by encoding/csv where needed.
+func NewResultCSVWriter(w io.Writer) *ResultWriter {
+	return &ResultWriter{csv: csv.NewWriter(w)}
+}
+
+// NewResultJSONLWriter returns a ResultWriter that writes one JSON object per line to w.
+func NewResultJSONLWriter(w io.Writer) *ResultWriter {
+	return &ResultWriter{json: json.NewEncoder(w)}
+}
+
+// Write writes row.
+func (rw *ResultWriter) Write(row RowResult) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.json != nil {
+		return rw.json.Encode(newRowRecord(row))
+	}
+	if err := rw.writeHeader(); err != nil {
+		return err
+	}
+	return rw.csv.Write(newRowRecord(row).strings())
+}
+
+// Line writes res with the line number as its identifier and ignores the password, for use as
+// the fn of AuditReader.
+func (rw *ResultWriter) Line(line int, _ string, res Result) error {
+	return rw.Write(RowResult{ID: strconv.Itoa(line), Result: res})
+}
+
+// Flush writes any buffered data, and the CSV header if no row was written, and returns the
+// first error from the underlying writer.
+func (rw *ResultWriter) Flush() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.csv == nil {
+		return nil
+	}
+	if err := rw.writeHeader(); err != nil {
+		return err
+	}
+	rw.csv.Flush()
+	return rw.csv.Error()
+}
+
+// writeHeader writes the CSV header once. rw.mu must be held.
+func (rw *ResultWriter) writeHeader() error {
+	if rw.header {
+		return nil
+	}
+	rw.header = true
+	return rw.csv.Write(ResultColumns)
+}
+
+// WriteResultsCSV writes results to w as CSV with a header row of ResultColumns.
+func WriteResultsCSV(w io.Writer, results []RowResult) error {
+	return writeResults(NewResultCSVWriter(w), results)
+}
+
+// WriteResultsJSONL writes results to w as JSON Lines, one object keyed by ResultColumns per row.
+func WriteResultsJSONL(w io.Writer, results []RowResult) error {
+	return writeResults(NewResultJSONLWriter(w), results)
+}
+
+// writeResults writes every row to rw and flushes it.
+func writeResults(rw *ResultWriter, results []RowResult) error {
+	for _, row := range results {
+		if err := rw.Write(row); err != nil {
+			return err
+		}
+	}
+	return rw.Flush()
+}
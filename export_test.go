@@ -0,0 +1,135 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// exportRows audits a few passwords, one of them breached, under account names that need quoting.
+func exportRows(t *testing.T) []RowResult {
+	t.Helper()
+	breached := BreachCheckerFunc(func(_ context.Context, password string) (bool, int, error) {
+		if password == "Summer2024!" {
+			return true, 4821, nil
+		}
+		return false, 0, nil
+	})
+	opts := Options{MinLength: 10, UseDigits: true, UseUpper: true, UseSymbols: true, BreachChecker: breached}
+	rows := []RowResult{
+		{ID: "svc-backup", Result: Audit("Xq7#mB2vLp9!w4Z", opts)},
+		{ID: "svc,reports", Result: Audit("Summer2024!", opts)},
+		{ID: `ops "deploy"`, Result: Audit("short", opts)},
+		{ID: "line\nbreak", Result: Audit("123456", opts)},
+	}
+	return rows
+}
+
+func TestWriteResultsCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteResultsCSV(&out, exportRows(t)); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "results.csv.golden", out.Bytes())
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 || strings.Join(records[0], ",") != strings.Join(ResultColumns, ",") {
+		t.Fatalf("records = %q, want a header and 4 rows", records)
+	}
+	if records[2][0] != "svc,reports" || records[3][0] != `ops "deploy"` || records[4][0] != "line\nbreak" {
+		t.Errorf("ids = %q, %q, %q, want them round-tripped", records[2][0], records[3][0], records[4][0])
+	}
+}
+
+func TestWriteResultsJSONL(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteResultsJSONL(&out, exportRows(t)); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "results.jsonl.golden", out.Bytes())
+	if lines := strings.Count(out.String(), "\n"); lines != 4 {
+		t.Errorf("WriteResultsJSONL() wrote %d lines, want 4", lines)
+	}
+}
+
+func TestWriteResultsEmpty(t *testing.T) {
+	var csvOut, jsonOut bytes.Buffer
+	if err := WriteResultsCSV(&csvOut, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteResultsJSONL(&jsonOut, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := csvOut.String(), strings.Join(ResultColumns, ",")+"\n"; got != want {
+		t.Errorf("WriteResultsCSV(nil) = %q, want %q", got, want)
+	}
+	if jsonOut.Len() != 0 {
+		t.Errorf("WriteResultsJSONL(nil) = %q, want nothing", jsonOut.String())
+	}
+}
+
+func TestResultWriterAuditReader(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(*bytes.Buffer) *ResultWriter
+		want string
+	}{
+		{"CSV", func(b *bytes.Buffer) *ResultWriter { return NewResultCSVWriter(b) }, "id,length,entropy,score,complexity,strong,violations,pwned_count\n1,"},
+		{"JSON Lines", func(b *bytes.Buffer) *ResultWriter { return NewResultJSONLWriter(b) }, `{"id":"1","length":8,`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rw := tt.new(&out)
+			err := AuditReader(context.Background(), strings.NewReader("hunter22\nCorrect-Horse-9\n"), Options{MinLength: 8}, rw.Line)
+			if err == nil {
+				err = rw.Flush()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); !strings.HasPrefix(got, tt.want) || strings.Count(got, "\n") < 2 {
+				t.Errorf("output = %q, want it to start with %q", got, tt.want)
+			}
+			if strings.Contains(out.String(), "hunter") || strings.Contains(out.String(), "Horse") {
+				t.Errorf("output contains a password: %q", out.String())
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteResultsError(t *testing.T) {
+	rows := exportRows(t)
+	if err := WriteResultsCSV(failingWriter{}, rows); err == nil {
+		t.Error("WriteResultsCSV() error = nil, want the writer's error")
+	}
+	if err := WriteResultsJSONL(failingWriter{}, rows); err == nil {
+		t.Error("WriteResultsJSONL() error = nil, want the writer's error")
+	}
+}
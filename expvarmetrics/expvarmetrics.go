@@ -0,0 +1,157 @@
+package expvarmetrics
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package expvarmetrics implements go_passwd.Metrics with expvar variables, so audit outcomes
+// are served on /debug/vars without a dependency on a metrics library.
+//
+//	opts.Metrics = expvarmetrics.Publish("passwd")
+//
+// publishes {"violations": {"too_short": n, ...}, "entropy_bits": {...}, "duration_seconds": {...}},
+// where each histogram holds cumulative bucket counts keyed by upper bound, like Prometheus.
+import (
+	"expvar"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	go_passwd "github.com/andreimerlescu/go-passwd"
+)
+
+// DefaultEntropyBuckets are the upper bounds, in bits, of the entropy histogram.
+var DefaultEntropyBuckets = []float64{10, 20, 30, 40, 50, 60, 70, 80, 100, 128}
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of the audit duration histogram.
+var DefaultDurationBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// Metrics counts violations by code and keeps histograms of entropy and audit duration. It is
+// safe for concurrent use.
+type Metrics struct {
+	Violations *expvar.Map // Violations by go_passwd Code
+	Entropy    *Histogram  // Result.EffectiveEntropy in bits
+	Duration   *Histogram  // Audit duration in seconds
+
+	root *expvar.Map
+}
+
+var _ go_passwd.Metrics = (*Metrics)(nil)
+
+// New returns Metrics with the default buckets that are not published; serve them with Var.
+func New() *Metrics {
+	m := &Metrics{
+		Violations: new(expvar.Map),
+		Entropy:    NewHistogram(DefaultEntropyBuckets...),
+		Duration:   NewHistogram(DefaultDurationBuckets...),
+		root:       new(expvar.Map),
+	}
+	m.root.Set("violations", m.Violations)
+	m.root.Set("entropy_bits", m.Entropy)
+	m.root.Set("duration_seconds", m.Duration)
+	return m
+}
+
+// Publish returns New Metrics published with expvar under name. Like expvar.Publish, it panics
+// if name is already in use, so call it once per name, typically at startup.
+func Publish(name string) *Metrics {
+	m := New()
+	expvar.Publish(name, m.root)
+	return m
+}
+
+// Var returns the expvar.Var holding all of m's variables.
+func (m *Metrics) Var() expvar.Var {
+	return m.root
+}
+
+// IncViolation counts one violation with code.
+func (m *Metrics) IncViolation(code string) {
+	m.Violations.Add(code, 1)
+}
+
+// ObserveEntropy adds bits to the entropy histogram.
+func (m *Metrics) ObserveEntropy(bits float64) {
+	m.Entropy.Observe(bits)
+}
+
+// ObserveDuration adds d, in seconds, to the duration histogram.
+func (m *Metrics) ObserveDuration(d time.Duration) {
+	m.Duration.Observe(d.Seconds())
+}
+
+// Histogram is an expvar.Var counting observations into buckets with fixed upper bounds.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // counts[i] holds values up to bounds[i]; the last, values above every bound
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram returns a Histogram with the given bucket upper bounds, in any order.
+func NewHistogram(bounds ...float64) *Histogram {
+	bounds = slices.Clone(bounds)
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+	return &Histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+// Observe adds value to the histogram. NaN and infinities are ignored, so String stays valid JSON.
+func (h *Histogram) Observe(value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+	i, _ := slices.BinarySearch(h.bounds, value)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.count++
+	h.sum += value
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// String returns the histogram as JSON, such as
+// {"buckets": {"10": 1, "20": 3, "+Inf": 4}, "count": 4, "sum": 61.5}, with cumulative counts.
+func (h *Histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var b strings.Builder
+	b.WriteString(`{"buckets": {`)
+	var cumulative uint64
+	for i, n := range h.counts {
+		cumulative += n
+		label := "+Inf"
+		if i < len(h.bounds) {
+			label = strconv.FormatFloat(h.bounds[i], 'g', -1, 64)
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(label) + ": " + strconv.FormatUint(cumulative, 10))
+	}
+	b.WriteString(`}, "count": ` + strconv.FormatUint(h.count, 10))
+	b.WriteString(`, "sum": ` + strconv.FormatFloat(h.sum, 'g', -1, 64) + "}")
+	return b.String()
+}
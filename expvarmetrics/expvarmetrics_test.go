@@ -0,0 +1,91 @@
+package expvarmetrics
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"testing"
+	"time"
+
+	go_passwd "github.com/andreimerlescu/go-passwd"
+)
+
+func TestMetricsAudit(t *testing.T) {
+	m := New()
+	opts := go_passwd.Options{MinLength: 12, UseDigits: true, UseSymbols: true, Metrics: m}
+	go_passwd.Audit("sunshine", opts)
+	go_passwd.Audit("short", opts)
+	go_passwd.Audit("Xq7#mB2vLp9!w4Z", opts)
+
+	var got struct {
+		Violations map[string]int `json:"violations"`
+		Entropy    struct {
+			Buckets map[string]uint64 `json:"buckets"`
+			Count   uint64            `json:"count"`
+		} `json:"entropy_bits"`
+		Duration struct {
+			Count uint64  `json:"count"`
+			Sum   float64 `json:"sum"`
+		} `json:"duration_seconds"`
+	}
+	if err := json.Unmarshal([]byte(m.Var().String()), &got); err != nil {
+		t.Fatalf("Var() = %s is not JSON: %v", m.Var(), err)
+	}
+	want := map[string]int{go_passwd.CodeTooShort: 2, go_passwd.CodeMissingDigit: 2, go_passwd.CodeMissingSymbol: 2}
+	for code, n := range want {
+		if got.Violations[code] != n {
+			t.Errorf("violations[%q] = %d, want %d", code, got.Violations[code], n)
+		}
+	}
+	if len(got.Violations) != len(want) {
+		t.Errorf("violations = %v, want %v", got.Violations, want)
+	}
+	if got.Entropy.Count != 3 || got.Entropy.Buckets["+Inf"] != 3 || got.Entropy.Buckets["40"] != 2 {
+		t.Errorf("entropy_bits = %+v, want 2 of 3 audits at or below 40 bits", got.Entropy)
+	}
+	if got.Duration.Count != 3 || got.Duration.Sum <= 0 {
+		t.Errorf("duration_seconds = %+v, want 3 positive observations", got.Duration)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram(10, 1, 5, 5)
+	for _, v := range []float64{0.5, 1, 3, 7, 12, 40} {
+		h.Observe(v)
+	}
+	h.Observe(math.NaN())
+	want := `{"buckets": {"1": 2, "5": 3, "10": 4, "+Inf": 6}, "count": 6, "sum": 63.5}`
+	if got := h.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	if !json.Valid([]byte(NewHistogram().String())) {
+		t.Errorf("empty histogram String() = %s, want JSON", NewHistogram())
+	}
+}
+
+func TestPublish(t *testing.T) {
+	m := Publish("passwd_test")
+	m.ObserveDuration(2 * time.Millisecond)
+	if v := expvar.Get("passwd_test"); v == nil || v.String() != m.Var().String() {
+		t.Errorf("expvar.Get() = %v, want the published Metrics", v)
+	}
+	if m.Duration.Count() != 1 {
+		t.Errorf("Duration.Count() = %d, want 1", m.Duration.Count())
+	}
+}
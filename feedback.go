@@ -0,0 +1,184 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"math"
+	"slices"
+)
+
+// feedbackPriority orders Feedback: replacing a compromised password comes before fixing its
+// length, classes, patterns, and strength. Codes and pattern kinds missing here come last.
+var feedbackPriority = map[string]int{
+	CodePwned:                    0,
+	CodeCommonPassword:           0,
+	CodeReused:                   0,
+	string(PatternCardNumber):    0,
+	string(PatternSSN):           0,
+	CodeTooSimilar:               1,
+	string(PatternUserInput):     1,
+	string(PatternLeet):          1,
+	string(PatternConfusable):    1,
+	string(PatternRepeatedWord):  1,
+	string(PatternReversedWord):  1,
+	CodeDisallowedChar:           2,
+	CodeControlChar:              2,
+	CodeInvisibleChar:            2,
+	CodeInvalidUTF8:              2,
+	CodeWhitespace:               2,
+	CodeEdgeWhitespace:           2,
+	CodeFirstChar:                2,
+	CodeLastChar:                 2,
+	CodeProhibitedChar:           2,
+	CodeForbiddenPattern:         2,
+	CodeMissingPattern:           2,
+	CodeTooShort:                 3,
+	CodeTooLong:                  3,
+	CodeTooManyBytes:             3,
+	CodeMissingDigit:             4,
+	CodeMissingLower:             4,
+	CodeMissingUpper:             4,
+	CodeMissingSymbol:            4,
+	CodeMissingExtended:          4,
+	CodeMissingEmoji:             4,
+	CodeTooFewClasses:            4,
+	CodeTooFewUnique:             4,
+	CodeClassRatio:               4,
+	string(PatternKeyboardWalk):  5,
+	string(PatternSequence):      5,
+	string(PatternRepeat):        5,
+	string(PatternRepeatedBlock): 5,
+	string(PatternDate):          5,
+	string(PatternYear):          5,
+	string(PatternPhone):         5,
+	CodeLowEntropy:               6,
+	CodeCustomRule:               7,
+	CodeBreachCheckFailed:        8,
+	CodeHistoryCheckFailed:       8,
+}
+
+// Feedback returns advice for improving the password, most important first. It is derived from
+// Violations and Patterns, so it never asks for more than the policy requires, and it is empty
+// for a Result decoded from JSON, whose violations no longer carry their codes.
+func (audit Result) Feedback() []string {
+	return audit.FeedbackLocale(DefaultLanguage)
+}
+
+// FeedbackLocale is like Feedback but uses the messages registered for lang with RegisterMessages,
+// falling back to English.
+func (audit Result) FeedbackLocale(lang string) []string {
+	return audit.feedback(lang, false)
+}
+
+// feedback is FeedbackLocale that, with redact set, uses the "_redacted" messages that leave out
+// the password's characters.
+func (audit Result) feedback(lang string, redact bool) []string {
+	type suggestion struct {
+		priority int
+		text     string
+	}
+	var suggestions []suggestion
+	add := func(kind, key string, args ...any) {
+		if _, ok := lookupMessage(lang, key); !ok {
+			return
+		}
+		priority, ok := feedbackPriority[kind]
+		if !ok {
+			priority = len(feedbackPriority)
+		}
+		text := message(lang, redactedKey(lang, key, redact), args...)
+		for _, s := range suggestions {
+			if s.text == text {
+				return
+			}
+		}
+		suggestions = append(suggestions, suggestion{priority, text})
+	}
+	// plural picks the "_one" variant of key when n is one.
+	plural := func(key string, n int) string {
+		if n == 1 {
+			return key + "_one"
+		}
+		return key
+	}
+
+	for _, err := range audit.Violations {
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			continue
+		}
+		key := "feedback." + verr.Code
+		switch verr.Code {
+		case CodeTooShort:
+			n := intParam(verr.Params, "min_length") - intParam(verr.Params, "length")
+			add(verr.Code, plural(key, n), "count", n)
+		case CodeTooLong:
+			n := intParam(verr.Params, "length") - intParam(verr.Params, "max_length")
+			add(verr.Code, plural(key, n), "count", n)
+		case CodeTooManyBytes:
+			n := intParam(verr.Params, "bytes") - intParam(verr.Params, "max_bytes")
+			add(verr.Code, plural(key, n), "count", n)
+		case CodeMissingDigit, CodeMissingLower, CodeMissingUpper, CodeMissingSymbol, CodeMissingExtended, CodeMissingEmoji:
+			n := intParam(verr.Params, "min") - intParam(verr.Params, "count")
+			add(verr.Code, plural(key, n), "count", n)
+		case CodeTooFewClasses:
+			n := intParam(verr.Params, "count")
+			add(verr.Code, plural(key, n), "count", n)
+		case CodeTooFewUnique:
+			n := intParam(verr.Params, "min_unique_chars") - intParam(verr.Params, "unique_chars")
+			add(verr.Code, plural(key, n), "count", n)
+		case CodeLowEntropy:
+			entropy, _ := verr.Params["entropy"].(float64)
+			minEntropy, _ := verr.Params["min_entropy"].(float64)
+			if audit.Length == 0 || audit.CharsetEntropy <= 0 {
+				add(verr.Code, key+"_generic")
+				continue
+			}
+			// Each added character is assumed to carry the bits per rune of the existing charset.
+			n := int(math.Ceil((minEntropy - entropy) / (audit.CharsetEntropy / float64(audit.Length))))
+			add(verr.Code, plural(key, n), "count", n)
+		default:
+			args := make([]any, 0, 2*len(verr.Params))
+			for name, value := range verr.Params {
+				args = append(args, name, value)
+			}
+			add(verr.Code, key, args...)
+		}
+	}
+	for _, p := range audit.Patterns {
+		add(p.Kind.String(), "feedback.pattern."+p.Kind.String(), "token", p.Token, "base", p.Base)
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b suggestion) int { return a.priority - b.priority })
+	lines := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		lines[i] = s.text
+	}
+	return lines
+}
+
+// intParam returns the integer parameter name of a ValidationError, zero when it is missing.
+func intParam(params map[string]any, name string) int {
+	switch v := params[name].(type) {
+	case int:
+		return v
+	case uint:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFeedbackGolden(t *testing.T) {
+	breached := BreachCheckerFunc(func(_ context.Context, password string) (bool, int, error) {
+		return password == "Summer2024!", 1234, nil
+	})
+	options := Options{
+		MinLength:           12,
+		MinDigits:           2,
+		UseUpper:            true,
+		UseSymbols:          true,
+		MinEntropy:          60,
+		RejectCommon:        true,
+		NormalizeLeet:       true,
+		MaxSequenceLength:   3,
+		RejectSequences:     true,
+		MaxRepeatRun:        2,
+		KeyboardWalkLength:  4,
+		RejectKeyboardWalks: true,
+		UserInputs:          []string{"andrei"},
+		BreachChecker:       breached,
+	}
+
+	var out strings.Builder
+	for _, password := range []string{
+		"Xq7mB2vLp9!w4Z",
+		"short",
+		"password",
+		"p@ssw0rd",
+		"Summer2024!",
+		"qwertyuiop12!A",
+		"Andrei1990!!xyz",
+		"aaabbbcccddd",
+		"abcdabcdabcd1!A",
+	} {
+		fmt.Fprintf(&out, "%s\n", password)
+		for _, line := range Audit(password, options).Feedback() {
+			fmt.Fprintf(&out, "\t%s\n", line)
+		}
+	}
+	assertGolden(t, "feedback.golden", []byte(out.String()))
+}
+
+func TestFeedback(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		lang     string
+		want     []string
+	}{
+		{"Strong password", "Xq7mB2vLp9!w", Options{MinLength: 8, UseSymbols: true}, "en", []string{}},
+		{"Singular", "Xq7mB2v", Options{MinLength: 8}, "en", []string{"Make it at least 1 character longer."}},
+		{
+			name:     "Compromised before length",
+			password: "password",
+			options:  Options{MinEntropy: 60, RejectCommon: true},
+			lang:     "en",
+			want:     []string{"This is a commonly used password. Choose something unique.", "Make it at least 11 characters longer to reach the required strength."},
+		},
+		{"Class shortfall", "Password1", Options{MinDigits: 3}, "en", []string{"Add 2 more digits."}},
+		{"Spanish", "password", Options{UseDigits: true}, "es", []string{"Añade un dígito."}},
+		{"Custom rule", "Acme-Q3-2026!", Options{CustomRules: []Rule{quarterRule}}, "en", []string{"Avoid years such as '2026'.", "Make sure it passes the quarter check."}},
+		{"Invalid options", "password", Options{MinLength: 9, MaxLength: 2}, "en", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Audit(tt.password, tt.options).FeedbackLocale(tt.lang)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("FeedbackLocale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedbackAfterJSON(t *testing.T) {
+	data, err := json.Marshal(Audit("short", Options{MinLength: 8}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Feedback(); len(got) != 0 {
+		t.Errorf("Feedback() = %q, want none for a decoded Result", got)
+	}
+}
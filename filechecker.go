@@ -0,0 +1,224 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// hashHexLen is the length of a SHA-1 hash in hex, the leading field of every pwned-passwords line.
+const hashHexLen = 2 * sha1.Size
+
+// maxPwnedLine is the longest line FileChecker reads: a hash, a colon, a count of up to 20 digits,
+// and CRLF.
+const maxPwnedLine = hashHexLen + 1 + 20 + 2
+
+// FileChecker is a BreachChecker that binary searches a local copy of the Have I Been Pwned
+// SHA-1 file ordered by hash, one "HASH:count" line per password, for exact offline lookups with
+// counts. Each lookup reads a few dozen lines with ReadAt, so it is safe for concurrent use and
+// needs no memory beyond a line buffer however large the file.
+type FileChecker struct {
+	f    *os.File
+	size int64
+}
+
+// NewFileChecker opens the ordered-by-hash file at path. It checks the format up front, reading
+// the first and last lines, which must be uppercase SHA-1 hashes in order followed by a count and
+// an LF or CRLF line ending, and fails with ErrMalformedPwnedFile otherwise.
+func NewFileChecker(path string) (*FileChecker, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newFileChecker(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// newFileChecker validates f and wraps it.
+func newFileChecker(f *os.File) (*FileChecker, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	c := &FileChecker{f: f, size: info.Size()}
+	if c.size == 0 {
+		return nil, fmt.Errorf("%w: %s is empty", ErrMalformedPwnedFile, f.Name())
+	}
+
+	var buf [maxPwnedLine]byte
+	first, _, err := c.lineAt(0, &buf)
+	if err != nil {
+		return nil, err
+	}
+	firstHash, err := parsePwnedLine(first)
+	if err != nil {
+		return nil, fmt.Errorf("%w: first line: %v", ErrMalformedPwnedFile, err)
+	}
+	firstHash = bytes.Clone(firstHash)
+
+	start := max(0, c.size-maxPwnedLine)
+	if start > 0 {
+		if start, err = c.nextLine(start-1, &buf); err != nil {
+			return nil, err
+		}
+	}
+	var last []byte
+	for start < c.size {
+		// Step through the lines of the tail; the last one read is the last line.
+		var line []byte
+		if line, start, err = c.lineAt(start, &buf); err != nil {
+			return nil, err
+		}
+		last = line
+	}
+	lastHash, err := parsePwnedLine(last)
+	if err != nil {
+		return nil, fmt.Errorf("%w: last line: %v", ErrMalformedPwnedFile, err)
+	}
+	if bytes.Compare(firstHash, lastHash) > 0 {
+		return nil, fmt.Errorf("%w: lines are not ordered by hash", ErrMalformedPwnedFile)
+	}
+	return c, nil
+}
+
+// parsePwnedLine returns the hash of a "HASH:count" line without its line ending.
+func parsePwnedLine(line []byte) ([]byte, error) {
+	hash, count, ok := bytes.Cut(line, []byte(":"))
+	if !ok || len(hash) != hashHexLen {
+		return nil, fmt.Errorf("%q is not a HASH:count line", line)
+	}
+	for _, c := range hash {
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'F') {
+			return nil, fmt.Errorf("%q is not an uppercase SHA-1 hash", hash)
+		}
+	}
+	if _, err := strconv.ParseUint(string(count), 10, 64); err != nil {
+		return nil, fmt.Errorf("%q has an invalid count", line)
+	}
+	return hash, nil
+}
+
+// nextLine returns the offset of the first line starting after offset.
+func (c *FileChecker) nextLine(offset int64, buf *[maxPwnedLine]byte) (int64, error) {
+	n, err := c.f.ReadAt(buf[:], offset)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	i := bytes.IndexByte(buf[:n], '\n')
+	if i < 0 {
+		if offset+int64(n) >= c.size {
+			return c.size, nil
+		}
+		return 0, fmt.Errorf("%w: line longer than %d bytes at offset %d", ErrMalformedPwnedFile, maxPwnedLine, offset)
+	}
+	return offset + int64(i) + 1, nil
+}
+
+// lineAt reads the line starting at offset into buf, returning it without its line ending and the
+// offset of the next line.
+func (c *FileChecker) lineAt(offset int64, buf *[maxPwnedLine]byte) ([]byte, int64, error) {
+	n, err := c.f.ReadAt(buf[:], offset)
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+	line := buf[:n]
+	next := offset + int64(n)
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line, next = line[:i], offset+int64(i)+1
+	} else if next < c.size {
+		return nil, 0, fmt.Errorf("%w: line longer than %d bytes at offset %d", ErrMalformedPwnedFile, maxPwnedLine, offset)
+	}
+	return bytes.TrimSuffix(line, []byte("\r")), next, nil
+}
+
+// CheckPwned returns how many times password appears in the file, zero when it does not.
+func (c *FileChecker) CheckPwned(ctx context.Context, password string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	p := []byte(password)
+	sum := sha1.Sum(p)
+	wipe(p)
+	var target [hashHexLen]byte
+	hex.Encode(target[:], sum[:])
+	for i, ch := range target {
+		if ch >= 'a' {
+			target[i] = ch - 'a' + 'A'
+		}
+	}
+
+	// The line sought, if present, starts in [lo, hi).
+	var buf [maxPwnedLine]byte
+	lo, hi := int64(0), c.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start := mid
+		if mid > 0 {
+			var err error
+			if start, err = c.nextLine(mid-1, &buf); err != nil {
+				return 0, err
+			}
+		}
+		if start >= hi {
+			hi = mid // No line starts in [mid, hi)
+			continue
+		}
+		line, next, err := c.lineAt(start, &buf)
+		if err != nil {
+			return 0, err
+		}
+		hash, count, ok := bytes.Cut(line, []byte(":"))
+		if !ok {
+			return 0, fmt.Errorf("%w: %q at offset %d", ErrMalformedPwnedFile, line, start)
+		}
+		switch bytes.Compare(hash, target[:]) {
+		case 0:
+			n, err := strconv.Atoi(string(count))
+			if err != nil {
+				return 0, fmt.Errorf("%w: invalid count at offset %d", ErrMalformedPwnedFile, start)
+			}
+			return n, nil
+		case -1:
+			lo = next
+		default:
+			hi = start
+		}
+	}
+	return 0, nil
+}
+
+// IsBreached implements BreachChecker using CheckPwned.
+func (c *FileChecker) IsBreached(ctx context.Context, password string) (bool, int, error) {
+	count, err := c.CheckPwned(ctx, password)
+	return count > 0, count, err
+}
+
+// Close closes the file.
+func (c *FileChecker) Close() error {
+	return c.f.Close()
+}
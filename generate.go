@@ -0,0 +1,654 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultGenerateLength is the length used by Generate when Options.MaxLength is zero.
+const DefaultGenerateLength = 16
+
+// MaxGenerateEntropy is the most bits GenerateWithEntropy and Generate's MinEntropy accept, far
+// beyond any key size, so a huge target cannot demand an unbounded password when MaxLength is zero.
+const MaxGenerateEntropy = 4096
+
+// generateAttempts bounds how many candidates Generate draws before giving up on MinEntropy,
+// RejectPII, or RejectSequences.
+const generateAttempts = 100
+
+// extendedChars are the extended Unicode letters Generate draws from when UseExtended is set.
+const extendedChars = "ßàáâãäåæçèéêëìíîïðñòóôõöøùúûüýþÿÀÁÂÃÄÅÆÇÈÉÊËÌÍÎÏÐÑÒÓÔÕÖØÙÚÛÜÝÞ"
+
+// DefaultAmbiguousSet lists the characters Generator leaves out when ExcludeAmbiguous is set and
+// AmbiguousSet is empty.
+const DefaultAmbiguousSet = "0O1lI|"
+
+// Generator holds settings shared by the password, template, passphrase, recovery code and token
+// generators. The zero value is ready to use and matches the package-level functions.
+type Generator struct {
+	// ExcludeAmbiguous leaves out characters that are easily confused when read or typed.
+	ExcludeAmbiguous bool
+	// AmbiguousSet replaces DefaultAmbiguousSet as the characters ExcludeAmbiguous leaves out.
+	AmbiguousSet string
+	// Rand is the entropy source, crypto/rand.Reader when nil. A deterministic reader makes the
+	// output reproducible for tests; its read errors are returned by the generators.
+	Rand io.Reader
+}
+
+// random returns the entropy source g reads from.
+func (g *Generator) random() io.Reader {
+	if g.Rand == nil {
+		return rand.Reader
+	}
+	return g.Rand
+}
+
+// charset returns chars without the characters g excludes.
+func (g *Generator) charset(chars string) []rune {
+	if !g.ExcludeAmbiguous {
+		return []rune(chars)
+	}
+	ambiguous := g.AmbiguousSet
+	if ambiguous == "" {
+		ambiguous = DefaultAmbiguousSet
+	}
+	set := make([]rune, 0, len(chars))
+	for _, r := range chars {
+		if !strings.ContainsRune(ambiguous, r) {
+			set = append(set, r)
+		}
+	}
+	return set
+}
+
+// Generate returns a random password built with crypto/rand that satisfies opts. The password
+// length falls between MinLength and MaxLength and contains at least one character from every class
+// enabled by UseDigits, UseLower, UseUpper, UseSymbols, UseExtended and UseEmoji, or the number set
+// by the matching Min* field. When no class is enabled, digits, lowercase, uppercase and symbols
+// are used. The first and last characters are of FirstCharClasses and LastCharClasses when set,
+// characters are drawn without replacement until there are MinUniqueChars distinct ones, and no
+// class fills more than MaxClassRatio of the password. The length is long enough for MinEntropy
+// with the whole pool, and candidates Audit credits with less are redrawn, as are candidates
+// holding a card or social security number when RejectPII is set. With RejectSequences, characters
+// that extend a run past MaxSequenceLength are redrawn. Contradictory Options return an error.
+func Generate(opts Options) (string, error) {
+	var g Generator
+	return g.Generate(opts)
+}
+
+// Generate is like the package-level Generate but leaves out the characters g excludes. It errors
+// when that leaves a required class with no characters.
+func (g *Generator) Generate(opts Options) (string, error) {
+	pool, required, err := g.pools(opts)
+	if err != nil {
+		return "", err
+	}
+
+	lo := max(int(opts.MinLength), len(required), int(opts.MinUniqueChars), 1)
+	if opts.MaxClassRatio > 0 {
+		// Each class, required ones included, may only fill MaxClassRatio of the password.
+		lo = max(lo, int(math.Ceil(float64(max(largestRequiredClass(required, &opts), 1))/opts.MaxClassRatio-1e-9)))
+	}
+	entropyLength := 0
+	if opts.MinEntropy > 0 {
+		if !(opts.MinEntropy <= MaxGenerateEntropy) {
+			return "", fmt.Errorf("minimum entropy must be at most %d bits, got %v", MaxGenerateEntropy, opts.MinEntropy)
+		}
+		bitsPerChar := math.Log2(float64(poolSize(pool)))
+		if bitsPerChar == 0 {
+			return "", errors.New("a single character pool cannot carry entropy")
+		}
+		// As in GenerateWithEntropy, the epsilon keeps exact multiples from rounding up.
+		entropyLength = int(math.Ceil(opts.MinEntropy/bitsPerChar - 1e-9))
+		lo = max(lo, entropyLength)
+	}
+	hi := int(opts.MaxLength)
+	if hi == 0 {
+		hi = DefaultGenerateLength
+		if hi < lo {
+			hi = lo
+		}
+	}
+	if opts.MaxBytes > 0 {
+		// Cap the length so even a password of the widest runes fits in MaxBytes.
+		if n := int(opts.MaxBytes) / widestRune(pool); hi > n {
+			hi = n
+		}
+	}
+	if opts.MaxLength > 0 && opts.MinLength > opts.MaxLength {
+		return "", errors.New("minimum length exceeds maximum length")
+	}
+	if entropyLength > hi {
+		return "", fmt.Errorf("%.2f bits needs %d characters, maximum length is %d", opts.MinEntropy, entropyLength, hi)
+	}
+	if lo > hi {
+		return "", errors.New("maximum length is too short to include every required character")
+	}
+
+	// Audit discounts patterns and classes a draw happens to miss, so a long enough password can
+	// still fall short of MinEntropy; redraw those, without the checks that leave the process. Card
+	// and social security numbers turn up by chance in digit-heavy pools and are redrawn too.
+	var check *Validator
+	if opts.MinEntropy > 0 {
+		local := opts
+		local.BreachChecker, local.History = nil, nil
+		v := newValidator(local, nil, nil)
+		check = &v
+	}
+	random := g.random()
+	for range generateAttempts {
+		password, err := g.build(random, pool, required, lo, hi, &opts)
+		if err != nil {
+			return "", err
+		}
+		if !redraw(check, password, &opts) {
+			return password, nil
+		}
+	}
+	return "", fmt.Errorf("no password passed Audit in %d attempts", generateAttempts)
+}
+
+// build draws one password of lo to hi characters from pool holding every required set.
+func (g *Generator) build(random io.Reader, pool, required [][]rune, lo, hi int, opts *Options) (string, error) {
+	length, err := randomInt(random, hi-lo+1)
+	if err != nil {
+		return "", err
+	}
+	length += lo
+
+	d := drawer{random: random, opts: opts, out: make([]rune, 0, length), unique: int(opts.MinUniqueChars)}
+	if opts.MaxClassRatio > 0 {
+		d.perClass = int(math.Floor(opts.MaxClassRatio*float64(length) + 1e-9))
+	}
+	for _, set := range required {
+		if err := d.draw(set); err != nil {
+			return "", err
+		}
+	}
+
+	var all []rune
+	for _, set := range pool {
+		all = append(all, set...)
+	}
+	if d.perClass > 0 && d.perClass*classCount(all, opts) < length {
+		// Too few classes to stay under MaxClassRatio: Audit accepts the other ASCII classes too.
+		for _, r := range g.charset(permittedChars(opts, digitChars+lowerChars+upperChars+symbolSetOf(opts))) {
+			if !slices.Contains(all, r) {
+				all = append(all, r)
+			}
+		}
+	}
+	for len(d.out) < length {
+		if err := d.draw(all); err != nil {
+			return "", err
+		}
+	}
+	out := d.out
+	if d.unique > 0 {
+		zeroRunes(out)
+		return "", fmt.Errorf("not enough distinct characters for a minimum of %d", opts.MinUniqueChars)
+	}
+
+	if err := shuffle(random, out); err != nil {
+		return "", err
+	}
+	if err := placeBoundaries(random, out, opts); err != nil {
+		return "", err
+	}
+	if opts.RejectSequences && opts.MaxSequenceLength > 0 {
+		chars := all
+		for _, set := range required {
+			for _, r := range set {
+				if !slices.Contains(chars, r) {
+					chars = append(chars, r)
+				}
+			}
+		}
+		if err := breakSequences(random, out, chars, opts); err != nil {
+			zeroRunes(out)
+			return "", err
+		}
+	}
+	password := string(out)
+	zeroRunes(out)
+	return password, nil
+}
+
+// redraw reports whether Audit would fail pass on something a fresh draw avoids: less than
+// MinEntropy as credited by v, digits shaped like a personal number with RejectPII, or a run
+// breakSequences found no replacement for.
+func redraw(v *Validator, pass string, opts *Options) bool {
+	if opts.RejectPII && len(detectPII(pass)) > 0 {
+		return true
+	}
+	if opts.RejectSequences && opts.MaxSequenceLength > 0 && len(detectSequences([]rune(pass), int(opts.MaxSequenceLength))) > 0 {
+		return true
+	}
+	return v != nil && auditedEntropy(v, pass) < opts.MinEntropy
+}
+
+// auditedEntropy returns the EffectiveEntropy v credits pass with.
+func auditedEntropy(v *Validator, pass string) float64 {
+	pass = NormalizePassword(pass, v.opts.Normalize)
+	counts, length := classify(pass, v.opts.SymbolSet, v.opts.UnicodeClasses)
+	return v.audit(context.Background(), pass, counts, length).EffectiveEntropy
+}
+
+// poolSize returns the number of runes across pool.
+func poolSize(pool [][]rune) int {
+	size := 0
+	for _, set := range pool {
+		size += len(set)
+	}
+	return size
+}
+
+// GenerateWithEntropy returns a random password carrying at least minBits of entropy and the
+// entropy it carries. The length is the shortest that reaches minBits with the character pool
+// implied by opts, but never below MinLength or the number of required characters. It errors when
+// that length exceeds MaxLength or minBits exceeds MaxGenerateEntropy.
+func GenerateWithEntropy(minBits float64, opts Options) (string, float64, error) {
+	var g Generator
+	return g.GenerateWithEntropy(minBits, opts)
+}
+
+// GenerateWithEntropy is like the package-level GenerateWithEntropy but uses the settings of g.
+func (g *Generator) GenerateWithEntropy(minBits float64, opts Options) (string, float64, error) {
+	if !(minBits > 0 && minBits <= MaxGenerateEntropy) {
+		return "", 0, fmt.Errorf("minimum entropy must be between 0 and %d bits, got %v", MaxGenerateEntropy, minBits)
+	}
+	pool, required, err := g.pools(opts)
+	if err != nil {
+		return "", 0, err
+	}
+	bitsPerChar := math.Log2(float64(poolSize(pool)))
+	if bitsPerChar == 0 {
+		return "", 0, errors.New("a single character pool cannot carry entropy")
+	}
+
+	// The epsilon keeps exact multiples such as 60 bits of 5-bit characters from rounding up.
+	length := max(uint(math.Ceil(minBits/bitsPerChar-1e-9)), opts.MinLength, uint(len(required)))
+	if opts.MaxLength > 0 && length > opts.MaxLength {
+		return "", 0, fmt.Errorf("%.2f bits needs %d characters, maximum length is %d", minBits, length, opts.MaxLength)
+	}
+	opts.MinLength, opts.MaxLength = length, length
+
+	password, err := g.Generate(opts)
+	if err != nil {
+		return "", 0, err
+	}
+	return password, float64(length) * bitsPerChar, nil
+}
+
+// pools returns the character sets opts draws from and one set per required character.
+func (g *Generator) pools(opts Options) (pool, required [][]rune, err error) {
+	classes := [...]struct {
+		name  string
+		use   bool
+		min   uint
+		chars string
+	}{
+		{"digits", opts.UseDigits, opts.MinDigits, digitChars},
+		{"lowercase letters", opts.UseLower, opts.MinLower, lowerChars},
+		{"uppercase letters", opts.UseUpper, opts.MinUpper, upperChars},
+		{"symbols", opts.UseSymbols, opts.MinSymbols, symbolSetOf(&opts)},
+		{"extended letters", opts.UseExtended, opts.MinExtended, extendedSetOf(&opts)},
+		{"emoji", opts.UseEmoji, 0, emojiChars},
+	}
+
+	for _, class := range classes {
+		n := minimumCount(class.use, class.min)
+		if n == 0 {
+			continue
+		}
+		set := g.charset(permittedChars(&opts, class.chars))
+		if len(set) == 0 {
+			return nil, nil, fmt.Errorf("no %s are left after excluding ambiguous and disallowed characters", class.name)
+		}
+		pool = append(pool, set)
+		for i := uint(0); i < n; i++ {
+			required = append(required, set)
+		}
+	}
+	if len(pool) == 0 {
+		for _, chars := range []string{digitChars, lowerChars, upperChars, symbolSetOf(&opts)} {
+			if set := g.charset(permittedChars(&opts, chars)); len(set) > 0 {
+				pool = append(pool, set)
+				if opts.MinClasses > 0 {
+					required = append(required, set) // Covers any number of the four classes
+				}
+			}
+		}
+		if len(pool) == 0 {
+			return nil, nil, errors.New("no characters are left after excluding ambiguous and disallowed characters")
+		}
+	}
+	extra, err := g.boundaryChars(pool, required, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pool, append(required, extra...), nil
+}
+
+// boundaryChars returns the sets of any extra characters needed so that, besides the required
+// ones, the password has a character of FirstCharClasses and a different one of LastCharClasses
+// for placeBoundaries to move to either end. A required set only counts when all of it fits, and
+// classes missing from pool are drawn from the ASCII classes Audit accepts anyway.
+func (g *Generator) boundaryChars(pool, required [][]rune, opts *Options) ([][]rune, error) {
+	first, last := opts.FirstCharClasses, opts.LastCharClasses
+	if first == 0 && last == 0 {
+		return nil, nil
+	}
+	fits := func(r rune, classes Class) bool {
+		return classes&classOf(r, opts.SymbolSet, opts.UnicodeClasses) != 0
+	}
+	allFit := func(set []rune, classes Class) bool {
+		return classes != 0 && !slices.ContainsFunc(set, func(r rune) bool { return !fits(r, classes) })
+	}
+	chars := func(classes Class) ([]rune, error) {
+		var set []rune
+		for _, s := range pool {
+			for _, r := range s {
+				if fits(r, classes) {
+					set = append(set, r)
+				}
+			}
+		}
+		if len(set) == 0 {
+			for _, r := range g.charset(permittedChars(opts, digitChars+lowerChars+upperChars+symbolSetOf(opts))) {
+				if fits(r, classes) {
+					set = append(set, r)
+				}
+			}
+		}
+		if len(set) == 0 {
+			return nil, fmt.Errorf("no %s characters are left to start or end the password", classes)
+		}
+		return set, nil
+	}
+
+	// Counts of required characters that can go first, last, and either, as in Hall's condition.
+	firstFits, lastFits, eitherFits := 0, 0, 0
+	for _, set := range required {
+		f, l := allFit(set, first), allFit(set, last)
+		if f {
+			firstFits++
+		}
+		if l {
+			lastFits++
+		}
+		if f || l {
+			eitherFits++
+		}
+	}
+	var extra [][]rune
+	add := func(classes Class) error {
+		set, err := chars(classes)
+		extra = append(extra, set)
+		return err
+	}
+	if first != 0 && firstFits == 0 {
+		if err := add(first); err != nil {
+			return nil, err
+		}
+		firstFits, eitherFits = 1, eitherFits+1
+	}
+	if last != 0 && lastFits == 0 {
+		if err := add(last); err != nil {
+			return nil, err
+		}
+		eitherFits++
+	}
+	if first != 0 && last != 0 && eitherFits < 2 {
+		if err := add(first); err != nil {
+			return nil, err
+		}
+	}
+	return extra, nil
+}
+
+// drawer draws the characters of a password into out, keeping every class to at most perClass
+// characters when that is set and drawing runes not in out yet while unique more are needed.
+type drawer struct {
+	random   io.Reader
+	opts     *Options
+	out      []rune
+	perClass int
+	unique   int
+	counts   [8]int // Characters drawn per class, indexed by bit
+}
+
+// draw appends a random rune of set to d.out, preferring runes that keep both limits and
+// erroring when every rune of set would break MaxClassRatio.
+func (d *drawer) draw(set []rune) error {
+	if d.perClass == 0 && d.unique == 0 {
+		r, err := randomRune(d.random, set)
+		if err != nil {
+			return err
+		}
+		d.out = append(d.out, r)
+		return nil
+	}
+	room := func(r rune) bool {
+		return d.perClass == 0 || d.counts[classIndex(r, d.opts)] < d.perClass
+	}
+	candidates := make([]rune, 0, len(set))
+	for _, r := range set {
+		if room(r) && !(d.unique > 0 && slices.Contains(d.out, r)) {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, r := range set {
+			if room(r) {
+				candidates = append(candidates, r)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no character keeps every class within a maximum ratio of %.2f", d.opts.MaxClassRatio)
+	}
+	r, err := randomRune(d.random, candidates)
+	if err != nil {
+		return err
+	}
+	if d.unique > 0 && !slices.Contains(d.out, r) {
+		d.unique--
+	}
+	d.counts[classIndex(r, d.opts)]++
+	d.out = append(d.out, r)
+	return nil
+}
+
+// classIndex returns the bit of r's class, as an index into per-class counts.
+func classIndex(r rune, opts *Options) int {
+	return bits.TrailingZeros8(uint8(classOf(r, opts.SymbolSet, opts.UnicodeClasses)))
+}
+
+// largestRequiredClass returns the most required characters that share a class.
+func largestRequiredClass(required [][]rune, opts *Options) int {
+	var counts [8]int
+	for _, set := range required {
+		counts[classIndex(set[0], opts)]++
+	}
+	return slices.Max(counts[:])
+}
+
+// classCount returns the number of classes among chars.
+func classCount(chars []rune, opts *Options) int {
+	var classes Class
+	for _, r := range chars {
+		classes |= classOf(r, opts.SymbolSet, opts.UnicodeClasses)
+	}
+	return bits.OnesCount8(uint8(classes))
+}
+
+// breakSequences replaces every character of out that extends an ascending or descending run
+// past MaxSequenceLength with a random one of chars that does not. The replacement keeps the class,
+// and so the first and last characters and the class ratio, and is new to out when MinUniqueChars
+// is set. A run with no such replacement is left for Generate to redraw.
+func breakSequences(random io.Reader, out, chars []rune, opts *Options) error {
+	limit := int(opts.MaxSequenceLength)
+	var candidates []rune
+	for i := 1; i < len(out); i++ {
+		if runEnding(out, i) <= limit {
+			continue
+		}
+		candidates = candidates[:0]
+		class, old := classIndex(out[i], opts), out[i]
+		for _, r := range chars {
+			if r == old || classIndex(r, opts) != class || (opts.MinUniqueChars > 0 && slices.Contains(out, r)) {
+				continue
+			}
+			if out[i] = r; runEnding(out, i) <= limit {
+				candidates = append(candidates, r)
+			}
+		}
+		out[i] = old
+		if len(candidates) == 0 {
+			continue
+		}
+		n, err := randomInt(random, len(candidates))
+		if err != nil {
+			return err
+		}
+		out[i] = candidates[n]
+	}
+	zeroRunes(candidates)
+	return nil
+}
+
+// runEnding returns the length of the ascending or descending run that ends at runes[i], as
+// detectSequences counts it.
+func runEnding(runes []rune, i int) int {
+	if i == 0 {
+		return 1
+	}
+	step := sequenceStep(runes[i-1], runes[i])
+	if step == 0 {
+		return 1
+	}
+	n := 2
+	for i-n >= 0 && sequenceStep(runes[i-n], runes[i-n+1]) == step {
+		n++
+	}
+	return n
+}
+
+// placeBoundaries swaps a random character of FirstCharClasses to the start of the shuffled out
+// and one of LastCharClasses to its end, which boundaryChars made sure out holds.
+func placeBoundaries(random io.Reader, out []rune, opts *Options) error {
+	first, last := opts.FirstCharClasses, opts.LastCharClasses
+	fits := func(r rune, classes Class) bool {
+		return classes&classOf(r, opts.SymbolSet, opts.UnicodeClasses) != 0
+	}
+	// pick swaps a random one of the candidates into position to.
+	pick := func(candidates []int, to int) error {
+		if len(candidates) == 0 {
+			return errors.New("no character can start or end the password")
+		}
+		i, err := randomInt(random, len(candidates))
+		if err != nil {
+			return err
+		}
+		out[to], out[candidates[i]] = out[candidates[i]], out[to]
+		return nil
+	}
+
+	start := 0
+	if first != 0 {
+		var candidates, lastOnes []int
+		for i, r := range out {
+			if fits(r, first) {
+				candidates = append(candidates, i)
+			}
+			if last != 0 && fits(r, last) {
+				lastOnes = append(lastOnes, i)
+			}
+		}
+		// Leave the only character that can end the password for the end.
+		if len(out) > 1 && len(lastOnes) == 1 && len(candidates) > 1 {
+			candidates = slices.DeleteFunc(candidates, func(i int) bool { return i == lastOnes[0] })
+		}
+		if err := pick(candidates, 0); err != nil {
+			return err
+		}
+		start = 1
+	}
+	if last != 0 && len(out) > start {
+		var candidates []int
+		for i := start; i < len(out); i++ {
+			if fits(out[i], last) {
+				candidates = append(candidates, i)
+			}
+		}
+		return pick(candidates, len(out)-1)
+	}
+	return nil
+}
+
+// widestRune returns the length in bytes of the widest rune in pool.
+func widestRune(pool [][]rune) int {
+	widest := 1
+	for _, set := range pool {
+		for _, r := range set {
+			widest = max(widest, utf8.RuneLen(r))
+		}
+	}
+	return widest
+}
+
+// randomRune picks a uniformly random rune from set using random.
+func randomRune(random io.Reader, set []rune) (rune, error) {
+	i, err := randomInt(random, len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[i], nil
+}
+
+// randomInt returns a uniform random integer in [0, n) read from random.
+func randomInt(random io.Reader, n int) (int, error) {
+	v, err := rand.Int(random, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
+
+// shuffle performs a Fisher-Yates shuffle of runes using random.
+func shuffle(random io.Reader, runes []rune) error {
+	for i := len(runes) - 1; i > 0; i-- {
+		j, err := randomInt(random, i+1)
+		if err != nil {
+			return err
+		}
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return nil
+}
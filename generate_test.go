@@ -0,0 +1,494 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{
+			name:    "No requirements",
+			options: Options{},
+		},
+		{
+			name:    "MinClasses without enabled classes",
+			options: Options{MinLength: 4, MaxLength: 4, MinClasses: 4},
+		},
+		{
+			name:    "Emoji within a byte limit",
+			options: Options{MinLength: 8, MaxLength: 64, MaxBytes: 72, UseLower: true, UseEmoji: true},
+		},
+		{
+			name:    "Digits only",
+			options: Options{MinLength: 6, MaxLength: 6, UseDigits: true},
+		},
+		{
+			name:    "Lower and upper",
+			options: Options{MinLength: 8, MaxLength: 12, UseLower: true, UseUpper: true},
+		},
+		{
+			name: "All ASCII classes",
+			options: Options{
+				MinLength:  12,
+				MaxLength:  32,
+				UseDigits:  true,
+				UseLower:   true,
+				UseUpper:   true,
+				UseSymbols: true,
+			},
+		},
+		{
+			name: "Every class",
+			options: Options{
+				MinLength:         8,
+				MaxLength:         20,
+				UseDigits:         true,
+				UseLower:          true,
+				UseUpper:          true,
+				UseSymbols:        true,
+				UseExtended:       true,
+				MinimumComplexity: PwComplexityExtendedMixed,
+			},
+		},
+		{
+			name:    "Extended only",
+			options: Options{MinLength: 5, MaxLength: 9, UseExtended: true},
+		},
+		{
+			name:    "Minimum length beyond default",
+			options: Options{MinLength: 40, UseSymbols: true},
+		},
+		{
+			name:    "Per-class minimums",
+			options: Options{MinLength: 10, MaxLength: 12, MinDigits: 3, MinSymbols: 2, UseUpper: true},
+		},
+		{
+			name:    "Exactly enough room for every class",
+			options: Options{MaxLength: 6, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true, UseExtended: true},
+		},
+		{
+			name:    "First character lowercase",
+			options: Options{MinLength: 8, MaxLength: 20, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true, FirstCharClasses: ClassLower},
+		},
+		{
+			name:    "Last character a digit or symbol",
+			options: Options{MinLength: 8, MaxLength: 20, UseLower: true, LastCharClasses: ClassDigits | ClassSymbols},
+		},
+		{
+			name: "Both ends from one required class",
+			options: Options{MinLength: 3, MaxLength: 3, UseDigits: true, UseUpper: true,
+				FirstCharClasses: ClassUpper, LastCharClasses: ClassUpper},
+		},
+		{
+			name:    "Every character distinct",
+			options: Options{MinLength: 16, MaxLength: 16, MinUniqueChars: 16},
+		},
+		{
+			name:    "Distinct digits",
+			options: Options{MinLength: 10, MaxLength: 10, UseDigits: true, MinUniqueChars: 10},
+		},
+		{
+			name:    "Class ratio",
+			options: Options{MinLength: 12, MaxLength: 20, MaxClassRatio: 0.4},
+		},
+		{
+			name:    "Class ratio with two classes enabled",
+			options: Options{MinLength: 16, MaxLength: 16, UseDigits: true, UseLower: true, MaxClassRatio: 0.3},
+		},
+		{
+			name:    "Class ratio and distinct characters",
+			options: Options{MinLength: 8, MaxLength: 8, UseLower: true, MinDigits: 2, MinUniqueChars: 8, MaxClassRatio: 0.5},
+		},
+		{
+			name:    "Both ends without enabled classes",
+			options: Options{MaxLength: 3, FirstCharClasses: ClassLower | ClassUpper, LastCharClasses: ClassDigits},
+		},
+		{
+			name:    "Minimum entropy",
+			options: Options{MinLength: 8, MaxLength: 20, MinEntropy: 100},
+		},
+		{
+			name:    "Minimum entropy without a maximum length",
+			options: Options{UseDigits: true, MinEntropy: 80},
+		},
+		{
+			name:    "Digits of card number length without personal numbers",
+			options: Options{MinLength: 13, MaxLength: 19, UseDigits: true, RejectPII: true},
+		},
+		{
+			name:    "Short sequences",
+			options: Options{MinLength: 8, MaxLength: 20, MaxSequenceLength: 2, RejectSequences: true},
+		},
+		{
+			name:    "No two consecutive digits",
+			options: Options{MinLength: 16, MaxLength: 16, UseDigits: true, MaxSequenceLength: 1, RejectSequences: true},
+		},
+		{
+			name: "Short sequences of distinct letters at both ends",
+			options: Options{MinLength: 12, MaxLength: 12, UseLower: true, MinUniqueChars: 12, MaxSequenceLength: 1,
+				RejectSequences: true, FirstCharClasses: ClassLower, LastCharClasses: ClassLower},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 500; i++ {
+				password, err := Generate(tt.options)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if result := Audit(password, tt.options); result.Err != nil {
+					t.Fatalf("Audit(%q) error = %v", password, result.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateContradictoryOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{
+			name:    "Every class in three characters",
+			options: Options{MaxLength: 3, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true, UseExtended: true},
+		},
+		{
+			name:    "Per-class minimums exceed maximum length",
+			options: Options{MaxLength: 4, MinDigits: 3, MinSymbols: 2},
+		},
+		{
+			name:    "Minimum length exceeds maximum length",
+			options: Options{MinLength: 12, MaxLength: 8},
+		},
+		{
+			name:    "Minimum length of emoji exceeds maximum bytes",
+			options: Options{MinLength: 20, MaxBytes: 72, UseEmoji: true},
+		},
+		{
+			name:    "First character of a class that is not allowed",
+			options: Options{UseDigits: true, AllowedChars: digitChars, FirstCharClasses: ClassLower},
+		},
+		{
+			name:    "More distinct characters than the pool holds",
+			options: Options{MinLength: 12, UseDigits: true, AllowedChars: digitChars, MinUniqueChars: 11},
+		},
+		{
+			name:    "Required class above the ratio",
+			options: Options{MaxLength: 8, MinDigits: 5, MaxClassRatio: 0.5},
+		},
+		{
+			name:    "No room for the boundary characters",
+			options: Options{MaxLength: 2, UseDigits: true, UseLower: true, LastCharClasses: ClassSymbols},
+		},
+		{
+			name:    "Maximum length too short for the minimum entropy",
+			options: Options{MinLength: 8, MaxLength: 12, MinEntropy: 100},
+		},
+		{
+			name:    "Minimum entropy above the maximum",
+			options: Options{MinEntropy: MaxGenerateEntropy + 1},
+		},
+		{
+			name:    "Minimum entropy from a single character",
+			options: Options{UseDigits: true, AllowedChars: "7", MinEntropy: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if password, err := Generate(tt.options); err == nil {
+				t.Errorf("Generate() = %q, want error", password)
+			}
+		})
+	}
+}
+
+func TestGenerateWithEntropy(t *testing.T) {
+	tests := []struct {
+		name    string
+		minBits float64
+		options Options
+		length  int
+		wantErr bool
+	}{
+		{name: "Default pool", minBits: 80, length: 13},
+		{name: "Digits only", minBits: 40, options: Options{UseDigits: true}, length: 13},
+		{name: "Rounded length fits MaxLength", minBits: 40, options: Options{MaxLength: 13, UseDigits: true}, length: 13},
+		{name: "Rounded length crosses MaxLength", minBits: 40, options: Options{MaxLength: 12, UseDigits: true}, wantErr: true},
+		{name: "Exact multiple fits MaxLength", minBits: 12 * math.Log2(26), options: Options{MaxLength: 12, UseLower: true}, length: 12},
+		{name: "Just above exact multiple", minBits: 12*math.Log2(26) + 0.01, options: Options{MaxLength: 12, UseLower: true}, wantErr: true},
+		{name: "MinLength wins", minBits: 20, options: Options{MinLength: 16, UseLower: true}, length: 16},
+		{name: "Required characters win", minBits: 1, options: Options{MinDigits: 3, MinSymbols: 3}, length: 6},
+		{name: "Zero bits", minBits: 0, wantErr: true},
+		{name: "Negative bits", minBits: -8, wantErr: true},
+		{name: "NaN bits", minBits: math.NaN(), wantErr: true},
+		{name: "Infinite bits", minBits: math.Inf(1), wantErr: true},
+		{name: "Maximum bits", minBits: MaxGenerateEntropy, options: Options{UseDigits: true}, length: 1234},
+		{name: "Above the maximum", minBits: MaxGenerateEntropy + 1, wantErr: true},
+		{name: "Huge bits", minBits: 1e300, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, bits, err := GenerateWithEntropy(tt.minBits, tt.options)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GenerateWithEntropy() = %q, want error", password)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateWithEntropy() error = %v", err)
+			}
+			if got := len([]rune(password)); got != tt.length {
+				t.Errorf("len(%q) = %d, want %d", password, got, tt.length)
+			}
+			if bits < tt.minBits {
+				t.Errorf("GenerateWithEntropy() entropy = %.2f, want at least %.2f", bits, tt.minBits)
+			}
+			if result := Audit(password, tt.options); result.Err != nil {
+				t.Errorf("Audit(%q) error = %v", password, result.Err)
+			}
+		})
+	}
+}
+
+func TestGeneratorExcludeAmbiguous(t *testing.T) {
+	options := Options{MinLength: 12, MaxLength: 24, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true}
+	tests := []struct {
+		name      string
+		generator Generator
+		forbidden string
+	}{
+		{name: "Default set", generator: Generator{ExcludeAmbiguous: true}, forbidden: DefaultAmbiguousSet},
+		{name: "Custom set", generator: Generator{ExcludeAmbiguous: true, AmbiguousSet: "5S2Z8B"}, forbidden: "5S2Z8B"},
+	}
+
+	generators := map[string]func(g *Generator) ([]string, error){
+		"Generate": func(g *Generator) ([]string, error) {
+			password, err := g.Generate(options)
+			return []string{password}, err
+		},
+		"GenerateFromTemplate": func(g *Generator) ([]string, error) {
+			password, err := g.GenerateFromTemplate("Uldsxxxxxxxx")
+			return []string{password}, err
+		},
+		"GenerateRecoveryCodes": func(g *Generator) ([]string, error) {
+			return g.GenerateRecoveryCodes(4, 3, 4)
+		},
+	}
+
+	for _, tt := range tests {
+		for name, generate := range generators {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				for i := 0; i < 2000; i++ {
+					outputs, err := generate(&tt.generator)
+					if err != nil {
+						t.Fatalf("%s() error = %v", name, err)
+					}
+					for _, out := range outputs {
+						if strings.ContainsAny(out, tt.forbidden) {
+							t.Fatalf("%s() = %q, contains one of %q", name, out, tt.forbidden)
+						}
+					}
+				}
+			})
+		}
+	}
+
+	g := Generator{ExcludeAmbiguous: true}
+	for i := 0; i < 500; i++ {
+		password, err := g.Generate(options)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if result := Audit(password, options); result.Err != nil {
+			t.Fatalf("Audit(%q) error = %v", password, result.Err)
+		}
+	}
+}
+
+func TestGeneratorExcludeAmbiguousEmptyClass(t *testing.T) {
+	g := Generator{ExcludeAmbiguous: true, AmbiguousSet: digitChars}
+
+	if password, err := g.Generate(Options{UseDigits: true, UseLower: true}); err == nil {
+		t.Errorf("Generate() = %q, want error", password)
+	}
+	if password, err := g.Generate(Options{UseLower: true}); err != nil || strings.ContainsAny(password, digitChars) {
+		t.Errorf("Generate() = %q, %v, want lowercase password", password, err)
+	}
+
+	var templateErr *TemplateError
+	if _, err := g.GenerateFromTemplate("Ulld"); !errors.As(err, &templateErr) || templateErr.Position != 3 {
+		t.Errorf("GenerateFromTemplate() error = %v, want *TemplateError at position 3", err)
+	}
+
+	g.AmbiguousSet = RecoveryCodeAlphabet
+	if codes, err := g.GenerateRecoveryCodes(1, 1, 4); err == nil {
+		t.Errorf("GenerateRecoveryCodes() = %v, want error", codes)
+	}
+	g.AmbiguousSet = digitChars + lowerChars + upperChars + symbolChars
+	if password, err := g.Generate(Options{}); err == nil {
+		t.Errorf("Generate() = %q, want error", password)
+	}
+}
+
+func TestGenerateCharacterSets(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   Options
+		forbidden string
+	}{
+		{"Custom symbols", Options{MinLength: 16, MaxLength: 24, UseSymbols: true, MinSymbols: 3, UseLower: true, SymbolSet: "-_."}, "!@#$%^&*"},
+		{"Allowed characters", Options{MinLength: 12, MaxLength: 12, UseDigits: true, AllowedChars: "0123abc"}, "456789defXYZ!"},
+		{"Disallowed characters", Options{MinLength: 32, MaxLength: 32, UseSymbols: true, DisallowedChars: "\"'`\\"}, "\"'`\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				password, err := Generate(tt.options)
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if strings.ContainsAny(password, tt.forbidden) {
+					t.Fatalf("Generate() = %q, contains one of %q", password, tt.forbidden)
+				}
+				if result := Audit(password, tt.options); result.Err != nil {
+					t.Fatalf("Audit(%q) error = %v", password, result.Err)
+				}
+			}
+		})
+	}
+
+	if password, err := Generate(Options{UseUpper: true, AllowedChars: lowerChars}); err == nil {
+		t.Errorf("Generate() = %q, want error", password)
+	}
+}
+
+func TestGeneratorRandDeterministic(t *testing.T) {
+	generators := map[string]func(g *Generator) (string, error){
+		"Generate": func(g *Generator) (string, error) {
+			return g.Generate(Options{MinLength: 12, MaxLength: 20, UseDigits: true, UseSymbols: true})
+		},
+		"GenerateFromTemplate": func(g *Generator) (string, error) {
+			return g.GenerateFromTemplate(`Ulll\-dddx`)
+		},
+		"GenerateRecoveryCodes": func(g *Generator) (string, error) {
+			codes, err := g.GenerateRecoveryCodes(8, 3, 4)
+			return strings.Join(codes, " "), err
+		},
+		"GenerateToken": func(g *Generator) (string, error) {
+			return g.GenerateToken("tok", 32)
+		},
+		"GeneratePassphrase": func(g *Generator) (string, error) {
+			passphrase, _, err := g.GeneratePassphrase(PassphraseOptions{AppendDigit: true})
+			return passphrase, err
+		},
+	}
+
+	for name, generate := range generators {
+		t.Run(name, func(t *testing.T) {
+			seeded := func(seed byte) *Generator {
+				return &Generator{Rand: rand.NewChaCha8([32]byte{seed})}
+			}
+			first, err := generate(seeded(1))
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			second, err := generate(seeded(1))
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if first != second {
+				t.Errorf("%s() with the same seed = %q and %q", name, first, second)
+			}
+			if other, _ := generate(seeded(2)); other == first {
+				t.Errorf("%s() with different seeds = %q both times", name, first)
+			}
+		})
+	}
+}
+
+// failingReader returns n bytes of zeros and then err.
+type failingReader struct {
+	n   int
+	err error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, r.err
+	}
+	n := min(len(p), r.n)
+	clear(p[:n])
+	r.n -= n
+	return n, nil
+}
+
+func TestGeneratorRandErrors(t *testing.T) {
+	errBroken := errors.New("entropy source broken")
+	for _, n := range []int{0, 3, 17} {
+		sources := []*Generator{
+			{Rand: &failingReader{n: n, err: errBroken}},
+			{Rand: &failingReader{n: n, err: io.EOF}},
+		}
+		for _, g := range sources {
+			if password, err := g.Generate(Options{MinLength: 16}); err == nil {
+				t.Errorf("Generate() after %d bytes = %q, want error", n, password)
+			}
+			if password, err := g.GenerateFromTemplate("xxxxxxxxxxxxxxxxxxxx"); err == nil {
+				t.Errorf("GenerateFromTemplate() after %d bytes = %q, want error", n, password)
+			}
+			if token, err := g.GenerateToken("tok", 32); err == nil {
+				t.Errorf("GenerateToken() after %d bytes = %q, want error", n, token)
+			}
+		}
+	}
+
+	g := Generator{Rand: &failingReader{err: errBroken}}
+	if _, err := g.Generate(Options{}); !errors.Is(err, errBroken) {
+		t.Errorf("Generate() error = %v, want %v", err, errBroken)
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	options := Options{
+		MinLength:  12,
+		MaxLength:  20,
+		UseDigits:  true,
+		UseLower:   true,
+		UseUpper:   true,
+		UseSymbols: true,
+	}
+
+	for i := 0; i < b.N; i++ {
+		_, _ = Generate(options)
+	}
+}
@@ -1 +1,11 @@
 module github.com/andreimerlescu/go-passwd
+
+go 1.27.1
+
+require (
+	golang.org/x/crypto v0.57.0
+	golang.org/x/term v0.46.0
+	golang.org/x/text v0.42.0
+)
+
+require golang.org/x/sys v0.48.0 // indirect
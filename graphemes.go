@@ -0,0 +1,189 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// graphemeBreak is the Grapheme_Cluster_Break property of a rune from UAX #29.
+type graphemeBreak uint8
+
+const (
+	gbOther graphemeBreak = iota
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbZWJ
+	gbRegionalIndicator
+	gbPrepend
+	gbSpacingMark
+	gbL // Hangul leading consonant
+	gbV // Hangul vowel
+	gbT // Hangul trailing consonant
+	gbLV
+	gbLVT
+)
+
+// skinToneModifiers are emoji modifiers, which UAX #29 treats as extending the emoji before them.
+var skinToneModifiers = &unicode.RangeTable{
+	R32: []unicode.Range32{{Lo: 0x1f3fb, Hi: 0x1f3ff, Stride: 1}},
+}
+
+// graphemeBreakOf derives the Grapheme_Cluster_Break property of r from the general categories
+// and properties in the unicode package. It does not distinguish the few letters UAX #29 lists as
+// spacing marks or prepends beyond the prepended concatenation marks.
+func graphemeBreakOf(r rune) graphemeBreak {
+	switch {
+	case r == '\r':
+		return gbCR
+	case r == '\n':
+		return gbLF
+	case r < 0x20 || r == 0x7f:
+		return gbControl
+	case r < utf8.RuneSelf:
+		return gbOther
+	case r == zeroWidthJoiner:
+		return gbZWJ
+	case isRegionalIndicator(r):
+		return gbRegionalIndicator
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return gbLV
+		}
+		return gbLVT
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return gbL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return gbV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return gbT
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Other_Grapheme_Extend, skinToneModifiers):
+		return gbExtend
+	case unicode.Is(unicode.Prepended_Concatenation_Mark, r):
+		return gbPrepend
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gbControl
+	case unicode.Is(unicode.Mc, r):
+		return gbSpacingMark
+	}
+	return gbOther
+}
+
+// pictographicRanges approximate the Extended_Pictographic property: the emoji blocks and the
+// older symbols that have emoji presentations.
+var pictographicRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00ae, Stride: 5}, // © ®
+		{Lo: 0x203c, Hi: 0x203c, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21a9, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+		{Lo: 0x23cf, Hi: 0x23cf, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23f3, Stride: 1},
+		{Lo: 0x23f8, Hi: 0x23fa, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25ab, Stride: 1},
+		{Lo: 0x25b6, Hi: 0x25b6, Stride: 1},
+		{Lo: 0x25c0, Hi: 0x25c0, Stride: 1},
+		{Lo: 0x25fb, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1}, // Miscellaneous Symbols and Dingbats
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b07, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2b50, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303d, Hi: 0x303d, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3299, Stride: 2},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1}, // Mahjong Tiles through Symbols and Pictographs Extended-A
+		{Lo: 0x1fc00, Hi: 0x1fffd, Stride: 1}, // Unassigned, reserved for emoji
+	},
+}
+
+// graphemeCount returns the number of extended grapheme clusters in s, the characters a user sees,
+// following the UAX #29 rules GB3 to GB13 (without the Indic conjunct rule GB9c).
+func graphemeCount(s string) int {
+	var (
+		count        int
+		prev         graphemeBreak
+		emoji        bool // The runes since the last break are an Extended_Pictographic and Extends
+		emojiZWJ     bool // And the previous rune is a ZWJ ending such a run
+		regionalRuns int  // Regional indicators in a row before the current rune
+	)
+	for i, r := range s {
+		gb := graphemeBreakOf(r)
+		pictographic := r >= utf8.RuneSelf && unicode.Is(pictographicRanges, r)
+		if i == 0 || graphemeBreaks(prev, gb, pictographic && emojiZWJ, regionalRuns%2 == 1) {
+			count++
+		}
+
+		switch {
+		case pictographic:
+			emoji, emojiZWJ = true, false
+		case emoji && gb == gbExtend:
+			emojiZWJ = false
+		case emoji && gb == gbZWJ:
+			emoji, emojiZWJ = false, true
+		default:
+			emoji, emojiZWJ = false, false
+		}
+		if gb == gbRegionalIndicator {
+			regionalRuns++
+		} else {
+			regionalRuns = 0
+		}
+		prev = gb
+	}
+	return count
+}
+
+// graphemeBreaks reports whether UAX #29 puts a cluster boundary between runes of the properties
+// prev and next. joinsEmoji is set when next is a pictograph after an emoji and a ZWJ (GB11), and
+// oddRegional when prev ends an odd run of regional indicators (GB12 and GB13).
+func graphemeBreaks(prev, next graphemeBreak, joinsEmoji, oddRegional bool) bool {
+	switch {
+	case prev == gbCR && next == gbLF: // GB3
+		return false
+	case prev == gbCR || prev == gbLF || prev == gbControl: // GB4
+		return true
+	case next == gbCR || next == gbLF || next == gbControl: // GB5
+		return true
+	case prev == gbL && (next == gbL || next == gbV || next == gbLV || next == gbLVT): // GB6
+		return false
+	case (prev == gbLV || prev == gbV) && (next == gbV || next == gbT): // GB7
+		return false
+	case (prev == gbLVT || prev == gbT) && next == gbT: // GB8
+		return false
+	case next == gbExtend || next == gbZWJ || next == gbSpacingMark: // GB9, GB9a
+		return false
+	case prev == gbPrepend: // GB9b
+		return false
+	case prev == gbZWJ && joinsEmoji: // GB11
+		return false
+	case prev == gbRegionalIndicator && next == gbRegionalIndicator && oddRegional: // GB12, GB13
+		return false
+	}
+	return true // GB999
+}
@@ -0,0 +1,124 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	familyEmoji   = "👨\u200d👩\u200d👧\u200d👦" // Four emoji joined by three ZWJs
+	flagEmoji     = "🇺🇸"
+	combiningE    = "e\u0301" // "é" as "e" and a combining acute accent
+	skinToneEmoji = "👍🏿"
+)
+
+func TestGraphemeCount(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{familyEmoji, 1},
+		{flagEmoji, 1},
+		{flagEmoji + flagEmoji + "🇫", 3}, // Regional indicators pair up from the start
+		{combiningE, 1},
+		{"e\u0301\u0323", 1}, // Two combining marks
+		{skinToneEmoji, 1},
+		{"❤\ufe0f", 1},       // Variation selector
+		{"1\ufe0f\u20e3", 1}, // Keycap
+		{"\r\n", 1},
+		{"\n\r", 2},
+		{"a\u200db", 2}, // A ZWJ only joins pictographs
+		{"🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", 1}, // Subdivision flag
+		{"\u1100\u1161\u11a8", 1}, // Hangul jamo L V T
+		{"한국", 2},
+		{"कि", 1},      // Devanagari consonant and spacing vowel sign
+		{"\u0600١", 1}, // Prepended concatenation mark
+		{"a\x00b", 3},
+	}
+	for _, tt := range tests {
+		if got := graphemeCount(tt.s); got != tt.want {
+			t.Errorf("graphemeCount(%+q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestAuditLengths(t *testing.T) {
+	tests := []struct {
+		pass                           string
+		bytes, runes, graphemes, chars int64
+	}{
+		{"password", 8, 8, 8, 8},
+		{"ab" + familyEmoji, 27, 9, 3, 3},
+		{"ab" + flagEmoji, 10, 4, 3, 3},
+		{"caf" + combiningE, 6, 5, 4, 5},
+	}
+	for _, tt := range tests {
+		result := Audit(tt.pass, Options{})
+		if result.LengthBytes != tt.bytes || result.LengthRunes != tt.runes || result.LengthGraphemes != tt.graphemes || result.Length != tt.chars {
+			t.Errorf("Audit(%+q) lengths = %d bytes, %d runes, %d graphemes, %d characters, want %d, %d, %d, %d", tt.pass,
+				result.LengthBytes, result.LengthRunes, result.LengthGraphemes, result.Length, tt.bytes, tt.runes, tt.graphemes, tt.chars)
+		}
+		if graphemes := Audit(tt.pass, Options{CountGraphemes: true}); graphemes.Length != tt.graphemes {
+			t.Errorf("Audit(%+q) with CountGraphemes Length = %d, want %d", tt.pass, graphemes.Length, tt.graphemes)
+		}
+	}
+}
+
+// TestAuditCountGraphemesBoundary puts each password exactly at MinLength in one counting mode.
+func TestAuditCountGraphemesBoundary(t *testing.T) {
+	tests := []struct {
+		name       string
+		pass       string
+		minLength  uint
+		characters bool // Passes when counting characters
+		graphemes  bool // Passes when counting grapheme clusters
+	}{
+		{"Family emoji is one of each", "Tr0ub4d" + familyEmoji, 8, true, true},
+		{"Family emoji is not seven", "Tr0ub4d" + familyEmoji, 9, false, false},
+		{"Flag is one of each", "Tr0ub4d" + flagEmoji, 8, true, true},
+		{"Skin tone is one of each", "Tr0ub4d" + skinToneEmoji, 8, true, true},
+		{"Combining accent at the character minimum", "Tr0ub4d" + combiningE, 9, true, false},
+		{"Combining accent at the grapheme minimum", "Tr0ub4d" + combiningE, 8, true, true},
+		{"Hangul jamo", "Tr0ub4d\u1100\u1161\u11a8", 10, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, mode := range []struct {
+				countGraphemes, want bool
+			}{{false, tt.characters}, {true, tt.graphemes}} {
+				result := Audit(tt.pass, Options{MinLength: tt.minLength, CountGraphemes: mode.countGraphemes})
+				if got := !errors.Is(result.Err, ErrTooShort); got != mode.want {
+					t.Errorf("Audit(%+q) CountGraphemes %v error = %v, want passing MinLength %v", tt.pass, mode.countGraphemes, result.Err, mode.want)
+				}
+			}
+		})
+	}
+
+	// MaxLength counts the same way.
+	pass := strings.Repeat(combiningE, 4)
+	if result := Audit(pass, Options{MaxLength: 4, CountGraphemes: true}); result.Err != nil {
+		t.Errorf("Audit(%+q) with CountGraphemes error = %v, want four characters to fit MaxLength 4", pass, result.Err)
+	}
+	if result := Audit(pass, Options{MaxLength: 4}); !errors.Is(result.Err, ErrTooLong) {
+		t.Errorf("Audit(%+q) error = %v, want ErrTooLong counting eight runes", pass, result.Err)
+	}
+}
@@ -0,0 +1,174 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// MaxStrengthRequestBytes is the largest request body StrengthHandler reads.
+const MaxStrengthRequestBytes = 8 << 10
+
+// strengthRequest is the JSON body StrengthHandler accepts.
+type strengthRequest struct {
+	Password   Secret   `json:"password"`
+	UserInputs []string `json:"user_inputs,omitempty"`
+}
+
+// strengthResponse is the JSON body StrengthHandler and RequireStrongPassword write.
+type strengthResponse struct {
+	Result      Result   `json:"result"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// StrengthHandler serves POST requests carrying {"password": "...", "user_inputs": [...]} and
+// responds with {"result": ..., "suggestions": [...]}, where result is the serialized Result of
+// auditing the password against opts with user_inputs added to UserInputs. Responses are never
+// cached and never contain the password: violations are translated with Translate for the
+// Accept-Language of the request, and pattern tokens are left out. Bodies over
+// MaxStrengthRequestBytes are rejected with 413, and Options that fail Validate answer 500.
+func StrengthHandler(opts Options) http.Handler {
+	optsErr := opts.Validate()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if optsErr != nil {
+			writeJSONError(w, http.StatusInternalServerError, "password policy is misconfigured")
+			return
+		}
+
+		var req strengthRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxStrengthRequestBytes)).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			writeJSONError(w, http.StatusBadRequest, "malformed request body")
+			return
+		}
+
+		audited := opts
+		if len(req.UserInputs) > 0 {
+			audited.UserInputs = slices.Concat(opts.UserInputs, req.UserInputs)
+		}
+		defer req.Password.Zero()
+		writeStrength(w, r, http.StatusOK, AuditSecret(req.Password, audited))
+	})
+}
+
+// RequireStrongPassword calls next only when the password extract pulls from the request is
+// Strong under opts. Weak passwords are answered with 422 and the body StrengthHandler writes,
+// extract errors with 400, and Options that fail Validate with 500. extract must leave the
+// request readable for next, for example by using r.FormValue, which keeps the parsed form on the
+// request.
+func RequireStrongPassword(opts Options, next http.Handler, extract func(*http.Request) (string, error)) http.Handler {
+	optsErr := opts.Validate()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if optsErr != nil {
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSONError(w, http.StatusInternalServerError, "password policy is misconfigured")
+			return
+		}
+		password, err := extract(r)
+		if err != nil {
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSONError(w, http.StatusBadRequest, "password missing from request")
+			return
+		}
+		if audit := Audit(password, opts); !audit.Strong {
+			w.Header().Set("Cache-Control", "no-store")
+			writeStrength(w, r, http.StatusUnprocessableEntity, audit)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeStrength writes audit and its suggestions without any part of the password.
+func writeStrength(w http.ResponseWriter, r *http.Request, status int, audit Result) {
+	lang := acceptLanguage(r)
+	// Suggestions built from the pattern tokens would echo the password too.
+	withoutPatterns := audit
+	withoutPatterns.Patterns = nil
+
+	writeJSON(w, status, strengthResponse{Result: audit.Redacted(lang), Suggestions: withoutPatterns.feedback(lang, true)})
+}
+
+// Redacted returns a copy of audit that is safe to show or log: the violations and warnings are
+// translated into lang like Translate, with messages that leave out the password's characters,
+// and the patterns and lookalike letters keep their positions but not the runes they matched.
+func (audit Result) Redacted(lang string) Result {
+	redacted := audit
+	redacted.Violations = make([]error, len(audit.Violations))
+	for i, err := range audit.Violations {
+		redacted.Violations[i] = errors.New(translate(err, lang, true))
+	}
+	if audit.Err != nil {
+		redacted.Err = errors.New(translate(audit.Err, lang, true))
+	}
+	redacted.Warnings = make([]error, len(audit.Warnings))
+	for i, err := range audit.Warnings {
+		redacted.Warnings[i] = errors.New(translate(err, lang, true))
+	}
+	redacted.ConfusableRunes = make([]ConfusableRune, len(audit.ConfusableRunes))
+	for i, c := range audit.ConfusableRunes {
+		redacted.ConfusableRunes[i] = ConfusableRune{LooksLike: c.LooksLike, Index: c.Index}
+	}
+	// Pattern tokens are pieces of the password and can be all of it.
+	redacted.Patterns = make([]Match, len(audit.Patterns))
+	for i, p := range audit.Patterns {
+		redacted.Patterns[i] = Match{Kind: p.Kind, Start: p.Start, End: p.End, Penalty: p.Penalty}
+	}
+	return redacted
+}
+
+// acceptLanguage returns the first language tag of the Accept-Language header, or
+// DefaultLanguage.
+func acceptLanguage(r *http.Request) string {
+	header := r.Header.Get("Accept-Language")
+	for i, c := range header {
+		if c == ',' || c == ';' || c == ' ' {
+			header = header[:i]
+			break
+		}
+	}
+	if header == "" || header == "*" {
+		return DefaultLanguage
+	}
+	return header
+}
+
+// writeJSONError writes {"error": msg} with status.
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// writeJSON writes v as the JSON body of a response with status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,326 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// strengthBody is the decoded response of StrengthHandler.
+type strengthBody struct {
+	Result struct {
+		Strong     bool     `json:"strong"`
+		Score      int      `json:"score"`
+		Violations []string `json:"violations"`
+		Patterns   []struct {
+			Kind  string `json:"kind"`
+			Token string `json:"token"`
+		} `json:"patterns"`
+	} `json:"result"`
+	Suggestions []string `json:"suggestions"`
+	Error       string   `json:"error"`
+}
+
+func TestStrengthHandler(t *testing.T) {
+	handler := StrengthHandler(Options{MinLength: 12, UseDigits: true, KeyboardWalkLength: 4, RejectKeyboardWalks: true})
+
+	tests := []struct {
+		name        string
+		method      string
+		body        string
+		lang        string
+		status      int
+		strong      bool
+		violations  []string
+		suggestions []string
+		error       string
+	}{
+		{
+			name:        "Strong password",
+			method:      http.MethodPost,
+			body:        `{"password": "Xq7mB2vLp9!w4Z"}`,
+			status:      http.StatusOK,
+			strong:      true,
+			suggestions: []string{},
+		},
+		{
+			name:        "Weak password",
+			method:      http.MethodPost,
+			body:        `{"password": "short"}`,
+			status:      http.StatusOK,
+			violations:  []string{"Password must be at least 12 characters long.", "Password must include a digit."},
+			suggestions: []string{"Make it at least 7 characters longer.", "Add a digit."},
+		},
+		{
+			name:        "Translated",
+			method:      http.MethodPost,
+			body:        `{"password": "short"}`,
+			lang:        "es-ES,es;q=0.9,en;q=0.8",
+			status:      http.StatusOK,
+			violations:  []string{"La contraseña debe tener al menos 12 caracteres.", "La contraseña debe incluir un dígito."},
+			suggestions: []string{"Añade al menos 7 caracteres más.", "Añade un dígito."},
+		},
+		{
+			name:        "User inputs",
+			method:      http.MethodPost,
+			body:        `{"password": "andreimerlescu42", "user_inputs": ["merlescu"]}`,
+			status:      http.StatusOK,
+			violations:  []string{"Password must not contain your name, username, or email address."},
+			suggestions: []string{},
+		},
+		{name: "Malformed JSON", method: http.MethodPost, body: `{"password": `, status: http.StatusBadRequest, error: "malformed request body"},
+		{name: "Wrong type", method: http.MethodPost, body: `{"password": 42}`, status: http.StatusBadRequest, error: "malformed request body"},
+		{
+			name:   "Oversized body",
+			method: http.MethodPost,
+			body:   `{"password": "` + strings.Repeat("a", MaxStrengthRequestBytes) + `"}`,
+			status: http.StatusRequestEntityTooLarge,
+			error:  "request body too large",
+		},
+		{name: "Wrong method", method: http.MethodGet, status: http.StatusMethodNotAllowed, error: "method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/password/strength", strings.NewReader(tt.body))
+			if tt.lang != "" {
+				r.Header.Set("Accept-Language", tt.lang)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			var body strengthBody
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %v: %s", err, w.Body)
+			}
+			if body.Error != tt.error {
+				t.Errorf("error = %q, want %q", body.Error, tt.error)
+			}
+			if tt.error != "" {
+				return
+			}
+			if body.Result.Strong != tt.strong || body.Result.Score == 0 {
+				t.Errorf("result = %+v, want strong %v and a score", body.Result, tt.strong)
+			}
+			if strings.Join(body.Result.Violations, "|") != strings.Join(tt.violations, "|") {
+				t.Errorf("violations = %q, want %q", body.Result.Violations, tt.violations)
+			}
+			if body.Suggestions == nil || strings.Join(body.Suggestions, "|") != strings.Join(tt.suggestions, "|") {
+				t.Errorf("suggestions = %q, want %q", body.Suggestions, tt.suggestions)
+			}
+		})
+	}
+}
+
+func TestStrengthHandlerNeverEchoesPassword(t *testing.T) {
+	handler := StrengthHandler(Options{
+		MinLength:           12,
+		MaxSequenceLength:   3,
+		RejectSequences:     true,
+		KeyboardWalkLength:  4,
+		RejectKeyboardWalks: true,
+		MaxRepeatRun:        2,
+		UserInputs:          []string{"asdf"},
+	})
+	for _, password := range []string{"asdfghjkl;", "mnopqrst", "zzzzzzzz", "hunter2hunter2"} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"password": "`+password+`"}`))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if body := w.Body.String(); strings.Contains(body, password) || strings.Contains(body, password[:4]) {
+			t.Errorf("response for %q contains the password: %s", password, body)
+		}
+	}
+}
+
+func TestStrengthHandlerRedactsConfusablesAndWarnings(t *testing.T) {
+	// The Cyrillic р and а of the password look like p and a.
+	const password = "раssword-correct-horse"
+	handler := StrengthHandler(Options{
+		BreachChecker: BreachCheckerFunc(func(_ context.Context, password string) (bool, int, error) {
+			return false, 0, fmt.Errorf("lookup of %s timed out", password)
+		}),
+		BreachCheckMode: BreachAdvisory,
+		CustomRules: []Rule{NewRule("advice", func(password string) error {
+			return Warn(fmt.Errorf("%s is a phrase", password))
+		})},
+	})
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"password": "`+password+`"}`))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	body := w.Body.String()
+	if strings.Contains(body, password) || strings.ContainsAny(body, "ра") {
+		t.Errorf("response contains the password or its lookalike letters: %s", body)
+	}
+	var got struct {
+		Result struct {
+			ConfusableRunes []ConfusableRune `json:"confusable_runes"`
+			Warnings        []string         `json:"warnings"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding %s: %v", body, err)
+	}
+	wantRunes := []ConfusableRune{{LooksLike: "p", Index: 0}, {LooksLike: "a", Index: 1}}
+	if len(got.Result.ConfusableRunes) != len(wantRunes) {
+		t.Fatalf("confusable_runes = %+v, want %+v", got.Result.ConfusableRunes, wantRunes)
+	}
+	for i, c := range got.Result.ConfusableRunes {
+		if c != wantRunes[i] {
+			t.Errorf("confusable_runes[%d] = %+v, want %+v", i, c, wantRunes[i])
+		}
+	}
+	wantWarnings := []string{"Password could not be checked against known data breaches. Try again later.", "Password does not pass the advice check."}
+	if len(got.Result.Warnings) != len(wantWarnings) {
+		t.Fatalf("warnings = %q, want %q", got.Result.Warnings, wantWarnings)
+	}
+	for i, msg := range got.Result.Warnings {
+		if msg != wantWarnings[i] {
+			t.Errorf("warnings[%d] = %q, want %q", i, msg, wantWarnings[i])
+		}
+	}
+}
+
+func TestStrengthHandlerRedactsCharacterViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		runes     string // Characters of the password the response must not contain
+		options   Options
+		lang      string
+		violation string
+	}{
+		{
+			name:      "Confusable",
+			password:  "pаssword9!", // Cyrillic а
+			runes:     "а",
+			options:   Options{RejectConfusables: true},
+			violation: "Password must not use a character that looks like a.",
+		},
+		{
+			name:      "Confusable in Spanish",
+			password:  "pаssword9!",
+			runes:     "а",
+			options:   Options{RejectConfusables: true},
+			lang:      "es",
+			violation: "La contraseña no debe usar un carácter que parece a.",
+		},
+		{
+			name:      "Disallowed character",
+			password:  "abc§def",
+			runes:     "§",
+			options:   Options{DisallowedChars: "§"},
+			violation: "Password contains a character that is not allowed.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"password": "`+tt.password+`"}`))
+			if tt.lang != "" {
+				r.Header.Set("Accept-Language", tt.lang)
+			}
+			w := httptest.NewRecorder()
+			StrengthHandler(tt.options).ServeHTTP(w, r)
+
+			body := w.Body.String()
+			if strings.Contains(body, tt.password) || strings.ContainsAny(body, tt.runes) {
+				t.Errorf("response contains the password's characters: %s", body)
+			}
+			var got struct {
+				Result struct {
+					Violations []string `json:"violations"`
+				} `json:"result"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding %s: %v", body, err)
+			}
+			if len(got.Result.Violations) != 1 || got.Result.Violations[0] != tt.violation {
+				t.Errorf("violations = %q, want [%q]", got.Result.Violations, tt.violation)
+			}
+		})
+	}
+}
+
+func TestStrengthHandlerInvalidOptions(t *testing.T) {
+	w := httptest.NewRecorder()
+	StrengthHandler(Options{MinLength: 9, MaxLength: 2}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"password": "x"}`)))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestRequireStrongPassword(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(r.FormValue("username")))
+	})
+	extract := func(r *http.Request) (string, error) {
+		password := r.FormValue("password")
+		if password == "" {
+			return "", errors.New("no password")
+		}
+		return password, nil
+	}
+	handler := RequireStrongPassword(Options{MinLength: 12, UseDigits: true}, next, extract)
+
+	tests := []struct {
+		name   string
+		form   url.Values
+		status int
+		body   string
+	}{
+		{"Strong password", url.Values{"username": {"andrei"}, "password": {"Xq7mB2vLp9!w4Z"}}, http.StatusCreated, "andrei"},
+		{"Weak password", url.Values{"username": {"andrei"}, "password": {"short"}}, http.StatusUnprocessableEntity, "Password must be at least 12 characters long."},
+		{"Missing password", url.Values{"username": {"andrei"}}, http.StatusBadRequest, "password missing from request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("body = %s, want it to contain %q", w.Body, tt.body)
+			}
+			if tt.form.Get("password") != "" && tt.status != http.StatusCreated && strings.Contains(w.Body.String(), tt.form.Get("password")+`"`) {
+				t.Errorf("body = %s, echoes the password", w.Body)
+			}
+		})
+	}
+}
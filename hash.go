@@ -0,0 +1,129 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is the bcrypt work factor used by Hash. Each step doubles the time to hash;
+// 12 takes a few hundred milliseconds on current server hardware.
+const DefaultBcryptCost = 12
+
+// MaxBcryptBytes is the longest password bcrypt hashes; longer inputs would be truncated.
+const MaxBcryptBytes = 72
+
+// Hash returns the bcrypt hash of password at DefaultBcryptCost.
+func Hash(password string) (string, error) {
+	return HashWithCost(password, DefaultBcryptCost)
+}
+
+// HashWithCost returns the bcrypt hash of password at the given cost, between bcrypt.MinCost and
+// bcrypt.MaxCost. Passwords longer than MaxBcryptBytes fail with ErrBcryptTooLong instead of being
+// truncated.
+func HashWithCost(password string, cost int) (string, error) {
+	if len(password) > MaxBcryptBytes {
+		return "", fmt.Errorf("%w: %d bytes", ErrBcryptTooLong, len(password))
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return "", fmt.Errorf("bcrypt cost %d is outside %d to %d", cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	p := []byte(password)
+	defer wipe(p)
+	hash, err := bcrypt.GenerateFromPassword(p, cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify compares password with a bcrypt hash in constant time. It returns nil on a match,
+// ErrPasswordMismatch when the password is wrong, and another error when encoded is not a valid
+// bcrypt hash.
+func Verify(password, encoded string) error {
+	if len(password) > MaxBcryptBytes {
+		return fmt.Errorf("%w: %d bytes", ErrBcryptTooLong, len(password))
+	}
+	p := []byte(password)
+	defer wipe(p)
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), p)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
+}
+
+// Hash schemes recognised by VerifyAny.
+const (
+	SchemeBcrypt      = "bcrypt"
+	SchemeArgon2id    = "argon2id"
+	SchemeSHA512Crypt = "sha512-crypt"
+	SchemePBKDF2      = "pbkdf2-sha256"
+	SchemeScrypt      = "scrypt"
+	SchemeDjango      = "django_pbkdf2_sha256"
+)
+
+// VerifyAny detects the scheme of encoded from its prefix, verifies password with it, and returns
+// the scheme so callers can rehash legacy formats. Supported prefixes are "$2a$", "$2b$", and
+// "$2y$" for bcrypt, "$argon2id$", "$6$" for sha512-crypt, "$pbkdf2-sha256$", "$scrypt$", and
+// Django's "pbkdf2_sha256$"; anything else fails with ErrUnknownHashScheme. The error is nil on a match and
+// ErrPasswordMismatch for a wrong password.
+func VerifyAny(password, encoded string) (string, error) {
+	scheme := hashScheme(encoded)
+	switch scheme {
+	case SchemeBcrypt:
+		err := Verify(password, encoded)
+		if err != nil && !errors.Is(err, ErrPasswordMismatch) && !errors.Is(err, ErrBcryptTooLong) {
+			err = fmt.Errorf("%w: %v", ErrMalformedHash, err)
+		}
+		return scheme, err
+	case SchemeArgon2id:
+		return scheme, VerifyArgon2id(password, encoded)
+	case SchemeSHA512Crypt:
+		return scheme, VerifySHA512Crypt(password, encoded)
+	case SchemePBKDF2:
+		return scheme, VerifyPBKDF2(password, encoded)
+	case SchemeScrypt:
+		return scheme, VerifyScrypt(password, encoded)
+	case SchemeDjango:
+		return scheme, VerifyDjango(password, encoded)
+	}
+	return "", ErrUnknownHashScheme
+}
+
+// hashScheme returns the scheme VerifyAny detects from the prefix of encoded, or "" for none.
+func hashScheme(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return SchemeBcrypt
+	case strings.HasPrefix(encoded, argon2Prefix):
+		return SchemeArgon2id
+	case strings.HasPrefix(encoded, sha512CryptPrefix):
+		return SchemeSHA512Crypt
+	case strings.HasPrefix(encoded, pbkdf2Prefix):
+		return SchemePBKDF2
+	case strings.HasPrefix(encoded, scryptPrefix):
+		return SchemeScrypt
+	case strings.HasPrefix(encoded, djangoPrefix):
+		return SchemeDjango
+	}
+	return ""
+}
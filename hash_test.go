@@ -0,0 +1,147 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashWithCostRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{"ASCII", "correct horse battery staple"},
+		{"Unicode", "Pässwørd-密码"},
+		{"Empty", ""},
+		{"Exactly 72 bytes", strings.Repeat("x", MaxBcryptBytes)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := HashWithCost(tt.password, bcrypt.MinCost)
+			if err != nil {
+				t.Fatalf("HashWithCost() error = %v", err)
+			}
+			if err := Verify(tt.password, hash); err != nil {
+				t.Errorf("Verify() error = %v, want nil", err)
+			}
+			wrong := "?" + tt.password
+			if len(wrong) > MaxBcryptBytes {
+				wrong = wrong[:MaxBcryptBytes]
+			}
+			if err := Verify(wrong, hash); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("Verify() with wrong password error = %v, want %v", err, ErrPasswordMismatch)
+			}
+		})
+	}
+}
+
+func TestHashDefaultCost(t *testing.T) {
+	hash, err := Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if cost, err := bcrypt.Cost([]byte(hash)); err != nil || cost != DefaultBcryptCost {
+		t.Errorf("bcrypt.Cost() = %d, %v, want %d", cost, err, DefaultBcryptCost)
+	}
+}
+
+func TestHashRejectsLongPasswords(t *testing.T) {
+	long := strings.Repeat("x", MaxBcryptBytes+1)
+	if _, err := HashWithCost(long, bcrypt.MinCost); !errors.Is(err, ErrBcryptTooLong) {
+		t.Errorf("HashWithCost() error = %v, want %v", err, ErrBcryptTooLong)
+	}
+
+	hash, err := HashWithCost(long[:MaxBcryptBytes], bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("HashWithCost() error = %v", err)
+	}
+	if err := Verify(long, hash); !errors.Is(err, ErrBcryptTooLong) {
+		t.Errorf("Verify() error = %v, want %v rather than a truncated match", err, ErrBcryptTooLong)
+	}
+}
+
+func TestHashWithCostInvalidCost(t *testing.T) {
+	for _, cost := range []int{bcrypt.MinCost - 1, bcrypt.MaxCost + 1} {
+		if _, err := HashWithCost("password", cost); err == nil {
+			t.Errorf("HashWithCost(cost %d) error = nil, want error", cost)
+		}
+	}
+}
+
+func TestVerifyMalformedHash(t *testing.T) {
+	err := Verify("password", "not a bcrypt hash")
+	if err == nil || errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Verify() error = %v, want a malformed hash error", err)
+	}
+}
+
+func BenchmarkHash(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = Hash("correct horse battery staple")
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	hash, err := Hash("correct horse battery staple")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Verify("correct horse battery staple", hash)
+	}
+}
+
+func TestVerifyAny(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		encoded    string
+		wantScheme string
+		wantErr    error
+	}{
+		{"bcrypt 2a", "U*U", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", SchemeBcrypt, nil},
+		{"bcrypt 2b", "U*U", "$2b$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", SchemeBcrypt, nil},
+		{"bcrypt wrong password", "U*V", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", SchemeBcrypt, ErrPasswordMismatch},
+		{"bcrypt corrupted", "U*U", "$2a$05$CCCCCCCC", SchemeBcrypt, ErrMalformedHash},
+		{"argon2id", argon2ReferenceVectors[0].password, argon2ReferenceVectors[0].encoded, SchemeArgon2id, nil},
+		{"argon2id corrupted", "password", "$argon2id$v=19$m=65536$c29tZXNhbHQ$CTFh", SchemeArgon2id, ErrMalformedHash},
+		{"sha512-crypt", sha512CryptVectors[0].password, sha512CryptVectors[0].encoded, SchemeSHA512Crypt, nil},
+		{"sha512-crypt wrong password", "Hello world", sha512CryptVectors[0].encoded, SchemeSHA512Crypt, ErrPasswordMismatch},
+		{"sha512-crypt corrupted", "Hello world!", "$6$saltstring$svn8", SchemeSHA512Crypt, ErrMalformedHash},
+		{"MD5 crypt", "password", "$1$saltsalt$qjXMvbEw8oaL.CzflDugX/", "", ErrUnknownHashScheme},
+		{"Plain text", "password", "password", "", ErrUnknownHashScheme},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, err := VerifyAny(tt.password, tt.encoded)
+			if scheme != tt.wantScheme {
+				t.Errorf("VerifyAny() scheme = %q, want %q", scheme, tt.wantScheme)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyAny() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hasher hashes new passwords with a preferred scheme and upgrades stored hashes made with a
+// legacy scheme or weaker parameters as users log in. The zero value hashes with Argon2id at
+// DefaultArgon2Params and accepts only Argon2id hashes.
+type Hasher struct {
+	Scheme     string       // Scheme of new hashes: SchemeArgon2id when empty, or SchemeBcrypt, SchemePBKDF2, or SchemeScrypt
+	BcryptCost int          // DefaultBcryptCost when zero
+	Argon2     Argon2Params // DefaultArgon2Params when zero
+	PBKDF2     PBKDF2Params // DefaultPBKDF2Params when zero
+	Scrypt     ScryptParams // DefaultScryptParams when zero
+	// Legacy lists the other schemes stored hashes may use, such as SchemeBcrypt or
+	// SchemeSHA512Crypt. They verify and are upgraded; hashes of a scheme not listed fail with
+	// ErrUnknownHashScheme.
+	Legacy []string
+	// Pepper, when set, peppers new hashes with its active key. Stored hashes without a pepper or
+	// with an inactive key still verify and are upgraded.
+	Pepper *Pepper
+	// OnUpgradeError, when set, is called when rehashing or storing an upgraded hash fails. The
+	// login still succeeds and the old hash is upgraded at the next one.
+	OnUpgradeError func(error)
+}
+
+// scheme returns the preferred scheme.
+func (h *Hasher) scheme() string {
+	if h.Scheme == "" {
+		return SchemeArgon2id
+	}
+	return h.Scheme
+}
+
+// Hash returns a new hash of password in the preferred scheme, peppered when Pepper is set.
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.Pepper != nil {
+		return h.Pepper.Hash(password, h.hashPlain)
+	}
+	return h.hashPlain(password)
+}
+
+// hashPlain hashes password in the preferred scheme without a pepper.
+func (h *Hasher) hashPlain(password string) (string, error) {
+	switch scheme := h.scheme(); scheme {
+	case SchemeArgon2id:
+		return HashArgon2id(password, h.argon2Params())
+	case SchemeBcrypt:
+		return HashWithCost(password, h.bcryptCost())
+	case SchemePBKDF2:
+		return HashPBKDF2(password, h.pbkdf2Params())
+	case SchemeScrypt:
+		return HashScrypt(password, h.scryptParams())
+	default:
+		return "", fmt.Errorf("%w: %q cannot be the preferred scheme", ErrUnknownHashScheme, scheme)
+	}
+}
+
+// Verify checks password against encoded, a hash in the preferred scheme or one of Legacy,
+// optionally peppered. It returns nil on a match and ErrPasswordMismatch for a wrong password.
+func (h *Hasher) Verify(password, encoded string) error {
+	inner := encoded
+	if strings.HasPrefix(encoded, pepperPrefix) {
+		if h.Pepper == nil {
+			return fmt.Errorf("%w: the hash is peppered and the Hasher has no Pepper", ErrUnknownPepper)
+		}
+		var err error
+		if _, inner, err = splitPepperHash(encoded); err != nil {
+			return err
+		}
+	}
+	if scheme := hashScheme(inner); scheme == "" || scheme != h.scheme() && !slices.Contains(h.Legacy, scheme) {
+		return fmt.Errorf("%w: %q is not accepted", ErrUnknownHashScheme, scheme)
+	}
+	if inner != encoded {
+		return h.Pepper.Verify(password, encoded)
+	}
+	_, err := VerifyAny(password, encoded)
+	return err
+}
+
+// NeedsUpgrade reports whether a stored hash that verifies should be replaced by Hash: it uses a
+// scheme other than the preferred one, weaker parameters than the Hasher's, or not the active
+// pepper key.
+func (h *Hasher) NeedsUpgrade(encoded string) bool {
+	if h.Pepper != nil {
+		id, inner, err := splitPepperHash(encoded)
+		if err != nil || id != h.Pepper.Active() {
+			return true
+		}
+		encoded = inner
+	}
+	if hashScheme(encoded) != h.scheme() {
+		return true
+	}
+	switch h.scheme() {
+	case SchemeArgon2id:
+		p, _, key, err := decodeArgon2id(encoded)
+		want := h.argon2Params()
+		return err != nil || p.Memory < want.Memory || p.Iterations < want.Iterations || len(key) < int(want.KeyLength)
+	case SchemeBcrypt:
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost < h.bcryptCost()
+	case SchemePBKDF2:
+		params, _, key, err := splitPHC(encoded, pbkdf2Prefix)
+		if err != nil {
+			return true
+		}
+		iterations, err := phcInt(params, "i", 1<<31-1)
+		want := h.pbkdf2Params()
+		return err != nil || iterations < want.Iterations || len(key) < want.KeyLength
+	case SchemeScrypt:
+		params, _, key, err := splitPHC(encoded, scryptPrefix)
+		if err != nil {
+			return true
+		}
+		ln, lnErr := phcInt(params, "ln", 62)
+		r, rErr := phcInt(params, "r", 1<<30)
+		want := h.scryptParams()
+		return lnErr != nil || rErr != nil || 1<<ln < want.N || r < want.R || len(key) < want.KeyLength
+	}
+	return false
+}
+
+// VerifyAndUpgrade checks password against encoded like Verify and, when it matches and
+// NeedsUpgrade reports encoded as outdated, hashes password again and passes the new hash to
+// store. It reports whether the password matched; a wrong password is false with a nil error.
+// Errors from rehashing or store go to OnUpgradeError and never fail the login.
+func (h *Hasher) VerifyAndUpgrade(password, encoded string, store func(newEncoded string) error) (bool, error) {
+	if err := h.Verify(password, encoded); err != nil {
+		if errors.Is(err, ErrPasswordMismatch) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !h.NeedsUpgrade(encoded) {
+		return true, nil
+	}
+	upgraded, err := h.Hash(password)
+	if err == nil {
+		err = store(upgraded)
+	}
+	if err != nil && h.OnUpgradeError != nil {
+		h.OnUpgradeError(fmt.Errorf("upgrading password hash: %w", err))
+	}
+	return true, nil
+}
+
+func (h *Hasher) bcryptCost() int {
+	if h.BcryptCost == 0 {
+		return DefaultBcryptCost
+	}
+	return h.BcryptCost
+}
+
+func (h *Hasher) argon2Params() Argon2Params {
+	if h.Argon2 == (Argon2Params{}) {
+		return DefaultArgon2Params
+	}
+	return h.Argon2
+}
+
+func (h *Hasher) pbkdf2Params() PBKDF2Params {
+	if h.PBKDF2 == (PBKDF2Params{}) {
+		return DefaultPBKDF2Params
+	}
+	return h.PBKDF2
+}
+
+func (h *Hasher) scryptParams() ScryptParams {
+	if h.Scrypt == (ScryptParams{}) {
+		return DefaultScryptParams
+	}
+	return h.Scrypt
+}
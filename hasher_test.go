@@ -0,0 +1,237 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHasherHashVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		hasher Hasher
+		prefix string
+	}{
+		{"argon2id by default", Hasher{Argon2: testArgon2Params}, argon2Prefix},
+		{"bcrypt", Hasher{Scheme: SchemeBcrypt, BcryptCost: bcrypt.MinCost}, "$2a$"},
+		{"pbkdf2", Hasher{Scheme: SchemePBKDF2, PBKDF2: testPBKDF2Params}, pbkdf2Prefix},
+		{"scrypt", Hasher{Scheme: SchemeScrypt, Scrypt: testScryptParams}, scryptPrefix},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.hasher.Hash("hunter2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(encoded, tt.prefix) {
+				t.Errorf("Hash() = %q, want prefix %q", encoded, tt.prefix)
+			}
+			if err := tt.hasher.Verify("hunter2", encoded); err != nil {
+				t.Errorf("Verify() = %v", err)
+			}
+			if err := tt.hasher.Verify("hunter3", encoded); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("Verify(wrong) = %v, want ErrPasswordMismatch", err)
+			}
+			if tt.hasher.NeedsUpgrade(encoded) {
+				t.Errorf("NeedsUpgrade(%q) = true for a fresh hash", encoded)
+			}
+		})
+	}
+	if _, err := (&Hasher{Scheme: SchemeSHA512Crypt}).Hash("hunter2"); !errors.Is(err, ErrUnknownHashScheme) {
+		t.Errorf("Hash() with a verify-only scheme = %v, want ErrUnknownHashScheme", err)
+	}
+}
+
+func TestHasherNeedsUpgrade(t *testing.T) {
+	cheapBcrypt, err := HashWithCost("hunter2", bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2, err := HashArgon2id("hunter2", testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbkdf2, err := HashPBKDF2("hunter2", testPBKDF2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scrypt, err := HashScrypt("hunter2", testScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stronger := testArgon2Params
+	stronger.Iterations++
+	morePBKDF2 := testPBKDF2Params
+	morePBKDF2.Iterations *= 2
+	moreScrypt := testScryptParams
+	moreScrypt.N *= 2
+
+	tests := []struct {
+		name    string
+		hasher  Hasher
+		encoded string
+		want    bool
+	}{
+		{"legacy scheme", Hasher{Argon2: testArgon2Params}, cheapBcrypt, true},
+		{"bcrypt cost below preferred", Hasher{Scheme: SchemeBcrypt}, cheapBcrypt, true},
+		{"bcrypt cost at preferred", Hasher{Scheme: SchemeBcrypt, BcryptCost: bcrypt.MinCost}, cheapBcrypt, false},
+		{"argon2id at preferred", Hasher{Argon2: testArgon2Params}, argon2, false},
+		{"argon2id weaker", Hasher{Argon2: stronger}, argon2, true},
+		{"pbkdf2 weaker", Hasher{Scheme: SchemePBKDF2, PBKDF2: morePBKDF2}, pbkdf2, true},
+		{"scrypt weaker", Hasher{Scheme: SchemeScrypt, Scrypt: moreScrypt}, scrypt, true},
+		{"malformed", Hasher{Argon2: testArgon2Params}, argon2Prefix + "v=19$", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsUpgrade(tt.encoded); got != tt.want {
+				t.Errorf("NeedsUpgrade() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasherVerifyAndUpgrade(t *testing.T) {
+	const password = "correct horse battery staple"
+	legacy, err := HashWithCost(password, bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Hasher{Argon2: testArgon2Params, Legacy: []string{SchemeBcrypt}}
+
+	var stored []string
+	store := func(encoded string) error {
+		stored = append(stored, encoded)
+		return nil
+	}
+	if ok, err := h.VerifyAndUpgrade("wrong", legacy, store); ok || err != nil || len(stored) != 0 {
+		t.Fatalf("VerifyAndUpgrade(wrong) = %v, %v with %d stored, want false, nil, none", ok, err, len(stored))
+	}
+	if ok, err := h.VerifyAndUpgrade(password, legacy, store); !ok || err != nil {
+		t.Fatalf("VerifyAndUpgrade() = %v, %v, want true, nil", ok, err)
+	}
+	if len(stored) != 1 || !strings.HasPrefix(stored[0], argon2Prefix) {
+		t.Fatalf("stored %q, want one argon2id hash", stored)
+	}
+	if err := VerifyArgon2id(password, stored[0]); err != nil {
+		t.Errorf("the upgraded hash does not verify: %v", err)
+	}
+	if ok, err := h.VerifyAndUpgrade(password, stored[0], store); !ok || err != nil || len(stored) != 1 {
+		t.Errorf("VerifyAndUpgrade(upgraded) = %v, %v with %d stored, want true, nil, no new store", ok, err, len(stored))
+	}
+
+	if _, err := (&Hasher{Argon2: testArgon2Params}).VerifyAndUpgrade(password, legacy, store); !errors.Is(err, ErrUnknownHashScheme) {
+		t.Errorf("VerifyAndUpgrade() without bcrypt in Legacy = %v, want ErrUnknownHashScheme", err)
+	}
+	if _, err := h.VerifyAndUpgrade(password, "plaintext", store); !errors.Is(err, ErrUnknownHashScheme) {
+		t.Errorf("VerifyAndUpgrade(plaintext) = %v, want ErrUnknownHashScheme", err)
+	}
+}
+
+func TestHasherUpgradeErrorKeepsLogin(t *testing.T) {
+	legacy, err := HashWithCost("hunter2", bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeErr := errors.New("database is read-only")
+	var reported error
+	h := &Hasher{
+		Argon2:         testArgon2Params,
+		Legacy:         []string{SchemeBcrypt},
+		OnUpgradeError: func(err error) { reported = err },
+	}
+	ok, err := h.VerifyAndUpgrade("hunter2", legacy, func(string) error { return storeErr })
+	if !ok || err != nil {
+		t.Errorf("VerifyAndUpgrade() = %v, %v, want true, nil", ok, err)
+	}
+	if !errors.Is(reported, storeErr) {
+		t.Errorf("OnUpgradeError got %v, want %v", reported, storeErr)
+	}
+}
+
+func TestHasherPepper(t *testing.T) {
+	const password = "hunter2"
+	p, err := NewPepper("v1", testPepperKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := HashArgon2id(password, testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Hasher{Argon2: testArgon2Params, Pepper: p}
+	var stored string
+	store := func(encoded string) error {
+		stored = encoded
+		return nil
+	}
+
+	// An unpeppered hash upgrades to the active key
+	if ok, err := h.VerifyAndUpgrade(password, plain, store); !ok || err != nil {
+		t.Fatalf("VerifyAndUpgrade(unpeppered) = %v, %v", ok, err)
+	}
+	if id, _ := PepperID(stored); id != "v1" {
+		t.Fatalf("stored %q, want a hash peppered with v1", stored)
+	}
+	v1 := stored
+
+	// Rotating the key upgrades v1 hashes to v2
+	if err := p.Add("v2", testPepperKey(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetActive("v2"); err != nil {
+		t.Fatal(err)
+	}
+	if !h.NeedsUpgrade(v1) {
+		t.Error("NeedsUpgrade() = false for a hash with an inactive pepper key")
+	}
+	if ok, err := h.VerifyAndUpgrade(password, v1, store); !ok || err != nil {
+		t.Fatalf("VerifyAndUpgrade(v1) = %v, %v", ok, err)
+	}
+	if id, _ := PepperID(stored); id != "v2" {
+		t.Errorf("stored %q, want a hash peppered with v2", stored)
+	}
+	if h.NeedsUpgrade(stored) {
+		t.Error("NeedsUpgrade() = true for a hash with the active pepper key")
+	}
+
+	if err := (&Hasher{Argon2: testArgon2Params}).Verify(password, stored); !errors.Is(err, ErrUnknownPepper) {
+		t.Errorf("Verify() of a peppered hash without a Pepper = %v, want ErrUnknownPepper", err)
+	}
+}
+
+func TestHasherBcryptCostUpgrade(t *testing.T) {
+	old, err := HashWithCost("hunter2", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Hasher{Scheme: SchemeBcrypt, BcryptCost: 12}
+	var stored string
+	ok, err := h.VerifyAndUpgrade("hunter2", old, func(encoded string) error {
+		stored = encoded
+		return nil
+	})
+	if !ok || err != nil {
+		t.Fatalf("VerifyAndUpgrade() = %v, %v, want true, nil", ok, err)
+	}
+	if cost, err := bcrypt.Cost([]byte(stored)); err != nil || cost != 12 {
+		t.Errorf("stored hash has cost %d (%v), want 12", cost, err)
+	}
+}
@@ -0,0 +1,114 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultHistoryWorkers is the number of hashes a HistoryChecker verifies at once when Workers is
+// zero. Slow hashes such as bcrypt keep a core busy each.
+const DefaultHistoryWorkers = 4
+
+// VerifyFunc reports whether password matches the stored hash encoded.
+type VerifyFunc func(password, encoded string) (bool, error)
+
+// HistoryChecker rejects reuse of a previous password when only its hashes are stored.
+type HistoryChecker struct {
+	Hashes  []string      // Hashes of previous passwords, most recent first
+	Verify  VerifyFunc    // Compares a password with one of Hashes
+	Workers int           // Hashes verified concurrently, DefaultHistoryWorkers when zero
+	Timeout time.Duration // Bound on the whole check when Audit runs it, zero for no limit
+}
+
+// Check verifies password against every hash and returns the index of a matching slot, or -1
+// when none match. Verification stops early on a match, an error, or when ctx is done. When
+// several slots match, the lowest index verified is returned.
+func (h *HistoryChecker) Check(ctx context.Context, password string) (int, error) {
+	if h.Verify == nil {
+		return -1, errors.New("history checker has no verify function")
+	}
+	if len(h.Hashes) == 0 {
+		return -1, nil
+	}
+
+	var cancel context.CancelFunc
+	if h.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	workers := h.Workers
+	if workers <= 0 {
+		workers = DefaultHistoryWorkers
+	}
+	if workers > len(h.Hashes) {
+		workers = len(h.Hashes)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		match    = -1
+		firstErr error
+		slots    = make(chan int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range slots {
+				ok, err := h.Verify(password, h.Hashes[i])
+				mu.Lock()
+				switch {
+				case err != nil && firstErr == nil:
+					firstErr = err
+					cancel()
+				case ok && (match < 0 || i < match):
+					match = i
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range h.Hashes {
+		select {
+		case slots <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(slots)
+	wg.Wait()
+
+	switch {
+	case match >= 0:
+		return match, nil
+	case firstErr != nil:
+		return -1, firstErr
+	}
+	// ctx is only cancelled here by the deadline or the caller.
+	return -1, ctx.Err()
+}
@@ -0,0 +1,148 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// plainVerify treats "plain:<password>" as the hash of <password>.
+func plainVerify(password, encoded string) (bool, error) {
+	if !strings.HasPrefix(encoded, "plain:") {
+		return false, errors.New("unknown hash format")
+	}
+	return strings.TrimPrefix(encoded, "plain:") == password, nil
+}
+
+func TestHistoryCheckerCheck(t *testing.T) {
+	history := &HistoryChecker{
+		Hashes: []string{"plain:Spring2024!", "plain:Winter2023!", "plain:hunter22", "plain:Autumn2023!", "plain:Summer2023!"},
+		Verify: plainVerify,
+	}
+
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"Spring2024!", 0},
+		{"hunter22", 2},
+		{"Summer2023!", 4},
+		{"brand new password", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			got, err := history.Check(context.Background(), tt.password)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Check(%q) = %d, want %d", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryCheckerBoundedWorkers(t *testing.T) {
+	var running, peak int32
+	history := &HistoryChecker{
+		Hashes:  make([]string, 12),
+		Workers: 3,
+		Verify: func(password, encoded string) (bool, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return false, nil
+		},
+	}
+
+	if _, err := history.Check(context.Background(), "password"); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if peak > 3 {
+		t.Errorf("Check() ran %d verifications at once, want at most 3", peak)
+	}
+}
+
+func TestHistoryCheckerDeadline(t *testing.T) {
+	history := &HistoryChecker{
+		Hashes:  make([]string, 50),
+		Workers: 1,
+		Timeout: 10 * time.Millisecond,
+		Verify: func(password, encoded string) (bool, error) {
+			time.Sleep(5 * time.Millisecond)
+			return false, nil
+		},
+	}
+
+	if _, err := history.Check(context.Background(), "password"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Check() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestHistoryCheckerErrors(t *testing.T) {
+	if _, err := (&HistoryChecker{Hashes: []string{"x"}}).Check(context.Background(), "pw"); err == nil {
+		t.Error("Check() without Verify error = nil, want error")
+	}
+	history := &HistoryChecker{Hashes: []string{"md5:abc"}, Verify: plainVerify}
+	if _, err := history.Check(context.Background(), "pw"); err == nil {
+		t.Error("Check() with unknown format error = nil, want error")
+	}
+}
+
+func TestAuditHistory(t *testing.T) {
+	options := Options{History: &HistoryChecker{Hashes: []string{"plain:old one", "plain:Reus3d!pass"}, Verify: plainVerify}}
+
+	result := Audit("Reus3d!pass", options)
+	if !errors.Is(result.Err, ErrPasswordReused) {
+		t.Fatalf("Audit() error = %v, want %v", result.Err, ErrPasswordReused)
+	}
+	if !result.HistoryMatch || result.HistoryIndex != 1 {
+		t.Errorf("Audit() history = %v/%d, want true/1", result.HistoryMatch, result.HistoryIndex)
+	}
+	if strings.Contains(result.Err.Error(), "plain:") {
+		t.Errorf("Audit() error %q leaks the stored hash", result.Err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !decoded.HistoryMatch || decoded.HistoryIndex != 1 {
+		t.Errorf("round trip history = %v/%d, want true/1", decoded.HistoryMatch, decoded.HistoryIndex)
+	}
+
+	options.History.Hashes = []string{"bogus"}
+	if result := Audit("Reus3d!pass", options); !errors.Is(result.Err, ErrHistoryCheckFailed) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrHistoryCheckFailed)
+	}
+}
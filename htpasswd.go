@@ -0,0 +1,273 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// HtpasswdScheme selects the hash NewHtpasswdEntry writes.
+type HtpasswdScheme int
+
+const (
+	HtpasswdBcrypt HtpasswdScheme = iota // bcrypt, "htpasswd -B"
+	HtpasswdAPR1                         // Apache MD5, "htpasswd -m"
+)
+
+// Prefixes of the hash formats found in htpasswd files and LDAP userPassword values.
+const (
+	apr1Prefix     = "$apr1$"
+	md5CryptPrefix = "$1$"
+	shaPrefix      = "{SHA}"
+)
+
+// HtpasswdEntry is one user line of an htpasswd file.
+type HtpasswdEntry struct {
+	User string
+	Hash string
+}
+
+// HtpasswdFile is an htpasswd file with its entries in file order.
+type HtpasswdFile struct {
+	Entries []HtpasswdEntry
+}
+
+// ReadHtpasswd parses an htpasswd file. Blank lines and lines starting with "#" are skipped; a
+// line without a colon or a repeated user is an error naming the line.
+func ReadHtpasswd(r io.Reader) (*HtpasswdFile, error) {
+	file := &HtpasswdFile{}
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("htpasswd line %d: want user:hash", n)
+		}
+		if seen[user] {
+			return nil, fmt.Errorf("htpasswd line %d: duplicate user %q", n, user)
+		}
+		seen[user] = true
+		file.Entries = append(file.Entries, HtpasswdEntry{User: user, Hash: hash})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// ParseHtpasswd reads an htpasswd file into a map of user to hash. Use ReadHtpasswd to keep the
+// entry order.
+func ParseHtpasswd(r io.Reader) (map[string]string, error) {
+	file, err := ReadHtpasswd(r)
+	if err != nil {
+		return nil, err
+	}
+	return file.Map(), nil
+}
+
+// Map returns the entries as a map of user to hash.
+func (file *HtpasswdFile) Map() map[string]string {
+	entries := make(map[string]string, len(file.Entries))
+	for _, e := range file.Entries {
+		entries[e.User] = e.Hash
+	}
+	return entries
+}
+
+// Set replaces the hash of user in place, or appends the user when it is new.
+func (file *HtpasswdFile) Set(user, hash string) {
+	for i := range file.Entries {
+		if file.Entries[i].User == user {
+			file.Entries[i].Hash = hash
+			return
+		}
+	}
+	file.Entries = append(file.Entries, HtpasswdEntry{User: user, Hash: hash})
+}
+
+// Delete removes user and reports whether it was present.
+func (file *HtpasswdFile) Delete(user string) bool {
+	for i := range file.Entries {
+		if file.Entries[i].User == user {
+			file.Entries = append(file.Entries[:i], file.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTo writes the entries as "user:hash" lines in order.
+func (file *HtpasswdFile) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, e := range file.Entries {
+		n, err := io.WriteString(w, e.User+":"+e.Hash+"\n")
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// VerifyHtpasswd checks pass against the hash stored for user. bcrypt, APR1-MD5 ("$apr1$"), and
+// legacy SHA-1 ("{SHA}") hashes are supported. An unknown user fails with ErrPasswordMismatch so
+// callers cannot tell it apart from a wrong password.
+func VerifyHtpasswd(entries map[string]string, user, pass string) error {
+	hash, ok := entries[user]
+	if !ok {
+		return ErrPasswordMismatch
+	}
+	switch {
+	case strings.HasPrefix(hash, apr1Prefix):
+		return verifyAPR1(pass, hash)
+	case strings.HasPrefix(hash, shaPrefix):
+		p := []byte(pass)
+		sum := sha1.Sum(p)
+		wipe(p)
+		if subtle.ConstantTimeCompare([]byte(base64.StdEncoding.EncodeToString(sum[:])), []byte(strings.TrimPrefix(hash, shaPrefix))) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	case strings.HasPrefix(hash, "$2"):
+		_, err := VerifyAny(pass, hash)
+		return err
+	}
+	return ErrUnknownHashScheme
+}
+
+// NewHtpasswdEntry returns the "user:hash" line for user with pass hashed by scheme.
+func NewHtpasswdEntry(user, pass string, scheme HtpasswdScheme) (string, error) {
+	if user == "" || strings.ContainsAny(user, ":\r\n") {
+		return "", fmt.Errorf("invalid htpasswd user %q", user)
+	}
+
+	var hash string
+	var err error
+	switch scheme {
+	case HtpasswdBcrypt:
+		hash, err = Hash(pass)
+	case HtpasswdAPR1:
+		var salt string
+		if salt, err = randomCryptSalt(8); err == nil {
+			hash = md5Crypt(pass, salt, apr1Prefix)
+		}
+	default:
+		err = errors.New("unsupported htpasswd scheme")
+	}
+	if err != nil {
+		return "", err
+	}
+	return user + ":" + hash, nil
+}
+
+// verifyAPR1 compares pass with an Apache "$apr1$salt$hash" string in constant time.
+func verifyAPR1(pass, encoded string) error {
+	salt, hash, ok := strings.Cut(strings.TrimPrefix(encoded, apr1Prefix), "$")
+	if !ok || len(hash) != 22 {
+		return fmt.Errorf("%w: want $apr1$salt$hash", ErrMalformedHash)
+	}
+	if subtle.ConstantTimeCompare([]byte(md5Crypt(pass, salt, apr1Prefix)), []byte(encoded)) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// md5Crypt implements the MD5-based crypt used by FreeBSD ("$1$") and Apache ("$apr1$").
+func md5Crypt(password, salt, magic string) string {
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	p, s := []byte(password), []byte(salt)
+	defer wipe(p)
+
+	alt := md5.New()
+	alt.Write(p)
+	alt.Write(s)
+	alt.Write(p)
+	altSum := alt.Sum(nil)
+
+	ctx := md5.New()
+	ctx.Write(p)
+	ctx.Write([]byte(magic))
+	ctx.Write(s)
+	for n := len(p); n > 0; n -= md5.Size {
+		ctx.Write(altSum[:min(n, md5.Size)])
+	}
+	for n := len(p); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write(p[:1])
+		}
+	}
+	final := ctx.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		round := md5.New()
+		if i&1 != 0 {
+			round.Write(p)
+		} else {
+			round.Write(final)
+		}
+		if i%3 != 0 {
+			round.Write(s)
+		}
+		if i%7 != 0 {
+			round.Write(p)
+		}
+		if i&1 != 0 {
+			round.Write(final)
+		} else {
+			round.Write(p)
+		}
+		final = round.Sum(final[:0])
+	}
+
+	var out strings.Builder
+	out.WriteString(magic + salt + "$")
+	for _, g := range [...][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		writeCrypt64(&out, uint(final[g[0]])<<16|uint(final[g[1]])<<8|uint(final[g[2]]), 4)
+	}
+	writeCrypt64(&out, uint(final[11]), 2)
+	return out.String()
+}
+
+// randomCryptSalt returns n characters of the crypt(3) alphabet from crypto/rand.
+func randomCryptSalt(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		// The alphabet has 64 characters, so masking keeps the choice uniform.
+		b[i] = cryptAlphabet[b[i]&0x3f]
+	}
+	return string(b), nil
+}
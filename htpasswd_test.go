@@ -0,0 +1,141 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readTestHtpasswd(t *testing.T) *HtpasswdFile {
+	t.Helper()
+	f, err := os.Open("testdata/htpasswd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	file, err := ReadHtpasswd(f)
+	if err != nil {
+		t.Fatalf("ReadHtpasswd() error = %v", err)
+	}
+	return file
+}
+
+func TestVerifyHtpasswd(t *testing.T) {
+	entries := readTestHtpasswd(t).Map()
+
+	tests := []struct {
+		user, pass string
+		want       error
+	}{
+		{"alice", "password", nil},
+		{"alice", "Password", ErrPasswordMismatch},
+		{"bob", "password", nil},
+		{"bob", "hunter2", ErrPasswordMismatch},
+		{"carol", "U*U", nil},
+		{"carol", "U*V", ErrPasswordMismatch},
+		{"dave", "Pässwørd", nil},
+		{"mallory", "password", ErrPasswordMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user+"/"+tt.pass, func(t *testing.T) {
+			if err := VerifyHtpasswd(entries, tt.user, tt.pass); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyHtpasswd() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMD5CryptMatchesOpenSSL(t *testing.T) {
+	tests := []struct{ pass, want string }{
+		{"test", "$apr1$x9Ab.Z/q$2f02WEPYArI6QG1pGqay4."},
+		{"", "$apr1$x9Ab.Z/q$.UQs2MGIy4nNtnrbFLltK."},
+		{"a-much-longer-password-exceeding-sixteen-bytes", "$apr1$x9Ab.Z/q$rdKevetDqQ7JHM2bvNQ.s0"},
+	}
+	for _, tt := range tests {
+		if got := md5Crypt(tt.pass, "x9Ab.Z/q", apr1Prefix); got != tt.want {
+			t.Errorf("md5Crypt(%q) = %q, want %q", tt.pass, got, tt.want)
+		}
+	}
+}
+
+func TestHtpasswdWritePreservesOrder(t *testing.T) {
+	file := readTestHtpasswd(t)
+	file.Set("bob", "{SHA}replaced")
+	file.Set("erin", "{SHA}new")
+	if !file.Delete("carol") {
+		t.Error("Delete(carol) = false, want true")
+	}
+
+	var buf bytes.Buffer
+	if _, err := file.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	var users []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		users = append(users, strings.SplitN(line, ":", 2)[0])
+	}
+	if got := strings.Join(users, ","); got != "alice,bob,dave,erin" {
+		t.Errorf("WriteTo() users = %s, want alice,bob,dave,erin", got)
+	}
+
+	again, err := ReadHtpasswd(&buf)
+	if err != nil {
+		t.Fatalf("ReadHtpasswd() error = %v", err)
+	}
+	if len(again.Entries) != 4 || again.Entries[1].Hash != "{SHA}replaced" {
+		t.Errorf("ReadHtpasswd() = %+v, want the written entries", again.Entries)
+	}
+}
+
+func TestParseHtpasswdErrors(t *testing.T) {
+	for _, input := range []string{
+		"alice:x\nalice:y\n",
+		"no colon here\n",
+		":empty-user\n",
+	} {
+		if _, err := ParseHtpasswd(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseHtpasswd(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestNewHtpasswdEntry(t *testing.T) {
+	for _, scheme := range []HtpasswdScheme{HtpasswdBcrypt, HtpasswdAPR1} {
+		line, err := NewHtpasswdEntry("frank", "s3cret!", scheme)
+		if err != nil {
+			t.Fatalf("NewHtpasswdEntry() error = %v", err)
+		}
+		entries, err := ParseHtpasswd(strings.NewReader(line + "\n"))
+		if err != nil {
+			t.Fatalf("ParseHtpasswd(%q) error = %v", line, err)
+		}
+		if err := VerifyHtpasswd(entries, "frank", "s3cret!"); err != nil {
+			t.Errorf("VerifyHtpasswd(%q) error = %v, want nil", line, err)
+		}
+	}
+
+	if _, err := NewHtpasswdEntry("bad:user", "pw", HtpasswdAPR1); err == nil {
+		t.Error("NewHtpasswdEntry() with colon in user error = nil, want error")
+	}
+	if _, err := NewHtpasswdEntry("frank", "pw", HtpasswdScheme(99)); err == nil {
+		t.Error("NewHtpasswdEntry() with unknown scheme error = nil, want error")
+	}
+}
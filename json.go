@@ -0,0 +1,210 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// resultJSON is the wire representation of Result.
+type resultJSON struct {
+	Entropy          float64          `json:"entropy"`
+	CharsetEntropy   float64          `json:"charset_entropy"`
+	ShannonEntropy   float64          `json:"shannon_entropy"`
+	EffectiveEntropy float64          `json:"effective_entropy"`
+	Strong           bool             `json:"strong"`
+	Length           int64            `json:"length"`
+	LengthBytes      int64            `json:"length_bytes"`
+	LengthRunes      int64            `json:"length_runes"`
+	LengthGraphemes  int64            `json:"length_graphemes"`
+	TruncationRisk   bool             `json:"truncation_risk,omitempty"`
+	UniqueChars      int              `json:"unique_chars"`
+	Complexity       Complexity       `json:"complexity"`
+	ComplexityName   string           `json:"complexity_name"`
+	HasExtended      bool             `json:"has_extended"`
+	HasEmoji         bool             `json:"has_emoji"`
+	ConfusableRunes  []ConfusableRune `json:"confusable_runes,omitempty"`
+	Scripts          []string         `json:"scripts,omitempty"`
+	Classes          Class            `json:"classes"`
+	Counts           Counts           `json:"counts"`
+	PwnedCount       int              `json:"pwned_count"`
+	BreachChecked    bool             `json:"breach_checked,omitempty"`
+	HistoryIndex     *int             `json:"history_index,omitempty"`
+	PassphraseWords  int              `json:"passphrase_words,omitempty"`
+	Policy           string           `json:"policy,omitempty"`
+	Score            int              `json:"score"`
+	Rating           string           `json:"rating"`
+	CrackTimes       CrackTimes       `json:"crack_times"`
+	Patterns         []Match          `json:"patterns,omitempty"`
+	Violations       []string         `json:"violations,omitempty"`
+	Warnings         []string         `json:"warnings,omitempty"`
+	Error            string           `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes the Result with stable snake_case keys, rendering errors as strings.
+func (audit Result) MarshalJSON() ([]byte, error) {
+	out := resultJSON{
+		Entropy:          audit.Entropy,
+		CharsetEntropy:   audit.CharsetEntropy,
+		ShannonEntropy:   audit.ShannonEntropy,
+		EffectiveEntropy: audit.EffectiveEntropy,
+		Strong:           audit.Strong,
+		Length:           audit.Length,
+		LengthBytes:      audit.LengthBytes,
+		LengthRunes:      audit.LengthRunes,
+		LengthGraphemes:  audit.LengthGraphemes,
+		TruncationRisk:   audit.TruncationRisk,
+		UniqueChars:      audit.UniqueChars,
+		Complexity:       audit.Complexity,
+		ComplexityName:   audit.Complexity.snakeName(),
+		HasExtended:      audit.HasExtended,
+		HasEmoji:         audit.HasEmoji,
+		ConfusableRunes:  audit.ConfusableRunes,
+		Scripts:          audit.Scripts,
+		Classes:          audit.Classes,
+		Counts:           audit.Counts,
+		PwnedCount:       audit.PwnedCount,
+		BreachChecked:    audit.BreachChecked,
+		PassphraseWords:  audit.PassphraseWords,
+		Policy:           audit.Policy,
+		Score:            audit.Score,
+		Rating:           audit.Rating.String(),
+		CrackTimes:       audit.CrackTimes,
+		Patterns:         audit.Patterns,
+	}
+	if audit.HistoryMatch {
+		index := audit.HistoryIndex
+		out.HistoryIndex = &index
+	}
+	for _, err := range audit.Violations {
+		out.Violations = append(out.Violations, err.Error())
+	}
+	for _, err := range audit.Warnings {
+		out.Warnings = append(out.Warnings, err.Error())
+	}
+	if audit.Err != nil {
+		out.Error = audit.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a Result produced by MarshalJSON. Errors are reconstructed as plain
+// errors carrying the original message; they no longer match the sentinel errors.
+func (audit *Result) UnmarshalJSON(data []byte) error {
+	var in resultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*audit = Result{
+		Entropy:          in.Entropy,
+		CharsetEntropy:   in.CharsetEntropy,
+		ShannonEntropy:   in.ShannonEntropy,
+		EffectiveEntropy: in.EffectiveEntropy,
+		Strong:           in.Strong,
+		Length:           in.Length,
+		LengthBytes:      in.LengthBytes,
+		LengthRunes:      in.LengthRunes,
+		LengthGraphemes:  in.LengthGraphemes,
+		TruncationRisk:   in.TruncationRisk,
+		UniqueChars:      in.UniqueChars,
+		Complexity:       in.Complexity,
+		HasExtended:      in.HasExtended,
+		HasEmoji:         in.HasEmoji,
+		ConfusableRunes:  in.ConfusableRunes,
+		Scripts:          in.Scripts,
+		Classes:          in.Classes,
+		Counts:           in.Counts,
+		PwnedCount:       in.PwnedCount,
+		BreachChecked:    in.BreachChecked,
+		PassphraseWords:  in.PassphraseWords,
+		Policy:           in.Policy,
+		Score:            in.Score,
+		Rating:           parseRating(in.Rating),
+		CrackTimes:       in.CrackTimes,
+		Patterns:         in.Patterns,
+	}
+	if in.HistoryIndex != nil {
+		audit.HistoryMatch = true
+		audit.HistoryIndex = *in.HistoryIndex
+	}
+	for _, msg := range in.Violations {
+		audit.Violations = append(audit.Violations, errors.New(msg))
+	}
+	for _, msg := range in.Warnings {
+		audit.Warnings = append(audit.Warnings, errors.New(msg))
+	}
+	if in.Error != "" {
+		audit.Err = errors.New(in.Error)
+	}
+	return nil
+}
+
+// optionsJSON is the wire representation of Options, with the complexity threshold by name.
+type optionsJSON struct {
+	jsonOptions
+	MinimumComplexity string `json:"minimum_complexity"`
+}
+
+// jsonOptions has the fields and tags of Options without its JSON methods.
+type jsonOptions Options
+
+// MarshalJSON encodes the policy fields of the Options with stable snake_case keys and
+// MinimumComplexity by name, such as "symbols_digits_mixed".
+func (opts Options) MarshalJSON() ([]byte, error) {
+	name := opts.MinimumComplexity.snakeName()
+	if name == "" {
+		return nil, fmt.Errorf("%w: unknown minimum complexity %d", ErrInvalidOptions, opts.MinimumComplexity)
+	}
+	return json.Marshal(optionsJSON{jsonOptions: jsonOptions(opts), MinimumComplexity: name})
+}
+
+// UnmarshalJSON decodes Options written by MarshalJSON, rejecting unknown fields and complexity
+// names. Fields left out of the JSON keep their zero value.
+func (opts *Options) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	in := optionsJSON{MinimumComplexity: PwComplexityDigitsOnly.snakeName()}
+	if err := dec.Decode(&in); err != nil {
+		return err
+	}
+
+	complexity, err := ParseComplexity(in.MinimumComplexity)
+	if err != nil {
+		return fmt.Errorf("%w: unknown minimum complexity %q", ErrInvalidOptions, in.MinimumComplexity)
+	}
+
+	*opts = Options(in.jsonOptions)
+	opts.MinimumComplexity = complexity
+	return nil
+}
+
+// ParseOptionsJSON decodes a policy written by Options.MarshalJSON, such as one stored in a config
+// file, and returns the error from Options.Validate when it can never be satisfied.
+func ParseOptionsJSON(data []byte) (Options, error) {
+	var opts Options
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return Options{}, err
+	}
+	if err := opts.Validate(); err != nil {
+		return Options{}, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,241 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden compares got against testdata/name, rewriting the file when -update is set.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestResultMarshalJSON(t *testing.T) {
+	options := Options{
+		MinLength:         8,
+		MaxLength:         32,
+		UseDigits:         true,
+		UseSymbols:        true,
+		MinimumComplexity: PwComplexitySymbolsDigitsMixed,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		golden   string
+	}{
+		{"Passing result", "P@sswørd123", "result_pass.golden"},
+		{"Failing result", "short", "result_fail.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(Audit(tt.password, options), "", "  ")
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			assertGolden(t, tt.golden, append(got, '\n'))
+		})
+	}
+}
+
+func TestResultUnmarshalJSON(t *testing.T) {
+	original := Audit("short", Options{MinLength: 8, UseDigits: true, PolicyName: PolicyPCIDSS})
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	var decoded Result
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if decoded.Err == nil || decoded.Err.Error() != original.Err.Error() {
+		t.Errorf("UnmarshalJSON() error = %v, want %v", decoded.Err, original.Err)
+	}
+	if len(decoded.Violations) != len(original.Violations) {
+		t.Errorf("UnmarshalJSON() violations = %v, want %v", decoded.Violations, original.Violations)
+	}
+	if decoded.Entropy != original.Entropy || decoded.Length != original.Length || decoded.Complexity != original.Complexity || decoded.Policy != original.Policy {
+		t.Errorf("UnmarshalJSON() = %+v, want %+v", decoded, original)
+	}
+}
+
+func TestOptionsJSONRoundTrip(t *testing.T) {
+	full := Options{
+		MinLength:                  12,
+		MaxLength:                  128,
+		UseDigits:                  true,
+		UseLower:                   true,
+		UseUpper:                   true,
+		UseSymbols:                 true,
+		UseExtended:                true,
+		MinDigits:                  2,
+		MinLower:                   2,
+		MinUpper:                   1,
+		MinSymbols:                 1,
+		MinExtended:                1,
+		MinClasses:                 3,
+		MinimumComplexity:          PwComplexitySymbolsDigitsMixed,
+		MinEntropy:                 60,
+		RejectCommon:               true,
+		ExtraDictionary:            []string{"acmecorp", "hunter"},
+		MinUniqueChars:             8,
+		MaxClassRatio:              0.6,
+		MaxBytes:                   72,
+		CountGraphemes:             true,
+		SymbolSet:                  "!#$%&*-_",
+		UnicodeClasses:             true,
+		DisallowedChars:            "\"'`",
+		RejectInvisibleChars:       true,
+		ForbidLeadingTrailingSpace: true,
+		FirstCharClasses:           ClassLower | ClassUpper,
+		ForbiddenPatterns:          []string{`(?i)acme`},
+		BreachCheckMode:            BreachAdvisory,
+		BreachCheckTimeout:         2 * time.Second,
+		BreachFailOpen:             true,
+		NormalizeLeet:              true,
+		UseMarkov:                  true,
+		MaxSequenceLength:          3,
+		RejectSequences:            true,
+		MaxRepeatRun:               2,
+		MaxSimilarity:              0.5,
+		KeyboardWalkLength:         4,
+		RejectKeyboardWalks:        true,
+		PolicyName:                 "acme",
+		FailFast:                   true,
+	}
+
+	tests := []struct {
+		name    string
+		options Options
+		golden  string
+	}{
+		{"Every field", full, "options_full.golden"},
+		{"NIST preset", NISTOptions(), "options_nist.golden"},
+		{"Zero value", Options{}, "options_zero.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.MarshalIndent(tt.options, "", "  ")
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			assertGolden(t, tt.golden, append(data, '\n'))
+
+			decoded, err := ParseOptionsJSON(data)
+			if err != nil {
+				t.Fatalf("ParseOptionsJSON() error = %v", err)
+			}
+			// The checkers are wired in code and never serialized.
+			want := tt.options
+			want.BreachChecker = nil
+			if !reflect.DeepEqual(decoded, want) {
+				t.Errorf("ParseOptionsJSON() = %+v, want %+v", decoded, want)
+			}
+		})
+	}
+}
+
+func TestOptionsMarshalJSONOmitsRuntimeFields(t *testing.T) {
+	options := Options{
+		MinLength:         8,
+		Dictionary:        NewWordList("acme"),
+		UserInputs:        []string{"alice@example.com"},
+		PreviousPasswords: []string{"Winter2023!"},
+		History:           &HistoryChecker{Hashes: []string{"$2y$10$abc"}},
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if want := `{"min_length":8,"minimum_complexity":"digits_only"}`; string(data) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", data, want)
+	}
+
+	if _, err := json.Marshal(Options{MinimumComplexity: 99}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("MarshalJSON() error = %v, want %v", err, ErrInvalidOptions)
+	}
+}
+
+func TestParseOptionsJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Options
+		wantErr bool
+		is      error // Sentinel the error must wrap, if any
+	}{
+		{name: "Minimal", data: `{"min_length": 10}`, want: Options{MinLength: 10}},
+		{name: "Complexity by name", data: `{"minimum_complexity": "lower_digits"}`, want: Options{MinimumComplexity: PwComplexityLowerDigits}},
+		{name: "Empty object", data: `{}`, want: Options{}},
+		{name: "Unknown field", data: `{"min_length": 10, "min_lenght": 12}`, wantErr: true},
+		{name: "Runtime field", data: `{"user_inputs": ["alice"]}`, wantErr: true},
+		{name: "Go field name", data: `{"MinLength": 10}`, wantErr: true},
+		{name: "Complexity as number", data: `{"minimum_complexity": 12}`, wantErr: true},
+		{name: "Unknown complexity", data: `{"minimum_complexity": "very_strong"}`, wantErr: true, is: ErrInvalidOptions},
+		{name: "Wrong type", data: `{"min_length": "ten"}`, wantErr: true},
+		{name: "Negative length", data: `{"min_length": -1}`, wantErr: true},
+		{name: "Contradictory", data: `{"min_length": 20, "max_length": 10}`, wantErr: true, is: ErrInvalidOptions},
+		{name: "Malformed", data: `{"min_length": 10`, wantErr: true},
+		{name: "Trailing data", data: `{"min_length": 10} {}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOptionsJSON([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil || (tt.is != nil && !errors.Is(err, tt.is)) {
+					t.Fatalf("ParseOptionsJSON(%s) = %+v, %v, want error %v", tt.data, got, err, tt.is)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOptionsJSON(%s) error = %v", tt.data, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOptionsJSON(%s) = %+v, want %+v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
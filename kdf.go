@@ -0,0 +1,226 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PBKDF2Params are the PBKDF2-HMAC-SHA256 parameters encoded with each hash.
+type PBKDF2Params struct {
+	Iterations int // Number of HMAC iterations, at least MinPBKDF2Iterations
+	SaltLength int // Random salt length in bytes
+	KeyLength  int // Derived key length in bytes
+}
+
+// ScryptParams are the scrypt parameters encoded with each hash.
+type ScryptParams struct {
+	N          int // CPU and memory cost, a power of two of at least MinScryptN
+	R          int // Block size
+	P          int // Parallelism
+	SaltLength int // Random salt length in bytes
+	KeyLength  int // Derived key length in bytes
+}
+
+// Defaults follow the OWASP password storage recommendations.
+var (
+	DefaultPBKDF2Params = PBKDF2Params{Iterations: 600000, SaltLength: 16, KeyLength: 32}
+	DefaultScryptParams = ScryptParams{N: 1 << 17, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+)
+
+// Lowest costs HashPBKDF2 and HashScrypt accept. Verification still accepts cheaper hashes so
+// legacy records can be checked and upgraded.
+const (
+	MinPBKDF2Iterations = 100000
+	MinScryptN          = 1 << 14
+)
+
+// Highest costs hashing and verification accept. A stored hash above them is rejected with
+// ErrMalformedHash rather than run, so a crafted or corrupted record cannot exhaust memory or
+// tie up a CPU for minutes on every login.
+const (
+	MaxPBKDF2Iterations  = 10000000
+	MaxScryptLogN        = 20      // N of at most 1<<20
+	MaxScryptMemory      = 1 << 30 // Bytes of 128*r*N
+	MaxScryptParallelism = 16      // p
+)
+
+// Prefixes of the PHC-style encodings.
+const (
+	pbkdf2Prefix = "$pbkdf2-sha256$"
+	scryptPrefix = "$scrypt$"
+)
+
+// HashPBKDF2 derives a PBKDF2-HMAC-SHA256 key from password with a salt from crypto/rand and
+// returns "$pbkdf2-sha256$i=<iterations>$<salt>$<hash>". PBKDF2 is the FIPS 140 approved choice.
+func HashPBKDF2(password string, p PBKDF2Params) (string, error) {
+	if p.Iterations < MinPBKDF2Iterations || p.Iterations > MaxPBKDF2Iterations {
+		return "", fmt.Errorf("pbkdf2 iterations %d is outside %d to %d", p.Iterations, MinPBKDF2Iterations, MaxPBKDF2Iterations)
+	}
+	if p.SaltLength < 16 || p.KeyLength < 16 {
+		return "", fmt.Errorf("invalid pbkdf2 lengths: salt %d bytes, key %d bytes", p.SaltLength, p.KeyLength)
+	}
+	salt, err := randomSalt(p.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, p.Iterations, p.KeyLength)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%si=%d$%s$%s", pbkdf2Prefix, p.Iterations, encodePHCBase64(salt), encodePHCBase64(key)), nil
+}
+
+// VerifyPBKDF2 compares password with a "$pbkdf2-sha256$" hash in constant time. It returns nil on
+// a match, ErrPasswordMismatch for a wrong password, and ErrMalformedHash when encoded cannot be
+// parsed.
+func VerifyPBKDF2(password, encoded string) error {
+	params, salt, key, err := splitPHC(encoded, pbkdf2Prefix)
+	if err != nil {
+		return err
+	}
+	iterations, err := phcInt(params, "i", MaxPBKDF2Iterations)
+	if err != nil {
+		return err
+	}
+	other, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(key))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// HashScrypt derives an scrypt key from password with a salt from crypto/rand and returns
+// "$scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<hash>".
+func HashScrypt(password string, p ScryptParams) (string, error) {
+	if p.N < MinScryptN || bits.OnesCount(uint(p.N)) != 1 {
+		return "", fmt.Errorf("scrypt N %d must be a power of two of at least %d", p.N, MinScryptN)
+	}
+	if err := checkScryptCost(bits.TrailingZeros(uint(p.N)), p.R, p.P); err != nil {
+		return "", err
+	}
+	if p.SaltLength < 16 || p.KeyLength < 16 {
+		return "", fmt.Errorf("invalid scrypt lengths: salt %d bytes, key %d bytes", p.SaltLength, p.KeyLength)
+	}
+	salt, err := randomSalt(p.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	pw := []byte(password)
+	defer wipe(pw)
+	key, err := scrypt.Key(pw, salt, p.N, p.R, p.P, p.KeyLength)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%sln=%d,r=%d,p=%d$%s$%s", scryptPrefix, bits.TrailingZeros(uint(p.N)), p.R, p.P,
+		encodePHCBase64(salt), encodePHCBase64(key)), nil
+}
+
+// VerifyScrypt compares password with a "$scrypt$" hash in constant time. It returns nil on a
+// match, ErrPasswordMismatch for a wrong password, and ErrMalformedHash when encoded cannot be
+// parsed.
+func VerifyScrypt(password, encoded string) error {
+	params, salt, key, err := splitPHC(encoded, scryptPrefix)
+	if err != nil {
+		return err
+	}
+	ln, err := phcInt(params, "ln", MaxScryptLogN)
+	if err != nil {
+		return err
+	}
+	r, err := phcInt(params, "r", MaxScryptMemory/128)
+	if err != nil {
+		return err
+	}
+	p, err := phcInt(params, "p", MaxScryptParallelism)
+	if err != nil {
+		return err
+	}
+	if err := checkScryptCost(ln, r, p); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+	pw := []byte(password)
+	defer wipe(pw)
+	other, err := scrypt.Key(pw, salt, 1<<ln, r, p, len(key))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// checkScryptCost reports an error when N = 1<<ln, r, and p exceed the Max limits.
+func checkScryptCost(ln, r, p int) error {
+	if ln < 1 || ln > MaxScryptLogN || r < 1 || p < 1 || p > MaxScryptParallelism || r > MaxScryptMemory/(128<<ln) {
+		return fmt.Errorf("scrypt parameters ln=%d, r=%d, p=%d exceed the limits", ln, r, p)
+	}
+	return nil
+}
+
+// splitPHC parses "<prefix><params>$<salt>$<hash>" with ParsePHC into its name=value parameters
+// and decoded salt and hash.
+func splitPHC(encoded, prefix string) (map[string]string, []byte, []byte, error) {
+	if !strings.HasPrefix(encoded, prefix) {
+		return nil, nil, nil, fmt.Errorf("%w: want prefix %q", ErrMalformedHash, prefix)
+	}
+	p, err := ParsePHC(encoded)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if p.Version != 0 || p.Params == nil {
+		return nil, nil, nil, fmt.Errorf("%w: want parameters, salt, and hash", ErrMalformedHash)
+	}
+	return p.Params, p.Salt, p.Hash, nil
+}
+
+// phcInt returns the positive integer parameter name, at most limit.
+func phcInt(params map[string]string, name string, limit int) (int, error) {
+	n, err := strconv.Atoi(params[name])
+	if err != nil || n < 1 || n > limit {
+		return 0, fmt.Errorf("%w: bad parameter %s=%q", ErrMalformedHash, name, params[name])
+	}
+	return n, nil
+}
+
+// encodePHCBase64 encodes b in the unpadded standard base64 used by PHC strings.
+func encodePHCBase64(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// randomSalt returns n bytes from crypto/rand.
+func randomSalt(n int) ([]byte, error) {
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
@@ -0,0 +1,165 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// RFC 7914 section 11 (PBKDF2-HMAC-SHA256) and section 12 (scrypt) vectors in PHC encoding.
+var (
+	pbkdf2RFCVectors = []struct{ password, encoded string }{
+		{"passwd", "$pbkdf2-sha256$i=1$c2FsdA$VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLxJypzM8Xm2RZkWZLOdd+8xfHG4RbHjC9UJESBB06GXgw"},
+		{"Password", "$pbkdf2-sha256$i=80000$TmFDbA$TdzY9guYviGDDO5e8icB+WQaRBjQTAQUrv8Ih2s0q1ah1CWhIlgzVJrbhBtRybMXaicr3ruh0HhHj2Kzl/M8jQ"},
+	}
+	scryptRFCVectors = []struct{ password, encoded string }{
+		{"", "$scrypt$ln=4,r=1,p=1$$d9ZXYjhleyA7GcpCwYoEl/FrSETjB0ro39/6P+3iFEL80Aad7QlI+DJqdToPyB8X6NPg+y4NNijPNeIMONGJBg"},
+		{"password", "$scrypt$ln=10,r=8,p=16$TmFDbA$/bq+HJ00cgB4VucZDQHp/nxq18vII3gw53N2Y0s3MWIurzDZLiKjiG/xCSedmDDaxyevuUqD7m2DYMvfoswGQA"},
+	}
+)
+
+var (
+	testPBKDF2Params = PBKDF2Params{Iterations: MinPBKDF2Iterations, SaltLength: 16, KeyLength: 32}
+	testScryptParams = ScryptParams{N: MinScryptN, R: 8, P: 1, SaltLength: 16, KeyLength: 32}
+)
+
+func TestVerifyPBKDF2RFCVectors(t *testing.T) {
+	for _, tt := range pbkdf2RFCVectors {
+		if err := VerifyPBKDF2(tt.password, tt.encoded); err != nil {
+			t.Errorf("VerifyPBKDF2(%q) error = %v, want nil", tt.password, err)
+		}
+		if err := VerifyPBKDF2(tt.password+"x", tt.encoded); !errors.Is(err, ErrPasswordMismatch) {
+			t.Errorf("VerifyPBKDF2() with wrong password error = %v, want %v", err, ErrPasswordMismatch)
+		}
+	}
+}
+
+func TestVerifyScryptRFCVectors(t *testing.T) {
+	for _, tt := range scryptRFCVectors {
+		if err := VerifyScrypt(tt.password, tt.encoded); err != nil {
+			t.Errorf("VerifyScrypt(%q) error = %v, want nil", tt.password, err)
+		}
+		if err := VerifyScrypt(tt.password+"x", tt.encoded); !errors.Is(err, ErrPasswordMismatch) {
+			t.Errorf("VerifyScrypt() with wrong password error = %v, want %v", err, ErrPasswordMismatch)
+		}
+	}
+}
+
+func TestHashPBKDF2RoundTrip(t *testing.T) {
+	encoded, err := HashPBKDF2("Pässwørd-密码", testPBKDF2Params)
+	if err != nil {
+		t.Fatalf("HashPBKDF2() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$pbkdf2-sha256$i=100000$") {
+		t.Errorf("HashPBKDF2() = %q, want PHC pbkdf2-sha256 encoding", encoded)
+	}
+	if scheme, err := VerifyAny("Pässwørd-密码", encoded); scheme != SchemePBKDF2 || err != nil {
+		t.Errorf("VerifyAny() = %q, %v, want %q, nil", scheme, err, SchemePBKDF2)
+	}
+}
+
+func TestHashScryptRoundTrip(t *testing.T) {
+	encoded, err := HashScrypt("Pässwørd-密码", testScryptParams)
+	if err != nil {
+		t.Fatalf("HashScrypt() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$scrypt$ln=14,r=8,p=1$") {
+		t.Errorf("HashScrypt() = %q, want PHC scrypt encoding", encoded)
+	}
+	if scheme, err := VerifyAny("Pässwørd-密码", encoded); scheme != SchemeScrypt || err != nil {
+		t.Errorf("VerifyAny() = %q, %v, want %q, nil", scheme, err, SchemeScrypt)
+	}
+}
+
+func TestHashKDFRejectsWeakParameters(t *testing.T) {
+	for _, p := range []PBKDF2Params{
+		{Iterations: 1000, SaltLength: 16, KeyLength: 32},
+		{Iterations: MinPBKDF2Iterations, SaltLength: 4, KeyLength: 32},
+		{Iterations: MaxPBKDF2Iterations + 1, SaltLength: 16, KeyLength: 32},
+	} {
+		if _, err := HashPBKDF2("password", p); err == nil {
+			t.Errorf("HashPBKDF2(%+v) error = nil, want error", p)
+		}
+	}
+	for _, p := range []ScryptParams{
+		{N: 1024, R: 8, P: 1, SaltLength: 16, KeyLength: 32},
+		{N: MinScryptN + 1, R: 8, P: 1, SaltLength: 16, KeyLength: 32},
+		{N: MinScryptN, R: 0, P: 1, SaltLength: 16, KeyLength: 32},
+		{N: 1 << (MaxScryptLogN + 1), R: 1, P: 1, SaltLength: 16, KeyLength: 32},
+		{N: 1 << MaxScryptLogN, R: 9, P: 1, SaltLength: 16, KeyLength: 32},
+		{N: MinScryptN, R: 8, P: MaxScryptParallelism + 1, SaltLength: 16, KeyLength: 32},
+	} {
+		if _, err := HashScrypt("password", p); err == nil {
+			t.Errorf("HashScrypt(%+v) error = nil, want error", p)
+		}
+	}
+}
+
+func TestVerifyKDFMalformed(t *testing.T) {
+	for _, encoded := range []string{
+		"$pbkdf2-sha256$c2FsdA$VawE",
+		"$pbkdf2-sha256$i=0$c2FsdA$VawE",
+		"$pbkdf2-sha256$i=1$c2FsdA$",
+		"$pbkdf2-sha256$i=1$!!$VawE",
+		"$pbkdf2-sha256$i=10000001$c2FsdA$VawE",
+		"$pbkdf2-sha256$i=2147483647$c2FsdA$VawE",
+	} {
+		if err := VerifyPBKDF2("passwd", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("VerifyPBKDF2(%q) error = %v, want %v", encoded, err, ErrMalformedHash)
+		}
+	}
+	for _, encoded := range []string{
+		"$scrypt$r=8,p=1$TmFDbA$/bq+",
+		"$scrypt$ln=99,r=8,p=1$TmFDbA$/bq+",
+		"$scrypt$ln=10,r=8$TmFDbA$/bq+",
+		"$scrypt$ln=42,r=8,p=1$TmFDbA$/bq+",
+		"$scrypt$ln=21,r=1,p=1$TmFDbA$/bq+",
+		"$scrypt$ln=20,r=9,p=1$TmFDbA$/bq+",
+		"$scrypt$ln=4,r=1073741824,p=1$TmFDbA$/bq+",
+		"$scrypt$ln=10,r=8,p=17$TmFDbA$/bq+",
+	} {
+		if err := VerifyScrypt("password", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("VerifyScrypt(%q) error = %v, want %v", encoded, err, ErrMalformedHash)
+		}
+	}
+}
+
+// FuzzVerifyKDFLimits checks that costs above the Max limits are rejected before any work is done.
+// Costs within the limits are skipped, as running them is the slow path the limits bound.
+func FuzzVerifyKDFLimits(f *testing.F) {
+	f.Add(42, 8, 1, 1<<31-1)
+	f.Add(MaxScryptLogN, 9, 1, MaxPBKDF2Iterations+1)
+	f.Add(4, 1, MaxScryptParallelism+1, 1)
+	f.Fuzz(func(t *testing.T, ln, r, p, iterations int) {
+		if iterations > MaxPBKDF2Iterations {
+			encoded := fmt.Sprintf("$pbkdf2-sha256$i=%d$c2FsdA$VawE", iterations)
+			if err := VerifyPBKDF2("passwd", encoded); !errors.Is(err, ErrMalformedHash) {
+				t.Errorf("VerifyPBKDF2(%q) error = %v, want %v", encoded, err, ErrMalformedHash)
+			}
+		}
+		if checkScryptCost(ln, r, p) == nil {
+			t.Skip("within the limits")
+		}
+		encoded := fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$TmFDbA$/bq+", ln, r, p)
+		if err := VerifyScrypt("password", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("VerifyScrypt(%q) error = %v, want %v", encoded, err, ErrMalformedHash)
+		}
+	})
+}
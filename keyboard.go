@@ -0,0 +1,199 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"unicode"
+)
+
+// PatternKeyboardWalk is the Pattern kind of a run of adjacent keys such as "qwerty" or "1qaz".
+const PatternKeyboardWalk MatchKind = "keyboard_walk"
+
+// Names of the built-in keyboard layouts.
+const (
+	LayoutQWERTY = "qwerty"
+	LayoutKeypad = "keypad"
+)
+
+// usShifted maps the shifted symbols of a US keyboard to the key they are typed on.
+var usShifted = map[rune]rune{
+	'~': '`', '!': '1', '@': '2', '#': '3', '$': '4', '%': '5', '^': '6', '&': '7', '*': '8',
+	'(': '9', ')': '0', '_': '-', '+': '=', '{': '[', '}': ']', '|': '\\', ':': ';', '"': '\'',
+	'<': ',', '>': '.', '?': '/',
+}
+
+// keyboardLayout is a registered adjacency graph.
+type keyboardLayout struct {
+	name      string
+	adjacency map[rune]map[rune]bool
+}
+
+var (
+	keyboardLayoutsMu sync.RWMutex
+	keyboardLayouts   []keyboardLayout
+)
+
+func init() {
+	mustRegister := func(name string, adjacency map[rune][]rune) {
+		if err := RegisterKeyboardLayout(name, adjacency); err != nil {
+			panic(err)
+		}
+	}
+	mustRegister(LayoutQWERTY, staggeredAdjacency([]string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}, []float64{0, 1.5, 1.75, 2.25}))
+	mustRegister(LayoutKeypad, gridAdjacency([]string{" /*-", "789+", "456 ", "123 ", "0 . "}))
+}
+
+// RegisterKeyboardLayout adds or replaces a layout used for keyboard-walk detection. adjacency maps
+// every key to its neighbours; it is copied and the relation is made symmetric. Uppercase letters
+// and US shifted symbols are folded onto their base key before lookup unless the layout defines
+// them itself, so "!QAZ" walks the same keys as "1qaz".
+func RegisterKeyboardLayout(name string, adjacency map[rune][]rune) error {
+	if name == "" {
+		return errors.New("keyboard layout name must not be empty")
+	}
+	if len(adjacency) == 0 {
+		return errors.New("keyboard layout must define at least one key")
+	}
+
+	graph := make(map[rune]map[rune]bool, len(adjacency))
+	link := func(a, b rune) {
+		if graph[a] == nil {
+			graph[a] = make(map[rune]bool)
+		}
+		graph[a][b] = true
+	}
+	for key, neighbours := range adjacency {
+		for _, n := range neighbours {
+			if n != key {
+				link(key, n)
+				link(n, key)
+			}
+		}
+	}
+
+	keyboardLayoutsMu.Lock()
+	defer keyboardLayoutsMu.Unlock()
+	for i, layout := range keyboardLayouts {
+		if layout.name == name {
+			keyboardLayouts[i].adjacency = graph
+			return nil
+		}
+	}
+	keyboardLayouts = append(keyboardLayouts, keyboardLayout{name: name, adjacency: graph})
+	return nil
+}
+
+// staggeredAdjacency builds the adjacency of a keyboard whose rows are shifted right by offsets.
+// Keys are adjacent when they sit next to each other in a row or less than a key apart in the
+// rows directly above and below.
+func staggeredAdjacency(rows []string, offsets []float64) map[rune][]rune {
+	type key struct {
+		r   rune
+		row int
+		x   float64
+	}
+	var keys []key
+	for row, line := range rows {
+		for col, r := range []rune(line) {
+			keys = append(keys, key{r, row, float64(col) + offsets[row]})
+		}
+	}
+
+	adjacency := make(map[rune][]rune)
+	for _, a := range keys {
+		for _, b := range keys {
+			dx := math.Abs(a.x - b.x)
+			sameRow := a.row == b.row && dx == 1
+			nextRow := (a.row-b.row == 1 || b.row-a.row == 1) && dx < 1
+			if sameRow || nextRow {
+				adjacency[a.r] = append(adjacency[a.r], b.r)
+			}
+		}
+	}
+	return adjacency
+}
+
+// gridAdjacency builds the adjacency of a key grid, including diagonals. Spaces are empty cells.
+func gridAdjacency(rows []string) map[rune][]rune {
+	grid := make([][]rune, len(rows))
+	for i, line := range rows {
+		grid[i] = []rune(line)
+	}
+
+	adjacency := make(map[rune][]rune)
+	for y, row := range grid {
+		for x, r := range row {
+			if r == ' ' {
+				continue
+			}
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					ny, nx := y+dy, x+dx
+					if (dy == 0 && dx == 0) || ny < 0 || ny >= len(grid) || nx < 0 || nx >= len(grid[ny]) {
+						continue
+					}
+					if n := grid[ny][nx]; n != ' ' {
+						adjacency[r] = append(adjacency[r], n)
+					}
+				}
+			}
+		}
+	}
+	return adjacency
+}
+
+// unshift folds r onto the key it is typed on within the layout.
+func (layout keyboardLayout) unshift(r rune) rune {
+	if _, ok := layout.adjacency[r]; ok {
+		return r
+	}
+	if lower := unicode.ToLower(r); lower != r {
+		return lower
+	}
+	if base, ok := usShifted[r]; ok {
+		return base
+	}
+	return r
+}
+
+// detectKeyboardWalks returns every run of at least minLen runes where each rune is adjacent to
+// the previous one in a registered layout. A walk found by an earlier layout is not reported again.
+func detectKeyboardWalks(runes []rune, minLen int) []Match {
+	keyboardLayoutsMu.RLock()
+	defer keyboardLayoutsMu.RUnlock()
+
+	var patterns []Match
+	seen := make(map[[2]int]bool)
+	for _, layout := range keyboardLayouts {
+		for i := 0; i < len(runes); {
+			end := i + 1
+			for end < len(runes) && layout.adjacency[layout.unshift(runes[end-1])][layout.unshift(runes[end])] {
+				end++
+			}
+			span := [2]int{i, end}
+			if end-i >= minLen && !seen[span] {
+				seen[span] = true
+				patterns = append(patterns, Match{Kind: PatternKeyboardWalk, Token: string(runes[i:end]), Start: i, End: end})
+			}
+			i = end
+		}
+	}
+	return patterns
+}
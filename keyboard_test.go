@@ -0,0 +1,120 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDetectKeyboardWalks(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		minLen   int
+		want     []Match
+	}{
+		{
+			name:     "Row walk",
+			password: "qwerty!9",
+			minLen:   4,
+			want:     []Match{{Kind: PatternKeyboardWalk, Token: "qwerty", Start: 0, End: 6}},
+		},
+		{
+			name:     "Column walks",
+			password: "1qaz2wsx",
+			minLen:   4,
+			want: []Match{
+				{Kind: PatternKeyboardWalk, Token: "1qaz", Start: 0, End: 4},
+				{Kind: PatternKeyboardWalk, Token: "2wsx", Start: 4, End: 8},
+			},
+		},
+		{
+			name:     "Shifted keys",
+			password: "!QAZ@WSX",
+			minLen:   4,
+			want: []Match{
+				{Kind: PatternKeyboardWalk, Token: "!QAZ", Start: 0, End: 4},
+				{Kind: PatternKeyboardWalk, Token: "@WSX", Start: 4, End: 8},
+			},
+		},
+		{
+			name:     "Keypad walk",
+			password: "x7415963",
+			minLen:   4,
+			want:     []Match{{Kind: PatternKeyboardWalk, Token: "7415963", Start: 1, End: 8}},
+		},
+		{
+			name:     "Short walks are ignored",
+			password: "qwe-asd",
+			minLen:   4,
+		},
+		{
+			name:     "Scattered keys",
+			password: "Tr0ub4dor",
+			minLen:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectKeyboardWalks([]rune(tt.password), tt.minLen); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectKeyboardWalks(%q) = %+v, want %+v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterKeyboardLayout(t *testing.T) {
+	if err := RegisterKeyboardLayout("", map[rune][]rune{'a': {'b'}}); err == nil {
+		t.Error("RegisterKeyboardLayout() with empty name error = nil, want error")
+	}
+	if err := RegisterKeyboardLayout("empty", nil); err == nil {
+		t.Error("RegisterKeyboardLayout() with no keys error = nil, want error")
+	}
+
+	// A fragment of the Dvorak home row; only one direction is given and must be made symmetric.
+	if err := RegisterKeyboardLayout("test-dvorak", map[rune][]rune{
+		'a': {'o'}, 'o': {'e'}, 'e': {'u'}, 'u': {'i'},
+	}); err != nil {
+		t.Fatalf("RegisterKeyboardLayout() error = %v", err)
+	}
+	want := []Match{{Kind: PatternKeyboardWalk, Token: "Ueoa", Start: 0, End: 4}}
+	if got := detectKeyboardWalks([]rune("Ueoa"), 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("detectKeyboardWalks(%q) = %+v, want %+v", "Ueoa", got, want)
+	}
+}
+
+func TestAuditKeyboardWalks(t *testing.T) {
+	opts := Options{KeyboardWalkLength: 4}
+	result := Audit("zxcvbn1985", opts)
+	if result.Err != nil {
+		t.Fatalf("Audit() error = %v, want nil without RejectKeyboardWalks", result.Err)
+	}
+	if len(result.Patterns) != 2 || result.Patterns[0].Kind != PatternKeyboardWalk || result.Patterns[1].Kind != PatternYear {
+		t.Fatalf("Audit() Patterns = %+v, want one keyboard walk and the year", result.Patterns)
+	}
+	if plain := Audit("zxcvbn1985", Options{}); result.EffectiveEntropy >= plain.EffectiveEntropy {
+		t.Errorf("Audit() EffectiveEntropy = %.2f, want less than %.2f", result.EffectiveEntropy, plain.EffectiveEntropy)
+	}
+
+	opts.RejectKeyboardWalks = true
+	if result := Audit("zxcvbn1985", opts); !errors.Is(result.Err, ErrKeyboardWalk) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrKeyboardWalk)
+	}
+}
@@ -0,0 +1,149 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// LDAPScheme selects the userPassword scheme HashLDAP writes.
+type LDAPScheme int
+
+const (
+	LDAPSSHA   LDAPScheme = iota // Salted SHA-1, "{SSHA}", the slappasswd default
+	LDAPSHA                      // Unsalted SHA-1, "{SHA}", for legacy directories only
+	LDAPCrypt                    // crypt(3), "{CRYPT}$6$...", with sha512-crypt
+	LDAPArgon2                   // "{ARGON2}$argon2id$...", as written by the slapd argon2 module
+)
+
+var ldapSchemeNames = [...]string{"{SSHA}", "{SHA}", "{CRYPT}", "{ARGON2}"}
+
+// String returns the scheme's userPassword prefix, such as "{SSHA}".
+func (s LDAPScheme) String() string {
+	if s < 0 || int(s) >= len(ldapSchemeNames) {
+		return fmt.Sprintf("LDAPScheme(%d)", int(s))
+	}
+	return ldapSchemeNames[s]
+}
+
+// sshaSaltLength is the salt HashLDAP uses for {SSHA}; slappasswd uses 4 bytes, and any length
+// verifies.
+const sshaSaltLength = 8
+
+// UnknownLDAPSchemeError reports a userPassword value whose scheme VerifyLDAP does not support.
+// It wraps ErrUnknownHashScheme.
+type UnknownLDAPSchemeError struct {
+	Scheme string // The prefix found, such as "{MD5}", or "" when the value has none
+}
+
+func (e *UnknownLDAPSchemeError) Error() string {
+	return fmt.Sprintf("unknown LDAP password scheme %q, want one of %s", e.Scheme, strings.Join(ldapSchemeNames[:], ", "))
+}
+
+func (e *UnknownLDAPSchemeError) Unwrap() error { return ErrUnknownHashScheme }
+
+// HashLDAP returns password hashed for an LDAP userPassword attribute, prefixed by the scheme.
+// {SSHA} and {CRYPT} use a salt from crypto/rand, and {ARGON2} uses DefaultArgon2Params.
+func HashLDAP(password string, scheme LDAPScheme) (string, error) {
+	switch scheme {
+	case LDAPSSHA:
+		salt := make([]byte, sshaSaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		return ssha(password, salt), nil
+	case LDAPSHA:
+		pw := []byte(password)
+		defer wipe(pw)
+		sum := sha1.Sum(pw)
+		return scheme.String() + base64.StdEncoding.EncodeToString(sum[:]), nil
+	case LDAPCrypt:
+		salt, err := GenerateShadowSalt()
+		if err != nil {
+			return "", err
+		}
+		crypt, err := CryptSHA512(password, salt, 0)
+		return scheme.String() + crypt, err
+	case LDAPArgon2:
+		encoded, err := HashArgon2id(password, DefaultArgon2Params)
+		return scheme.String() + encoded, err
+	}
+	return "", fmt.Errorf("unsupported LDAP scheme %v", scheme)
+}
+
+// ssha returns the {SSHA} value: the base64 of the SHA-1 of password and salt, followed by salt.
+func ssha(password string, salt []byte) string {
+	pw := append([]byte(password), salt...)
+	defer wipe(pw)
+	sum := sha1.Sum(pw)
+	return LDAPSSHA.String() + base64.StdEncoding.EncodeToString(append(sum[:], salt...))
+}
+
+// VerifyLDAP checks password against an LDAP userPassword value. Scheme prefixes match without
+// regard to case, as slapd does. {CRYPT} values may use sha512-crypt ("$6$"), MD5-crypt ("$1$"),
+// or bcrypt; DES crypt is not supported. Unknown schemes return an *UnknownLDAPSchemeError.
+func VerifyLDAP(password, encoded string) error {
+	scheme, value := "", encoded
+	if strings.HasPrefix(encoded, "{") {
+		if end := strings.IndexByte(encoded, '}'); end > 0 {
+			scheme, value = strings.ToUpper(encoded[:end+1]), encoded[end+1:]
+		}
+	}
+	switch scheme {
+	case LDAPSSHA.String(), LDAPSHA.String():
+		raw, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrMalformedHash, scheme, err)
+		}
+		if len(raw) < sha1.Size || scheme == LDAPSHA.String() && len(raw) != sha1.Size {
+			return fmt.Errorf("%w: %s digest is %d bytes", ErrMalformedHash, scheme, len(raw))
+		}
+		pw := append([]byte(password), raw[sha1.Size:]...)
+		sum := sha1.Sum(pw)
+		wipe(pw)
+		if subtle.ConstantTimeCompare(sum[:], raw[:sha1.Size]) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	case LDAPCrypt.String():
+		switch {
+		case strings.HasPrefix(value, sha512CryptPrefix):
+			return VerifySHA512Crypt(password, value)
+		case strings.HasPrefix(value, md5CryptPrefix):
+			salt, _, ok := strings.Cut(strings.TrimPrefix(value, md5CryptPrefix), "$")
+			if !ok {
+				return fmt.Errorf("%w: want $1$salt$hash", ErrMalformedHash)
+			}
+			if subtle.ConstantTimeCompare([]byte(md5Crypt(password, salt, md5CryptPrefix)), []byte(value)) != 1 {
+				return ErrPasswordMismatch
+			}
+			return nil
+		case hashScheme(value) == SchemeBcrypt:
+			_, err := VerifyAny(password, value)
+			return err
+		}
+		return fmt.Errorf("%w: unsupported {CRYPT} format", ErrUnknownHashScheme)
+	case LDAPArgon2.String():
+		return VerifyArgon2id(password, value)
+	}
+	return &UnknownLDAPSchemeError{Scheme: scheme}
+}
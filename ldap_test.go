@@ -0,0 +1,116 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The {SSHA} and {SHA} vectors are base64(SHA1(password + salt) + salt), the layout slappasswd
+// writes; the {CRYPT} vectors come from "openssl passwd -1" and "openssl passwd -6".
+var ldapVectors = []struct {
+	password string
+	encoded  string
+}{
+	{"secret", "{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME"},
+	{"password", "{SSHA}w/ljm7IDvaUnGYzzX1x2qY+bj+iaezxtXk8QKQ=="},
+	{"password", "{ssha}w/ljm7IDvaUnGYzzX1x2qY+bj+iaezxtXk8QKQ=="},
+	{"password", "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="},
+	{"password", "{CRYPT}$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+	{"password", "{CRYPT}$6$saltsalt$qFmFH.bQmmtXzyBY0s9v7Oicd2z4XSIecDzlB5KiA2/jctKu9YterLp8wwnSq.qc.eoxqOmSuNp2xS0ktL3nh/"},
+	{"password", "{ARGON2}$argon2id$v=19$m=256,t=2,p=1$c29tZXNhbHQ$nf65EOgLrQMR/uIPnA4rEsF5h7TKyQwu9U1bMCHGi/4"},
+}
+
+func TestVerifyLDAPVectors(t *testing.T) {
+	for _, v := range ldapVectors {
+		t.Run(v.encoded, func(t *testing.T) {
+			if err := VerifyLDAP(v.password, v.encoded); err != nil {
+				t.Errorf("VerifyLDAP() = %v", err)
+			}
+			if err := VerifyLDAP(v.password+"x", v.encoded); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("VerifyLDAP(wrong) = %v, want ErrPasswordMismatch", err)
+			}
+		})
+	}
+}
+
+func TestSSHAVector(t *testing.T) {
+	if got, want := ssha("secret", []byte{1, 2, 3, 4}), "{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME"; got != want {
+		t.Errorf("ssha() = %q, want %q", got, want)
+	}
+}
+
+func TestHashLDAP(t *testing.T) {
+	const password = "Pässwørd-密码"
+
+	for _, scheme := range []LDAPScheme{LDAPSSHA, LDAPSHA, LDAPCrypt, LDAPArgon2} {
+		t.Run(scheme.String(), func(t *testing.T) {
+			encoded, err := HashLDAP(password, scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(encoded, scheme.String()) {
+				t.Errorf("HashLDAP() = %q, want prefix %s", encoded, scheme)
+			}
+			if err := VerifyLDAP(password, encoded); err != nil {
+				t.Errorf("VerifyLDAP(HashLDAP()) = %v", err)
+			}
+		})
+	}
+	if _, err := HashLDAP(password, LDAPScheme(9)); err == nil {
+		t.Error("HashLDAP() with an unknown scheme succeeded")
+	}
+	a, _ := HashLDAP(password, LDAPSSHA)
+	b, _ := HashLDAP(password, LDAPSSHA)
+	if a == b {
+		t.Error("HashLDAP({SSHA}) reused a salt")
+	}
+}
+
+func TestVerifyLDAPErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    error
+	}{
+		{"Unknown scheme", "{MD5}X03MO1qnZdYdgyfeuILPmQ==", ErrUnknownHashScheme},
+		{"No scheme", "password", ErrUnknownHashScheme},
+		{"Bad base64", "{SSHA}not base64!", ErrMalformedHash},
+		{"Short digest", "{SSHA}AQIDBA==", ErrMalformedHash},
+		{"Salted {SHA}", "{SHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME", ErrMalformedHash},
+		{"DES crypt", "{CRYPT}abJnggxhB/yJU", ErrUnknownHashScheme},
+		{"Malformed argon2", "{ARGON2}$argon2id$v=19$", ErrMalformedHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyLDAP("password", tt.encoded); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyLDAP() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	var schemeErr *UnknownLDAPSchemeError
+	err := VerifyLDAP("password", "{md5}X03MO1qnZdYdgyfeuILPmQ==")
+	if !errors.As(err, &schemeErr) || schemeErr.Scheme != "{MD5}" {
+		t.Fatalf("VerifyLDAP() = %v, want an *UnknownLDAPSchemeError for {MD5}", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "{SSHA}, {SHA}, {CRYPT}, {ARGON2}") {
+		t.Errorf("Error() = %q, want it to list the supported schemes", msg)
+	}
+}
@@ -0,0 +1,128 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"strings"
+)
+
+// PatternLeet is the Pattern kind of a dictionary or breached password disguised with character
+// substitutions such as "P@ssw0rd". Base holds the decoded word.
+const PatternLeet MatchKind = "leet"
+
+// MaxLeetCandidates bounds the number of decoded spellings tried per password so long passwords
+// full of ambiguous substitutions stay cheap.
+const MaxLeetCandidates = 32
+
+// maxLeetBreachChecks bounds the decoded spellings sent to Options.BreachChecker, which is usually
+// a network call.
+const maxLeetBreachChecks = 3
+
+// LeetSubstitutions maps a substituted character to the letters it may stand for, most likely
+// first. Extend or replace it before auditing; it is read without locking.
+var LeetSubstitutions = map[rune][]rune{
+	'4': {'a'},
+	'@': {'a'},
+	'8': {'b'},
+	'(': {'c'},
+	'<': {'c'},
+	'{': {'c'},
+	'3': {'e'},
+	'6': {'g'},
+	'9': {'g'},
+	'#': {'h'},
+	'1': {'l', 'i'},
+	'!': {'i', 'l'},
+	'|': {'l', 'i'},
+	'0': {'o'},
+	'$': {'s'},
+	'5': {'s'},
+	'7': {'t'},
+	'+': {'t'},
+	'%': {'x'},
+	'2': {'z'},
+}
+
+// leetCandidates returns up to limit decoded spellings of the lowercased word, most substituted
+// first. The word itself is never included.
+func leetCandidates(word string, limit int) []string {
+	runes := []rune(word)
+	candidates := [][]rune{runes}
+	for i, r := range runes {
+		subs, ok := LeetSubstitutions[r]
+		if !ok {
+			continue
+		}
+		next := make([][]rune, 0, limit)
+		for _, c := range candidates {
+			for _, s := range subs {
+				if len(next) == limit {
+					break
+				}
+				decoded := append([]rune(nil), c...)
+				decoded[i] = s
+				next = append(next, decoded)
+			}
+		}
+		// Keep the character as written too: "!" is as often a symbol as an "i".
+		for _, c := range candidates {
+			if len(next) == limit {
+				break
+			}
+			next = append(next, c)
+		}
+		candidates = next
+	}
+
+	out := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if s := string(c); s != word {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// leetDictionaryMatch decodes the password and reports the first spelling found in an enabled
+// dictionary as a leet pattern.
+func (v *Validator) leetDictionaryMatch(pass string) (Match, bool) {
+	for _, base := range dictionaryCandidates(pass) {
+		for _, candidate := range leetCandidates(base, MaxLeetCandidates) {
+			if v.containsWord(candidate) {
+				return prefixMatch(PatternLeet, pass, candidate), true
+			}
+		}
+	}
+	return Match{}, false
+}
+
+// leetBreachMatch sends the most likely decoded spellings to checker and returns the
+// first one reported as breached with its count.
+func leetBreachMatch(ctx context.Context, checker BreachChecker, pass string) (Match, int, error) {
+	candidates := leetCandidates(strings.ToLower(pass), maxLeetBreachChecks)
+	for _, candidate := range candidates {
+		breached, count, err := checker.IsBreached(ctx, candidate)
+		if err != nil {
+			return Match{}, 0, err
+		}
+		if breached {
+			return prefixMatch(PatternLeet, pass, candidate), count, nil
+		}
+	}
+	return Match{}, 0, nil
+}
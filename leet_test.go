@@ -0,0 +1,127 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLeetCandidates(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		want []string
+	}{
+		{
+			name: "Single spelling first",
+			word: "p@ssw0rd",
+			want: []string{"password", "p@ssword", "passw0rd"},
+		},
+		{
+			name: "Ambiguous substitution",
+			word: "1ce",
+			want: []string{"lce", "ice"},
+		},
+		{
+			name: "Nothing to decode",
+			word: "plain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := leetCandidates(tt.word, MaxLeetCandidates)
+			if len(got) < len(tt.want) {
+				t.Fatalf("leetCandidates(%q) = %q, want prefix %q", tt.word, got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i] != want {
+					t.Errorf("leetCandidates(%q)[%d] = %q, want %q", tt.word, i, got[i], want)
+				}
+			}
+			if len(tt.want) == 0 && len(got) != 0 {
+				t.Errorf("leetCandidates(%q) = %q, want none", tt.word, got)
+			}
+		})
+	}
+}
+
+func TestLeetCandidatesBounded(t *testing.T) {
+	word := strings.Repeat("1!|", 20)
+	if got := leetCandidates(word, MaxLeetCandidates); len(got) > MaxLeetCandidates {
+		t.Errorf("leetCandidates() returned %d candidates, want at most %d", len(got), MaxLeetCandidates)
+	}
+}
+
+func TestAuditNormalizeLeet(t *testing.T) {
+	options := Options{RejectCommon: true}
+	if result := Audit("P@ssw0rd!", options); result.Err != nil {
+		t.Fatalf("Audit() without NormalizeLeet error = %v, want nil", result.Err)
+	}
+
+	options.NormalizeLeet = true
+	result := Audit("P@ssw0rd!", options)
+	if !errors.Is(result.Err, ErrCommonPassword) {
+		t.Fatalf("Audit() error = %v, want %v", result.Err, ErrCommonPassword)
+	}
+	want := Match{Kind: PatternLeet, Token: "P@ssw0rd", Start: 0, End: 8, Base: "password", Penalty: result.CharsetEntropy - compromisedEntropy}
+	if len(result.Patterns) != 1 || result.Patterns[0] != want {
+		t.Errorf("Audit() Patterns = %+v, want [%+v]", result.Patterns, want)
+	}
+}
+
+func TestAuditNormalizeLeetCustomTable(t *testing.T) {
+	saved := LeetSubstitutions['¥']
+	LeetSubstitutions['¥'] = []rune{'y'}
+	defer func() {
+		if saved == nil {
+			delete(LeetSubstitutions, '¥')
+		} else {
+			LeetSubstitutions['¥'] = saved
+		}
+	}()
+
+	options := Options{Dictionary: NewWordList("yankees"), NormalizeLeet: true}
+	if result := Audit("¥ankees", options); !errors.Is(result.Err, ErrCommonPassword) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrCommonPassword)
+	}
+}
+
+func TestAuditNormalizeLeetBreachChecker(t *testing.T) {
+	var asked []string
+	breach := BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+		asked = append(asked, password)
+		return password == "monkey", 42, nil
+	})
+
+	result := Audit("m0nk3y", Options{BreachChecker: breach, NormalizeLeet: true})
+	if !errors.Is(result.Err, ErrPwnedPassword) {
+		t.Fatalf("Audit() error = %v, want %v", result.Err, ErrPwnedPassword)
+	}
+	if result.PwnedCount != 42 {
+		t.Errorf("Audit() PwnedCount = %d, want 42", result.PwnedCount)
+	}
+	if len(asked) > maxLeetBreachChecks+1 {
+		t.Errorf("BreachChecker asked %d times, want at most %d", len(asked), maxLeetBreachChecks+1)
+	}
+	if len(result.Patterns) != 1 || result.Patterns[0].Base != "monkey" {
+		t.Errorf("Audit() Patterns = %+v, want a leet match for monkey", result.Patterns)
+	}
+}
@@ -0,0 +1,108 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// LogMessage is the message of the record Options.Logger receives for each audit.
+const LogMessage = "password audit"
+
+// logAudit logs the outcome of audit on opts.Logger at opts.LogLevel: the policy name, how long
+// it took since start, whether the password is strong, the violation codes, the effective entropy
+// rounded down to ReportEntropyBucketBits, whether a breach check found it, and the name and
+// message of each failed custom rule. The codes and numbers come from closed sets; every other
+// key and value is scanned for pass by guardAttrs before it is logged.
+func (v *Validator) logAudit(ctx context.Context, pass string, audit *Result, start time.Time) {
+	logger := v.opts.Logger
+	if logger == nil || !logger.Enabled(ctx, v.opts.LogLevel) {
+		return
+	}
+
+	var rules []any
+	for _, errs := range [][]error{audit.Violations, audit.Warnings} {
+		for _, err := range errs {
+			var rerr *RuleError
+			if errors.As(err, &rerr) {
+				rules = append(rules, slog.String(rerr.Rule, fmt.Sprint(rerr.Err)))
+			}
+		}
+	}
+	scanned := []slog.Attr{slog.String("policy", audit.Policy)}
+	if len(rules) > 0 {
+		scanned = append(scanned, slog.Group("custom_rules", rules...))
+	}
+	scanned, leaked := guardAttrs(scanned, pass)
+
+	attrs := append(scanned,
+		slog.Duration("duration", time.Since(start)),
+		slog.Bool("strong", audit.Strong),
+		slog.Any("violations", violationCodes(audit.Violations)),
+		slog.Int("entropy_bucket", int(max(audit.EffectiveEntropy, 0))/ReportEntropyBucketBits*ReportEntropyBucketBits),
+		slog.Bool("pwned", audit.PwnedCount > 0),
+	)
+	if leaked {
+		attrs = append(attrs, slog.Bool("redacted", true))
+	}
+	logger.LogAttrs(ctx, v.opts.LogLevel, LogMessage, attrs...)
+}
+
+// panicOnLeak makes guardAttrs panic instead of redacting, so a test that leaks a password into
+// a log fails loudly. Only the package's own tests set it; applications always get redaction.
+var panicOnLeak bool
+
+// guardAttrs returns attrs with every occurrence of pass in a key or value, within groups too,
+// replaced by "[REDACTED]", and whether it found one. With panicOnLeak it panics instead.
+func guardAttrs(attrs []slog.Attr, pass string) ([]slog.Attr, bool) {
+	if pass == "" {
+		return attrs, false
+	}
+	leaked := false
+	clean := func(s string) string {
+		if !strings.Contains(s, pass) {
+			return s
+		}
+		if panicOnLeak {
+			panic(fmt.Sprintf("go_passwd: the audited password would be logged in %q", strings.ReplaceAll(s, pass, redacted)))
+		}
+		leaked = true
+		return strings.ReplaceAll(s, pass, redacted)
+	}
+
+	var guard func(attrs []slog.Attr) []slog.Attr
+	guard = func(attrs []slog.Attr) []slog.Attr {
+		out := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			value := a.Value.Resolve()
+			switch value.Kind() {
+			case slog.KindGroup:
+				value = slog.GroupValue(guard(value.Group())...)
+			case slog.KindString, slog.KindAny:
+				value = slog.StringValue(clean(value.String()))
+			}
+			out[i] = slog.Attr{Key: clean(a.Key), Value: value}
+		}
+		return out
+	}
+	return guard(attrs), leaked
+}
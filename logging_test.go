@@ -0,0 +1,152 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// jsonLogger returns a Logger writing JSON records at level and above to a buffer.
+func jsonLogger(level slog.Level) (*slog.Logger, *bytes.Buffer) {
+	var out bytes.Buffer
+	return slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: level})), &out
+}
+
+// The package's tests run with the leak guard panicking, so any of them that logs a password fails.
+func init() { panicOnLeak = true }
+
+func TestLogAuditOutcome(t *testing.T) {
+	logger, out := jsonLogger(slog.LevelDebug)
+	opts := Options{MinLength: 12, UseDigits: true, PolicyName: "staging", Logger: logger, LogLevel: slog.LevelDebug}
+	Audit("sunshine", opts)
+
+	var record map[string]any
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("log = %q: %v", out, err)
+	}
+	want := map[string]any{"msg": LogMessage, "level": "DEBUG", "policy": "staging", "strong": false, "pwned": false, "entropy_bucket": 30.0}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("record[%q] = %v, want %v", key, record[key], value)
+		}
+	}
+	if codes, _ := record["violations"].([]any); len(codes) != 2 || codes[0] != CodeTooShort || codes[1] != CodeMissingDigit {
+		t.Errorf("violations = %v, want too_short and missing_digit", record["violations"])
+	}
+	if _, ok := record["duration"]; !ok || record["redacted"] != nil {
+		t.Errorf("record = %v, want a duration and nothing redacted", record)
+	}
+	if strings.Contains(out.String(), "sunshine") {
+		t.Errorf("log contains the password: %s", out)
+	}
+}
+
+func TestLogAuditSilentByDefault(t *testing.T) {
+	logger, out := jsonLogger(slog.LevelInfo)
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"No logger", Options{MinLength: 12}},
+		{"Level below the handler's", Options{MinLength: 12, Logger: logger, LogLevel: slog.LevelDebug}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Audit("sunshine", tt.opts)
+			if out.Len() != 0 {
+				t.Errorf("log = %s, want nothing", out)
+			}
+		})
+	}
+}
+
+// leakyRule copies the password into its error, as a careless custom rule might.
+var leakyRule = NewRule("no-company-name", func(password string) error {
+	if strings.Contains(strings.ToLower(password), "acme") {
+		return errors.New("password " + password + " contains the company name")
+	}
+	return nil
+})
+
+func TestLogAuditRedactsCustomRule(t *testing.T) {
+	panicOnLeak = false
+	defer func() { panicOnLeak = true }()
+
+	logger, out := jsonLogger(slog.LevelInfo)
+	res := Audit("Acme-Rocket-2024", Options{CustomRules: []Rule{leakyRule}, Logger: logger})
+	if res.Err == nil {
+		t.Fatal("Audit() error = nil, want the custom rule to fail")
+	}
+	if strings.Contains(out.String(), "Acme-Rocket-2024") {
+		t.Errorf("log contains the password: %s", out)
+	}
+	var record struct {
+		Rules    map[string]string `json:"custom_rules"`
+		Redacted bool              `json:"redacted"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := record.Rules["no-company-name"], "password [REDACTED] contains the company name"; got != want || !record.Redacted {
+		t.Errorf("custom_rules = %v, redacted = %v, want %q", record.Rules, record.Redacted, want)
+	}
+}
+
+func TestLogAuditPanicsOnLeakInTests(t *testing.T) {
+	if !panicOnLeak {
+		t.Fatal("panicOnLeak = false under go test")
+	}
+	defer func() {
+		if r := recover(); r == nil || strings.Contains(r.(string), "Acme-Rocket-2024") {
+			t.Errorf("recover() = %v, want a panic that does not repeat the password", r)
+		}
+	}()
+	logger, _ := jsonLogger(slog.LevelInfo)
+	Audit("Acme-Rocket-2024", Options{CustomRules: []Rule{leakyRule}, Logger: logger})
+}
+
+func TestGuardAttrs(t *testing.T) {
+	panicOnLeak = false
+	defer func() { panicOnLeak = true }()
+
+	attrs, leaked := guardAttrs([]slog.Attr{
+		slog.String("clean", "nothing here"),
+		slog.Group("nested", slog.String("hunter2", "value"), slog.Any("list", []string{"a", "xhunter2x"})),
+		slog.Int("n", 2),
+	}, "hunter2")
+	got := slog.GroupValue(attrs...).String()
+	if !leaked || strings.Contains(got, "hunter2") || !strings.Contains(got, "nothing here") {
+		t.Errorf("guardAttrs() = %s, %v, want the password redacted from keys and values", got, leaked)
+	}
+	if _, leaked := guardAttrs([]slog.Attr{slog.String("policy", "x")}, ""); leaked {
+		t.Error("guardAttrs() with an empty password reported a leak")
+	}
+}
+
+func TestLogAuditContext(t *testing.T) {
+	logger, out := jsonLogger(slog.LevelInfo)
+	AuditForUser(context.Background(), "alice2024!", UserContext{Username: "alice"}, Options{Logger: logger})
+	if !strings.Contains(out.String(), CodeUserInput) || strings.Contains(out.String(), "alice2024") {
+		t.Errorf("log = %s, want user_input without the password", out)
+	}
+}
@@ -0,0 +1,269 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// markovMagic starts every serialized MarkovModel, followed by markovVersion.
+const markovMagic = "GPMM"
+
+// markovVersion is the serialization format MarkovModel writes. Version 1 is the magic, a version
+// byte, the number of passwords trained on and of trigrams as uint64s, and each trigram as three
+// int32 runes and a uint64 count, all little-endian and sorted by runes.
+const markovVersion = 1
+
+// markovStart pads the two runes before a password and markovEnd follows its last rune, so the
+// model learns how passwords begin and end.
+const (
+	markovStart rune = -1
+	markovEnd   rune = -2
+)
+
+// markovUnseenRunes is the number of runes the model reserves probability for beyond the ones it
+// was trained on, which prices a rune it never saw as one of that many more.
+const markovUnseenRunes = 1 << 16
+
+// MarkovModel is an order-2 character model of how people write passwords: the probability of
+// each rune given the two before it, learned from a corpus of real passwords. Runes are compared
+// lowercased and each uppercase rune costs one more bit. Contexts and runes the corpus lacks back
+// off to shorter contexts and finally to a uniform guess among 65,536 unseen runes, so any password
+// has a probability. A model is safe for concurrent use once built.
+type MarkovModel struct {
+	trigrams   map[[3]rune]uint64 // Times the third rune followed the first two
+	bigrams    map[[2]rune]uint64 // Times the second rune followed the first
+	contexts   map[[2]rune]uint64 // Times the two runes were followed by anything
+	preceding  map[rune]uint64    // Times the rune was followed by anything
+	followers2 map[[2]rune]uint64 // Distinct runes seen after the two runes
+	followers1 map[rune]uint64    // Distinct runes seen after the rune
+	unigrams   map[rune]uint64    // Times the rune appeared, markovEnd included
+	total      uint64             // Runes seen, markovEnd included
+	passwords  uint64             // Passwords trained on
+	alphabet   float64            // len(unigrams) + markovUnseenRunes
+}
+
+// TrainMarkov builds a MarkovModel from r, one password per line with "\n" or "\r\n" endings.
+// Blank lines are skipped.
+func TrainMarkov(r io.Reader) (*MarkovModel, error) {
+	m := newMarkovModel()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			m.train(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	m.finish()
+	return m, nil
+}
+
+func newMarkovModel() *MarkovModel {
+	return &MarkovModel{
+		trigrams:   make(map[[3]rune]uint64),
+		bigrams:    make(map[[2]rune]uint64),
+		contexts:   make(map[[2]rune]uint64),
+		preceding:  make(map[rune]uint64),
+		followers2: make(map[[2]rune]uint64),
+		followers1: make(map[rune]uint64),
+		unigrams:   make(map[rune]uint64),
+	}
+}
+
+// train counts the trigrams of pass.
+func (m *MarkovModel) train(pass string) {
+	a, b := markovStart, markovStart
+	for _, r := range pass {
+		r = unicode.ToLower(r)
+		m.add([3]rune{a, b, r}, 1)
+		a, b = b, r
+	}
+	m.add([3]rune{a, b, markovEnd}, 1)
+	m.passwords++
+}
+
+// add counts n occurrences of trigram t and of the shorter n-grams it ends with.
+func (m *MarkovModel) add(t [3]rune, n uint64) {
+	if m.trigrams[t] == 0 {
+		m.followers2[[2]rune{t[0], t[1]}]++
+	}
+	if m.bigrams[[2]rune{t[1], t[2]}] == 0 {
+		m.followers1[t[1]]++
+	}
+	m.trigrams[t] += n
+	m.bigrams[[2]rune{t[1], t[2]}] += n
+	m.contexts[[2]rune{t[0], t[1]}] += n
+	m.preceding[t[1]] += n
+	m.unigrams[t[2]] += n
+	m.total += n
+}
+
+// finish derives what LogProb needs once every trigram is counted.
+func (m *MarkovModel) finish() {
+	m.alphabet = float64(len(m.unigrams) + markovUnseenRunes)
+}
+
+// prob returns the probability of r after a and b with Witten-Bell smoothing: a context backs off
+// to the shorter one in proportion to how many different runes it was seen followed by, so a
+// context with varied continuations expects new ones more than a predictable one does.
+func (m *MarkovModel) prob(a, b, r rune) float64 {
+	p := (float64(m.unigrams[r]) + 1) / (float64(m.total) + m.alphabet)
+	p = wittenBell(m.bigrams[[2]rune{b, r}], m.preceding[b], m.followers1[b], p)
+	return wittenBell(m.trigrams[[3]rune{a, b, r}], m.contexts[[2]rune{a, b}], m.followers2[[2]rune{a, b}], p)
+}
+
+// wittenBell interpolates count out of total observations of a context with distinct followers
+// and the backoff probability lower.
+func wittenBell(count, total, distinct uint64, lower float64) float64 {
+	if total == 0 {
+		return lower
+	}
+	return (float64(count) + float64(distinct)*lower) / float64(total+distinct)
+}
+
+// LogProb returns the base 2 logarithm of the probability of pass under the model, so its negation
+// is the information in the password in bits.
+func (m *MarkovModel) LogProb(pass string) float64 {
+	logp := 0.0
+	a, b := markovStart, markovStart
+	for _, r := range pass {
+		if lower := unicode.ToLower(r); lower != r {
+			logp--
+			r = lower
+		}
+		logp += math.Log2(m.prob(a, b, r))
+		a, b = b, r
+	}
+	return logp + math.Log2(m.prob(a, b, markovEnd))
+}
+
+// Len returns the number of passwords the model was trained on.
+func (m *MarkovModel) Len() uint64 {
+	return m.passwords
+}
+
+var (
+	defaultMarkovOnce  sync.Once
+	defaultMarkovModel *MarkovModel
+)
+
+// DefaultMarkovModel returns the model trained on the embedded common password list on first use.
+func DefaultMarkovModel() *MarkovModel {
+	defaultMarkovOnce.Do(func() {
+		defaultMarkovModel, _ = TrainMarkov(strings.NewReader(commonPasswordsData))
+	})
+	return defaultMarkovModel
+}
+
+// MarkovLogProb returns the base 2 logarithm of the probability of pass under DefaultMarkovModel.
+func MarkovLogProb(pass string) float64 {
+	return DefaultMarkovModel().LogProb(pass)
+}
+
+// WriteTo writes the model in the format NewMarkovFromReader reads, so a model trained once on a
+// large corpus can be shipped with an application.
+func (m *MarkovModel) WriteTo(w io.Writer) (int64, error) {
+	trigrams := make([][3]rune, 0, len(m.trigrams))
+	for t := range m.trigrams {
+		trigrams = append(trigrams, t)
+	}
+	slices.SortFunc(trigrams, func(x, y [3]rune) int {
+		return cmp.Or(cmp.Compare(x[0], y[0]), cmp.Compare(x[1], y[1]), cmp.Compare(x[2], y[2]))
+	})
+
+	bw := bufio.NewWriter(w)
+	var header [len(markovMagic) + 1 + 8 + 8]byte
+	copy(header[:], markovMagic)
+	header[4] = markovVersion
+	binary.LittleEndian.PutUint64(header[5:], m.passwords)
+	binary.LittleEndian.PutUint64(header[13:], uint64(len(trigrams)))
+	written, err := bw.Write(header[:])
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+	var entry [3*4 + 8]byte
+	for _, t := range trigrams {
+		for i, r := range t {
+			binary.LittleEndian.PutUint32(entry[4*i:], uint32(r))
+		}
+		binary.LittleEndian.PutUint64(entry[12:], m.trigrams[t])
+		written, err := bw.Write(entry[:])
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, bw.Flush()
+}
+
+// NewMarkovFromReader loads a model written by MarkovModel.WriteTo. It fails with
+// ErrMalformedMarkov for data that is not a model and ErrUnsupportedMarkovVersion for a format
+// this release cannot read.
+func NewMarkovFromReader(r io.Reader) (*MarkovModel, error) {
+	br := bufio.NewReader(r)
+	var header [len(markovMagic) + 1 + 8 + 8]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformedMarkov, err)
+	}
+	if string(header[:4]) != markovMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrMalformedMarkov, header[:4])
+	}
+	if header[4] != markovVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedMarkovVersion, header[4])
+	}
+	m := newMarkovModel()
+	m.passwords = binary.LittleEndian.Uint64(header[5:])
+	n := binary.LittleEndian.Uint64(header[13:])
+	var entry [3*4 + 8]byte
+	for i := uint64(0); i < n; i++ {
+		if _, err := io.ReadFull(br, entry[:]); err != nil {
+			return nil, fmt.Errorf("%w: trigram %d: %v", ErrMalformedMarkov, i, err)
+		}
+		var t [3]rune
+		for j := range t {
+			t[j] = rune(binary.LittleEndian.Uint32(entry[4*j:]))
+		}
+		count := binary.LittleEndian.Uint64(entry[12:])
+		if !validTrigram(t) || count == 0 {
+			return nil, fmt.Errorf("%w: trigram %d is %q with count %d", ErrMalformedMarkov, i, t, count)
+		}
+		m.add(t, count)
+	}
+	m.finish()
+	return m, nil
+}
+
+// validTrigram reports whether t could have been counted by train: runes after markovStart
+// padding, ending in a rune or markovEnd.
+func validTrigram(t [3]rune) bool {
+	isRune := func(r rune) bool { return r >= 0 && r <= unicode.MaxRune }
+	return (t[0] == markovStart || isRune(t[0])) &&
+		(t[1] == markovStart && t[0] == markovStart || isRune(t[1])) &&
+		(t[2] == markovEnd || isRune(t[2]))
+}
@@ -0,0 +1,176 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestMarkovLogProbPinned catches changes to the embedded model or its smoothing. Update the
+// values deliberately when either changes.
+func TestMarkovLogProbPinned(t *testing.T) {
+	tests := []struct {
+		pass string
+		want float64
+	}{
+		{"password", -22.49},
+		{"Password", -23.49},
+		{"iloveyou", -28.51},
+		{"Iloveyou1!", -56.86},
+		{"qwerty123", -26.97},
+		{"Xqj9#mB2", -80.63},
+		{"Tr0ub4dor&3", -109.63},
+		{"", -19.11}, // No password in the corpus is empty
+	}
+	for _, tt := range tests {
+		if got := MarkovLogProb(tt.pass); math.Abs(got-tt.want) > 0.005 {
+			t.Errorf("MarkovLogProb(%q) = %.2f, want %.2f", tt.pass, got, tt.want)
+		}
+	}
+}
+
+func TestAuditUseMarkov(t *testing.T) {
+	tests := []struct {
+		pass                 string
+		wantEntropy          float64
+		wantScore, wantPlain int
+	}{
+		{"Iloveyou1!", 56.86, 64, 69},
+		{"qwerty123", 26.97, 35, 47},
+		{"Xqj9#mB2", 52.31, 59, 59}, // Random characters keep their charset entropy
+	}
+	for _, tt := range tests {
+		result := Audit(tt.pass, Options{UseMarkov: true})
+		if math.Abs(result.EffectiveEntropy-tt.wantEntropy) > 0.005 || result.Score != tt.wantScore {
+			t.Errorf("Audit(%q) = %.2f bits, score %d, want %.2f bits, score %d", tt.pass, result.EffectiveEntropy, result.Score, tt.wantEntropy, tt.wantScore)
+		}
+		if plain := Audit(tt.pass, Options{}); plain.Score != tt.wantPlain {
+			t.Errorf("Audit(%q) without UseMarkov score = %d, want %d", tt.pass, plain.Score, tt.wantPlain)
+		}
+	}
+
+	model, err := TrainMarkov(strings.NewReader("xqj9#mb2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := Audit("Xqj9#mB2", Options{UseMarkov: true, MarkovModel: model})
+	if custom.EffectiveEntropy >= 10 {
+		t.Errorf("Audit() with a model trained on the password = %.2f bits, want under 10", custom.EffectiveEntropy)
+	}
+}
+
+func TestTrainMarkov(t *testing.T) {
+	m, err := TrainMarkov(strings.NewReader("monkey\r\nmonkey1\n\nmonday\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", m.Len())
+	}
+	if trained, reversed := m.LogProb("monkey"), m.LogProb("yeknom"); trained <= reversed {
+		t.Errorf("LogProb(monkey) = %.2f, want more likely than LogProb(yeknom) = %.2f", trained, reversed)
+	}
+	if lower, upper := m.LogProb("monkey"), m.LogProb("Monkey"); math.Abs(lower-upper-1) > 1e-9 {
+		t.Errorf("LogProb(Monkey) = %.2f, want one bit less than %.2f", upper, lower)
+	}
+	for _, pass := range []string{"жук", "🐒🐒", "\x00"} {
+		if logp := m.LogProb(pass); math.IsInf(logp, 0) || math.IsNaN(logp) || logp >= 0 {
+			t.Errorf("LogProb(%q) = %v, want a finite negative value for unseen runes", pass, logp)
+		}
+	}
+}
+
+// TestMarkovProbabilitiesSum checks the smoothing leaves a proper distribution over the next
+// rune: the trained runes and the reserved unseen ones.
+func TestMarkovProbabilitiesSum(t *testing.T) {
+	m := DefaultMarkovModel()
+	for _, context := range [][2]rune{{markovStart, markovStart}, {'p', 'a'}, {'1', '2'}, {'ж', 'у'}} {
+		sum := float64(markovUnseenRunes) * m.prob(context[0], context[1], 'ж')
+		for r := range m.unigrams {
+			sum += m.prob(context[0], context[1], r)
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("probabilities after %q sum to %v, want 1", context, sum)
+		}
+	}
+}
+
+func TestMarkovModelRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := DefaultMarkovModel().WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo() = %d, %v, want %d bytes", n, err, buf.Len())
+	}
+	loaded, err := NewMarkovFromReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewMarkovFromReader() error = %v", err)
+	}
+	if loaded.Len() != DefaultMarkovModel().Len() {
+		t.Errorf("Len() = %d, want %d", loaded.Len(), DefaultMarkovModel().Len())
+	}
+	for _, pass := range []string{"password", "Iloveyou1!", "Xqj9#mB2", "жук"} {
+		if got, want := loaded.LogProb(pass), MarkovLogProb(pass); got != want {
+			t.Errorf("loaded LogProb(%q) = %v, want %v", pass, got, want)
+		}
+	}
+
+	var again bytes.Buffer
+	loaded.WriteTo(&again)
+	if !bytes.Equal(again.Bytes(), buf.Bytes()) {
+		t.Error("WriteTo() of a loaded model differs from the original")
+	}
+}
+
+func TestNewMarkovFromReaderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	m, _ := TrainMarkov(strings.NewReader("ab\n"))
+	m.WriteTo(&buf)
+	valid := buf.Bytes()
+
+	withVersion := bytes.Clone(valid)
+	withVersion[4] = 2
+	badRune := bytes.Clone(valid)
+	copy(badRune[21:], []byte{0xfe, 0xff, 0xff, 0xff}) // markovEnd as the first rune of a trigram
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"Empty", nil, ErrMalformedMarkov},
+		{"Bad magic", append([]byte("GPBF"), valid[4:]...), ErrMalformedMarkov},
+		{"Future version", withVersion, ErrUnsupportedMarkovVersion},
+		{"Truncated", valid[:len(valid)-1], ErrMalformedMarkov},
+		{"Invalid trigram", badRune, ErrMalformedMarkov},
+	}
+	for _, tt := range tests {
+		if _, err := NewMarkovFromReader(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: NewMarkovFromReader() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func BenchmarkMarkovLogProb(b *testing.B) {
+	m := DefaultMarkovModel()
+	for b.Loop() {
+		m.LogProb("Iloveyou1!")
+	}
+}
@@ -0,0 +1,314 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language every message is available in and the fallback for missing ones.
+const DefaultLanguage = "en"
+
+// englishMessages is the built-in catalog. Placeholders such as {min_length} are interpolated.
+var englishMessages = map[string]string{
+	"class.digits":                "digit",
+	"class.lower":                 "lowercase letter",
+	"class.upper":                 "uppercase letter",
+	"class.symbols":               "symbol",
+	"class.extended":              "accented or non-Latin letter",
+	"class.other":                 "other character",
+	"class.emoji":                 "emoji",
+	"describe.length_range":       "Must be between {min_length} and {max_length} characters long.",
+	"describe.min_length":         "Must be at least {min_length} characters long.",
+	"describe.max_length":         "Must be at most {max_length} characters long.",
+	"describe.max_bytes":          "Must be at most {max_bytes} bytes long when encoded as UTF-8.",
+	"describe.min_unique_chars":   "Must contain at least {min_unique_chars} different characters.",
+	"describe.max_class_ratio":    "No more than {max_percent}% of it may be one kind of character.",
+	"describe.allowed_chars":      "May only contain these characters: {chars}",
+	"describe.disallowed_chars":   "Must not contain any of these characters: {chars}",
+	"describe.confusables":        "Must not mix in letters from other scripts that look like Latin letters.",
+	"describe.whitespace":         "Must not contain spaces.",
+	"describe.edge_whitespace":    "Must not start or end with a space.",
+	"describe.first_char":         "The first character must be a {classes}.",
+	"describe.last_char":          "The last character must be a {classes}.",
+	"describe.stringprep":         "Must not contain unassigned, private-use, or other characters unsafe in passwords.",
+	"describe.invisible_chars":    "Must not contain invisible characters such as zero-width spaces or direction overrides.",
+	"describe.required_pattern":   "Must match the pattern {pattern}",
+	"describe.forbidden_pattern":  "Must not match the pattern {pattern}",
+	"describe.min_classes":        "Must meet at least {min_classes} of the following {count} requirements:",
+	"describe.digit":              "Must include a digit.",
+	"describe.digits":             "Must include at least {count} digits.",
+	"describe.lower":              "Must include a lowercase letter.",
+	"describe.lowers":             "Must include at least {count} lowercase letters.",
+	"describe.upper":              "Must include an uppercase letter.",
+	"describe.uppers":             "Must include at least {count} uppercase letters.",
+	"describe.symbol":             "Must include a symbol.",
+	"describe.symbols":            "Must include at least {count} symbols.",
+	"describe.symbol_from":        "Must include one of these symbols: {symbols}",
+	"describe.symbols_from":       "Must include at least {count} of these symbols: {symbols}",
+	"describe.extended":           "Must include an accented or non-Latin letter.",
+	"describe.extendeds":          "Must include at least {count} accented or non-Latin letters.",
+	"describe.emoji":              "Must include an emoji.",
+	"describe.min_entropy":        "Must carry at least {bits} bits of entropy.",
+	"describe.custom_rule":        "Must pass the {name} check.",
+	"describe.reject_common":      "Must not be a commonly used password.",
+	"describe.extra_dictionary":   "Must not be one of {count} banned words.",
+	"describe.dictionary":         "Must not be a dictionary word.",
+	"describe.normalize_leet":     "Swapping letters for look-alikes, as in p@ssw0rd, does not disguise a banned word.",
+	"describe.breach":             "Must not appear in known data breaches.",
+	"describe.sequences":          "Must not contain more than {max} sequential characters, such as abcd or 4321.",
+	"describe.repeats":            "Must not repeat a character more than {max} times in a row.",
+	"describe.user_inputs":        "Must not contain your name, username, or email address.",
+	"describe.previous_passwords": "Must be less than {percent}% similar to your previous passwords.",
+	"describe.history_one":        "Must not reuse your previous password.",
+	"describe.history":            "Must not reuse any of your last {count} passwords.",
+	"describe.dates":              "Must not contain dates, years from {min_year} to {max_year}, or phone numbers.",
+	"describe.pii":                "Must not contain a card number or social security number.",
+	"describe.keyboard_walks":     "Must not contain keyboard patterns of {length} or more keys, such as qwerty.",
+
+	"error.too_short":                "Password must be at least {min_length} characters long.",
+	"error.too_long":                 "Password must be at most {max_length} characters long.",
+	"error.too_many_bytes":           "Password must be at most {max_bytes} bytes long when encoded as UTF-8.",
+	"error.too_few_classes":          "Password must include at least {min_classes} kinds of characters.",
+	"error.too_few_unique":           "Password must contain at least {min_unique_chars} different characters.",
+	"error.class_ratio":              "No more than {max_percent}% of the password may be one kind of character.",
+	"error.disallowed_char":          "Password must not contain the character {char}.",
+	"error.disallowed_char_redacted": "Password contains a character that is not allowed.",
+	"error.control_char":             "Password must not contain the control character {char}.",
+	"error.invisible_char":           "Password must not contain the invisible character {char}.",
+	"error.whitespace":               "Password must not contain spaces.",
+	"error.edge_whitespace":          "Password must not start or end with a space.",
+	"error.first_char":               "Password must not start with this kind of character.",
+	"error.last_char":                "Password must not end with this kind of character.",
+	"error.confusable":               "Password must not use {char}, which looks like {looks_like}.",
+	"error.confusable_redacted":      "Password must not use a character that looks like {looks_like}.",
+	"error.prohibited_char":          "Password must not contain the character {char}.",
+	"error.invalid_utf8":             "Password contains bytes that are not valid text.",
+	"error.missing_pattern":          "Password must match the pattern {pattern}.",
+	"error.forbidden_pattern":        "Password must not match the pattern {pattern}.",
+	"error.missing_digit_one":        "Password must include a digit.",
+	"error.missing_digit":            "Password must include at least {min} digits.",
+	"error.missing_lower_one":        "Password must include a lowercase letter.",
+	"error.missing_lower":            "Password must include at least {min} lowercase letters.",
+	"error.missing_upper_one":        "Password must include an uppercase letter.",
+	"error.missing_upper":            "Password must include at least {min} uppercase letters.",
+	"error.missing_symbol_one":       "Password must include a symbol.",
+	"error.missing_symbol":           "Password must include at least {min} symbols.",
+	"error.missing_extended_one":     "Password must include an accented or non-Latin letter.",
+	"error.missing_extended":         "Password must include at least {min} accented or non-Latin letters.",
+	"error.missing_emoji_one":        "Password must include an emoji.",
+	"error.missing_emoji":            "Password must include at least {min} emoji.",
+	"error.sequence":                 "Password must not contain sequential characters, such as abcd or 4321.",
+	"error.repeated_chars":           "Password must not repeat a character more than {max_run} times in a row.",
+	"error.date":                     "Password must not contain dates, years, or phone numbers.",
+	"error.pii":                      "Password must not contain a card number or social security number.",
+	"error.keyboard_walk":            "Password must not contain keyboard patterns, such as qwerty.",
+	"error.user_input":               "Password must not contain your name, username, or email address.",
+	"error.too_similar":              "Password is too similar to a previous password.",
+	"error.common_password":          "Password is too common.",
+	"error.breach_check_failed":      "Password could not be checked against known data breaches. Try again later.",
+	"error.pwned":                    "Password has appeared {pwned_count} times in known data breaches.",
+	"error.history_check_failed":     "Password history could not be checked. Try again later.",
+	"error.reused":                   "Password was used before.",
+	"error.low_entropy":              "Password is too predictable: it carries {entropy} bits of entropy, at least {min_entropy} are required.",
+	"error.custom_rule":              "Password does not pass the {rule} check.",
+	"error.invalid_options":          "The password policy is misconfigured.",
+	"error.pin_not_digits":           "PIN must contain only digits.",
+	"error.pin_repeated":             "PIN must not repeat the same digits.",
+	"error.pin_sequence":             "PIN must not be a sequence, such as 1234.",
+	"error.common_pin":               "PIN is too common.",
+	"error.pin_year":                 "PIN must not be a year.",
+
+	"feedback.pwned":                    "This password appears in breach data. Choose something unique.",
+	"feedback.common_password":          "This is a commonly used password. Choose something unique.",
+	"feedback.reused":                   "You have used this password before. Choose a new one.",
+	"feedback.too_similar":              "Change more of it; it is too close to a previous password.",
+	"feedback.disallowed_char":          "Remove the character {char}.",
+	"feedback.disallowed_char_redacted": "Remove the character that is not allowed.",
+	"feedback.control_char":             "Remove the control character {char}.",
+	"feedback.invisible_char":           "Remove the invisible character {char}.",
+	"feedback.whitespace":               "Remove the spaces.",
+	"feedback.edge_whitespace":          "Remove the space at the start or end.",
+	"feedback.first_char":               "Start with a different kind of character.",
+	"feedback.last_char":                "End with a different kind of character.",
+	"feedback.confusable":               "Replace {char} with a character that does not imitate {looks_like}.",
+	"feedback.confusable_redacted":      "Replace the character that imitates {looks_like}.",
+	"feedback.prohibited_char":          "Remove the character {char}.",
+	"feedback.invalid_utf8":             "Retype the password; part of it was not valid text.",
+	"feedback.forbidden_pattern":        "Avoid text matching the pattern {pattern}.",
+	"feedback.missing_pattern":          "Make it match the pattern {pattern}.",
+	"feedback.too_short_one":            "Make it at least 1 character longer.",
+	"feedback.too_short":                "Make it at least {count} characters longer.",
+	"feedback.too_long_one":             "Shorten it by at least 1 character.",
+	"feedback.too_long":                 "Shorten it by at least {count} characters.",
+	"feedback.too_many_bytes_one":       "Shorten it by at least 1 byte; accented letters and emoji take several.",
+	"feedback.too_many_bytes":           "Shorten it by at least {count} bytes; accented letters and emoji take several.",
+	"feedback.too_few_classes_one":      "Add another kind of character.",
+	"feedback.too_few_classes":          "Add {count} more kinds of characters.",
+	"feedback.too_few_unique_one":       "Use 1 more different character.",
+	"feedback.too_few_unique":           "Use {count} more different characters.",
+	"feedback.class_ratio":              "Mix in other kinds of characters; {percent}% are the same kind.",
+	"feedback.missing_digit_one":        "Add a digit.",
+	"feedback.missing_digit":            "Add {count} more digits.",
+	"feedback.missing_lower_one":        "Add a lowercase letter.",
+	"feedback.missing_lower":            "Add {count} more lowercase letters.",
+	"feedback.missing_upper_one":        "Add an uppercase letter.",
+	"feedback.missing_upper":            "Add {count} more uppercase letters.",
+	"feedback.missing_symbol_one":       "Add a symbol.",
+	"feedback.missing_symbol":           "Add {count} more symbols.",
+	"feedback.missing_extended_one":     "Add an accented or non-Latin letter.",
+	"feedback.missing_extended":         "Add {count} more accented or non-Latin letters.",
+	"feedback.missing_emoji_one":        "Add an emoji.",
+	"feedback.missing_emoji":            "Add {count} more emoji.",
+	"feedback.low_entropy_one":          "Make it at least 1 character longer to reach the required strength.",
+	"feedback.low_entropy":              "Make it at least {count} characters longer to reach the required strength.",
+	"feedback.low_entropy_generic":      "Make it longer and less predictable.",
+	"feedback.custom_rule":              "Make sure it passes the {rule} check.",
+	"feedback.breach_check_failed":      "It could not be checked against breach data. Try again later.",
+	"feedback.history_check_failed":     "Your password history could not be checked. Try again later.",
+	"feedback.pattern.date":             "Avoid dates such as '{token}' that are easy to guess.",
+	"feedback.pattern.year":             "Avoid years such as '{token}'.",
+	"feedback.pattern.phone":            "Avoid phone numbers and other long runs of digits such as '{token}'.",
+	"feedback.pattern.keyboard_walk":    "Avoid the keyboard pattern '{token}'.",
+	"feedback.pattern.sequence":         "Avoid the sequence '{token}'.",
+	"feedback.analysis.dictionary":      "Avoid common words and passwords such as '{token}'.",
+	"feedback.pattern.repeat":           "Avoid repeated characters such as '{token}'.",
+	"feedback.pattern.repeated_block":   "Avoid repeating '{base}'.",
+	"feedback.pattern.user_input":       "Avoid '{token}', which is part of your personal details.",
+	"feedback.pattern.confusable":       "Letters from other scripts that look the same do not disguise '{base}'.",
+	"feedback.pattern.card_number":      "Never use a payment card number as a password.",
+	"feedback.pattern.ssn":              "Never use a social security number as a password.",
+	"feedback.pattern.leet":             "Swapping letters for look-alikes does not disguise '{base}'.",
+	"feedback.pattern.repeated_word":    "Typing '{base}' twice does not make it harder to guess.",
+	"feedback.pattern.reversed_word":    "Typing '{base}' backwards does not disguise it.",
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalogs  = map[string]map[string]string{DefaultLanguage: englishMessages, "es": spanishMessages}
+)
+
+// RegisterMessages adds or replaces messages for lang, such as "de" or "pt-BR". Keys match those
+// of the English catalog: "describe." plus a Describe rule, or "error." plus a ValidationError
+// code, with a "_one" variant used when a class minimum is one and a "_redacted" variant, leaving
+// out the password's characters, used by Result.Redacted. Keys left out fall back to the
+// base language ("pt" for "pt-BR") and then to English.
+func RegisterMessages(lang string, msgs map[string]string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	merged := make(map[string]string, len(catalogs[lang])+len(msgs))
+	for key, msg := range catalogs[lang] {
+		merged[key] = msg
+	}
+	for key, msg := range msgs {
+		merged[key] = msg
+	}
+	catalogs[lang] = merged
+}
+
+// Translate returns err as a message in lang, falling back to English for missing translations.
+// Each *ValidationError is looked up by its Code with its Params interpolated; a Result.Err joining
+// several violations yields one line per violation. Other errors return their Error text.
+func Translate(err error, lang string) string {
+	return translate(err, lang, false)
+}
+
+// translate is Translate that, with redact set, uses the "_redacted" variant of messages that
+// would otherwise quote a character of the password.
+func translate(err error, lang string, redact bool) string {
+	if err == nil {
+		return ""
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		lines := make([]string, 0, len(joined.Unwrap()))
+		for _, e := range joined.Unwrap() {
+			lines = append(lines, translate(e, lang, redact))
+		}
+		return strings.Join(lines, "\n")
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return err.Error()
+	}
+
+	args := make([]any, 0, 2*len(verr.Params))
+	for name, value := range verr.Params {
+		if f, ok := value.(float64); ok {
+			value = strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+		}
+		args = append(args, name, value)
+	}
+	key := "error." + verr.Code
+	if min, ok := verr.Params["min"].(uint); ok && min == 1 {
+		if _, ok := lookupMessage(lang, key+"_one"); ok {
+			key += "_one"
+		}
+	}
+	if _, ok := lookupMessage(lang, key); !ok {
+		return err.Error()
+	}
+	return message(lang, redactedKey(lang, key, redact), args...)
+}
+
+// redactedKey returns the "_redacted" variant of key when redact is set and lang has one.
+func redactedKey(lang, key string, redact bool) string {
+	if redact {
+		if _, ok := lookupMessage(lang, key+"_redacted"); ok {
+			return key + "_redacted"
+		}
+	}
+	return key
+}
+
+// message returns the message for key in lang with its placeholders replaced by args, given as
+// name and value pairs.
+func message(lang, key string, args ...any) string {
+	msg, ok := lookupMessage(lang, key)
+	if !ok {
+		return key
+	}
+
+	for i := 0; i+1 < len(args); i += 2 {
+		msg = strings.ReplaceAll(msg, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return msg
+}
+
+// lookupMessage returns the uninterpolated message for key in lang, its base language, or English.
+func lookupMessage(lang, key string) (string, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	for _, l := range []string{lang, baseLanguage(lang), DefaultLanguage} {
+		if msg, ok := catalogs[l][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// baseLanguage strips the region from lang, so "pt-BR" and "pt_BR" become "pt".
+func baseLanguage(lang string) string {
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		return lang[:i]
+	}
+	return lang
+}
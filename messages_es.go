@@ -0,0 +1,191 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// spanishMessages is the built-in Spanish catalog, and a template for registering other languages.
+var spanishMessages = map[string]string{
+	"class.digits":                "dígito",
+	"class.lower":                 "letra minúscula",
+	"class.upper":                 "letra mayúscula",
+	"class.symbols":               "símbolo",
+	"class.extended":              "letra acentuada o no latina",
+	"class.other":                 "otro carácter",
+	"class.emoji":                 "emoji",
+	"describe.length_range":       "Debe tener entre {min_length} y {max_length} caracteres.",
+	"describe.min_length":         "Debe tener al menos {min_length} caracteres.",
+	"describe.max_length":         "Debe tener como máximo {max_length} caracteres.",
+	"describe.max_bytes":          "Debe ocupar como máximo {max_bytes} bytes en UTF-8.",
+	"describe.min_unique_chars":   "Debe contener al menos {min_unique_chars} caracteres distintos.",
+	"describe.max_class_ratio":    "No más del {max_percent}% puede ser de un mismo tipo de carácter.",
+	"describe.allowed_chars":      "Solo puede contener estos caracteres: {chars}",
+	"describe.disallowed_chars":   "No debe contener ninguno de estos caracteres: {chars}",
+	"describe.confusables":        "No debe mezclar letras de otros alfabetos que parecen letras latinas.",
+	"describe.whitespace":         "No debe contener espacios.",
+	"describe.edge_whitespace":    "No debe empezar ni terminar con un espacio.",
+	"describe.first_char":         "El primer carácter debe ser: {classes}.",
+	"describe.last_char":          "El último carácter debe ser: {classes}.",
+	"describe.stringprep":         "No debe contener caracteres sin asignar, de uso privado u otros inseguros en contraseñas.",
+	"describe.invisible_chars":    "No debe contener caracteres invisibles como espacios de ancho cero o cambios de dirección.",
+	"describe.required_pattern":   "Debe coincidir con el patrón {pattern}",
+	"describe.forbidden_pattern":  "No debe coincidir con el patrón {pattern}",
+	"describe.min_classes":        "Debe cumplir al menos {min_classes} de los siguientes {count} requisitos:",
+	"describe.digit":              "Debe incluir un dígito.",
+	"describe.digits":             "Debe incluir al menos {count} dígitos.",
+	"describe.lower":              "Debe incluir una letra minúscula.",
+	"describe.lowers":             "Debe incluir al menos {count} letras minúsculas.",
+	"describe.upper":              "Debe incluir una letra mayúscula.",
+	"describe.uppers":             "Debe incluir al menos {count} letras mayúsculas.",
+	"describe.symbol":             "Debe incluir un símbolo.",
+	"describe.symbols":            "Debe incluir al menos {count} símbolos.",
+	"describe.symbol_from":        "Debe incluir uno de estos símbolos: {symbols}",
+	"describe.symbols_from":       "Debe incluir al menos {count} de estos símbolos: {symbols}",
+	"describe.extended":           "Debe incluir una letra acentuada o no latina.",
+	"describe.extendeds":          "Debe incluir al menos {count} letras acentuadas o no latinas.",
+	"describe.emoji":              "Debe incluir un emoji.",
+	"describe.min_entropy":        "Debe tener al menos {bits} bits de entropía.",
+	"describe.custom_rule":        "Debe superar la comprobación {name}.",
+	"describe.reject_common":      "No debe ser una contraseña de uso común.",
+	"describe.extra_dictionary":   "No debe ser una de las {count} palabras prohibidas.",
+	"describe.dictionary":         "No debe ser una palabra del diccionario.",
+	"describe.normalize_leet":     "Cambiar letras por otras parecidas, como en p@ssw0rd, no disimula una palabra prohibida.",
+	"describe.breach":             "No debe aparecer en filtraciones de datos conocidas.",
+	"describe.sequences":          "No debe contener más de {max} caracteres consecutivos, como abcd o 4321.",
+	"describe.repeats":            "No debe repetir un carácter más de {max} veces seguidas.",
+	"describe.user_inputs":        "No debe contener tu nombre, usuario ni correo electrónico.",
+	"describe.previous_passwords": "Debe parecerse menos de un {percent}% a tus contraseñas anteriores.",
+	"describe.history_one":        "No debe repetir tu contraseña anterior.",
+	"describe.history":            "No debe repetir ninguna de tus últimas {count} contraseñas.",
+	"describe.dates":              "No debe contener fechas, años de {min_year} a {max_year} ni números de teléfono.",
+	"describe.pii":                "No debe contener un número de tarjeta ni de seguro social.",
+	"describe.keyboard_walks":     "No debe contener secuencias de {length} o más teclas contiguas, como qwerty.",
+
+	"error.too_short":                "La contraseña debe tener al menos {min_length} caracteres.",
+	"error.too_long":                 "La contraseña debe tener como máximo {max_length} caracteres.",
+	"error.too_many_bytes":           "La contraseña debe ocupar como máximo {max_bytes} bytes en UTF-8.",
+	"error.too_few_classes":          "La contraseña debe incluir al menos {min_classes} tipos de caracteres.",
+	"error.too_few_unique":           "La contraseña debe contener al menos {min_unique_chars} caracteres distintos.",
+	"error.class_ratio":              "No más del {max_percent}% de la contraseña puede ser de un mismo tipo de carácter.",
+	"error.disallowed_char":          "La contraseña no debe contener el carácter {char}.",
+	"error.disallowed_char_redacted": "La contraseña contiene un carácter no permitido.",
+	"error.control_char":             "La contraseña no debe contener el carácter de control {char}.",
+	"error.invisible_char":           "La contraseña no debe contener el carácter invisible {char}.",
+	"error.whitespace":               "La contraseña no debe contener espacios.",
+	"error.edge_whitespace":          "La contraseña no debe empezar ni terminar con un espacio.",
+	"error.first_char":               "La contraseña no debe empezar con este tipo de carácter.",
+	"error.last_char":                "La contraseña no debe terminar con este tipo de carácter.",
+	"error.confusable":               "La contraseña no debe usar {char}, que parece {looks_like}.",
+	"error.confusable_redacted":      "La contraseña no debe usar un carácter que parece {looks_like}.",
+	"error.prohibited_char":          "La contraseña no debe contener el carácter {char}.",
+	"error.invalid_utf8":             "La contraseña contiene bytes que no son texto válido.",
+	"error.missing_pattern":          "La contraseña debe coincidir con el patrón {pattern}.",
+	"error.forbidden_pattern":        "La contraseña no debe coincidir con el patrón {pattern}.",
+	"error.missing_digit_one":        "La contraseña debe incluir un dígito.",
+	"error.missing_digit":            "La contraseña debe incluir al menos {min} dígitos.",
+	"error.missing_lower_one":        "La contraseña debe incluir una letra minúscula.",
+	"error.missing_lower":            "La contraseña debe incluir al menos {min} letras minúsculas.",
+	"error.missing_upper_one":        "La contraseña debe incluir una letra mayúscula.",
+	"error.missing_upper":            "La contraseña debe incluir al menos {min} letras mayúsculas.",
+	"error.missing_symbol_one":       "La contraseña debe incluir un símbolo.",
+	"error.missing_symbol":           "La contraseña debe incluir al menos {min} símbolos.",
+	"error.missing_extended_one":     "La contraseña debe incluir una letra acentuada o no latina.",
+	"error.missing_extended":         "La contraseña debe incluir al menos {min} letras acentuadas o no latinas.",
+	"error.missing_emoji_one":        "La contraseña debe incluir un emoji.",
+	"error.missing_emoji":            "La contraseña debe incluir al menos {min} emojis.",
+	"error.sequence":                 "La contraseña no debe contener caracteres consecutivos, como abcd o 4321.",
+	"error.repeated_chars":           "La contraseña no debe repetir un carácter más de {max_run} veces seguidas.",
+	"error.date":                     "La contraseña no debe contener fechas, años ni números de teléfono.",
+	"error.pii":                      "La contraseña no debe contener un número de tarjeta ni de seguro social.",
+	"error.keyboard_walk":            "La contraseña no debe contener secuencias de teclado, como qwerty.",
+	"error.user_input":               "La contraseña no debe contener tu nombre, usuario ni correo electrónico.",
+	"error.too_similar":              "La contraseña se parece demasiado a una anterior.",
+	"error.common_password":          "La contraseña es demasiado común.",
+	"error.breach_check_failed":      "No se pudo comprobar la contraseña en filtraciones de datos conocidas. Inténtalo más tarde.",
+	"error.pwned":                    "La contraseña ha aparecido {pwned_count} veces en filtraciones de datos conocidas.",
+	"error.history_check_failed":     "No se pudo comprobar el historial de contraseñas. Inténtalo más tarde.",
+	"error.reused":                   "La contraseña ya se usó antes.",
+	"error.low_entropy":              "La contraseña es demasiado predecible: tiene {entropy} bits de entropía y se requieren al menos {min_entropy}.",
+	"error.custom_rule":              "La contraseña no supera la comprobación {rule}.",
+	"error.invalid_options":          "La política de contraseñas está mal configurada.",
+	"error.pin_not_digits":           "El PIN solo debe contener dígitos.",
+	"error.pin_repeated":             "El PIN no debe repetir los mismos dígitos.",
+	"error.pin_sequence":             "El PIN no debe ser una secuencia, como 1234.",
+	"error.common_pin":               "El PIN es demasiado común.",
+	"error.pin_year":                 "El PIN no debe ser un año.",
+
+	"feedback.pwned":                    "Esta contraseña aparece en filtraciones de datos. Elige otra única.",
+	"feedback.common_password":          "Es una contraseña de uso común. Elige otra única.",
+	"feedback.reused":                   "Ya usaste esta contraseña. Elige una nueva.",
+	"feedback.too_similar":              "Cambia más partes; se parece demasiado a una contraseña anterior.",
+	"feedback.disallowed_char":          "Quita el carácter {char}.",
+	"feedback.disallowed_char_redacted": "Quita el carácter no permitido.",
+	"feedback.control_char":             "Quita el carácter de control {char}.",
+	"feedback.invisible_char":           "Quita el carácter invisible {char}.",
+	"feedback.whitespace":               "Quita los espacios.",
+	"feedback.edge_whitespace":          "Quita el espacio del principio o del final.",
+	"feedback.first_char":               "Empieza con otro tipo de carácter.",
+	"feedback.last_char":                "Termina con otro tipo de carácter.",
+	"feedback.confusable":               "Cambia {char} por un carácter que no imite a {looks_like}.",
+	"feedback.confusable_redacted":      "Cambia el carácter que imita a {looks_like}.",
+	"feedback.prohibited_char":          "Quita el carácter {char}.",
+	"feedback.invalid_utf8":             "Vuelve a escribir la contraseña; una parte no era texto válido.",
+	"feedback.forbidden_pattern":        "Evita texto que coincida con el patrón {pattern}.",
+	"feedback.missing_pattern":          "Haz que coincida con el patrón {pattern}.",
+	"feedback.too_short_one":            "Añade al menos 1 carácter más.",
+	"feedback.too_short":                "Añade al menos {count} caracteres más.",
+	"feedback.too_long_one":             "Acórtala al menos 1 carácter.",
+	"feedback.too_long":                 "Acórtala al menos {count} caracteres.",
+	"feedback.too_many_bytes_one":       "Acórtala al menos 1 byte; las letras acentuadas y los emoji ocupan varios.",
+	"feedback.too_many_bytes":           "Acórtala al menos {count} bytes; las letras acentuadas y los emoji ocupan varios.",
+	"feedback.too_few_classes_one":      "Añade otro tipo de carácter.",
+	"feedback.too_few_classes":          "Añade {count} tipos de caracteres más.",
+	"feedback.too_few_unique_one":       "Usa 1 carácter distinto más.",
+	"feedback.too_few_unique":           "Usa {count} caracteres distintos más.",
+	"feedback.class_ratio":              "Mezcla otros tipos de caracteres; el {percent}% son del mismo tipo.",
+	"feedback.missing_digit_one":        "Añade un dígito.",
+	"feedback.missing_digit":            "Añade {count} dígitos más.",
+	"feedback.missing_lower_one":        "Añade una letra minúscula.",
+	"feedback.missing_lower":            "Añade {count} letras minúsculas más.",
+	"feedback.missing_upper_one":        "Añade una letra mayúscula.",
+	"feedback.missing_upper":            "Añade {count} letras mayúsculas más.",
+	"feedback.missing_symbol_one":       "Añade un símbolo.",
+	"feedback.missing_symbol":           "Añade {count} símbolos más.",
+	"feedback.missing_extended_one":     "Añade una letra acentuada o no latina.",
+	"feedback.missing_extended":         "Añade {count} letras acentuadas o no latinas más.",
+	"feedback.missing_emoji_one":        "Añade un emoji.",
+	"feedback.missing_emoji":            "Añade {count} emojis más.",
+	"feedback.low_entropy_one":          "Añade al menos 1 carácter más para alcanzar la seguridad requerida.",
+	"feedback.low_entropy":              "Añade al menos {count} caracteres más para alcanzar la seguridad requerida.",
+	"feedback.low_entropy_generic":      "Hazla más larga y menos predecible.",
+	"feedback.custom_rule":              "Asegúrate de que supera la comprobación {rule}.",
+	"feedback.breach_check_failed":      "No se pudo comprobar en filtraciones de datos. Inténtalo más tarde.",
+	"feedback.history_check_failed":     "No se pudo comprobar tu historial de contraseñas. Inténtalo más tarde.",
+	"feedback.pattern.date":             "Evita fechas fáciles de adivinar como '{token}'.",
+	"feedback.pattern.year":             "Evita años como '{token}'.",
+	"feedback.pattern.phone":            "Evita números de teléfono y otras series largas de dígitos como '{token}'.",
+	"feedback.pattern.keyboard_walk":    "Evita la secuencia de teclado '{token}'.",
+	"feedback.pattern.sequence":         "Evita la secuencia '{token}'.",
+	"feedback.analysis.dictionary":      "Evita palabras y contraseñas comunes como '{token}'.",
+	"feedback.pattern.repeat":           "Evita caracteres repetidos como '{token}'.",
+	"feedback.pattern.repeated_block":   "Evita repetir '{base}'.",
+	"feedback.pattern.user_input":       "Evita '{token}', que forma parte de tus datos personales.",
+	"feedback.pattern.confusable":       "Las letras de otros alfabetos que se ven iguales no disimulan '{base}'.",
+	"feedback.pattern.card_number":      "Nunca uses un número de tarjeta de pago como contraseña.",
+	"feedback.pattern.ssn":              "Nunca uses un número de seguro social como contraseña.",
+	"feedback.pattern.leet":             "Cambiar letras por otras parecidas no disimula '{base}'.",
+	"feedback.pattern.repeated_word":    "Escribir '{base}' dos veces no lo hace más difícil de adivinar.",
+	"feedback.pattern.reversed_word":    "Escribir '{base}' al revés no lo disimula.",
+}
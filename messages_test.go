@@ -0,0 +1,131 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	RegisterMessages("qq", map[string]string{"describe.digit": "first", "describe.lower": "lower {count}"})
+	RegisterMessages("qq", map[string]string{"describe.digit": "second"})
+	RegisterMessages("qq-RR", map[string]string{"describe.upper": "regional"})
+
+	tests := []struct {
+		lang, key string
+		args      []any
+		want      string
+	}{
+		{"en", "describe.min_length", []any{"min_length", 8}, "Must be at least 8 characters long."},
+		{"qq", "describe.digit", nil, "second"},
+		{"qq", "describe.lower", []any{"count", 3}, "lower 3"},
+		{"qq-RR", "describe.upper", nil, "regional"},
+		{"qq-RR", "describe.digit", nil, "second"},
+		{"qq-RR", "describe.symbol", nil, "Must include a symbol."},
+		{"", "describe.symbol", nil, "Must include a symbol."},
+		{"en", "describe.min_length", nil, "Must be at least {min_length} characters long."},
+		{"en", "no.such.key", nil, "no.such.key"},
+	}
+
+	for _, tt := range tests {
+		if got := message(tt.lang, tt.key, tt.args...); got != tt.want {
+			t.Errorf("message(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	breached := BreachCheckerFunc(func(context.Context, string) (bool, int, error) { return true, 42, nil })
+	RegisterMessages("qq", map[string]string{"error.too_short": "short, want {min_length}"})
+
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		lang     string
+		want     string
+	}{
+		{"English", "abc", Options{MinLength: 8}, "en", "Password must be at least 8 characters long."},
+		{"Spanish", "abc", Options{MinLength: 8}, "es", "La contraseña debe tener al menos 8 caracteres."},
+		{"Regional variant", "abc", Options{MinLength: 8}, "es-MX", "La contraseña debe tener al menos 8 caracteres."},
+		{"Registered language", "abc", Options{MinLength: 8}, "qq", "short, want 8"},
+		{"Registered language falls back to English", "password", Options{UseDigits: true}, "qq", "Password must include a digit."},
+		{"Unknown language", "password", Options{UseDigits: true}, "xx", "Password must include a digit."},
+		{"Plural minimum", "password", Options{MinDigits: 2}, "en", "Password must include at least 2 digits."},
+		{"Pwned count", "Xq7mB2vLp9!", Options{BreachChecker: breached}, "es", "La contraseña ha aparecido 42 veces en filtraciones de datos conocidas."},
+		{"Rounded entropy", "aaaa", Options{MinEntropy: 50}, "en", "Password is too predictable: it carries 18.8 bits of entropy, at least 50 are required."},
+		{"Custom rule", "Acme-Q3-2026!", Options{CustomRules: []Rule{quarterRule}}, "en", "Password does not pass the quarter check."},
+		{
+			name:     "Every violation",
+			password: "abc",
+			options:  Options{MinLength: 8, UseUpper: true},
+			lang:     "en",
+			want:     "Password must be at least 8 characters long.\nPassword must include an uppercase letter.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Translate(Audit(tt.password, tt.options).Err, tt.lang); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := Translate(nil, "en"); got != "" {
+		t.Errorf("Translate(nil) = %q, want empty", got)
+	}
+	plain := fmt.Errorf("wrapped: %w", errors.New("not a validation error"))
+	if got := Translate(plain, "es"); got != plain.Error() {
+		t.Errorf("Translate() = %q, want %q", got, plain.Error())
+	}
+	unknown := &ValidationError{Code: "no_such_code", Err: errors.New("raw text")}
+	if got := Translate(unknown, "es"); got != "raw text" {
+		t.Errorf("Translate() = %q, want the error text for an unknown code", got)
+	}
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	codes := []string{
+		CodeTooShort, CodeTooLong, CodeDisallowedChar, CodeMissingPattern, CodeForbiddenPattern,
+		CodeMissingDigit, CodeMissingLower, CodeMissingUpper, CodeMissingSymbol, CodeMissingExtended,
+		CodeSequence, CodeRepeatedChars, CodeKeyboardWalk, CodeUserInput, CodeTooSimilar,
+		CodeCommonPassword, CodeBreachCheckFailed, CodePwned, CodeHistoryCheckFailed, CodeReused,
+		CodeLowEntropy, CodeCustomRule, CodeInvalidOptions, CodePINNotDigits, CodePINRepeated,
+		CodePINSequence, CodeCommonPIN, CodePINYear, CodeMissingEmoji, CodeControlChar,
+		CodeInvisibleChar, CodeInvalidUTF8, CodeProhibitedChar, CodeConfusable, CodeTooFewUnique, CodeTooFewClasses, CodeClassRatio, CodeDate, CodePII, CodeTooManyBytes, CodeWhitespace, CodeEdgeWhitespace, CodeFirstChar, CodeLastChar,
+	}
+	for _, code := range codes {
+		if _, ok := englishMessages["error."+code]; !ok {
+			t.Errorf("English catalog has no message for code %q", code)
+		}
+	}
+
+	for key := range englishMessages {
+		if _, ok := spanishMessages[key]; !ok {
+			t.Errorf("Spanish catalog has no message for %q", key)
+		}
+	}
+	for key := range spanishMessages {
+		if _, ok := englishMessages[key]; !ok {
+			t.Errorf("Spanish catalog has %q, which English does not", key)
+		}
+	}
+}
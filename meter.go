@@ -0,0 +1,108 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"sync"
+	"unicode/utf8"
+)
+
+// Meter audits a password as it is typed, one Update per keystroke. It validates the Options
+// and compiles them once, and when the new input only appends to or deletes from the end of the
+// previous one it classifies just the changed runes; any other edit classifies the input again.
+// Only the classification is incremental: pattern detection, entropy, and the dictionary and
+// breach checks run on the whole input every Update, so an Update costs about as much as
+// Validator.Audit. What it saves is the compilation the package-level Audit repeats per call,
+// and a BreachChecker is best left to the final Audit on submit. Keystrokes are not audits of a
+// submitted password, so Update reports nothing to the Metrics or Logger. A Meter is safe for
+// concurrent use.
+type Meter struct {
+	mu     sync.Mutex
+	v      Validator
+	prev   []byte // Copy of the previous input, zeroed by Reset
+	counts Counts // Classification of prev
+	length int    // Runes in prev
+}
+
+// NewMeter returns a Meter auditing against opts. It returns the error from Options.Validate when
+// the Options can never be satisfied.
+func NewMeter(opts Options) (*Meter, error) {
+	v, err := NewValidator(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Meter{v: *v}, nil
+}
+
+// Update audits password, reusing the classification of the previous input where it can.
+func (m *Meter) Update(password string) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	password = NormalizePassword(password, m.v.opts.Normalize)
+	symbols, unicodeClasses := m.v.opts.SymbolSet, m.v.opts.UnicodeClasses
+	prev := bytesView(m.prev) // Shares m.prev, so the comparison leaves no copy of the input behind
+	switch {
+	case len(password) >= len(prev) && password[:len(prev)] == prev && graphemeBoundary(password, len(prev)):
+		added, n := classify(password[len(prev):], symbols, unicodeClasses)
+		m.counts, m.length = m.counts.plus(added), m.length+n
+	case len(password) < len(prev) && prev[:len(password)] == password && graphemeBoundary(prev, len(password)):
+		removed, n := classify(prev[len(password):], symbols, unicodeClasses)
+		m.counts, m.length = m.counts.minus(removed), m.length-n
+	default:
+		m.counts, m.length = classify(password, symbols, unicodeClasses)
+	}
+
+	// Reuse the buffer, wiping what a shorter input leaves behind.
+	if cap(m.prev) < len(password) {
+		Zero(m.prev)
+		m.prev = make([]byte, len(password), 2*len(password))
+	} else {
+		if len(password) < len(m.prev) {
+			Zero(m.prev[len(password):])
+		}
+		m.prev = m.prev[:len(password)]
+	}
+	copy(m.prev, password)
+
+	return m.v.audit(context.Background(), password, m.counts, m.length)
+}
+
+// Reset zeroes the copy of the last input the Meter keeps and forgets its classification.
+func (m *Meter) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	Zero(m.prev[:cap(m.prev)])
+	m.prev = m.prev[:0]
+	m.counts, m.length = Counts{}, 0
+}
+
+// runeBoundary reports whether s splits into two runs of whole runes at byte i.
+func runeBoundary(s string, i int) bool {
+	return i == 0 || i == len(s) || utf8.RuneStart(s[i])
+}
+
+// plus returns the sum of c and o.
+func (c Counts) plus(o Counts) Counts {
+	return Counts{c.Digits + o.Digits, c.Lower + o.Lower, c.Upper + o.Upper, c.Symbols + o.Symbols, c.Extended + o.Extended, c.Other + o.Other, c.Emoji + o.Emoji}
+}
+
+// minus returns c without o.
+func (c Counts) minus(o Counts) Counts {
+	return Counts{c.Digits - o.Digits, c.Lower - o.Lower, c.Upper - o.Upper, c.Symbols - o.Symbols, c.Extended - o.Extended, c.Other - o.Other, c.Emoji - o.Emoji}
+}
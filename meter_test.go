@@ -0,0 +1,226 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMeterMatchesAudit(t *testing.T) {
+	options := Options{
+		MinLength:          10,
+		UseDigits:          true,
+		UseUpper:           true,
+		SymbolSet:          "!€",
+		RejectCommon:       true,
+		MaxSequenceLength:  3,
+		MaxRepeatRun:       2,
+		KeyboardWalkLength: 4,
+		MinEntropy:         40,
+	}
+	m, err := NewMeter(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Typing, deleting, pasting, and editing in the middle, including multibyte runes.
+	inputs := []string{
+		"", "p", "pa", "pas", "pass", "passw", "passwo", "passwor", "password", "passwor", "passwo",
+		"passwo€", "passwo€é", "passwo€", "passwo", "Passwo", "Passwo1!", "Xq7mB2vLp9!w", "Xq7mB2vLp9!w€€",
+		"Xq7", "", "qwerty1234", "qwerty1234é",
+	}
+	for _, input := range inputs {
+		got, want := m.Update(input), Audit(input, options)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Update(%q) = %+v, want %+v", input, got, want)
+		}
+	}
+}
+
+func TestMeterSplitRunes(t *testing.T) {
+	m, err := NewMeter(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Typing a multibyte rune one byte at a time passes through invalid UTF-8.
+	euro := "€"
+	for i := 0; i <= len(euro); i++ {
+		if got, want := m.Update(euro[:i]).Counts, Audit(euro[:i], Options{}).Counts; got != want {
+			t.Errorf("Update(%q) counts = %+v, want %+v", euro[:i], got, want)
+		}
+	}
+	for i := len(euro); i >= 0; i-- {
+		if got, want := m.Update(euro[:i]).Counts, Audit(euro[:i], Options{}).Counts; got != want {
+			t.Errorf("Update(%q) counts = %+v, want %+v", euro[:i], got, want)
+		}
+	}
+}
+
+func TestMeterRandomEdits(t *testing.T) {
+	m, err := NewMeter(Options{UseSymbols: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	random := rand.New(rand.NewPCG(1, 2))
+	alphabet := []rune("aZ9!é€ 🔒")
+	input := ""
+	for i := 0; i < 2000; i++ {
+		switch runes := []rune(input); random.IntN(4) {
+		case 0, 1:
+			input += string(alphabet[random.IntN(len(alphabet))])
+		case 2:
+			if len(runes) > 0 {
+				input = string(runes[:random.IntN(len(runes))])
+			}
+		case 3:
+			input = string(alphabet[random.IntN(len(alphabet))]) + input
+		}
+		if got, want := m.Update(input), Audit(input, Options{UseSymbols: true}); got.Counts != want.Counts || got.Length != want.Length {
+			t.Fatalf("Update(%q) counts = %+v length %d, want %+v length %d", input, got.Counts, got.Length, want.Counts, want.Length)
+		}
+	}
+}
+
+func TestMeterEmojiSequences(t *testing.T) {
+	m, err := NewMeter(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Joiners, modifiers, and regional indicators change how the runes before them are counted.
+	random := rand.New(rand.NewPCG(3, 4))
+	alphabet := []rune("a👩\u200d🏽\ufe0f🇫🇷")
+	input := ""
+	for i := 0; i < 2000; i++ {
+		switch runes := []rune(input); random.IntN(3) {
+		case 0, 1:
+			input += string(alphabet[random.IntN(len(alphabet))])
+		case 2:
+			if len(runes) > 0 {
+				input = string(runes[:random.IntN(len(runes))])
+			}
+		}
+		if got, want := m.Update(input), Audit(input, Options{}); got.Counts != want.Counts || got.Length != want.Length {
+			t.Fatalf("Update(%q) counts = %+v length %d, want %+v length %d", input, got.Counts, got.Length, want.Counts, want.Length)
+		}
+	}
+}
+
+func TestMeterReset(t *testing.T) {
+	m, err := NewMeter(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Update("Xq7mB2vLp9!w")
+	buffer := m.prev[:cap(m.prev)]
+	m.Update("Xq7")
+	if tail := buffer[3:12]; strings.Trim(string(tail), "\x00") != "" {
+		t.Errorf("Update() left %q of the longer input in its buffer", tail)
+	}
+	m.Reset()
+	if strings.Trim(string(buffer), "\x00") != "" {
+		t.Errorf("Reset() left %q in the buffer", buffer)
+	}
+	if got, want := m.Update("ab"), Audit("ab", Options{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Update() after Reset() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMeterInvalidOptions(t *testing.T) {
+	if _, err := NewMeter(Options{MinLength: 9, MaxLength: 2}); err == nil {
+		t.Error("NewMeter() error = nil, want the validation error")
+	}
+}
+
+func TestMeterConcurrentUpdate(t *testing.T) {
+	m, err := NewMeter(Options{MinLength: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				input := strings.Repeat("ab", j%10)
+				if got := m.Update(input); got.Length != int64(len(input)) {
+					t.Errorf("Update(%q) length = %d", input, got.Length)
+					return
+				}
+			}
+			m.Reset()
+		}()
+	}
+	wg.Wait()
+}
+
+// meterOptions is a policy whose compilation dominates a single audit.
+func meterOptions() Options {
+	words := make([]string, 5000)
+	for i := range words {
+		words[i] = "banned" + strings.Repeat("x", i%7) + string(rune('a'+i%26)) + string(rune('a'+i/26%26))
+	}
+	return Options{
+		MinLength:         12,
+		UseDigits:         true,
+		UseUpper:          true,
+		RejectCommon:      true,
+		ExtraDictionary:   words,
+		ForbiddenPatterns: []string{`(?i)acme`, `(19|20)[0-9]{2}`},
+	}
+}
+
+// BenchmarkMeterUpdate compares a keystroke against both ways of auditing from scratch. Append is
+// expected to track Validator, which also compiles once; only Audit recompiles the Options.
+func BenchmarkMeterUpdate(b *testing.B) {
+	options := meterOptions()
+	typed := "Xq7mB2vLp9!wZ4"
+
+	b.Run("Audit", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Audit(typed[:1+i%len(typed)], options)
+		}
+	})
+	b.Run("Validator", func(b *testing.B) {
+		v, err := NewValidator(options)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			v.Audit(typed[:1+i%len(typed)])
+		}
+	})
+	b.Run("Append", func(b *testing.B) {
+		m, err := NewMeter(options)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			n := 1 + i%len(typed)
+			if n == 1 {
+				m.Reset()
+			}
+			m.Update(typed[:n])
+		}
+	})
+}
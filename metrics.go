@@ -0,0 +1,60 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics receives counters and observations from every audit a Validator runs, for export to
+// a monitoring system such as Prometheus or expvar. It never sees the password or any part of
+// it: only violation codes, the effective entropy in bits, and how long the audit took.
+// Implementations must be safe for concurrent use. The expvarmetrics package provides one.
+type Metrics interface {
+	IncViolation(code string)        // Called once for each violation, with its ValidationError Code
+	ObserveEntropy(bits float64)     // Called once per audit with Result.EffectiveEntropy
+	ObserveDuration(d time.Duration) // Called once per audit with the time it took
+}
+
+// NopMetrics is a Metrics that discards everything, used when Options.Metrics is nil.
+type NopMetrics struct{}
+
+func (NopMetrics) IncViolation(string) {}
+
+func (NopMetrics) ObserveEntropy(float64) {}
+
+func (NopMetrics) ObserveDuration(time.Duration) {}
+
+// record reports audit of pass, which started at start, to the Metrics and Logger.
+func (v *Validator) record(ctx context.Context, pass string, audit *Result, start time.Time) {
+	if v.metrics != nil { // A zero Validator has none
+		observe(v.metrics, audit, start)
+	}
+	v.logAudit(ctx, pass, audit, start)
+}
+
+// observe reports audit to m, which took the time since start.
+func observe(m Metrics, audit *Result, start time.Time) {
+	for _, violation := range audit.Violations {
+		if verr, ok := violation.(*ValidationError); ok {
+			m.IncViolation(verr.Code)
+		}
+	}
+	m.ObserveEntropy(audit.EffectiveEntropy)
+	m.ObserveDuration(time.Since(start))
+}
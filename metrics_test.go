@@ -0,0 +1,129 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMetrics keeps everything a Validator reports.
+type recordingMetrics struct {
+	mu        sync.Mutex
+	codes     []string
+	entropy   []float64
+	durations []time.Duration
+}
+
+func (m *recordingMetrics) IncViolation(code string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes = append(m.codes, code)
+}
+
+func (m *recordingMetrics) ObserveEntropy(bits float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entropy = append(m.entropy, bits)
+}
+
+func (m *recordingMetrics) ObserveDuration(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations = append(m.durations, d)
+}
+
+func TestMetricsFailingAudit(t *testing.T) {
+	metrics := &recordingMetrics{}
+	opts := Options{MinLength: 12, UseDigits: true, UseUpper: true, UseSymbols: true, Metrics: metrics}
+	res := Audit("sunshine", opts)
+
+	want := []string{CodeTooShort, CodeMissingDigit, CodeMissingUpper, CodeMissingSymbol}
+	slices.Sort(want)
+	got := slices.Clone(metrics.codes)
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("IncViolation codes = %v, want %v", metrics.codes, want)
+	}
+	if len(metrics.entropy) != 1 || metrics.entropy[0] != res.EffectiveEntropy {
+		t.Errorf("ObserveEntropy = %v, want [%v]", metrics.entropy, res.EffectiveEntropy)
+	}
+	if len(metrics.durations) != 1 || metrics.durations[0] <= 0 {
+		t.Errorf("ObserveDuration = %v, want one positive duration", metrics.durations)
+	}
+	for _, code := range metrics.codes {
+		if strings.Contains(code, "sun") || strings.Contains(code, "shine") {
+			t.Errorf("IncViolation(%q) leaks part of the password", code)
+		}
+	}
+}
+
+func TestMetricsEveryAuditPath(t *testing.T) {
+	metrics := &recordingMetrics{}
+	v, err := NewValidator(Options{MinLength: 8, Metrics: metrics})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Audit("Xq7#mB2vLp9!")
+	v.AuditBytes([]byte("short"))
+	secret := NewSecret("Correct-Horse-9")
+	v.AuditSecret(secret)
+	secret.Zero()
+	if _, err := v.AuditAll(context.Background(), []string{"a", "b", "c"}, 2); err != nil {
+		t.Fatal(err)
+	}
+	AuditForUser(context.Background(), "alice2024", UserContext{Username: "alice"}, v.Options())
+
+	if len(metrics.durations) != 7 || len(metrics.entropy) != 7 {
+		t.Errorf("observed %d durations and %d entropies, want 7 audits", len(metrics.durations), len(metrics.entropy))
+	}
+	if n := strings.Count(strings.Join(metrics.codes, ","), CodeTooShort); n != 4 {
+		t.Errorf("too_short counted %d times, want 4: %v", n, metrics.codes)
+	}
+	if !slices.Contains(metrics.codes, CodeUserInput) {
+		t.Errorf("codes = %v, want user_input from AuditForUser", metrics.codes)
+	}
+}
+
+func TestMetricsMeterUpdate(t *testing.T) {
+	metrics := &recordingMetrics{}
+	m, err := NewMeter(Options{MinLength: 8, Metrics: metrics})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{"s", "sh", "sho", "shor", "short"} {
+		m.Update(input)
+	}
+	if len(metrics.codes) != 0 || len(metrics.entropy) != 0 || len(metrics.durations) != 0 {
+		t.Errorf("Meter.Update() reported codes %v, entropy %v, durations %v, want no keystroke counted as an audit",
+			metrics.codes, metrics.entropy, metrics.durations)
+	}
+}
+
+func TestMetricsPassingAudit(t *testing.T) {
+	metrics := &recordingMetrics{}
+	if res := Audit("Xq7#mB2vLp9!w4Z", Options{MinLength: 12, Metrics: metrics}); !res.Strong {
+		t.Fatalf("Audit() = %v, want a strong password", res.Err)
+	}
+	if len(metrics.codes) != 0 || len(metrics.entropy) != 1 {
+		t.Errorf("codes = %v, entropy = %v, want no violations and one observation", metrics.codes, metrics.entropy)
+	}
+}
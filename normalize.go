@@ -0,0 +1,94 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	"golang.org/x/text/secure/precis"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Form is a Unicode normalization form applied to passwords before they are audited.
+type Form int
+
+const (
+	NormalizeNone Form = iota // Audit passwords as entered
+	NormalizeNFC              // Compose characters, so "e" and U+0301 equal U+00E9 "é"
+	NormalizeNFKC             // Also fold compatibility characters such as full-width letters and ligatures
+)
+
+var formNames = [...]string{"none", "nfc", "nfkc"}
+
+// String returns the lowercase name of the form, such as "nfc".
+func (f Form) String() string {
+	if f < NormalizeNone || f > NormalizeNFKC {
+		return fmt.Sprintf("Form(%d)", int(f))
+	}
+	return formNames[f]
+}
+
+// MarshalText encodes the form by name, so it appears as "nfc" in JSON.
+func (f Form) MarshalText() ([]byte, error) {
+	if f < NormalizeNone || f > NormalizeNFKC {
+		return nil, fmt.Errorf("unknown normalization form %d", int(f))
+	}
+	return []byte(formNames[f]), nil
+}
+
+// UnmarshalText decodes a form written by MarshalText.
+func (f *Form) UnmarshalText(text []byte) error {
+	for i, name := range formNames {
+		if name == string(text) {
+			*f = Form(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown normalization form %q", text)
+}
+
+// NormalizePassword returns pass in the normalization form form. Login paths should apply the same
+// form as the Options the password was audited with before hashing or verifying it, so a password
+// typed with combining characters matches the one that was set.
+func NormalizePassword(pass string, form Form) string {
+	switch form {
+	case NormalizeNFC:
+		return norm.NFC.String(pass)
+	case NormalizeNFKC:
+		return norm.NFKC.String(pass)
+	}
+	return pass
+}
+
+// prohibitedRune returns the first rune of pass that the RFC 8265 OpaqueString profile disallows
+// and its position in runes. The position is -1 when pass is allowed, and err holds the profile's
+// reason when no single rune is at fault.
+func prohibitedRune(pass string) (r rune, i int, err error) {
+	if pass == "" {
+		return 0, -1, nil
+	}
+	if _, err = precis.OpaqueString.String(pass); err == nil {
+		return 0, -1, nil
+	}
+	for _, r := range pass {
+		if _, runeErr := precis.OpaqueString.String(string(r)); runeErr != nil {
+			return r, i, nil
+		}
+		i++
+	}
+	return 0, -1, err
+}
@@ -0,0 +1,137 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAuditNormalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		composed   string
+		decomposed string
+		form       Form
+	}{
+		{"Acute accent", "Café-Noir-2024", "Cafe\u0301-Noir-2024", NormalizeNFC},
+		{"Hangul", "한국어-pass1", "\u1112\u1161\u11ab\u1100\u116e\u11a8\u110b\u1165-pass1", NormalizeNFC},
+		{"Angstrom sign", "Ångström-99", "\u212bngstro\u0308m-99", NormalizeNFC},
+		{"Full-width letters", "Password-77", "Ｐａｓｓｗｏｒｄ-77", NormalizeNFKC},
+		{"Ligature", "office-Door-5", "oﬃce-Door-5", NormalizeNFKC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{MinLength: 8, UseDigits: true, Normalize: tt.form}
+			composed, decomposed := Audit(tt.composed, opts), Audit(tt.decomposed, opts)
+			if !reflect.DeepEqual(composed, decomposed) {
+				t.Errorf("Audit(%q) = %+v\nAudit(%q) = %+v, want identical results", tt.composed, composed, tt.decomposed, decomposed)
+			}
+			if got := NormalizePassword(tt.decomposed, tt.form); got != tt.composed {
+				t.Errorf("NormalizePassword(%q, %v) = %q, want %q", tt.decomposed, tt.form, got, tt.composed)
+			}
+			opts.Normalize = NormalizeNone
+			if reflect.DeepEqual(Audit(tt.composed, opts), Audit(tt.decomposed, opts)) {
+				t.Errorf("Audit() without normalization treats %q and %q the same", tt.composed, tt.decomposed)
+			}
+		})
+	}
+}
+
+func TestMeterNormalize(t *testing.T) {
+	opts := Options{Normalize: NormalizeNFC}
+	m, err := NewMeter(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A combining accent typed after its letter rewrites the preceding rune.
+	for _, input := range []string{"cafe", "cafe\u0301", "cafe\u0301s", "cafe"} {
+		if got, want := m.Update(input), Audit(input, opts); got.Counts != want.Counts || got.Length != want.Length {
+			t.Errorf("Update(%q) counts = %+v length %d, want %+v length %d", input, got.Counts, got.Length, want.Counts, want.Length)
+		}
+	}
+}
+
+func TestFormText(t *testing.T) {
+	for _, form := range []Form{NormalizeNone, NormalizeNFC, NormalizeNFKC} {
+		text, err := form.MarshalText()
+		if err != nil {
+			t.Fatalf("%v.MarshalText() error = %v", form, err)
+		}
+		var got Form
+		if err := got.UnmarshalText(text); err != nil || got != form {
+			t.Errorf("UnmarshalText(%q) = %v, %v, want %v", text, got, err, form)
+		}
+	}
+	if _, err := Form(7).MarshalText(); err == nil {
+		t.Error("Form(7).MarshalText() error = nil, want unknown form")
+	}
+	var f Form
+	if err := f.UnmarshalText([]byte("nfd")); err == nil {
+		t.Error(`UnmarshalText("nfd") error = nil, want unknown form`)
+	}
+	if err := (Options{Normalize: Form(7)}).Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() error = %v, want ErrInvalidOptions for an unknown form", err)
+	}
+}
+
+func TestOptionsNormalizeJSON(t *testing.T) {
+	data, err := json.Marshal(Options{Normalize: NormalizeNFKC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := ParseOptionsJSON(data)
+	if err != nil {
+		t.Fatalf("ParseOptionsJSON(%s) error = %v", data, err)
+	}
+	if opts.Normalize != NormalizeNFKC {
+		t.Errorf("ParseOptionsJSON(%s) Normalize = %v, want nfkc", data, opts.Normalize)
+	}
+}
+
+func TestAuditStringprep(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		detail   string
+	}{
+		{"ASCII", "Correct-Horse-9", ""},
+		{"Accents and symbols", "Pässwörd €9", ""},
+		{"Private use", "pass\ue000word", "password contains a character prohibited by RFC 8265: U+E000 at position 4"},
+		{"Unassigned", "ab\u0378cd", "password contains a character prohibited by RFC 8265: U+0378 at position 2"},
+		{"Noncharacter", "ab\ufdd0cd", "password contains a character prohibited by RFC 8265: U+FDD0 at position 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, Options{Stringprep: true})
+			if tt.detail == "" {
+				if result.Err != nil {
+					t.Errorf("Audit(%q) error = %v, want nil", tt.password, result.Err)
+				}
+				return
+			}
+			if !errors.Is(result.Err, ErrProhibitedChar) || result.Err.Error() != tt.detail {
+				t.Errorf("Audit(%q) error = %v, want %q", tt.password, result.Err, tt.detail)
+			}
+			if Audit(tt.password, Options{}).Err != nil {
+				t.Errorf("Audit(%q) fails without Stringprep", tt.password)
+			}
+		})
+	}
+}
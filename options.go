@@ -0,0 +1,146 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// Option configures the Options built by New. Options are applied in order, so when two set the
+// same field the last one wins.
+type Option func(*Options) error
+
+// New builds a Validator from functional options. It returns the first error reported by an
+// Option, or the error from Options.Validate when the combined Options are contradictory.
+func New(opts ...Option) (*Validator, error) {
+	var o Options
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	return NewValidator(o)
+}
+
+// WithOptions replaces everything configured so far with base, so existing Options structs can be
+// extended with further Option values. Validator.Options converts the other way.
+func WithOptions(base Options) Option {
+	return func(o *Options) error {
+		*o = base
+		return nil
+	}
+}
+
+// WithMinLength sets MinLength.
+func WithMinLength(n uint) Option {
+	return func(o *Options) error {
+		o.MinLength = n
+		return nil
+	}
+}
+
+// WithMaxLength sets MaxLength. Zero removes the limit.
+func WithMaxLength(n uint) Option {
+	return func(o *Options) error {
+		o.MaxLength = n
+		return nil
+	}
+}
+
+// WithClasses requires one character of each class in classes, e.g. ClassDigits|ClassUpper, and
+// clears the requirement for the classes left out. ClassOther cannot be required.
+func WithClasses(classes Class) Option {
+	return func(o *Options) error {
+		if classes&^(ClassDigits|ClassLower|ClassUpper|ClassSymbols|ClassExtended|ClassEmoji) != 0 {
+			return fmt.Errorf("%w: class %d cannot be required", ErrInvalidOptions, classes)
+		}
+		o.UseDigits = classes.Has(ClassDigits)
+		o.UseLower = classes.Has(ClassLower)
+		o.UseUpper = classes.Has(ClassUpper)
+		o.UseSymbols = classes.Has(ClassSymbols)
+		o.UseExtended = classes.Has(ClassExtended)
+		o.UseEmoji = classes.Has(ClassEmoji)
+		return nil
+	}
+}
+
+// WithMinEntropy sets MinEntropy, which must be zero or more bits.
+func WithMinEntropy(bits float64) Option {
+	return func(o *Options) error {
+		if !(bits >= 0) || math.IsInf(bits, 1) {
+			return fmt.Errorf("%w: minimum entropy %v is not a finite number of bits", ErrInvalidOptions, bits)
+		}
+		o.MinEntropy = bits
+		return nil
+	}
+}
+
+// WithDictionary rejects the embedded common passwords and words, setting RejectCommon and
+// replacing ExtraDictionary.
+func WithDictionary(words ...string) Option {
+	return func(o *Options) error {
+		for _, word := range words {
+			if word == "" {
+				return fmt.Errorf("%w: dictionary contains an empty word", ErrInvalidOptions)
+			}
+		}
+		o.RejectCommon = true
+		o.ExtraDictionary = words
+		return nil
+	}
+}
+
+// WithWordlist rejects the embedded common passwords and the words LoadWordlist reads from r,
+// setting RejectCommon and appending them to ExtraDictionary.
+func WithWordlist(r io.Reader) Option {
+	return func(o *Options) error {
+		words, err := LoadWordlist(r)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
+		o.RejectCommon = true
+		o.ExtraDictionary = append(slices.Clip(o.ExtraDictionary), words...)
+		return nil
+	}
+}
+
+// WithBreachChecker sets BreachChecker, which cannot be nil.
+func WithBreachChecker(c BreachChecker) Option {
+	return func(o *Options) error {
+		if c == nil {
+			return fmt.Errorf("%w: breach checker is nil", ErrInvalidOptions)
+		}
+		o.BreachChecker = c
+		return nil
+	}
+}
+
+// WithRules appends rules to CustomRules. A nil rule is an error.
+func WithRules(rules ...Rule) Option {
+	return func(o *Options) error {
+		for _, rule := range rules {
+			if rule == nil {
+				return fmt.Errorf("%w: custom rule is nil", ErrInvalidOptions)
+			}
+		}
+		o.CustomRules = slices.Concat(o.CustomRules, rules)
+		return nil
+	}
+}
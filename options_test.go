@@ -0,0 +1,159 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	checker := BreachCheckerFunc(func(context.Context, string) (bool, int, error) { return false, 0, nil })
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    Options
+	}{
+		{
+			name:    "Empty",
+			options: nil,
+			want:    Options{},
+		},
+		{
+			name: "Every option",
+			options: []Option{
+				WithMinLength(12),
+				WithMaxLength(128),
+				WithClasses(ClassDigits | ClassUpper | ClassLower | ClassSymbols),
+				WithMinEntropy(60),
+				WithDictionary("acmecorp", "hunter"),
+			},
+			want: Options{
+				MinLength:       12,
+				MaxLength:       128,
+				UseDigits:       true,
+				UseLower:        true,
+				UseUpper:        true,
+				UseSymbols:      true,
+				MinEntropy:      60,
+				RejectCommon:    true,
+				ExtraDictionary: []string{"acmecorp", "hunter"},
+			},
+		},
+		{
+			name:    "Last minimum length wins",
+			options: []Option{WithMinLength(8), WithMinLength(14)},
+			want:    Options{MinLength: 14},
+		},
+		{
+			name:    "Last classes win",
+			options: []Option{WithClasses(ClassDigits | ClassSymbols), WithClasses(ClassLower)},
+			want:    Options{UseLower: true},
+		},
+		{
+			name:    "Last dictionary wins",
+			options: []Option{WithDictionary("first"), WithDictionary("second")},
+			want:    Options{RejectCommon: true, ExtraDictionary: []string{"second"}},
+		},
+		{
+			name:    "Wordlist appends to dictionary",
+			options: []Option{WithDictionary("first"), WithWordlist(strings.NewReader("# Banned\nAcme\nacme\n"))},
+			want:    Options{RejectCommon: true, ExtraDictionary: []string{"first", "acme"}},
+		},
+		{
+			name:    "Options struct then overrides",
+			options: []Option{WithOptions(NISTOptions()), WithBreachChecker(checker), WithMinLength(15)},
+			want: func() Options {
+				o := NISTOptions()
+				o.BreachChecker = checker
+				o.MinLength = 15
+				return o
+			}(),
+		},
+		{
+			name:    "Options struct replaces earlier options",
+			options: []Option{WithMinLength(20), WithOptions(Options{MaxLength: 10})},
+			want:    Options{MaxLength: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := New(tt.options...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			got := v.Options()
+			// Functions cannot be compared, so compare the breach checker by presence.
+			if (got.BreachChecker == nil) != (tt.want.BreachChecker == nil) {
+				t.Fatalf("New() BreachChecker = %v, want %v", got.BreachChecker, tt.want.BreachChecker)
+			}
+			got.BreachChecker, tt.want.BreachChecker = nil, nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New().Options() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+	}{
+		{"Other class", []Option{WithClasses(ClassDigits | ClassOther)}},
+		{"Unknown class bit", []Option{WithClasses(1 << 7)}},
+		{"Negative entropy", []Option{WithMinEntropy(-1)}},
+		{"NaN entropy", []Option{WithMinEntropy(math.NaN())}},
+		{"Infinite entropy", []Option{WithMinEntropy(math.Inf(1))}},
+		{"Empty dictionary word", []Option{WithDictionary("acme", "")}},
+		{"Invalid wordlist", []Option{WithWordlist(strings.NewReader("acme\nab\n"))}},
+		{"Nil breach checker", []Option{WithBreachChecker(nil)}},
+		{"Minimum exceeds maximum", []Option{WithMinLength(20), WithMaxLength(10)}},
+		{"Classes exceed maximum", []Option{WithMaxLength(2), WithClasses(ClassDigits | ClassLower | ClassUpper)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, err := New(tt.options...); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("New() = %v, %v, want %v", v, err, ErrInvalidOptions)
+			}
+		})
+	}
+}
+
+func TestNewMatchesNewValidator(t *testing.T) {
+	fluent, err := New(WithMinLength(10), WithClasses(ClassDigits|ClassLower), WithDictionary("acmecorp"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	plain, err := NewValidator(Options{MinLength: 10, UseDigits: true, UseLower: true, RejectCommon: true, ExtraDictionary: []string{"acmecorp"}})
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	for _, password := range []string{"short1", "acmecorp2024", "password123", "zq8vj3mlkw2x"} {
+		if got, want := fluent.Audit(password), plain.Audit(password); got.Strong != want.Strong || len(got.Violations) != len(want.Violations) {
+			t.Errorf("Audit(%q) = %+v, want %+v", password, got, want)
+		}
+	}
+}
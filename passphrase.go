@@ -0,0 +1,209 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed data/eff_large_wordlist.txt
+var effWordlistData string
+
+// DefaultPassphraseWords is the number of words GeneratePassphrase picks when WordCount is zero.
+// Six words from the EFF list give about 77 bits of entropy.
+const DefaultPassphraseWords = 6
+
+// DefaultPassphraseSeparator joins words when PassphraseOptions.Separator is empty.
+const DefaultPassphraseSeparator = "-"
+
+// MinPassphraseWords is the number of wordlist words Audit needs to see before it estimates the
+// entropy of a password from its word count, as for a passphrase.
+const MinPassphraseWords = 4
+
+// PassphraseOptions configures GeneratePassphrase.
+type PassphraseOptions struct {
+	WordCount   int       // Number of words, DefaultPassphraseWords when zero
+	Separator   string    // Placed between words, DefaultPassphraseSeparator when empty
+	Capitalize  bool      // Uppercase the first letter of every word
+	AppendDigit bool      // Append a random digit after the last word
+	Words       []string  // Custom wordlist; overrides WordList and the embedded EFF list
+	WordList    io.Reader // Custom wordlist, one word per line, optionally after a dice roll
+}
+
+var (
+	effWordsOnce sync.Once
+	effWords     []string
+	effWordSet   map[string]struct{}
+)
+
+// loadEFFWords parses the embedded EFF large wordlist on first use. The lookup set also holds
+// the parts of hyphenated words such as "t-shirt", as Audit splits passwords on non-letters.
+func loadEFFWords() ([]string, map[string]struct{}) {
+	effWordsOnce.Do(func() {
+		effWords, _ = readWordList(strings.NewReader(effWordlistData))
+		effWordSet = make(map[string]struct{}, len(effWords))
+		for _, word := range effWords {
+			effWordSet[word] = struct{}{}
+			for _, part := range strings.Split(word, "-") {
+				effWordSet[part] = struct{}{}
+			}
+		}
+	})
+	return effWords, effWordSet
+}
+
+// readWordList reads one word per line, taking the last field so diceware files with a roll
+// before each word work too. Blank lines and duplicates are dropped.
+func readWordList(r io.Reader) ([]string, error) {
+	var words []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if word := fields[len(fields)-1]; !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// GeneratePassphrase returns random words from the embedded EFF large wordlist, or a custom list,
+// chosen with crypto/rand, along with the entropy of the choice in bits: log2 of the list size
+// per word, plus log2(10) for AppendDigit.
+func GeneratePassphrase(opts PassphraseOptions) (string, float64, error) {
+	var g Generator
+	return g.GeneratePassphrase(opts)
+}
+
+// GeneratePassphrase is like the package-level GeneratePassphrase but picks words using g.Rand.
+func (g *Generator) GeneratePassphrase(opts PassphraseOptions) (string, float64, error) {
+	words := opts.Words
+	switch {
+	case words != nil:
+		words, _ = readWordList(strings.NewReader(strings.Join(words, "\n")))
+	case opts.WordList != nil:
+		var err error
+		if words, err = readWordList(opts.WordList); err != nil {
+			return "", 0, err
+		}
+	default:
+		words, _ = loadEFFWords()
+	}
+	if len(words) < 2 {
+		return "", 0, errors.New("wordlist needs at least two distinct words")
+	}
+
+	count := opts.WordCount
+	if count == 0 {
+		count = DefaultPassphraseWords
+	}
+	if count < 0 {
+		return "", 0, errors.New("word count cannot be negative")
+	}
+	separator := opts.Separator
+	if separator == "" {
+		separator = DefaultPassphraseSeparator
+	}
+
+	picked := make([]string, count)
+	for i := range picked {
+		n, err := randomInt(g.random(), len(words))
+		if err != nil {
+			return "", 0, err
+		}
+		picked[i] = words[n]
+		if opts.Capitalize {
+			r, size := utf8.DecodeRuneInString(picked[i])
+			picked[i] = string(unicode.ToUpper(r)) + picked[i][size:]
+		}
+	}
+	passphrase := strings.Join(picked, separator)
+	entropy := float64(count) * math.Log2(float64(len(words)))
+	if opts.AppendDigit {
+		n, err := randomInt(g.random(), 10)
+		if err != nil {
+			return "", 0, err
+		}
+		passphrase += strconv.Itoa(n)
+		entropy += math.Log2(10)
+	}
+	return passphrase, entropy, nil
+}
+
+// passphraseWords returns how many words of the embedded EFF list make up pass when it is split
+// on non-letters, or zero when a letter run of three or more is not a word. Runs shorter than
+// three letters are skipped. It does not allocate.
+func passphraseWords(pass string) int {
+	_, set := loadEFFWords()
+
+	var buf [16]byte
+	n, count := 0, 0
+	flush := func() bool {
+		if n >= 3 {
+			if _, ok := set[string(buf[:n])]; !ok {
+				return false
+			}
+			count++
+		}
+		n = 0
+		return true
+	}
+	for i := 0; i < len(pass); i++ {
+		c := pass[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c >= 'a' && c <= 'z' {
+			if n == len(buf) {
+				return 0
+			}
+			buf[n] = c
+			n++
+			continue
+		}
+		if c >= utf8.RuneSelf {
+			// The EFF list is ASCII, so non-ASCII letters cannot be part of a word.
+			return 0
+		}
+		if !flush() {
+			return 0
+		}
+	}
+	if !flush() {
+		return 0
+	}
+	return count
+}
+
+// passphraseEntropy is the entropy in bits of a passphrase of words chosen from the EFF list.
+func passphraseEntropy(words int) float64 {
+	list, _ := loadEFFWords()
+	return float64(words) * math.Log2(float64(len(list)))
+}
@@ -0,0 +1,146 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestEmbeddedEFFWordlist(t *testing.T) {
+	words, _ := loadEFFWords()
+	if len(words) != 7776 {
+		t.Errorf("embedded wordlist has %d words, want 7776", len(words))
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     PassphraseOptions
+		wantWords   int
+		wantEntropy float64
+	}{
+		{"Defaults", PassphraseOptions{}, 6, 6 * math.Log2(7776)},
+		{"Four words", PassphraseOptions{WordCount: 4, Separator: " "}, 4, 4 * math.Log2(7776)},
+		{"Capitalized with digit", PassphraseOptions{WordCount: 5, Capitalize: true, AppendDigit: true}, 5, 5*math.Log2(7776) + math.Log2(10)},
+		{"Custom words", PassphraseOptions{WordCount: 3, Words: []string{"alpha", "beta", "gamma", "delta", "delta"}}, 3, 3 * 2},
+		{"Dice wordlist reader", PassphraseOptions{WordCount: 2, WordList: strings.NewReader("11\tone\n12\ttwo\n\n")}, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passphrase, entropy, err := GeneratePassphrase(tt.options)
+			if err != nil {
+				t.Fatalf("GeneratePassphrase() error = %v", err)
+			}
+			if math.Abs(entropy-tt.wantEntropy) > 1e-9 {
+				t.Errorf("GeneratePassphrase() entropy = %.4f, want %.4f", entropy, tt.wantEntropy)
+			}
+			separator := tt.options.Separator
+			if separator == "" {
+				separator = DefaultPassphraseSeparator
+			}
+			body := strings.TrimRightFunc(passphrase, unicode.IsDigit)
+			if tt.options.AppendDigit && len(body) != len(passphrase)-1 {
+				t.Errorf("GeneratePassphrase() = %q, want one trailing digit", passphrase)
+			}
+			if separator == "-" {
+				// Some EFF words such as "t-shirt" contain the separator.
+				return
+			}
+			if got := len(strings.Split(body, separator)); got != tt.wantWords {
+				t.Errorf("GeneratePassphrase() = %q, want %d words", passphrase, tt.wantWords)
+			}
+		})
+	}
+}
+
+func TestGeneratePassphraseCapitalize(t *testing.T) {
+	passphrase, _, err := GeneratePassphrase(PassphraseOptions{Separator: " ", Capitalize: true})
+	if err != nil {
+		t.Fatalf("GeneratePassphrase() error = %v", err)
+	}
+	for _, word := range strings.Fields(passphrase) {
+		if !unicode.IsUpper([]rune(word)[0]) {
+			t.Errorf("GeneratePassphrase() word %q is not capitalized", word)
+		}
+	}
+}
+
+func TestGeneratePassphraseErrors(t *testing.T) {
+	for _, options := range []PassphraseOptions{
+		{WordCount: -1},
+		{Words: []string{"only"}},
+		{Words: []string{"same", "same"}},
+		{WordList: strings.NewReader("")},
+	} {
+		if got, _, err := GeneratePassphrase(options); err == nil {
+			t.Errorf("GeneratePassphrase(%+v) = %q, want error", options, got)
+		}
+	}
+}
+
+func TestPassphraseWords(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"correct-horse-battery-staple", 0}, // "horse" is not in the EFF list
+		{"abacus-zoology-unwind-ruby", 4},
+		{"Abacus Zoology Unwind Ruby 7", 4},
+		{"tidy.t-shirt.zoom.abacus", 4}, // the "t" of "t-shirt" is too short to count
+		{"abacus-zoology-qqqq-ruby", 0},
+		{"abacusabacusabacusabacus", 0},
+		{"Øresund-abacus-zoology", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := passphraseWords(tt.password); got != tt.want {
+			t.Errorf("passphraseWords(%q) = %d, want %d", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestAuditRecognizesPassphrases(t *testing.T) {
+	options := Options{MinLength: 12, MinimumComplexity: PwComplexitySymbolsDigitsMixed}
+
+	passphrase, entropy, err := GeneratePassphrase(PassphraseOptions{Separator: " "})
+	if err != nil {
+		t.Fatalf("GeneratePassphrase() error = %v", err)
+	}
+	result := Audit(passphrase, options)
+	if !result.Strong || result.PassphraseWords != 6 {
+		t.Errorf("Audit(%q) Strong = %v, PassphraseWords = %d, want true, 6", passphrase, result.Strong, result.PassphraseWords)
+	}
+	if math.Abs(result.EffectiveEntropy-entropy) > 1e-9 {
+		t.Errorf("Audit(%q) EffectiveEntropy = %.2f, want the word-count entropy %.2f", passphrase, result.EffectiveEntropy, entropy)
+	}
+
+	if result := Audit("lowercaseonlyletters", options); result.Strong {
+		t.Error("Audit() Strong = true for a lowercase non-passphrase, want false")
+	}
+}
+
+func BenchmarkPassphraseWords(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		passphraseWords("Abacus Zoology Unwind Ruby 7")
+	}
+}
@@ -17,101 +17,679 @@ package go_passwd
 */
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"math"
+	"slices"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
+// Class is a bitmask of the character classes detected in a password.
+type Class uint8
+
+const (
+	ClassDigits Class = 1 << iota
+	ClassLower
+	ClassUpper
+	ClassSymbols
+	ClassExtended
+	ClassOther // Whitespace, control characters, and anything not covered by the other classes
+	ClassEmoji // Emoji, and symbols and punctuation beyond ASCII such as '€' or '§'
+)
+
+// otherCharsetSize is the charset size credited to characters outside the other classes.
+const otherCharsetSize = 32
+
+// classNames are the names of the classes from ClassDigits up, as in the JSON encoding of Counts.
+var classNames = [...]string{"digits", "lower", "upper", "symbols", "extended", "other", "emoji"}
+
+// String returns the names of the classes in the bitmask joined by "|", such as "lower|upper", or
+// "none" for the empty bitmask.
+func (classes Class) String() string {
+	var s string
+	for i, name := range classNames {
+		if classes&(1<<i) != 0 {
+			if s != "" {
+				s += "|"
+			}
+			s += name
+		}
+	}
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// Has reports whether every class in c is present.
+func (classes Class) Has(c Class) bool {
+	return classes&c == c
+}
+
 const (
-	PwComplexityDigitsOnly = iota
-	PwComplexityLowerOnly
-	PwComplexityUpperOnly
-	PwComplexityLowerDigits
-	PwComplexityUpperDigits
-	PwComplexityMixedOnly
-	PwComplexityDigitsMixed
-	PwComplexitySymbolsOnly
-	PwComplexitySymbolsDigits
-	PwComplexitySymbolsUpper
-	PwComplexitySymbolsLower
-	PwComplexitySymbolsMixed
-	PwComplexitySymbolsDigitsMixed
-	PwComplexityExtendedOnly
-	PwComplexityExtendedMixed // Includes extended characters and other types
+	digitChars  = "0123456789"
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	symbolChars = "!@#$%^&*()-_=+[]{}|;:'\",.<>?/`~"
 )
 
+// Options is a password policy. Its JSON form, written by MarshalJSON and read by
+// ParseOptionsJSON, covers the policy fields only: the checkers, History, UserInputs, and
+// PreviousPasswords are wired up in code.
 type Options struct {
-	MinLength         uint
-	MaxLength         uint
-	UseDigits         bool
-	UseLower          bool
-	UseUpper          bool
-	UseSymbols        bool
-	UseExtended       bool // Check for extended Unicode characters
-	MinimumComplexity int64
+	MinLength         uint       `json:"min_length,omitempty"`
+	MaxLength         uint       `json:"max_length,omitempty"`
+	MaxBytes          uint       `json:"max_bytes,omitempty"`       // Maximum length of the UTF-8 encoding, such as MaxBcryptBytes
+	CountGraphemes    bool       `json:"count_graphemes,omitempty"` // Count MinLength and MaxLength in grapheme clusters, as users see them
+	UseDigits         bool       `json:"use_digits,omitempty"`
+	UseLower          bool       `json:"use_lower,omitempty"`
+	UseUpper          bool       `json:"use_upper,omitempty"`
+	UseSymbols        bool       `json:"use_symbols,omitempty"`
+	UseExtended       bool       `json:"use_extended,omitempty"` // Check for extended Unicode characters
+	UseEmoji          bool       `json:"use_emoji,omitempty"`    // Require an emoji or a symbol beyond ASCII
+	MinDigits         uint       `json:"min_digits,omitempty"`   // Minimum number of digits, UseDigits implies at least one
+	MinLower          uint       `json:"min_lower,omitempty"`    // Minimum number of lowercase letters, UseLower implies at least one
+	MinUpper          uint       `json:"min_upper,omitempty"`    // Minimum number of uppercase letters, UseUpper implies at least one
+	MinSymbols        uint       `json:"min_symbols,omitempty"`  // Minimum number of symbols, UseSymbols implies at least one
+	MinExtended       uint       `json:"min_extended,omitempty"` // Minimum number of extended letters, UseExtended implies at least one
+	MinClasses        uint       `json:"min_classes,omitempty"`  // Require only this many of the enabled classes, or of the four ASCII classes when none is
+	MinimumComplexity Complexity `json:"minimum_complexity"`
+	MinEntropy        float64    `json:"min_entropy,omitempty"`      // Minimum EffectiveEntropy in bits, zero disables the check
+	RejectCommon      bool       `json:"reject_common,omitempty"`    // Reject passwords found in the embedded common password list
+	ExtraDictionary   []string   `json:"extra_dictionary,omitempty"` // Additional words rejected when RejectCommon is set
+	MinUniqueChars    uint       `json:"min_unique_chars,omitempty"` // Minimum number of distinct runes, case-sensitive
+	// MaxClassRatio, when set, is the largest share of the password from 0 to 1 that a single
+	// character class may make up, so 0.75 rejects "aaaaaaaaaaaA1!" with ErrClassRatio.
+	MaxClassRatio float64 `json:"max_class_ratio,omitempty"`
+
+	// SymbolSet, when set, replaces the default symbols: only its runes count as symbols for
+	// UseSymbols, MinSymbols, and entropy, and other punctuation counts as ClassOther.
+	SymbolSet string `json:"symbol_set,omitempty"`
+	// UnicodeClasses classifies runes beyond ASCII by their Unicode category instead of counting
+	// them as extended letters or emoji: digits of every script count as digits, cased letters such
+	// as Cyrillic or accented Latin as lowercase or uppercase, punctuation and symbols as symbols,
+	// and only letters without case as extended. Entropy credits each script's digits and letters.
+	UnicodeClasses bool `json:"unicode_classes,omitempty"`
+	// AllowedChars, when set, rejects passwords containing a rune outside it with ErrDisallowedChar.
+	AllowedChars string `json:"allowed_chars,omitempty"`
+	// DisallowedChars rejects passwords containing any of its runes with ErrDisallowedChar.
+	DisallowedChars string `json:"disallowed_chars,omitempty"`
+	// AllowControlChars accepts control characters such as U+0000, tabs, and the line and
+	// paragraph separators. Audit rejects them by default with ErrControlChar, since they truncate
+	// passwords in C libraries and inject lines into logs.
+	AllowControlChars bool `json:"allow_control_chars,omitempty"`
+	// RejectInvisibleChars fails passwords with zero-width, bidi, and other format characters
+	// (category Cf), including the ZWJ of emoji sequences, with ErrInvisibleChar. They make
+	// different passwords look identical.
+	RejectInvisibleChars bool `json:"reject_invisible_chars,omitempty"`
+	// ForbidLeadingTrailingSpace fails passwords starting or ending with whitespace, which login
+	// forms often trim, with ErrEdgeWhitespace.
+	ForbidLeadingTrailingSpace bool `json:"forbid_leading_trailing_space,omitempty"`
+	// RejectWhitespace fails passwords containing whitespace anywhere with ErrWhitespace. Whitespace
+	// is allowed by default so passphrases can separate their words with spaces.
+	RejectWhitespace bool `json:"reject_whitespace,omitempty"`
+	// FirstCharClasses and LastCharClasses, when set, are the classes the first and last characters
+	// must belong to, such as ClassLower|ClassUpper to forbid a leading digit.
+	FirstCharClasses Class `json:"first_char_classes,omitempty"`
+	LastCharClasses  Class `json:"last_char_classes,omitempty"`
+	// Normalize converts passwords to a Unicode normalization form before they are classified,
+	// counted, and handed to the BreachChecker and History. Use NormalizePassword with the same
+	// form when hashing and verifying.
+	Normalize Form `json:"normalize,omitempty"`
+	// Stringprep rejects characters the RFC 8265 OpaqueString profile prohibits in passwords,
+	// such as unassigned and private-use code points, with ErrProhibitedChar.
+	Stringprep bool `json:"stringprep,omitempty"`
+	// RejectConfusables fails passwords that mix in letters of other scripts looking like ASCII
+	// letters, such as the Cyrillic "а" in "pаssword", with ErrConfusable. They are reported in
+	// Result.ConfusableRunes and credited as the letters they imitate either way.
+	RejectConfusables bool `json:"reject_confusables,omitempty"`
+	// ReplaceInvalidUTF8 audits each byte of an invalid UTF-8 sequence as U+FFFD instead of failing
+	// the password with ErrInvalidUTF8.
+	ReplaceInvalidUTF8 bool `json:"replace_invalid_utf8,omitempty"`
+
+	// RequiredPatterns are regular expressions, in RE2 syntax, every password must match.
+	RequiredPatterns []string `json:"required_patterns,omitempty"`
+	// ForbiddenPatterns are regular expressions, in RE2 syntax, no password may match.
+	ForbiddenPatterns []string `json:"forbidden_patterns,omitempty"`
+	// CustomRules are checked in order after the built-in requirements; see Rule.
+	CustomRules []Rule `json:"-"`
+
+	// Dictionary, when set, rejects passwords it contains. It is always consulted, so
+	// Result.Patterns and Score reflect a dictionary word even when FailFast drops its violation.
+	Dictionary DictionaryChecker `json:"-"`
+	// BreachChecker, when set, is consulted once every local check has passed, so a password that
+	// already fails costs no network call.
+	BreachChecker BreachChecker `json:"-"`
+	// BreachCheckMode is what Audit does when BreachChecker errors or times out: reject the password
+	// (BreachEnforce, the default), accept it with a warning (BreachAdvisory), or not consult the
+	// BreachChecker at all (BreachSkip).
+	BreachCheckMode BreachCheckMode `json:"breach_check_mode,omitempty"`
+	// BreachCheckTimeout, when set, bounds how long Audit waits for BreachChecker. A checker that
+	// has not answered by then has failed, even if it ignores its context.
+	BreachCheckTimeout time.Duration `json:"breach_check_timeout,omitempty"`
+	// BreachFailOpen accepts the password when BreachChecker errors, like BreachAdvisory.
+	//
+	// Deprecated: set BreachCheckMode to BreachAdvisory.
+	BreachFailOpen bool `json:"breach_fail_open,omitempty"`
+	// NormalizeLeet decodes substitutions listed in LeetSubstitutions ("P@ssw0rd" to "password")
+	// and checks the decoded spellings against the dictionaries and BreachChecker. Matches are
+	// reported in Result.Patterns.
+	NormalizeLeet bool `json:"normalize_leet,omitempty"`
+	// UseMarkov caps EffectiveEntropy at the bits a character model of leaked passwords assigns the
+	// password, -MarkovModel.LogProb, so "Iloveyou1!" no longer rates like random characters.
+	UseMarkov bool `json:"use_markov,omitempty"`
+	// MarkovModel replaces DefaultMarkovModel when UseMarkov is set, such as one trained on your
+	// own corpus with TrainMarkov.
+	MarkovModel *MarkovModel `json:"-"`
+	// Matchers, when set, caps EffectiveEntropy at the Entropy of Analyze with these matchers, the
+	// cheapest segmentation of the password into the structures they find. Matchers() returns the
+	// registered ones.
+	Matchers []Matcher `json:"-"`
+
+	// MaxSequenceLength, when set, reports ascending or descending runs such as "abcd" or "9876"
+	// longer than this many runes in Result.Patterns and counts each run as a single character
+	// when estimating entropy. Zero disables sequence detection.
+	MaxSequenceLength uint `json:"max_sequence_length,omitempty"`
+	// RejectSequences fails passwords with a run reported by MaxSequenceLength.
+	RejectSequences bool `json:"reject_sequences,omitempty"`
+	// MaxRepeatRun, when set, rejects passwords repeating the same rune back-to-back more than this
+	// many times and reports blocks of three or more runes repeated back-to-back ("abcabc"). Both
+	// appear in Result.Patterns and are compressed when estimating entropy. Zero disables both.
+	MaxRepeatRun uint `json:"max_repeat_run,omitempty"`
+	// UserInputs are personal details such as the username, email address, or name. Passwords
+	// containing one, ignoring case and also reversed or leet-spelled, fail with
+	// ErrContainsUserInput. Inputs shorter than MinUserInputLength runes are ignored.
+	UserInputs []string `json:"-"`
+	// PreviousPasswords are earlier passwords of the same account. Candidates whose Similarity to
+	// any of them exceeds MaxSimilarity fail with ErrTooSimilar.
+	PreviousPasswords []string `json:"-"`
+	// MaxSimilarity is the highest Similarity allowed to PreviousPasswords, between 0 and 1. Zero
+	// uses DefaultMaxSimilarity.
+	MaxSimilarity float64 `json:"max_similarity,omitempty"`
+	// History, when set, rejects passwords matching one of the stored hashes of previous passwords
+	// with ErrPasswordReused. It runs last as verifying slow hashes is expensive.
+	History *HistoryChecker `json:"-"`
+	// KeyboardWalkLength, when set, reports runs of at least this many adjacent keys ("qwerty",
+	// "1qaz") on any registered keyboard layout in Result.Patterns. Zero disables detection.
+	KeyboardWalkLength uint `json:"keyboard_walk_length,omitempty"`
+	// RejectKeyboardWalks fails passwords with a walk reported by KeyboardWalkLength.
+	RejectKeyboardWalks bool `json:"reject_keyboard_walks,omitempty"`
+	// YearRange bounds the four-digit years detected as a PatternYear or within a PatternDate,
+	// DefaultYearRange when zero. Dates, years, and phone numbers always lower EffectiveEntropy.
+	YearRange Years `json:"year_range,omitzero"`
+	// RejectDates fails passwords containing a date, a year, or a phone number with ErrDate.
+	RejectDates bool `json:"reject_dates,omitempty"`
+	// RejectPII fails passwords containing a payment card number or an SSN-like number with
+	// ErrLooksLikePII. Both are reported in Result.Patterns either way, without their digits.
+	RejectPII bool `json:"reject_pii,omitempty"`
+	// PolicyName names the policy these Options encode, such as "NIST SP 800-63B". It is copied to
+	// Result.Policy so audits can be traced back to the policy that produced them.
+	PolicyName string `json:"policy_name,omitempty"`
+	FailFast   bool   `json:"fail_fast,omitempty"` // Report only the first failed requirement and skip the checks after it; the analysis is still computed
+	// Report, when set, receives the Result of every password AuditAll and AuditReader audit, so a
+	// corpus can be summarized without keeping its Results. Audit does not add to it.
+	Report *Report `json:"-"`
+	// Metrics, when set, receives the violation codes, entropy, and duration of every audit the
+	// Validator runs, never the password. Nil discards them.
+	Metrics Metrics `json:"-"`
+	// Logger, when set, receives a record of every audit at LogLevel with the policy, duration,
+	// violation codes, entropy bucket, and breach outcome. Values that could carry the password are
+	// redacted. Nil, the default, logs nothing.
+	Logger   *slog.Logger `json:"-"`
+	LogLevel slog.Level   `json:"-"` // Level of the audit records, slog.LevelInfo when zero
+}
+
+// Clone returns a deep copy of opts: its slices and History, with its Hashes, are copied, so
+// changing either Options afterwards does not affect the other. The services it refers to,
+// Dictionary, BreachChecker, MarkovModel, the CustomRules and Matchers themselves,
+// History.Verify, Report, Metrics, and Logger, are shared, as they are meant to be.
+func (opts Options) Clone() Options {
+	opts.ExtraDictionary = slices.Clone(opts.ExtraDictionary)
+	opts.RequiredPatterns = slices.Clone(opts.RequiredPatterns)
+	opts.ForbiddenPatterns = slices.Clone(opts.ForbiddenPatterns)
+	opts.CustomRules = slices.Clone(opts.CustomRules)
+	opts.Matchers = slices.Clone(opts.Matchers)
+	opts.UserInputs = slices.Clone(opts.UserInputs)
+	opts.PreviousPasswords = slices.Clone(opts.PreviousPasswords)
+	if opts.History != nil {
+		history := *opts.History
+		history.Hashes = slices.Clone(history.Hashes)
+		opts.History = &history
+	}
+	return opts
 }
 
 type Result struct {
-	Entropy     float64
-	Strong      bool
-	Length      int64
-	Complexity  int64
-	HasExtended bool // True if the password contains extended characters
-	Err         error
+	Entropy          float64 // Alias of CharsetEntropy, kept for compatibility
+	CharsetEntropy   float64 // Length times the bits per rune of the inferred charset, the uniform random maximum
+	ShannonEntropy   float64 // Length times the Shannon entropy of the password's own rune frequencies
+	EffectiveEntropy float64 // CharsetEntropy after penalties for patterns, passphrases, and dictionary hits
+	Strong           bool
+	Length           int64 // Length in characters: runes, with each emoji sequence counted once, or LengthGraphemes with CountGraphemes
+	LengthBytes      int64 // Length of the UTF-8 encoding in bytes
+	LengthRunes      int64 // Length in Unicode code points
+	LengthGraphemes  int64 // Length in extended grapheme clusters (UAX #29), "e" and a combining accent counted once
+	TruncationRisk   bool  // True if LengthBytes exceeds MaxBcryptBytes, so bcrypt would ignore the rest
+	UniqueChars      int   // Number of distinct runes, with 'A' and 'a' counted as two
+	Complexity       Complexity
+	HasExtended      bool             // True if the password contains extended characters
+	HasEmoji         bool             // True if the password contains emoji or symbols beyond ASCII
+	ConfusableRunes  []ConfusableRune // Letters of other scripts mixed in that look like ASCII letters
+	Scripts          []string         // Scripts of the extended letters, such as "Cyrillic"
+	Classes          Class            // Bitmask of every character class detected
+	Counts           Counts           // Number of runes found in each character class
+	PwnedCount       int              // Times the password appears in breach data, zero when unchecked or unseen
+	HistoryMatch     bool             // True if the password matches a hash in Options.History
+	HistoryIndex     int              // Slot of Options.History.Hashes that matched, valid when HistoryMatch is set
+	PassphraseWords  int              // Words of the embedded EFF list found when the password is a passphrase, else zero
+	Policy           string           // Options.PolicyName of the policy the password was audited against
+	Score            int              // Strength from 0 to 100, see ScoreOf
+	Rating           Rating           // Qualitative bucket of Score
+	CrackTimes       CrackTimes       // Estimated seconds to guess the password for each attacker profile
+	Patterns         []Match          // Weak structures detected in the password
+	Violations       []error          // Every failed requirement, in the order they were checked
+	BreachChecked    bool             // True if Options.BreachChecker answered, so a zero PwnedCount means unseen
+	Warnings         []error          // Advisory *Warning failures, which do not fail the password: Options.CustomRules and BreachAdvisory checks
+	Err              error            // All violations joined with errors.Join, nil when the password passes
 }
 
+// Audit checks pass against opts. It is a thin wrapper around a throwaway Validator; use
+// NewValidator when auditing many passwords with the same Options. Options that fail Validate
+// are reported in Result.Err, wrapping ErrInvalidOptions, without auditing pass.
 func Audit(pass string, opts Options) Result {
-	var audit Result
+	required, forbidden, err := opts.validate()
+	if err != nil {
+		return invalidOptionsResult(&opts, err)
+	}
+	v := newValidator(opts, required, forbidden)
+	return v.Audit(pass)
+}
+
+// Audit checks pass against the Validator's Options.
+func (v *Validator) Audit(pass string) Result {
+	return v.auditContext(context.Background(), pass)
+}
+
+// auditContext normalizes, classifies, and audits pass, reporting the audit to the Metrics and
+// Logger. ctx bounds the breach and history checks.
+func (v *Validator) auditContext(ctx context.Context, pass string) Result {
+	start := time.Now()
+	pass = NormalizePassword(pass, v.opts.Normalize)
+	counts, length := classify(pass, v.opts.SymbolSet, v.opts.UnicodeClasses)
+	audit := v.audit(ctx, pass, counts, length)
+	v.record(ctx, pass, &audit, start)
+	return audit
+}
+
+// audit is Audit with pass already classified, so Meter can keep the counts up to date itself.
+// It reports nothing to the Metrics or Logger, leaving that to its callers.
+func (v *Validator) audit(ctx context.Context, pass string, counts Counts, length int) Result {
+	opts := &v.opts
+	audit := Result{Policy: opts.PolicyName}
 
-	length := len(pass)
 	audit.Length = int64(length)
+	audit.LengthBytes = int64(len(pass))
+	audit.LengthRunes = int64(utf8.RuneCountInString(pass))
+	audit.LengthGraphemes = int64(graphemeCount(pass))
+	audit.TruncationRisk = len(pass) > MaxBcryptBytes
 
-	if length < int(opts.MinLength) {
-		audit.Err = errors.New("password too short")
-		return audit
+	// The length limits count what users see; everything else, entropy included, counts characters.
+	counted := length
+	if opts.CountGraphemes {
+		counted = int(audit.LengthGraphemes)
+		audit.Length = audit.LengthGraphemes
 	}
 
-	if opts.MaxLength > 0 && length > int(opts.MaxLength) {
-		audit.Err = errors.New("password too long")
-		return audit
+	if counted < int(opts.MinLength) {
+		audit.violate(validationError(CodeTooShort, "MinLength", fmt.Errorf("%w: %d characters, minimum is %d", ErrTooShort, counted, opts.MinLength),
+			"length", counted, "min_length", opts.MinLength), opts.FailFast)
 	}
 
-	// Initialize character type flags
-	hasDigits := strings.ContainsAny(pass, "0123456789")
-	hasLower := strings.ContainsAny(pass, "abcdefghijklmnopqrstuvwxyz")
-	hasUpper := strings.ContainsAny(pass, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
-	hasSymbols := strings.ContainsAny(pass, "!@#$%^&*()-_=+[]{}|;:'\",.<>?/`~")
-	hasExtended := containsExtended(pass)
+	if opts.MaxLength > 0 && counted > int(opts.MaxLength) {
+		audit.violate(validationError(CodeTooLong, "MaxLength", fmt.Errorf("%w: %d characters, maximum is %d", ErrTooLong, counted, opts.MaxLength),
+			"length", counted, "max_length", opts.MaxLength), opts.FailFast)
+	}
+
+	if opts.MaxBytes > 0 && len(pass) > int(opts.MaxBytes) {
+		audit.violate(validationError(CodeTooManyBytes, "MaxBytes", fmt.Errorf("%w: %d bytes, maximum is %d", ErrTooManyBytes, len(pass), opts.MaxBytes),
+			"bytes", len(pass), "max_bytes", opts.MaxBytes), opts.FailFast)
+	}
+
+	if !opts.ReplaceInvalidUTF8 && !utf8.ValidString(pass) {
+		b, i := invalidUTF8(pass)
+		audit.violate(validationError(CodeInvalidUTF8, "ReplaceInvalidUTF8", fmt.Errorf("%w: byte %#02x at position %d", ErrInvalidUTF8, b, i),
+			"byte", fmt.Sprintf("%#02x", b), "position", i), opts.FailFast)
+	}
+	if !opts.AllowControlChars {
+		if r, i := firstRune(pass, isControl); i >= 0 {
+			audit.violate(validationError(CodeControlChar, "AllowControlChars", fmt.Errorf("%w: %U at position %d", ErrControlChar, r, i),
+				"char", fmt.Sprintf("%U", r), "position", i), opts.FailFast)
+		}
+	}
+	if opts.Stringprep {
+		r, i, err := prohibitedRune(pass)
+		switch {
+		case i >= 0:
+			err = fmt.Errorf("%w: %U at position %d", ErrProhibitedChar, r, i)
+			audit.violate(validationError(CodeProhibitedChar, "Stringprep", err, "char", fmt.Sprintf("%U", r), "position", i), opts.FailFast)
+		case err != nil:
+			audit.violate(validationError(CodeProhibitedChar, "Stringprep", fmt.Errorf("%w: %v", ErrProhibitedChar, err)), opts.FailFast)
+		}
+	}
+	if opts.RejectInvisibleChars {
+		if r, i := firstRune(pass, isInvisible); i >= 0 {
+			audit.violate(validationError(CodeInvisibleChar, "RejectInvisibleChars", fmt.Errorf("%w: %U at position %d", ErrInvisibleChar, r, i),
+				"char", fmt.Sprintf("%U", r), "position", i), opts.FailFast)
+		}
+	}
+
+	if opts.RejectWhitespace {
+		if r, i := firstRune(pass, isWhitespace); i >= 0 {
+			audit.violate(validationError(CodeWhitespace, "RejectWhitespace", fmt.Errorf("%w: %U at position %d", ErrWhitespace, r, i),
+				"char", fmt.Sprintf("%U", r), "position", i), opts.FailFast)
+		}
+	} else if opts.ForbidLeadingTrailingSpace {
+		if i := edgeWhitespace(pass); i >= 0 {
+			audit.violate(validationError(CodeEdgeWhitespace, "ForbidLeadingTrailingSpace", fmt.Errorf("%w: at position %d", ErrEdgeWhitespace, i),
+				"position", i), opts.FailFast)
+		}
+	}
+	if (opts.FirstCharClasses != 0 || opts.LastCharClasses != 0) && length > 0 {
+		first, last, lastIndex := boundaryClasses(pass, opts.SymbolSet, opts.UnicodeClasses)
+		if opts.FirstCharClasses != 0 && opts.FirstCharClasses&first == 0 {
+			audit.violate(validationError(CodeFirstChar, "FirstCharClasses", fmt.Errorf("%w: %s", ErrFirstChar, first),
+				"class", first.String(), "position", 0), opts.FailFast)
+		}
+		if opts.LastCharClasses != 0 && opts.LastCharClasses&last == 0 {
+			audit.violate(validationError(CodeLastChar, "LastCharClasses", fmt.Errorf("%w: %s at position %d", ErrLastChar, last, lastIndex),
+				"class", last.String(), "position", lastIndex), opts.FailFast)
+		}
+	}
+
+	var skeleton string
+	// Under UnicodeClasses lookalike letters are counted as lowercase or uppercase.
+	if counts.Extended > 0 || opts.UnicodeClasses && length < len(pass) {
+		audit.ConfusableRunes, skeleton = confusablesOf(pass)
+		if opts.RejectConfusables && len(audit.ConfusableRunes) > 0 {
+			c := audit.ConfusableRunes[0]
+			audit.violate(validationError(CodeConfusable, "RejectConfusables", fmt.Errorf("%w: %q looks like %q at position %d", ErrConfusable, c.Char, c.LooksLike, c.Index),
+				"char", c.Char, "looks_like", c.LooksLike, "position", c.Index), opts.FailFast)
+		}
+	}
+
+	if opts.AllowedChars != "" || opts.DisallowedChars != "" {
+		if r, i := disallowedRune(pass, opts.AllowedChars, opts.DisallowedChars); i >= 0 {
+			field := "DisallowedChars"
+			if opts.AllowedChars != "" && !strings.ContainsRune(opts.AllowedChars, r) {
+				field = "AllowedChars"
+			}
+			audit.violate(validationError(CodeDisallowedChar, field, fmt.Errorf("%w: %q at position %d", ErrDisallowedChar, r, i),
+				"char", string(r), "position", i), opts.FailFast)
+		}
+	}
+
+	if len(v.required) > 0 || len(v.forbidden) > 0 {
+		v.matchPatterns(&audit, pass)
+	}
+
+	// Initialize character type flags. Lookalike letters are credited as the ASCII letters they
+	// imitate, so disguising a password with them does not make it look stronger.
+	classes := counts.Classes()
+	strength, credited := classes, pass
+	if skeleton != "" {
+		skeletonCounts, _ := classify(skeleton, opts.SymbolSet, opts.UnicodeClasses)
+		strength, credited = skeletonCounts.Classes(), skeleton
+	}
+	hasDigits := strength.Has(ClassDigits)
+	hasLower := strength.Has(ClassLower)
+	hasUpper := strength.Has(ClassUpper)
+	hasSymbols := strength.Has(ClassSymbols)
+	hasExtended := strength.Has(ClassExtended)
+	hasOther := strength.Has(ClassOther)
+	hasEmoji := strength.Has(ClassEmoji)
+	audit.Counts = counts
 
 	// Check requirements
-	if opts.UseDigits && !hasDigits {
-		audit.Err = errors.New("password must contain digits")
-		return audit
+	requirements := [...]struct {
+		min   uint
+		count int
+		err   error
+		code  string
+		field string
+		name  string
+	}{
+		{minimumCount(opts.UseDigits, opts.MinDigits), counts.Digits, ErrMissingDigits, CodeMissingDigit, "MinDigits", "digits"},
+		{minimumCount(opts.UseLower, opts.MinLower), counts.Lower, ErrMissingLower, CodeMissingLower, "MinLower", "lower"},
+		{minimumCount(opts.UseUpper, opts.MinUpper), counts.Upper, ErrMissingUpper, CodeMissingUpper, "MinUpper", "upper"},
+		{minimumCount(opts.UseSymbols, opts.MinSymbols), counts.Symbols, ErrMissingSymbols, CodeMissingSymbol, "MinSymbols", "symbols"},
+		{minimumCount(opts.UseExtended, opts.MinExtended), counts.Extended, ErrMissingExtended, CodeMissingExtended, "MinExtended", "extended"},
+		{minimumCount(opts.UseEmoji, 0), counts.Emoji, ErrMissingEmoji, CodeMissingEmoji, "UseEmoji", "emoji"},
+	}
+	if opts.MinClasses > 0 {
+		defaults := true
+		for _, req := range requirements {
+			defaults = defaults && req.min == 0
+		}
+		var found, missing []string
+		for i, req := range requirements {
+			if defaults && i < defaultClassCount {
+				req.min = 1
+			}
+			switch {
+			case req.min == 0:
+			case req.count >= int(req.min):
+				found = append(found, req.name)
+			default:
+				missing = append(missing, req.name)
+			}
+		}
+		if need := int(opts.MinClasses) - len(found); need > 0 {
+			list := strings.Join(found, ", ")
+			if list == "" {
+				list = "none"
+			}
+			audit.violate(validationError(CodeTooFewClasses, "MinClasses", fmt.Errorf("%w: found %s, %d more of %s needed", ErrTooFewClasses, list, need, strings.Join(missing, ", ")),
+				"found", strings.Join(found, ","), "missing", strings.Join(missing, ","), "count", need, "min_classes", opts.MinClasses), opts.FailFast)
+		}
+	} else {
+		for _, req := range requirements {
+			if req.count >= int(req.min) {
+				continue
+			}
+			err := req.err
+			if req.min > 1 {
+				err = fmt.Errorf("%w: found %d, minimum is %d", req.err, req.count, req.min)
+			}
+			audit.violate(validationError(req.code, req.field, err, "count", req.count, "min", req.min), opts.FailFast)
+		}
+	}
+
+	audit.UniqueChars = uniqueRunes(pass)
+	if audit.UniqueChars < int(opts.MinUniqueChars) {
+		audit.violate(validationError(CodeTooFewUnique, "MinUniqueChars", fmt.Errorf("%w: %d, minimum is %d", ErrTooFewUnique, audit.UniqueChars, opts.MinUniqueChars),
+			"unique_chars", audit.UniqueChars, "min_unique_chars", opts.MinUniqueChars), opts.FailFast)
+	}
+
+	if opts.MaxClassRatio > 0 && length > 0 {
+		class, count := largestClass(counts)
+		if ratio := float64(count) / float64(length); ratio > opts.MaxClassRatio {
+			audit.violate(validationError(CodeClassRatio, "MaxClassRatio", fmt.Errorf("%w: %s are %.0f%%, maximum is %.0f%%", ErrClassRatio, class, ratio*100, opts.MaxClassRatio*100),
+				"class", class, "percent", math.Round(ratio*100), "max_percent", math.Round(opts.MaxClassRatio*100)), opts.FailFast)
+		}
 	}
 
-	if opts.UseLower && !hasLower {
-		audit.Err = errors.New("password must contain lowercase letters")
-		return audit
+	// Personal numbers are found first so no other detector quotes their digits in an error.
+	pii := detectPII(pass)
+	if opts.MaxSequenceLength > 0 || opts.MaxRepeatRun > 0 || opts.KeyboardWalkLength > 0 || len(opts.UserInputs) > 0 {
+		runes := []rune(pass)
+		if opts.MaxSequenceLength > 0 {
+			sequences := detectSequences(runes, int(opts.MaxSequenceLength))
+			redactPII(sequences, pii)
+			audit.Patterns = append(audit.Patterns, sequences...)
+			if opts.RejectSequences && len(sequences) > 0 {
+				p := sequences[0]
+				audit.violate(validationError(CodeSequence, "RejectSequences", patternError(ErrSequentialChars, p),
+					"position", p.Start, "max_length", opts.MaxSequenceLength), opts.FailFast)
+			}
+		}
+		if opts.MaxRepeatRun > 0 {
+			repeats := detectRepeats(runes, int(opts.MaxRepeatRun))
+			blocks := detectRepeatedBlocks(runes)
+			redactPII(repeats, pii)
+			redactPII(blocks, pii)
+			audit.Patterns = append(audit.Patterns, repeats...)
+			audit.Patterns = append(audit.Patterns, blocks...)
+			if len(repeats) > 0 {
+				p := repeats[0]
+				audit.violate(validationError(CodeRepeatedChars, "MaxRepeatRun", patternError(ErrRepeatedChars, p),
+					"position", p.Start, "max_run", opts.MaxRepeatRun), opts.FailFast)
+			}
+		}
+		if opts.KeyboardWalkLength > 0 {
+			walks := detectKeyboardWalks(runes, int(opts.KeyboardWalkLength))
+			redactPII(walks, pii)
+			audit.Patterns = append(audit.Patterns, walks...)
+			if opts.RejectKeyboardWalks && len(walks) > 0 {
+				p := walks[0]
+				audit.violate(validationError(CodeKeyboardWalk, "RejectKeyboardWalks", patternError(ErrKeyboardWalk, p),
+					"position", p.Start, "length", opts.KeyboardWalkLength), opts.FailFast)
+			}
+		}
+		if len(opts.UserInputs) > 0 {
+			matches := detectUserInputs(pass, opts.UserInputs)
+			redactPII(matches, pii)
+			audit.Patterns = append(audit.Patterns, matches...)
+			for _, p := range matches {
+				err := fmt.Errorf("%w: %q", ErrContainsUserInput, p.Base)
+				if p.Base == "" {
+					err = fmt.Errorf("%w at position %d", ErrContainsUserInput, p.Start)
+				}
+				audit.violate(validationError(CodeUserInput, "UserInputs", err,
+					"position", p.Start), opts.FailFast)
+			}
+		}
 	}
 
-	if opts.UseUpper && !hasUpper {
-		audit.Err = errors.New("password must contain uppercase letters")
-		return audit
+	if dates := detectDates(pass, yearRangeOf(opts.YearRange)); len(dates) > 0 {
+		redactPII(dates, pii)
+		audit.Patterns = append(audit.Patterns, dates...)
+		if opts.RejectDates {
+			p := dates[0]
+			audit.violate(validationError(CodeDate, "RejectDates", patternError(ErrDate, p),
+				"kind", p.Kind, "position", p.Start), opts.FailFast)
+		}
+	}
+	if len(pii) > 0 {
+		audit.Patterns = append(audit.Patterns, pii...)
+		if opts.RejectPII {
+			p := pii[0]
+			audit.violate(validationError(CodePII, "RejectPII", fmt.Errorf("%w: %s at position %d", ErrLooksLikePII, p.Kind, p.Start),
+				"kind", p.Kind, "position", p.Start), opts.FailFast)
+		}
 	}
+	compressible := len(audit.Patterns) // Matches the password is compressed by; later ones mark it compromised
 
-	if opts.UseSymbols && !hasSymbols {
-		audit.Err = errors.New("password must contain symbols")
-		return audit
+	if len(opts.PreviousPasswords) > 0 {
+		limit := opts.MaxSimilarity
+		if limit == 0 {
+			limit = DefaultMaxSimilarity
+		}
+		if i, similarity := mostSimilar(pass, opts.PreviousPasswords); similarity > limit {
+			audit.violate(validationError(CodeTooSimilar, "PreviousPasswords", fmt.Errorf("%w: %.0f%% similar to previous password %d", ErrTooSimilar, similarity*100, i),
+				"similarity", similarity, "max_similarity", limit, "index", i), opts.FailFast)
+		}
 	}
 
-	if opts.UseExtended && !hasExtended {
-		audit.Err = errors.New("password must contain extended Unicode characters")
-		return audit
+	compromised := false
+	dictionaryField := "Dictionary"
+	if opts.RejectCommon {
+		dictionaryField = "RejectCommon"
+	}
+	// The local dictionaries always run so Patterns and Score reflect a common password even when
+	// an earlier requirement failed; violate drops the violation under FailFast.
+	if word, ok := v.dictionaryWord(pass); ok {
+		compromised = true
+		audit.Patterns = append(audit.Patterns, prefixMatch(PatternDictionary, pass, word))
+		audit.violate(validationError(CodeCommonPassword, dictionaryField, ErrCommonPassword), opts.FailFast)
+	} else if _, ok := v.dictionaryWord(skeleton); skeleton != "" && ok {
+		compromised = true
+		audit.Patterns = append(audit.Patterns, confusablePattern(pass, skeleton))
+		audit.violate(validationError(CodeCommonPassword, dictionaryField, fmt.Errorf("%w: disguised %q", ErrCommonPassword, skeleton)), opts.FailFast)
+	} else if opts.NormalizeLeet && (opts.RejectCommon || opts.Dictionary != nil) {
+		if p, ok := v.leetDictionaryMatch(pass); ok {
+			compromised = true
+			audit.Patterns = append(audit.Patterns, p)
+			audit.violate(validationError(CodeCommonPassword, "NormalizeLeet", fmt.Errorf("%w: disguised %q", ErrCommonPassword, p.Base)), opts.FailFast)
+		}
+	}
+	if !compromised {
+		if p, ok := v.wordVariantMatch(pass); ok {
+			compromised = true
+			audit.Patterns = append(audit.Patterns, p)
+			audit.violate(validationError(CodeCommonPassword, dictionaryField, fmt.Errorf("%w: %s %q", ErrCommonPassword, variantName(p.Kind), p.Base)), opts.FailFast)
+		}
+	}
+	if d, ok := opts.Dictionary.(*Dictionary); ok && !compromised {
+		runes := utf8.RuneCountInString(pass)
+		if word, ok := d.Closest(pass); ok {
+			compromised = true
+			audit.Patterns = append(audit.Patterns, Match{Kind: PatternDictionary, Token: strings.Clone(pass), End: runes, Base: word})
+			audit.violate(validationError(CodeCommonPassword, "Dictionary", fmt.Errorf("%w: one edit from %q", ErrCommonPassword, word)), opts.FailFast)
+		} else if p, ok := d.ContainsSubstring(pass); ok && 2*p.Len() >= runes {
+			// A word making up at least half the password is what an attacker tries first.
+			compromised = true
+			audit.Patterns = append(audit.Patterns, p)
+			audit.violate(validationError(CodeCommonPassword, "Dictionary", fmt.Errorf("%w: contains %q", ErrCommonPassword, p.Base)), opts.FailFast)
+		}
+	}
+
+	if mode := opts.breachMode(); opts.BreachChecker != nil && mode != BreachSkip && audit.Err == nil {
+		out := checkBreach(ctx, opts.BreachChecker, opts.BreachCheckTimeout, pass, skeleton, opts.NormalizeLeet)
+		audit.BreachChecked = out.err == nil
+		switch {
+		case out.err != nil && mode == BreachAdvisory:
+			audit.Warnings = append(audit.Warnings, Warn(validationError(CodeBreachCheckFailed, "BreachChecker", fmt.Errorf("%w: %w", ErrBreachCheckUnavailable, out.err))))
+		case out.err != nil:
+			audit.violate(validationError(CodeBreachCheckFailed, "BreachChecker", fmt.Errorf("%w: %w", ErrBreachCheckUnavailable, out.err)), opts.FailFast)
+		case out.breached:
+			compromised = true
+			audit.PwnedCount = out.count
+			if out.pattern.Kind != "" {
+				audit.Patterns = append(audit.Patterns, out.pattern)
+			}
+			audit.violate(validationError(CodePwned, "BreachChecker", fmt.Errorf("%w: seen %d times", ErrPwnedPassword, out.count),
+				"pwned_count", out.count), opts.FailFast)
+		}
+	}
+
+	if opts.History != nil && audit.Err == nil {
+		slot, err := opts.History.Check(ctx, pass)
+		switch {
+		case err != nil:
+			audit.violate(validationError(CodeHistoryCheckFailed, "History", fmt.Errorf("%w: %v", ErrHistoryCheckFailed, err)), opts.FailFast)
+		case slot >= 0:
+			audit.HistoryMatch = true
+			audit.HistoryIndex = slot
+			audit.violate(validationError(CodeReused, "History", fmt.Errorf("%w: history slot %d", ErrPasswordReused, slot),
+				"index", slot), opts.FailFast)
+		}
 	}
 
 	// Calculate entropy
 	charsetSize := 0
+	if opts.UnicodeClasses {
+		// Only the ASCII runes of a class get its ASCII charset; the rest are credited by script.
+		ascii, size := unicodeCharset(credited, opts.SymbolSet)
+		hasDigits, hasLower, hasUpper = ascii.Has(ClassDigits), ascii.Has(ClassLower), ascii.Has(ClassUpper)
+		hasSymbols = ascii.Has(ClassSymbols)
+		hasExtended = hasExtended || len(credited) > length // Cased letters beyond ASCII
+		charsetSize += size
+	}
 	if hasDigits {
 		charsetSize += 10
 	}
@@ -122,62 +700,316 @@ func Audit(pass string, opts Options) Result {
 		charsetSize += 26
 	}
 	if hasSymbols {
-		charsetSize += len("!@#$%^&*()-_=+[]{}|;:'\",.<>?/`~")
+		charsetSize += v.symbols
 	}
 	if hasExtended {
-		charsetSize += 100 // Rough estimate for Unicode letters beyond ASCII
+		scripts, size := scriptsOf(credited, opts.SymbolSet)
+		audit.Scripts = scripts
+		charsetSize += size
+	}
+	if skeleton != "" {
+		audit.Scripts, _ = scriptsOf(pass, opts.SymbolSet) // The scripts as written
+	}
+	if hasOther {
+		// Whitespace alone is a small alphabet; anything else of the class is credited in full.
+		spaces := countWhitespace(pass, opts.SymbolSet, opts.UnicodeClasses)
+		if spaces > 0 {
+			charsetSize += whitespaceCharsetSize
+		}
+		if spaces < counts.Other {
+			charsetSize += otherCharsetSize
+		}
+	}
+	if hasEmoji {
+		charsetSize += emojiCharsetSize
+	}
+
+	if v.allowed > 0 && charsetSize > v.allowed {
+		charsetSize = v.allowed // No guess needs to try runes outside AllowedChars
+	}
+	bitsPerRune := 0.0
+	if charsetSize > 0 {
+		bitsPerRune = math.Log2(float64(charsetSize))
+		audit.CharsetEntropy = float64(length) * bitsPerRune
+		audit.EffectiveEntropy = float64(compress(length, audit.Patterns[:compressible], bitsPerRune)) * bitsPerRune
+	}
+	audit.Entropy = audit.CharsetEntropy
+	audit.ShannonEntropy = shannonEntropy(pass, length)
+	// A passphrase is only as strong as the number of words picked, not its letters.
+	if words := passphraseWords(pass); words >= MinPassphraseWords {
+		audit.PassphraseWords = words
+		audit.EffectiveEntropy = passphraseEntropy(words)
+	}
+	if opts.UseMarkov && length > 0 {
+		model := opts.MarkovModel
+		if model == nil {
+			model = DefaultMarkovModel()
+		}
+		audit.EffectiveEntropy = min(audit.EffectiveEntropy, -model.LogProb(pass))
+	}
+	if len(opts.Matchers) > 0 && length > 0 {
+		audit.EffectiveEntropy = min(audit.EffectiveEntropy, analyze(pass, bitsPerRune, opts.Matchers).Entropy)
+	}
+	if compromised && audit.EffectiveEntropy > compromisedEntropy {
+		// The dictionary or disguised breach match, when there is one, removed what is left.
+		if compressible < len(audit.Patterns) {
+			audit.Patterns[compressible].Penalty = audit.EffectiveEntropy - compromisedEntropy
+		}
+		audit.EffectiveEntropy = compromisedEntropy
+	}
+	audit.HasExtended = classes.Has(ClassExtended)
+	audit.HasEmoji = classes.Has(ClassEmoji)
+
+	if opts.MinEntropy > 0 && audit.EffectiveEntropy < opts.MinEntropy {
+		audit.violate(validationError(CodeLowEntropy, "MinEntropy", fmt.Errorf("%w: %.2f bits, minimum is %.2f", ErrEntropyTooLow, audit.EffectiveEntropy, opts.MinEntropy),
+			"entropy", audit.EffectiveEntropy, "min_entropy", opts.MinEntropy), opts.FailFast)
+	}
+
+	if len(opts.CustomRules) > 0 && !(opts.FailFast && audit.Err != nil) {
+		runRules(&audit, opts, pass)
+	}
+
+	audit.Classes = classes
+	audit.Complexity = complexityOf(strength)
+	audit.Score = scoreOf(audit.EffectiveEntropy, audit.Length, strength, compromised)
+	audit.Rating = RatingOf(audit.Score)
+	audit.CrackTimes = crackTimesOf(audit.EffectiveEntropy)
+
+	// Passphrases earn their strength from length rather than character classes.
+	audit.Strong = audit.Err == nil && (audit.PassphraseWords > 0 || ComplexityStrength(audit.Complexity) >= ComplexityStrength(opts.MinimumComplexity))
+
+	return audit
+}
+
+// invalidOptionsResult is the failed Result Audit returns for Options that fail Validate.
+func invalidOptionsResult(opts *Options, err error) Result {
+	err = validationError(CodeInvalidOptions, "", err)
+	return Result{Policy: opts.PolicyName, Violations: []error{err}, Err: err}
+}
+
+// violate records a failed requirement, unless failFast is set and one is already recorded, and
+// reports whether failFast stops the remaining checks. The analysis of the password is computed
+// either way.
+func (audit *Result) violate(err error, failFast bool) bool {
+	if failFast && audit.Err != nil {
+		return true
+	}
+	audit.Violations = append(audit.Violations, err)
+	audit.Err = errors.Join(audit.Violations...)
+	return failFast
+}
+
+// Counts holds the number of runes found in each character class.
+type Counts struct {
+	Digits   int `json:"digits"`
+	Lower    int `json:"lower"`
+	Upper    int `json:"upper"`
+	Symbols  int `json:"symbols"`
+	Extended int `json:"extended"`
+	Other    int `json:"other"`
+	Emoji    int `json:"emoji"`
+}
+
+// Classes returns the bitmask of classes with at least one rune.
+func (counts Counts) Classes() Class {
+	var classes Class
+	if counts.Digits > 0 {
+		classes |= ClassDigits
+	}
+	if counts.Lower > 0 {
+		classes |= ClassLower
+	}
+	if counts.Upper > 0 {
+		classes |= ClassUpper
+	}
+	if counts.Symbols > 0 {
+		classes |= ClassSymbols
+	}
+	if counts.Extended > 0 {
+		classes |= ClassExtended
+	}
+	if counts.Other > 0 {
+		classes |= ClassOther
+	}
+	if counts.Emoji > 0 {
+		classes |= ClassEmoji
+	}
+	return classes
+}
+
+// asciiClasses maps every ASCII rune to its character class so classify avoids string scans.
+var asciiClasses = func() (table [utf8.RuneSelf]Class) {
+	for i := range table {
+		table[i] = ClassOther
+	}
+	for _, set := range []struct {
+		chars string
+		class Class
+	}{
+		{digitChars, ClassDigits},
+		{lowerChars, ClassLower},
+		{upperChars, ClassUpper},
+		{symbolChars, ClassSymbols},
+	} {
+		for i := 0; i < len(set.chars); i++ {
+			table[set.chars[i]] = set.class
+		}
+	}
+	return table
+}()
+
+// classify walks the password once, counting the characters in each character class and its
+// length. Characters are runes, except that an emoji sequence joined with ZWJs, modifiers, or
+// variation selectors, and a flag, count as one emoji. A non-empty symbols replaces the default
+// symbol characters, and unicodeClasses classifies runes beyond ASCII as Options.UnicodeClasses
+// describes.
+func classify(pass, symbols string, unicodeClasses bool) (Counts, int) {
+	var counts Counts
+	var joiner emojiJoiner
+	length := 0
+	for _, r := range pass {
+		class := classOf(r, symbols, unicodeClasses)
+		if joiner.extends(r, class) {
+			continue
+		}
+		length++
+		switch class {
+		case ClassDigits:
+			counts.Digits++
+		case ClassLower:
+			counts.Lower++
+		case ClassUpper:
+			counts.Upper++
+		case ClassSymbols:
+			counts.Symbols++
+		case ClassExtended:
+			counts.Extended++
+		case ClassEmoji:
+			counts.Emoji++
+		default:
+			counts.Other++
+		}
+	}
+	return counts, length
+}
+
+// classOf returns the class of r, counting only the runes of symbols as symbols when it is set.
+func classOf(r rune, symbols string, unicodeClasses bool) Class {
+	switch {
+	case symbols != "" && strings.ContainsRune(symbols, r):
+		return ClassSymbols
+	case r < utf8.RuneSelf:
+		if class := asciiClasses[r]; class != ClassSymbols || symbols == "" {
+			return class
+		}
+	case unicodeClasses:
+		return unicodeClassOf(r, symbols)
+	case unicode.IsLetter(r):
+		return ClassExtended
+	case isEmoji(r):
+		return ClassEmoji
+	}
+	return ClassOther
+}
+
+// symbolSetOf returns the runes opts counts as symbols.
+func symbolSetOf(opts *Options) string {
+	if opts.SymbolSet != "" {
+		return opts.SymbolSet
+	}
+	return symbolChars
+}
+
+// extendedSetOf returns the extended letters Generate draws from for opts.
+func extendedSetOf(opts *Options) string {
+	if opts.UnicodeClasses {
+		return uncasedChars
+	}
+	return extendedChars
+}
+
+// permittedChars returns chars without the runes opts does not allow.
+func permittedChars(opts *Options, chars string) string {
+	if opts.AllowedChars == "" && opts.DisallowedChars == "" {
+		return chars
 	}
+	return strings.Map(func(r rune) rune {
+		if (opts.AllowedChars != "" && !strings.ContainsRune(opts.AllowedChars, r)) || strings.ContainsRune(opts.DisallowedChars, r) {
+			return -1
+		}
+		return r
+	}, chars)
+}
 
-	audit.Entropy = float64(length) * math.Log2(float64(charsetSize))
-	audit.HasExtended = hasExtended
+// disallowedRune returns the first rune of pass, and its rune index, that is outside a non-empty
+// allowed or inside disallowed. The index is -1 when every rune is permitted.
+func disallowedRune(pass, allowed, disallowed string) (rune, int) {
+	i := 0
+	for _, r := range pass {
+		if (allowed != "" && !strings.ContainsRune(allowed, r)) || strings.ContainsRune(disallowed, r) {
+			return r, i
+		}
+		i++
+	}
+	return 0, -1
+}
+
+// defaultClassCount is the number of classes Options.MinClasses picks from when no class is
+// enabled: digits, lowercase letters, uppercase letters, and symbols.
+const defaultClassCount = 4
+
+// minimumCount returns the number of runes a class requires; a Use* flag implies at least one.
+func minimumCount(use bool, min uint) uint {
+	if use && min == 0 {
+		return 1
+	}
+	return min
+}
+
+// complexityOf derives the legacy complexity constant from a class bitmask.
+func complexityOf(classes Class) Complexity {
+	hasDigits := classes.Has(ClassDigits)
+	hasLower := classes.Has(ClassLower)
+	hasUpper := classes.Has(ClassUpper)
+	hasSymbols := classes.Has(ClassSymbols)
+	hasExtended := classes.Has(ClassExtended)
 
-	// Determine complexity
 	switch {
 	case hasExtended && !(hasSymbols || hasDigits || hasLower || hasUpper):
-		audit.Complexity = PwComplexityExtendedOnly
-	case hasExtended && (hasSymbols || hasDigits || hasLower || hasUpper):
-		audit.Complexity = PwComplexityExtendedMixed
+		return PwComplexityExtendedOnly
+	case hasExtended:
+		return PwComplexityExtendedMixed
 	case hasSymbols && hasDigits && hasLower && hasUpper:
-		audit.Complexity = PwComplexitySymbolsDigitsMixed
+		return PwComplexitySymbolsDigitsMixed
+	case hasSymbols && hasDigits && hasUpper:
+		return PwComplexitySymbolsDigitsUpper
+	case hasSymbols && hasDigits && hasLower:
+		return PwComplexitySymbolsDigitsLower
 	case hasSymbols && hasDigits:
-		audit.Complexity = PwComplexitySymbolsDigits
+		return PwComplexitySymbolsDigits
 	case hasSymbols && hasLower && hasUpper:
-		audit.Complexity = PwComplexitySymbolsMixed
+		return PwComplexitySymbolsMixed
 	case hasSymbols && hasLower:
-		audit.Complexity = PwComplexitySymbolsLower
+		return PwComplexitySymbolsLower
 	case hasSymbols && hasUpper:
-		audit.Complexity = PwComplexitySymbolsUpper
+		return PwComplexitySymbolsUpper
 	case hasSymbols:
-		audit.Complexity = PwComplexitySymbolsOnly
+		return PwComplexitySymbolsOnly
 	case hasDigits && hasLower && hasUpper:
-		audit.Complexity = PwComplexityDigitsMixed
+		return PwComplexityDigitsMixed
 	case hasLower && hasDigits:
-		audit.Complexity = PwComplexityLowerDigits
+		return PwComplexityLowerDigits
 	case hasUpper && hasDigits:
-		audit.Complexity = PwComplexityUpperDigits
+		return PwComplexityUpperDigits
 	case hasLower && hasUpper:
-		audit.Complexity = PwComplexityMixedOnly
+		return PwComplexityMixedOnly
 	case hasDigits:
-		audit.Complexity = PwComplexityDigitsOnly
+		return PwComplexityDigitsOnly
 	case hasLower:
-		audit.Complexity = PwComplexityLowerOnly
+		return PwComplexityLowerOnly
 	case hasUpper:
-		audit.Complexity = PwComplexityUpperOnly
+		return PwComplexityUpperOnly
 	default:
-		audit.Complexity = PwComplexityDigitsOnly // Fallback to weakest
-	}
-
-	audit.Strong = audit.Complexity >= opts.MinimumComplexity
-
-	return audit
-}
-
-// containsExtended checks if the password contains any extended Unicode letters (beyond ASCII).
-func containsExtended(pass string) bool {
-	for _, r := range pass {
-		if r > unicode.MaxASCII && unicode.IsLetter(r) {
-			return true
-		}
+		return PwComplexityDigitsOnly // Fallback to weakest
 	}
-	return false
 }
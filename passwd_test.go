@@ -17,6 +17,9 @@ package go_passwd
 */
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -26,13 +29,14 @@ func TestAudit(t *testing.T) {
 		password string
 		options  Options
 		wantErr  bool
-		wantComp int64
+		wantComp Complexity
 	}{
 		{
 			name:     "Short password fails",
 			password: "abc",
 			options:  Options{MinLength: 8},
 			wantErr:  true,
+			wantComp: PwComplexityLowerOnly,
 		},
 		{
 			name:     "Simple password, no requirements",
@@ -53,7 +57,7 @@ func TestAudit(t *testing.T) {
 			password: "PASS@1234",
 			options:  Options{MinLength: 8, UseUpper: true, UseSymbols: true},
 			wantErr:  false,
-			wantComp: PwComplexitySymbolsDigits,
+			wantComp: PwComplexitySymbolsDigitsUpper,
 		},
 		{
 			name:     "Password with extended characters",
@@ -67,18 +71,21 @@ func TestAudit(t *testing.T) {
 			password: "Password123",
 			options:  Options{MinLength: 8, UseSymbols: true},
 			wantErr:  true,
+			wantComp: PwComplexityDigitsMixed,
 		},
 		{
 			name:     "Fails without required uppercase",
 			password: "password123!",
 			options:  Options{MinLength: 8, UseUpper: true},
 			wantErr:  true,
+			wantComp: PwComplexitySymbolsDigitsLower,
 		},
 		{
 			name:     "Maximum length exceeded",
 			password: "ThisIsAVeryLongPassword123!",
 			options:  Options{MaxLength: 20},
 			wantErr:  true,
+			wantComp: PwComplexitySymbolsDigitsMixed,
 		},
 		{
 			name:     "Complex password with symbols and digits",
@@ -102,13 +109,515 @@ func TestAudit(t *testing.T) {
 			if (result.Err != nil) != tt.wantErr {
 				t.Errorf("Audit() error = %v, wantErr %v", result.Err, tt.wantErr)
 			}
-			if result.Complexity < tt.wantComp && !tt.wantErr {
+			if result.Complexity < tt.wantComp {
 				t.Errorf("Audit() complexity = %v, want < %v", result.Complexity, tt.wantComp)
 			}
+			// A failing password is analyzed all the same, so a strength meter can be shown.
+			if !(result.CharsetEntropy > 0) || result.Score == 0 || result.Classes == 0 {
+				t.Errorf("Audit() CharsetEntropy = %.2f, Score = %d, Classes = %s, want them computed", result.CharsetEntropy, result.Score, result.Classes)
+			}
+			if tt.wantErr && result.Strong {
+				t.Errorf("Audit() Strong = true with error %v", result.Err)
+			}
+		})
+	}
+}
+
+func TestAuditSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		want     error
+	}{
+		{"Too short", "abc", Options{MinLength: 8}, ErrTooShort},
+		{"Too long", "abcdefghijk", Options{MaxLength: 10}, ErrTooLong},
+		{"Missing digits", "password", Options{UseDigits: true}, ErrMissingDigits},
+		{"Missing lower", "PASSWORD", Options{UseLower: true}, ErrMissingLower},
+		{"Missing upper", "password", Options{UseUpper: true}, ErrMissingUpper},
+		{"Missing symbols", "password", Options{UseSymbols: true}, ErrMissingSymbols},
+		{"Missing extended", "password", Options{UseExtended: true}, ErrMissingExtended},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if !errors.Is(result.Err, tt.want) {
+				t.Errorf("Audit() error = %v, want errors.Is %v", result.Err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditViolations(t *testing.T) {
+	options := Options{MinLength: 8, UseDigits: true, UseUpper: true, MinimumComplexity: PwComplexityDigitsOnly}
+
+	result := Audit("abcde", options)
+	want := []error{ErrTooShort, ErrMissingDigits, ErrMissingUpper}
+	if len(result.Violations) != len(want) {
+		t.Fatalf("Audit() violations = %v, want %d violations", result.Violations, len(want))
+	}
+	for i, err := range want {
+		if !errors.Is(result.Violations[i], err) {
+			t.Errorf("Audit() violations[%d] = %v, want %v", i, result.Violations[i], err)
+		}
+		if !errors.Is(result.Err, err) {
+			t.Errorf("Audit() error = %v, want errors.Is %v", result.Err, err)
+		}
+	}
+	if result.Entropy <= 0 {
+		t.Errorf("Audit() entropy = %v, want it computed despite violations", result.Entropy)
+	}
+	if result.Classes != ClassLower || result.Complexity != PwComplexityLowerOnly {
+		t.Errorf("Audit() classes = %05b complexity = %d, want lowercase only", result.Classes, result.Complexity)
+	}
+	if result.Strong {
+		t.Errorf("Audit() strong = true, want false when requirements fail")
+	}
+}
+
+func TestAuditFailFast(t *testing.T) {
+	options := Options{MinLength: 8, UseDigits: true, UseUpper: true, FailFast: true}
+
+	result := Audit("abcde", options)
+	if len(result.Violations) != 1 || !errors.Is(result.Err, ErrTooShort) {
+		t.Errorf("Audit() violations = %v, want only %v", result.Violations, ErrTooShort)
+	}
+	if errors.Is(result.Err, ErrMissingDigits) {
+		t.Errorf("Audit() error = %v, want evaluation to stop at the first violation", result.Err)
+	}
+
+	// Stopping early still analyzes the password in full.
+	options.FailFast = false
+	full := Audit("abcde", options)
+	if result.EffectiveEntropy != full.EffectiveEntropy || result.Complexity != full.Complexity || result.Counts != full.Counts ||
+		result.UniqueChars != full.UniqueChars || result.Score != full.Score || result.Strong {
+		t.Errorf("Audit() with FailFast = %+v, want the analysis of %+v", result, full)
+	}
+}
+
+func TestAuditCommonPasswordTooShort(t *testing.T) {
+	want := Audit("password1", Options{RejectCommon: true, MinLength: 8})
+
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{"collects violations", Options{RejectCommon: true, MinLength: 20}},
+		{"fail fast", Options{RejectCommon: true, MinLength: 20, FailFast: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit("password1", tt.options)
+			if !errors.Is(result.Err, ErrTooShort) {
+				t.Errorf("Audit() error = %v, want %v", result.Err, ErrTooShort)
+			}
+			if len(result.Patterns) != 1 || result.Patterns[0].Kind != PatternDictionary {
+				t.Errorf("Audit() patterns = %v, want one %s match", result.Patterns, PatternDictionary)
+			}
+			if result.Score != want.Score || result.Rating != want.Rating {
+				t.Errorf("Audit() score = %d (%s), want %d (%s) as when only the dictionary fails",
+					result.Score, result.Rating, want.Score, want.Rating)
+			}
 		})
 	}
 }
 
+func TestAuditMinEntropy(t *testing.T) {
+	options := Options{MinEntropy: 60}
+
+	// Twenty digits carry about 66 bits despite the weakest complexity level.
+	result := Audit("73920584617309256184", options)
+	if result.Err != nil || !result.Strong {
+		t.Errorf("Audit() error = %v strong = %v, want a strong pass", result.Err, result.Strong)
+	}
+
+	// Six symbols carry about 30 bits despite outranking digits on the legacy scale.
+	result = Audit("@#$%^&", options)
+	if !errors.Is(result.Err, ErrEntropyTooLow) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrEntropyTooLow)
+	}
+	if result.Strong {
+		t.Errorf("Audit() strong = true, want false below the entropy floor")
+	}
+	if want := "password entropy too low: 29.73 bits, minimum is 60.00"; result.Err.Error() != want {
+		t.Errorf("Audit() error = %q, want %q", result.Err, want)
+	}
+}
+
+func TestAuditMinimumCounts(t *testing.T) {
+	options := Options{MinDigits: 2, MinSymbols: 2, UseUpper: true}
+
+	result := Audit("Password1!", options)
+	if !errors.Is(result.Err, ErrMissingDigits) || !errors.Is(result.Err, ErrMissingSymbols) {
+		t.Errorf("Audit() error = %v, want missing digits and symbols", result.Err)
+	}
+	if want := "password must contain digits: found 1, minimum is 2"; result.Violations[0].Error() != want {
+		t.Errorf("Audit() violations[0] = %q, want %q", result.Violations[0], want)
+	}
+
+	result = Audit("Pass12!?", options)
+	if result.Err != nil {
+		t.Errorf("Audit() error = %v, want nil", result.Err)
+	}
+	want := Counts{Digits: 2, Lower: 3, Upper: 1, Symbols: 2}
+	if result.Counts != want {
+		t.Errorf("Audit() counts = %+v, want %+v", result.Counts, want)
+	}
+
+	// A Use* flag alone behaves as a minimum of one.
+	if result := Audit("password", Options{UseUpper: true}); !errors.Is(result.Violations[0], ErrMissingUpper) || result.Violations[0].Error() != ErrMissingUpper.Error() {
+		t.Errorf("Audit() violations[0] = %v, want %v", result.Violations[0], ErrMissingUpper)
+	}
+}
+
+func TestAuditMinClasses(t *testing.T) {
+	fourClasses := Options{MinClasses: 3}
+	explicit := Options{MinClasses: 2, UseDigits: true, UseUpper: true, MinSymbols: 2}
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		want     string
+	}{
+		{"Three of four", "Password1", fourClasses, ""},
+		{"All four", "Password1!", fourClasses, ""},
+		{"Two of four", "password1", fourClasses, "password must contain more kinds of characters: found digits, lower, 1 more of upper, symbols needed"},
+		{"None found", "🔒🔑🚀", fourClasses, "password must contain more kinds of characters: found none, 3 more of digits, lower, upper, symbols needed"},
+		{"Exactly two enabled", "PASSWORD1", explicit, ""},
+		{"Unenabled class does not count", "password1", explicit, "password must contain more kinds of characters: found digits, 1 more of upper, symbols needed"},
+		{"Minimum counts still apply", "Password!", explicit, "password must contain more kinds of characters: found upper, 1 more of digits, symbols needed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if tt.want == "" {
+				if result.Err != nil {
+					t.Errorf("Audit(%q) error = %v, want nil", tt.password, result.Err)
+				}
+				return
+			}
+			if len(result.Violations) != 1 || result.Violations[0].Error() != tt.want || !errors.Is(result.Err, ErrTooFewClasses) {
+				t.Errorf("Audit(%q) violations = %v, want [%s]", tt.password, result.Violations, tt.want)
+			}
+		})
+	}
+
+	for _, opts := range []Options{{MinClasses: 5}, {MinClasses: 3, UseDigits: true, UseLower: true}} {
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Validate(%+v) = %v, want %v", opts, err, ErrInvalidOptions)
+		}
+	}
+	if err := (Options{MinClasses: 2, UseDigits: true, UseLower: true}).Validate(); err != nil {
+		t.Errorf("Validate() with MinClasses equal to the enabled classes = %v, want nil", err)
+	}
+}
+
+func TestAuditCharacterSets(t *testing.T) {
+	tests := []struct {
+		name    string
+		pass    string
+		options Options
+		want    error
+		counts  Counts
+		entropy float64
+	}{
+		{
+			name:    "Custom symbols count as symbols",
+			pass:    "abc€£",
+			options: Options{UseSymbols: true, SymbolSet: "€£!"},
+			counts:  Counts{Lower: 3, Symbols: 2},
+			entropy: 5 * math.Log2(26+3),
+		},
+		{
+			name:    "Default punctuation outside the set is other",
+			pass:    "abc#",
+			options: Options{UseSymbols: true, SymbolSet: "€£!"},
+			want:    ErrMissingSymbols,
+			counts:  Counts{Lower: 3, Other: 1},
+		},
+		{
+			name:    "Empty set keeps the defaults",
+			pass:    "abc#",
+			options: Options{UseSymbols: true},
+			counts:  Counts{Lower: 3, Symbols: 1},
+		},
+		{
+			name:    "Allowed characters cap entropy",
+			pass:    "abcabc",
+			options: Options{AllowedChars: "abc"},
+			counts:  Counts{Lower: 6},
+			entropy: 6 * math.Log2(3),
+		},
+		{
+			name:    "Rune outside allowed characters",
+			pass:    "abcd",
+			options: Options{AllowedChars: "abc"},
+			want:    ErrDisallowedChar,
+			counts:  Counts{Lower: 4},
+		},
+		{
+			name:    "Disallowed character",
+			pass:    "it's",
+			options: Options{DisallowedChars: "\"'`"},
+			want:    ErrDisallowedChar,
+			counts:  Counts{Lower: 3, Symbols: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.pass, tt.options)
+			if tt.want == nil && result.Err != nil {
+				t.Fatalf("Audit() error = %v, want nil", result.Err)
+			}
+			if !errors.Is(result.Err, tt.want) {
+				t.Errorf("Audit() error = %v, want %v", result.Err, tt.want)
+			}
+			if result.Counts != tt.counts {
+				t.Errorf("Audit() counts = %+v, want %+v", result.Counts, tt.counts)
+			}
+			if tt.entropy != 0 && math.Abs(result.Entropy-tt.entropy) > 1e-9 {
+				t.Errorf("Audit() entropy = %v, want %v", result.Entropy, tt.entropy)
+			}
+		})
+	}
+}
+
+func TestAuditDisallowedCharDetail(t *testing.T) {
+	result := Audit("pass€word", Options{AllowedChars: lowerChars})
+	if want := `password contains a character that is not allowed: '€' at position 4`; result.Err == nil || result.Err.Error() != want {
+		t.Errorf("Audit() error = %v, want %q", result.Err, want)
+	}
+}
+
+func TestAuditLengthErrorDetail(t *testing.T) {
+	result := Audit("abc", Options{MinLength: 8})
+	if want := "password too short: 3 characters, minimum is 8"; result.Err == nil || result.Err.Error() != want {
+		t.Errorf("Audit() error = %v, want %q", result.Err, want)
+	}
+}
+
+func TestAuditMaxBytes(t *testing.T) {
+	emoji := strings.Repeat("🔒", 20)              // 20 runes, 80 bytes
+	accented := strings.Repeat("Øversættelse", 6) // 72 runes, 84 bytes
+	tests := []struct {
+		name         string
+		password     string
+		maxBytes     uint
+		wantErr      bool
+		wantTruncate bool
+	}{
+		{"Under the limit", "Øversættelse", 72, false, false},
+		{"Emoji under the rune limit, over the byte limit", emoji, 72, true, true},
+		{"Accented letters at the rune limit, over the byte limit", accented, 72, true, true},
+		{"Exactly the limit", strings.Repeat("a", 72), 72, false, false},
+		{"Truncation risk without MaxBytes", emoji, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, Options{MaxLength: 72, MaxBytes: tt.maxBytes})
+			if result.TruncationRisk != tt.wantTruncate {
+				t.Errorf("Audit() TruncationRisk = %v, want %v", result.TruncationRisk, tt.wantTruncate)
+			}
+			if gotErr := errors.Is(result.Err, ErrTooManyBytes); gotErr != tt.wantErr {
+				t.Fatalf("Audit() error = %v, want ErrTooManyBytes %v", result.Err, tt.wantErr)
+			}
+			if errors.Is(result.Err, ErrTooLong) {
+				t.Errorf("Audit() error = %v, want no ErrTooLong for %d runes", result.Err, result.Length)
+			}
+			var verr *ValidationError
+			if tt.wantErr && (!errors.As(result.Err, &verr) || verr.Code != CodeTooManyBytes || verr.Params["bytes"] != len(tt.password)) {
+				t.Errorf("Audit() error = %#v, want %s with %d bytes", result.Err, CodeTooManyBytes, len(tt.password))
+			}
+		})
+	}
+}
+
+func TestAuditRuneLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		options   Options
+		wantErr   bool
+		wantRunes int64
+		wantBytes int64
+	}{
+		{
+			name:      "Exactly MinLength in runes but not bytes",
+			password:  "Øversættelse",
+			options:   Options{MinLength: 12},
+			wantRunes: 12,
+			wantBytes: 14,
+		},
+		{
+			name:      "One rune short of MinLength despite enough bytes",
+			password:  "Øversættels",
+			options:   Options{MinLength: 12},
+			wantErr:   true,
+			wantRunes: 11,
+			wantBytes: 13,
+		},
+		{
+			name:      "Exactly MaxLength in runes but over in bytes",
+			password:  "ñøßæ",
+			options:   Options{MaxLength: 4},
+			wantRunes: 4,
+			wantBytes: 8,
+		},
+		{
+			name:      "One rune over MaxLength",
+			password:  "ñøßæå",
+			options:   Options{MaxLength: 4},
+			wantErr:   true,
+			wantRunes: 5,
+			wantBytes: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if (result.Err != nil) != tt.wantErr {
+				t.Errorf("Audit() error = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			if result.Length != tt.wantRunes {
+				t.Errorf("Audit() length = %d, want %d", result.Length, tt.wantRunes)
+			}
+			if result.LengthBytes != tt.wantBytes {
+				t.Errorf("Audit() length bytes = %d, want %d", result.LengthBytes, tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestAuditRuneEntropy(t *testing.T) {
+	// Twelve runes of lowercase and extended letters regardless of their encoded width, with Ø and
+	// æ crediting the Latin-1 letters once per case.
+	result := Audit("Øversættelse", Options{})
+	want := 12 * math.Log2(26+32+32)
+	if math.Abs(result.Entropy-want) > 1e-9 {
+		t.Errorf("Audit() entropy = %v, want %v", result.Entropy, want)
+	}
+}
+
+func TestAuditUnclassifiedCharacters(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		class    Class
+	}{
+		{"Space only", "        ", ClassOther},
+		{"Tab containing", "pass\tword", ClassOther},
+		{"Emoji only", "🔒🔑🚀🚀", ClassEmoji},
+		{"Middle dots only", "····", ClassEmoji},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, Options{AllowControlChars: true})
+			if result.Err != nil {
+				t.Fatalf("Audit() error = %v", result.Err)
+			}
+			if !result.Classes.Has(tt.class) {
+				t.Errorf("Audit() classes = %07b, want %07b set", result.Classes, tt.class)
+			}
+			if math.IsNaN(result.Entropy) || math.IsInf(result.Entropy, 0) || result.Entropy <= 0 {
+				t.Errorf("Audit() entropy = %v, want a finite positive value", result.Entropy)
+			}
+		})
+	}
+}
+
+func TestAuditEmptyPasswordEntropy(t *testing.T) {
+	if result := Audit("", Options{}); result.Entropy != 0 {
+		t.Errorf("Audit() entropy = %v, want 0", result.Entropy)
+	}
+}
+
+func TestAuditClassCombinations(t *testing.T) {
+	samples := []struct {
+		class Class
+		char  string
+	}{
+		{ClassDigits, "1"},
+		{ClassLower, "a"},
+		{ClassUpper, "A"},
+		{ClassSymbols, "@"},
+		{ClassExtended, "ø"},
+	}
+
+	for mask := Class(0); mask < 32; mask++ {
+		password := ""
+		for _, s := range samples {
+			if mask.Has(s.class) {
+				password += s.char
+			}
+		}
+
+		result := Audit(password, Options{})
+		if result.Err != nil {
+			t.Fatalf("Audit(%q) error = %v", password, result.Err)
+		}
+		if result.Classes != mask {
+			t.Errorf("Audit(%q) classes = %05b, want %05b", password, result.Classes, mask)
+		}
+		if got := complexityOf(mask); result.Complexity != got {
+			t.Errorf("Audit(%q) complexity = %v, want %v", password, result.Complexity, got)
+		}
+
+		// Adding a class must never make the password weaker.
+		for sub := Class(0); sub < 32; sub++ {
+			if mask.Has(sub) && ComplexityStrength(complexityOf(sub)) > ComplexityStrength(complexityOf(mask)) {
+				t.Errorf("strength of %05b exceeds its superset %05b", sub, mask)
+			}
+		}
+	}
+}
+
+func TestAuditComplexityNonExtended(t *testing.T) {
+	tests := []struct {
+		password string
+		want     Complexity
+	}{
+		{"1234", PwComplexityDigitsOnly},
+		{"abcd", PwComplexityLowerOnly},
+		{"ABCD", PwComplexityUpperOnly},
+		{"ab12", PwComplexityLowerDigits},
+		{"AB12", PwComplexityUpperDigits},
+		{"abAB", PwComplexityMixedOnly},
+		{"aB12", PwComplexityDigitsMixed},
+		{"@#$%", PwComplexitySymbolsOnly},
+		{"@#12", PwComplexitySymbolsDigits},
+		{"@#AB", PwComplexitySymbolsUpper},
+		{"@#ab", PwComplexitySymbolsLower},
+		{"@#aB", PwComplexitySymbolsMixed},
+		{"@#1aB", PwComplexitySymbolsDigitsMixed},
+		{"@#1B", PwComplexitySymbolsDigitsUpper},
+		{"@#1b", PwComplexitySymbolsDigitsLower},
+	}
+
+	for _, tt := range tests {
+		if got := Audit(tt.password, Options{}).Complexity; got != tt.want {
+			t.Errorf("Audit(%q) complexity = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestAuditStrongUsesStrengthOrder(t *testing.T) {
+	// Digits and mixed case imply a larger charset than symbols alone even though its constant is lower.
+	result := Audit("aB12", Options{MinimumComplexity: PwComplexitySymbolsOnly})
+	if !result.Strong {
+		t.Errorf("Audit() strong = false, want true")
+	}
+
+	result = Audit("@#$%", Options{MinimumComplexity: PwComplexityDigitsMixed})
+	if result.Strong {
+		t.Errorf("Audit() strong = true, want false")
+	}
+}
+
 func BenchmarkAudit(b *testing.B) {
 	password := "P@sswørd12345!"
 	options := Options{
@@ -126,3 +635,43 @@ func BenchmarkAudit(b *testing.B) {
 		Audit(password, options)
 	}
 }
+
+func TestAuditZeroAllocs(t *testing.T) {
+	options := Options{
+		MinLength:         8,
+		MaxLength:         20,
+		UseDigits:         true,
+		UseLower:          true,
+		UseUpper:          true,
+		UseSymbols:        true,
+		UseExtended:       true,
+		MinimumComplexity: PwComplexitySymbolsDigitsMixed,
+	}
+
+	ascii := options
+	ascii.UseExtended = false
+	allocs := testing.AllocsPerRun(100, func() {
+		Audit("P@ssword12345!", ascii)
+	})
+	if allocs != 0 {
+		t.Errorf("Audit() allocs/op = %v, want 0 on the non-error path", allocs)
+	}
+
+	// Extended letters only cost the Result its own copy of Scripts.
+	allocs = testing.AllocsPerRun(100, func() {
+		Audit("P@sswørd12345!", options)
+	})
+	if allocs != 1 {
+		t.Errorf("Audit() allocs/op = %v, want 1 for Result.Scripts", allocs)
+	}
+}
+
+func BenchmarkAuditAllocs(b *testing.B) {
+	password := "P@sswørd12345!"
+	options := Options{MinLength: 8, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Audit(password, options)
+	}
+}
@@ -0,0 +1,262 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PasswordRules returns the policy in the passwordrules format read by Safari and iCloud Keychain
+// when they generate passwords, such as "minlength: 12; required: lower; required: digit". It
+// covers the length bounds, class requirements, MaxRepeatRun, and an ASCII AllowedChars, and
+// errors for requirements the format cannot express: per-class minimums above one, extended
+// letters, emoji, MinClasses, SymbolSet, DisallowedChars, and required patterns. Checks that only
+// Audit can perform, such as dictionaries, are left out.
+func (opts Options) PasswordRules() (string, error) {
+	if opts.UseExtended || opts.MinExtended > 0 {
+		return "", errors.New("passwordrules cannot require extended letters")
+	}
+	if opts.UseEmoji {
+		return "", errors.New("passwordrules cannot require emoji")
+	}
+	if opts.SymbolSet != "" || opts.DisallowedChars != "" {
+		return "", errors.New("passwordrules export does not support custom character sets")
+	}
+	if opts.MinClasses > 0 {
+		return "", errors.New("passwordrules cannot require some of the classes")
+	}
+	if len(opts.RequiredPatterns) > 0 {
+		return "", errors.New("passwordrules cannot require regular expressions")
+	}
+
+	var rules []string
+	if opts.MinLength > 0 {
+		rules = append(rules, "minlength: "+strconv.FormatUint(uint64(opts.MinLength), 10))
+	}
+	// Generated passwords are ASCII, so a byte limit is also a limit on characters.
+	if maxLength := opts.MaxLength; maxLength > 0 || opts.MaxBytes > 0 {
+		if opts.MaxBytes > 0 && (maxLength == 0 || opts.MaxBytes < maxLength) {
+			maxLength = opts.MaxBytes
+		}
+		rules = append(rules, "maxlength: "+strconv.FormatUint(uint64(maxLength), 10))
+	}
+	classes := []struct {
+		name string
+		use  bool
+		min  uint
+	}{
+		{"lower", opts.UseLower, opts.MinLower},
+		{"upper", opts.UseUpper, opts.MinUpper},
+		{"digit", opts.UseDigits, opts.MinDigits},
+		{"special", opts.UseSymbols, opts.MinSymbols},
+	}
+	for _, class := range classes {
+		switch n := minimumCount(class.use, class.min); {
+		case n > 1:
+			return "", fmt.Errorf("passwordrules cannot require %d %s characters", n, class.name)
+		case n == 1:
+			// Safari allows every character of a required class, so AllowedChars must hold it all.
+			if opts.AllowedChars != "" && strings.IndexFunc(ruleClassChars[class.name], func(r rune) bool {
+				return !strings.ContainsRune(opts.AllowedChars, r)
+			}) >= 0 {
+				return "", fmt.Errorf("passwordrules cannot require %s characters outside the allowed characters", class.name)
+			}
+			rules = append(rules, "required: "+class.name)
+		}
+	}
+	if opts.AllowedChars != "" {
+		allowed, err := customRuleClass(opts.AllowedChars)
+		if err != nil {
+			return "", err
+		}
+		rules = append(rules, "allowed: "+allowed)
+	}
+	if opts.MaxRepeatRun > 0 {
+		rules = append(rules, "max-consecutive: "+strconv.FormatUint(uint64(opts.MaxRepeatRun), 10))
+	}
+	return strings.Join(rules, "; "), nil
+}
+
+// ruleClassChars maps the passwordrules class names to the characters Audit counts in them.
+var ruleClassChars = map[string]string{
+	"lower":   lowerChars,
+	"upper":   upperChars,
+	"digit":   digitChars,
+	"special": symbolChars,
+}
+
+// customRuleClass renders chars as a passwordrules custom class such as [-().&], sorted, with "-"
+// first and "]" last as the format requires. It errors for characters outside printable ASCII.
+func customRuleClass(chars string) (string, error) {
+	var set [128]bool
+	for _, r := range chars {
+		if r < ' ' || r > '~' {
+			return "", fmt.Errorf("passwordrules cannot allow %q, which is not printable ASCII", r)
+		}
+		set[r] = true
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	if set['-'] {
+		b.WriteByte('-')
+	}
+	for c := byte(' '); c <= '~'; c++ {
+		if set[c] && c != '-' && c != ']' {
+			b.WriteByte(c)
+		}
+	}
+	if set[']'] {
+		b.WriteByte(']')
+	}
+	b.WriteByte(']')
+	return b.String(), nil
+}
+
+// ParsePasswordRules reads a policy in the passwordrules format into Options. Rule names are
+// case-insensitive and a trailing semicolon is allowed. "allowed" rules narrower than every ASCII
+// class set AllowedChars to the characters of the required and allowed classes, sorted, as Safari
+// also allows required classes. Constructs Options cannot model return an error instead of being
+// dropped: custom classes in "required" rules and "required" rules listing more than one class.
+// The result is checked with Options.Validate.
+func ParsePasswordRules(rules string) (Options, error) {
+	var opts Options
+	var allowed strings.Builder // Characters of the allowed and required classes
+	restricted, unrestricted := false, false
+	for _, rule := range strings.Split(rules, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(rule, ":")
+		if !ok {
+			return Options{}, fmt.Errorf("passwordrules rule %q has no value", rule)
+		}
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+
+		switch name {
+		case "minlength", "maxlength", "max-consecutive":
+			n, err := strconv.ParseUint(value, 10, 0)
+			if err != nil {
+				return Options{}, fmt.Errorf("passwordrules %s %q is not a whole number", name, value)
+			}
+			switch name {
+			case "minlength":
+				opts.MinLength = uint(n)
+			case "maxlength":
+				opts.MaxLength = uint(n)
+			default:
+				opts.MaxRepeatRun = uint(n)
+			}
+		case "required":
+			classes, custom, err := parseRuleClasses(value)
+			if err != nil {
+				return Options{}, err
+			}
+			if custom != "" {
+				return Options{}, fmt.Errorf("passwordrules required: %s uses a custom character class, which is not supported", value)
+			}
+			if len(classes) != 1 {
+				return Options{}, fmt.Errorf("passwordrules required: %s needs one of several classes, which is not supported", value)
+			}
+			switch classes[0] {
+			case "lower":
+				opts.UseLower = true
+			case "upper":
+				opts.UseUpper = true
+			case "digit":
+				opts.UseDigits = true
+			case "special":
+				opts.UseSymbols = true
+			default:
+				return Options{}, fmt.Errorf("passwordrules required: %s is not supported", classes[0])
+			}
+			allowed.WriteString(ruleClassChars[classes[0]])
+		case "allowed":
+			classes, custom, err := parseRuleClasses(value)
+			if err != nil {
+				return Options{}, err
+			}
+			// An allowed set covering every class leaves Audit accepting any character.
+			all := map[string]bool{}
+			for _, class := range classes {
+				all[class] = true
+				allowed.WriteString(ruleClassChars[class])
+			}
+			allowed.WriteString(custom)
+			if all["ascii-printable"] || all["unicode"] || (all["lower"] && all["upper"] && all["digit"] && all["special"]) {
+				unrestricted = true
+			} else {
+				restricted = true
+			}
+		default:
+			return Options{}, fmt.Errorf("passwordrules rule %q is not supported", name)
+		}
+	}
+	if restricted && !unrestricted {
+		class, err := customRuleClass(allowed.String())
+		if err != nil {
+			return Options{}, err
+		}
+		opts.AllowedChars = class[1 : len(class)-1]
+	}
+
+	if err := opts.Validate(); err != nil {
+		return Options{}, err
+	}
+	return opts, nil
+}
+
+// parseRuleClasses splits a comma-separated passwordrules class list into the named classes and
+// the characters of custom classes such as [-_], rejecting unknown names. A custom class may hold
+// commas and ends at the first "]" followed by a comma or the end of the list.
+func parseRuleClasses(value string) (classes []string, custom string, err error) {
+	if value == "" {
+		return nil, "", errors.New("passwordrules character class list is empty")
+	}
+	for value != "" {
+		var class string
+		if strings.HasPrefix(value, "[") {
+			end := -1
+			for i := 2; i < len(value); i++ {
+				if rest := strings.TrimSpace(value[i+1:]); value[i] == ']' && (rest == "" || rest[0] == ',') {
+					end = i
+					break
+				}
+			}
+			if end < 0 {
+				return nil, "", fmt.Errorf("passwordrules custom character class %q is not closed", value)
+			}
+			custom += value[1:end]
+			value = strings.TrimSpace(value[end+1:])
+			value = strings.TrimSpace(strings.TrimPrefix(value, ","))
+			continue
+		}
+		class, value, _ = strings.Cut(value, ",")
+		value = strings.TrimSpace(value)
+		class = strings.ToLower(strings.TrimSpace(class))
+		switch class {
+		case "lower", "upper", "digit", "special", "ascii-printable", "unicode":
+			classes = append(classes, class)
+		default:
+			return nil, "", fmt.Errorf("passwordrules character class %q is not recognized", class)
+		}
+	}
+	return classes, custom, nil
+}
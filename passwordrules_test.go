@@ -0,0 +1,186 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePasswordRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  Options
+	}{
+		{
+			name:  "Apple AutoFill example",
+			rules: "required: upper; required: lower; required: digit; max-consecutive: 2; minlength: 8;",
+			want:  Options{MinLength: 8, UseUpper: true, UseLower: true, UseDigits: true, MaxRepeatRun: 2},
+		},
+		{
+			name:  "Length bounds and special",
+			rules: "minlength: 12; maxlength: 64; required: special",
+			want:  Options{MinLength: 12, MaxLength: 64, UseSymbols: true},
+		},
+		{
+			name:  "Case and spacing",
+			rules: "  MinLength :10 ;REQUIRED:Digit;;",
+			want:  Options{MinLength: 10, UseDigits: true},
+		},
+		{
+			name:  "Allowed covering every class",
+			rules: "minlength: 8; allowed: lower, upper, digit, special",
+			want:  Options{MinLength: 8},
+		},
+		{
+			name:  "Allowed ascii-printable",
+			rules: "required: lower; allowed: ascii-printable",
+			want:  Options{UseLower: true},
+		},
+		{
+			name:  "Apple custom allowed class",
+			rules: "minlength: 12; required: lower; required: upper; required: digit; allowed: [-().&@?'#,/\"+]",
+			want: Options{MinLength: 12, UseLower: true, UseUpper: true, UseDigits: true,
+				AllowedChars: "-\"#&'()+,./0123456789?@ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"},
+		},
+		{
+			name:  "Restricted allowed set",
+			rules: "minlength: 8; allowed: lower, digit",
+			want:  Options{MinLength: 8, AllowedChars: "0123456789abcdefghijklmnopqrstuvwxyz"},
+		},
+		{
+			name:  "Closing bracket in a custom class",
+			rules: "required: digit; allowed: [-]], [_]",
+			want:  Options{UseDigits: true, AllowedChars: "-0123456789_]"},
+		},
+		{
+			name:  "Empty",
+			rules: "",
+			want:  Options{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePasswordRules(tt.rules)
+			if err != nil {
+				t.Fatalf("ParsePasswordRules(%q) error = %v", tt.rules, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParsePasswordRules(%q) = %+v, want %+v", tt.rules, got, tt.want)
+			}
+
+			rules, err := got.PasswordRules()
+			if err != nil {
+				t.Fatalf("PasswordRules() error = %v", err)
+			}
+			again, err := ParsePasswordRules(rules)
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("ParsePasswordRules(%q) = %+v, %v, want %+v", rules, again, err, got)
+			}
+		})
+	}
+}
+
+func TestParsePasswordRulesUnsupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{"Apple custom required class", "minlength: 20; required: lower; required: upper; required: digit; required: [-];"},
+		{"One of several classes", "required: lower, upper; required: digit; allowed: [-().&@?'#,/\"+]"},
+		{"Unclosed custom class", "allowed: lower, [-_"},
+		{"Empty class list", "allowed: "},
+		{"Non-ASCII custom class", "allowed: lower, [é]"},
+		{"Required unicode", "required: unicode"},
+		{"Unknown class", "required: emoji"},
+		{"Unknown rule", "minlength: 8; max-repeated: 3"},
+		{"Missing value", "minlength"},
+		{"Negative length", "minlength: -1"},
+		{"Not a number", "maxlength: many"},
+		{"Contradictory lengths", "minlength: 20; maxlength: 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParsePasswordRules(tt.rules); err == nil {
+				t.Errorf("ParsePasswordRules(%q) = %+v, want error", tt.rules, got)
+			}
+		})
+	}
+}
+
+func TestPasswordRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Every class",
+			options: Options{MinLength: 12, MaxLength: 64, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true, MaxRepeatRun: 3},
+			want:    "minlength: 12; maxlength: 64; required: lower; required: upper; required: digit; required: special; max-consecutive: 3",
+		},
+		{
+			name:    "Minimum of one counts as required",
+			options: Options{MinDigits: 1, RejectCommon: true},
+			want:    "required: digit",
+		},
+		{
+			name:    "Byte limit below the length limit",
+			options: Options{MinLength: 12, MaxLength: 128, MaxBytes: MaxBcryptBytes},
+			want:    "minlength: 12; maxlength: 72",
+		},
+		{
+			name:    "Nothing to say",
+			options: Options{},
+			want:    "",
+		},
+		{name: "Two digits", options: Options{MinDigits: 2}, wantErr: true},
+		{name: "Extended letters", options: Options{UseExtended: true}, wantErr: true},
+		{name: "Custom symbols", options: Options{UseSymbols: true, SymbolSet: "!#"}, wantErr: true},
+		{
+			name:    "Allowed characters",
+			options: Options{UseDigits: true, AllowedChars: "9876543210ba]-"},
+			want:    "required: digit; allowed: [-0123456789ab]]",
+		},
+		{name: "Required class outside the allowed characters", options: Options{UseDigits: true, AllowedChars: "abc123"}, wantErr: true},
+		{name: "Non-ASCII allowed characters", options: Options{AllowedChars: "abcé"}, wantErr: true},
+		{name: "Disallowed characters", options: Options{DisallowedChars: `"'\`}, wantErr: true},
+		{name: "Required pattern", options: Options{RequiredPatterns: []string{`^[a-z]`}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.PasswordRules()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("PasswordRules() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PasswordRules() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PasswordRules() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchKind names the detector behind a Match. It is written to JSON as its name, such as
+// "keyboard_walk".
+type MatchKind string
+
+// String returns the name of the kind.
+func (k MatchKind) String() string {
+	return string(k)
+}
+
+// Match kinds reported in Result.Patterns.
+const (
+	PatternSequence      MatchKind = "sequence"       // Ascending or descending run such as "abcd", "1234", or "zyxw"
+	PatternRepeat        MatchKind = "repeat"         // The same rune repeated back-to-back such as "aaaa"
+	PatternRepeatedBlock MatchKind = "repeated_block" // A block of three or more runes repeated back-to-back such as "abcabc"
+	PatternDictionary    MatchKind = "dictionary"     // A word of the common password list or Options.Dictionary
+)
+
+// minRepeatedBlock is the shortest block detectRepeatedBlocks looks for.
+const minRepeatedBlock = 3
+
+// maxRepeatedBlock bounds the block sizes detectRepeatedBlocks tries so long inputs stay cheap.
+const maxRepeatedBlock = 32
+
+// Match is a weak structure detected in a password. Start and End delimit Token in runes, so a UI
+// can highlight it with []rune(password)[Start:End].
+type Match struct {
+	Kind    MatchKind `json:"kind"`
+	Token   string    `json:"token"`
+	Start   int       `json:"start"`             // Position of the first rune of Token
+	End     int       `json:"end"`               // Position just past the last rune of Token
+	Base    string    `json:"base,omitempty"`    // Repeated unit of a repeat or repeated block, or the word a disguised token decodes to
+	Penalty float64   `json:"penalty"`           // Bits of EffectiveEntropy the match removed
+	Guesses float64   `json:"guesses,omitempty"` // Guesses to crack Token, set by Analyze and optionally by a Matcher
+}
+
+// Len returns the length of the match in runes.
+func (m Match) Len() int {
+	return m.End - m.Start
+}
+
+// detectSequences returns every ascending or descending run of letters or digits longer than
+// maxLen runes. Runs are compared case-insensitively and never wrap from "z" to "a" or "9" to "0".
+func detectSequences(runes []rune, maxLen int) []Match {
+	var patterns []Match
+	for i := 0; i < len(runes)-1; {
+		delta := sequenceStep(runes[i], runes[i+1])
+		if delta == 0 {
+			i++
+			continue
+		}
+		end := i + 2
+		for end < len(runes) && sequenceStep(runes[end-1], runes[end]) == delta {
+			end++
+		}
+		if end-i > maxLen {
+			patterns = append(patterns, Match{Kind: PatternSequence, Token: string(runes[i:end]), Start: i, End: end})
+		}
+		// The last rune may start a run in the other direction, as in "abcba".
+		i = end - 1
+	}
+	return patterns
+}
+
+// sequenceStep returns +1 or -1 when b directly follows or precedes a within the same kind of
+// character, and 0 otherwise.
+func sequenceStep(a, b rune) int {
+	a, b = unicode.ToLower(a), unicode.ToLower(b)
+	sameKind := (unicode.IsLetter(a) && unicode.IsLetter(b)) || (unicode.IsDigit(a) && unicode.IsDigit(b))
+	if !sameKind {
+		return 0
+	}
+	switch b - a {
+	case 1:
+		return 1
+	case -1:
+		return -1
+	}
+	return 0
+}
+
+// detectRepeats returns every run of the same rune longer than maxRun runes.
+func detectRepeats(runes []rune, maxRun int) []Match {
+	var patterns []Match
+	for i := 0; i < len(runes); {
+		end := i + 1
+		for end < len(runes) && runes[end] == runes[i] {
+			end++
+		}
+		if end-i > maxRun {
+			patterns = append(patterns, Match{Kind: PatternRepeat, Token: string(runes[i:end]), Start: i, End: end, Base: string(runes[i])})
+		}
+		i = end
+	}
+	return patterns
+}
+
+// detectRepeatedBlocks returns every block of at least three runes repeated back-to-back, using
+// the shortest block that explains the repetition. Blocks made of a single repeated rune are left
+// to detectRepeats.
+func detectRepeatedBlocks(runes []rune) []Match {
+	var patterns []Match
+	for i := 0; i < len(runes); {
+		found := false
+		for size := minRepeatedBlock; size <= maxRepeatedBlock && i+2*size <= len(runes); size++ {
+			block := runes[i : i+size]
+			if uniform(block) {
+				continue
+			}
+			end := i + size
+			for end+size <= len(runes) && equalRunes(runes[end:end+size], block) {
+				end += size
+			}
+			if end == i+size {
+				continue
+			}
+			patterns = append(patterns, Match{Kind: PatternRepeatedBlock, Token: string(runes[i:end]), Start: i, End: end, Base: string(block)})
+			i, found = end, true
+			break
+		}
+		if !found {
+			i++
+		}
+	}
+	return patterns
+}
+
+// uniform reports whether every rune in block is the same.
+func uniform(block []rune) bool {
+	for _, r := range block[1:] {
+		if r != block[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalRunes reports whether a and b hold the same runes.
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// prefixMatch describes the prefix of pass that spells word, which is as long as word in runes.
+func prefixMatch(kind MatchKind, pass, word string) Match {
+	runes := []rune(pass)
+	n := min(utf8.RuneCountInString(word), len(runes))
+	return Match{Kind: kind, Token: string(runes[:n]), Start: 0, End: n, Base: word}
+}
+
+// compress returns the length of a password once every match is replaced by its compressed form:
+// a repeated block by one copy of its block, anything else by a single character. Runes covered by
+// overlapping matches are only removed once, by the first match covering them, and a match adding
+// nothing new is dropped. Each match is charged bitsPerRune for every rune it removed as its Penalty.
+func compress(length int, matches []Match, bitsPerRune float64) int {
+	if len(matches) == 0 {
+		return length
+	}
+	covered := make([]bool, length)
+	compressed := length
+	for i := range matches {
+		m := &matches[i]
+		removed := 0
+		for j := max(m.Start, 0); j < m.End && j < length; j++ {
+			if !covered[j] {
+				covered[j] = true
+				removed++
+			}
+		}
+		if removed == 0 {
+			continue
+		}
+		kept := 1
+		if m.Kind == PatternRepeatedBlock {
+			kept = utf8.RuneCountInString(m.Base)
+		}
+		removed = max(removed-kept, 0)
+		compressed -= removed
+		m.Penalty = float64(removed) * bitsPerRune
+	}
+	return compressed
+}
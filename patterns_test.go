@@ -0,0 +1,262 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectSequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		maxLen   int
+		want     []Match
+	}{
+		{
+			name:     "Ascending letters and digits",
+			password: "Abcdef123!",
+			maxLen:   2,
+			want: []Match{
+				{Kind: PatternSequence, Token: "Abcdef", Start: 0, End: 6},
+				{Kind: PatternSequence, Token: "123", Start: 6, End: 9},
+			},
+		},
+		{
+			name:     "Descending letters",
+			password: "xzyxw9",
+			maxLen:   3,
+			want:     []Match{{Kind: PatternSequence, Token: "zyxw", Start: 1, End: 5}},
+		},
+		{
+			name:     "Case insensitive",
+			password: "aBcD",
+			maxLen:   3,
+			want:     []Match{{Kind: PatternSequence, Token: "aBcD", Start: 0, End: 4}},
+		},
+		{
+			name:     "Runs at or below the limit are ignored",
+			password: "abc-123",
+			maxLen:   3,
+		},
+		{
+			name:     "No wrap around",
+			password: "yzab8901",
+			maxLen:   2,
+		},
+		{
+			name:     "Letters and digits do not mix",
+			password: "9:;<",
+			maxLen:   2,
+		},
+		{
+			name:     "Direction change shares a rune",
+			password: "abcba",
+			maxLen:   2,
+			want: []Match{
+				{Kind: PatternSequence, Token: "abc", Start: 0, End: 3},
+				{Kind: PatternSequence, Token: "cba", Start: 2, End: 5},
+			},
+		},
+		{
+			name:     "Positions count runes",
+			password: "øøabcd",
+			maxLen:   3,
+			want:     []Match{{Kind: PatternSequence, Token: "abcd", Start: 2, End: 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectSequences([]rune(tt.password), tt.maxLen)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectSequences(%q) = %+v, want %+v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectSequencesLongInput(t *testing.T) {
+	password := []rune(strings.Repeat("abcdefghijklmnopqrstuvwxyz", 4000))
+	if got := detectSequences(password, 3); len(got) != 4000 {
+		t.Errorf("detectSequences() found %d runs, want 4000", len(got))
+	}
+}
+
+func TestAuditSequences(t *testing.T) {
+	options := Options{MaxSequenceLength: 3, RejectSequences: true}
+
+	result := Audit("Abcdef123!", options)
+	if !errors.Is(result.Err, ErrSequentialChars) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrSequentialChars)
+	}
+	if len(result.Patterns) != 1 || result.Patterns[0].Token != "Abcdef" {
+		t.Errorf("Audit() patterns = %+v, want the Abcdef run", result.Patterns)
+	}
+
+	// The six rune run counts as one character, leaving five.
+	plain := Audit("Abcdef123!", Options{})
+	if want := plain.EffectiveEntropy * 5 / 10; result.EffectiveEntropy != want {
+		t.Errorf("Audit() effective entropy = %v, want %v", result.EffectiveEntropy, want)
+	}
+
+	options.RejectSequences = false
+	if result := Audit("Abcdef123!", options); result.Err != nil || len(result.Patterns) != 1 {
+		t.Errorf("Audit() error = %v patterns = %+v, want the run reported but not rejected", result.Err, result.Patterns)
+	}
+
+	if result := Audit("Abcdef123!", Options{}); result.Patterns != nil {
+		t.Errorf("Audit() patterns = %+v, want detection disabled by default", result.Patterns)
+	}
+}
+
+func TestDetectRepeats(t *testing.T) {
+	tests := []struct {
+		password string
+		maxRun   int
+		want     []Match
+	}{
+		{"aaaaaaaA1!", 3, []Match{{Kind: PatternRepeat, Token: "aaaaaaa", Start: 0, End: 7, Base: "a"}}},
+		{"aaA1!", 2, nil},
+		{"x🚀🚀🚀🚀y", 2, []Match{{Kind: PatternRepeat, Token: "🚀🚀🚀🚀", Start: 1, End: 5, Base: "🚀"}}},
+		{"ßßßaaaa", 2, []Match{
+			{Kind: PatternRepeat, Token: "ßßß", Start: 0, End: 3, Base: "ß"},
+			{Kind: PatternRepeat, Token: "aaaa", Start: 3, End: 7, Base: "a"},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := detectRepeats([]rune(tt.password), tt.maxRun); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("detectRepeats(%q) = %+v, want %+v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestDetectRepeatedBlocks(t *testing.T) {
+	tests := []struct {
+		password string
+		want     []Match
+	}{
+		{"abcabcabc1!", []Match{{Kind: PatternRepeatedBlock, Token: "abcabcabc", Start: 0, End: 9, Base: "abc"}}},
+		{"1!xyzwxyzw", []Match{{Kind: PatternRepeatedBlock, Token: "xyzwxyzw", Start: 2, End: 10, Base: "xyzw"}}},
+		{"🔒🔑🚀🔒🔑🚀", []Match{{Kind: PatternRepeatedBlock, Token: "🔒🔑🚀🔒🔑🚀", Start: 0, End: 6, Base: "🔒🔑🚀"}}},
+		{"abab", nil},
+		{"aaaaaa", nil},
+		{"abcdef", nil},
+	}
+
+	for _, tt := range tests {
+		if got := detectRepeatedBlocks([]rune(tt.password)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("detectRepeatedBlocks(%q) = %+v, want %+v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestAuditRepeats(t *testing.T) {
+	options := Options{MaxRepeatRun: 3}
+
+	result := Audit("aaaaaaaA1!", options)
+	if !errors.Is(result.Err, ErrRepeatedChars) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrRepeatedChars)
+	}
+
+	// Nine runes of "abcabcabc" compress to the three rune block, leaving five runes.
+	result = Audit("abcabcabc1!", options)
+	if result.Err != nil {
+		t.Errorf("Audit() error = %v, want repeated blocks reported but not rejected", result.Err)
+	}
+	if len(result.Patterns) != 1 || result.Patterns[0].Kind != PatternRepeatedBlock {
+		t.Errorf("Audit() patterns = %+v, want the repeated block", result.Patterns)
+	}
+	plain := Audit("abcabcabc1!", Options{})
+	if want := plain.EffectiveEntropy * 5 / 11; math.Abs(result.EffectiveEntropy-want) > 1e-9 {
+		t.Errorf("Audit() effective entropy = %v, want %v", result.EffectiveEntropy, want)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	patterns := []Match{
+		{Kind: PatternSequence, Token: "abc", Start: 0, End: 3},
+		{Kind: PatternSequence, Token: "cba", Start: 2, End: 5},
+		{Kind: PatternRepeat, Token: "bc", Start: 1, End: 3},
+	}
+	if got := compress(5, patterns, 2); got != 2 {
+		t.Errorf("compress() = %d, want 2", got)
+	}
+	for i, want := range []float64{4, 2, 0} {
+		if patterns[i].Penalty != want {
+			t.Errorf("compress() penalty of %q = %v, want %v", patterns[i].Token, patterns[i].Penalty, want)
+		}
+	}
+
+	block := []Match{{Kind: PatternRepeatedBlock, Token: "abcabcabc", Start: 0, End: 9, Base: "abc"}}
+	if got := compress(11, block, 1); got != 5 || block[0].Penalty != 6 {
+		t.Errorf("compress() = %d with penalty %v, want 5 with penalty 6", got, block[0].Penalty)
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	pass := "ßüñ-qwerty-1987-ßßßß-🔒🔑🚀🔒🔑🚀"
+	result := Audit(pass, Options{KeyboardWalkLength: 4, MaxRepeatRun: 3})
+	runes := []rune(pass)
+	kinds := map[MatchKind]bool{}
+	for _, m := range result.Patterns {
+		kinds[m.Kind] = true
+		if m.Start < 0 || m.End > len(runes) || string(runes[m.Start:m.End]) != m.Token {
+			t.Errorf("match %+v does not delimit its token in %q", m, pass)
+		}
+		if m.Penalty <= 0 {
+			t.Errorf("match %+v has no penalty", m)
+		}
+	}
+	for _, kind := range []MatchKind{PatternKeyboardWalk, PatternYear, PatternRepeat, PatternRepeatedBlock} {
+		if !kinds[kind] {
+			t.Errorf("Audit(%q) Patterns = %+v, want a %s match", pass, result.Patterns, kind)
+		}
+	}
+}
+
+func TestDictionaryMatch(t *testing.T) {
+	result := Audit("Password123!", Options{RejectCommon: true})
+	if len(result.Patterns) == 0 {
+		t.Fatalf("Audit() Patterns = %+v, want a dictionary match", result.Patterns)
+	}
+	m := result.Patterns[len(result.Patterns)-1]
+	want := Match{Kind: PatternDictionary, Token: "Password", Start: 0, End: 8, Base: "password", Penalty: m.Penalty}
+	if m != want || m.Penalty <= 0 {
+		t.Errorf("Audit() dictionary match = %+v, want %+v with a penalty", m, want)
+	}
+}
+
+func TestMatchKindJSON(t *testing.T) {
+	data, err := json.Marshal(Match{Kind: PatternKeyboardWalk, Token: "qwerty", End: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"kind":"keyboard_walk","token":"qwerty","start":0,"end":6,"penalty":0}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+	var m Match
+	if err := json.Unmarshal(data, &m); err != nil || m.Kind != PatternKeyboardWalk || m.Kind.String() != "keyboard_walk" {
+		t.Errorf("json.Unmarshal(%s) = %+v, %v", data, m, err)
+	}
+}
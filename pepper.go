@@ -0,0 +1,233 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MinPepperKeyBytes is the shortest key NewPepper and Pepper.Add accept, the output size of
+// SHA-256.
+const MinPepperKeyBytes = 32
+
+// maxPepperID bounds the key IDs encoded into peppered hashes.
+const maxPepperID = 32
+
+// pepperPrefix starts every hash produced by Pepper.Hash, followed by "id=<key ID>" and the hash
+// of the peppered password.
+const pepperPrefix = "$pepper-hmac-sha256$"
+
+// Pepper holds server-side secret keys that passwords are run through with HMAC-SHA-256 before
+// they are hashed, so a leaked hash database cannot be cracked without the keys, which are stored
+// elsewhere. Keys have IDs for rotation: the active key peppers new hashes, and every key added
+// verifies the hashes that name it. A Pepper is safe for concurrent use; Close zeroes the keys.
+type Pepper struct {
+	mu     sync.RWMutex
+	keys   map[string][]byte
+	active string
+}
+
+// NewPepper returns a Pepper whose active key is a copy of key, named id. IDs are up to 32 ASCII
+// letters, digits, '.', '_', or '-'.
+func NewPepper(id string, key []byte) (*Pepper, error) {
+	p := &Pepper{keys: make(map[string][]byte)}
+	if err := p.Add(id, key); err != nil {
+		return nil, err
+	}
+	p.active = id
+	return p, nil
+}
+
+// Add stores a copy of key as id without activating it, so hashes peppered with id verify. Adding
+// an ID twice fails rather than replacing the key the existing hashes need.
+func (p *Pepper) Add(id string, key []byte) error {
+	if !validPepperID(id) {
+		return fmt.Errorf("invalid pepper key id %q", id)
+	}
+	if len(key) < MinPepperKeyBytes {
+		return fmt.Errorf("pepper key %q is %d bytes, minimum is %d", id, len(key), MinPepperKeyBytes)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys == nil {
+		return ErrPepperClosed
+	}
+	if _, ok := p.keys[id]; ok {
+		return fmt.Errorf("pepper key %q already exists", id)
+	}
+	p.keys[id] = append([]byte(nil), key...)
+	return nil
+}
+
+// SetActive makes the key id pepper new hashes. Hashes made with the previous active key keep
+// verifying as long as its key stays in the Pepper.
+func (p *Pepper) SetActive(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys == nil {
+		return ErrPepperClosed
+	}
+	if _, ok := p.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownPepper, id)
+	}
+	p.active = id
+	return nil
+}
+
+// Active returns the ID of the key that peppers new hashes.
+func (p *Pepper) Active() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.active
+}
+
+// Apply returns the HMAC-SHA-256 of password under the active key, or nil once the Pepper is
+// closed.
+func (p *Pepper) Apply(password []byte) []byte {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.keys == nil {
+		return nil
+	}
+	return pepperMAC(p.keys[p.active], password)
+}
+
+// Close zeroes every key. The Pepper cannot be used afterwards.
+func (p *Pepper) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, key := range p.keys {
+		Zero(key)
+	}
+	p.keys = nil
+	return nil
+}
+
+// Hash peppers password with the active key and hashes the result with hash, such as Hash or a
+// closure over HashArgon2id, returning "$pepper-hmac-sha256$id=<key ID>" followed by that hash. The
+// MAC is base64 encoded before hashing, so it stays within MaxBcryptBytes for any password. hash
+// must not retain its argument.
+func (p *Pepper) Hash(password string, hash func(string) (string, error)) (string, error) {
+	p.mu.RLock()
+	if p.keys == nil {
+		p.mu.RUnlock()
+		return "", ErrPepperClosed
+	}
+	id := p.active
+	peppered := pepperedPassword(p.keys[id], password)
+	p.mu.RUnlock()
+	defer wipe(peppered)
+
+	encoded, err := hash(bytesView(peppered))
+	if err != nil {
+		return "", err
+	}
+	return pepperPrefix + "id=" + id + encoded, nil
+}
+
+// Verify checks password against a hash from Pepper.Hash using the key the hash names, whether or
+// not it is active, and VerifyAny for the hash itself. It returns ErrUnknownPepper when the key is
+// not in the Pepper and ErrMalformedHash when encoded is not a peppered hash.
+func (p *Pepper) Verify(password, encoded string) error {
+	id, inner, err := splitPepperHash(encoded)
+	if err != nil {
+		return err
+	}
+	p.mu.RLock()
+	if p.keys == nil {
+		p.mu.RUnlock()
+		return ErrPepperClosed
+	}
+	key, ok := p.keys[id]
+	if !ok {
+		p.mu.RUnlock()
+		return fmt.Errorf("%w: %q", ErrUnknownPepper, id)
+	}
+	peppered := pepperedPassword(key, password)
+	p.mu.RUnlock()
+	defer wipe(peppered)
+
+	_, err = VerifyAny(bytesView(peppered), inner)
+	return err
+}
+
+// HashWithPepper returns the bcrypt hash, at DefaultBcryptCost, of password peppered with the
+// active key of p.
+func HashWithPepper(password string, p *Pepper) (string, error) {
+	return p.Hash(password, Hash)
+}
+
+// VerifyWithPepper checks password against a hash from HashWithPepper or Pepper.Hash.
+func VerifyWithPepper(password, encoded string, p *Pepper) error {
+	return p.Verify(password, encoded)
+}
+
+// PepperID returns the ID of the key a peppered hash was made with, so stored hashes can be
+// found and rehashed before a retired key is removed.
+func PepperID(encoded string) (string, error) {
+	id, _, err := splitPepperHash(encoded)
+	return id, err
+}
+
+// splitPepperHash returns the key ID and the inner hash of a peppered hash.
+func splitPepperHash(encoded string) (id, inner string, err error) {
+	rest, ok := strings.CutPrefix(encoded, pepperPrefix+"id=")
+	if !ok {
+		return "", "", fmt.Errorf("%w: not a peppered hash", ErrMalformedHash)
+	}
+	i := strings.IndexByte(rest, '$')
+	if i < 0 || !validPepperID(rest[:i]) {
+		return "", "", fmt.Errorf("%w: invalid pepper key id", ErrMalformedHash)
+	}
+	return rest[:i], rest[i:], nil
+}
+
+// pepperedPassword returns the base64 encoded HMAC-SHA-256 of password under key.
+func pepperedPassword(key []byte, password string) []byte {
+	pw := []byte(password)
+	defer wipe(pw)
+	mac := pepperMAC(key, pw)
+	defer wipe(mac)
+	peppered := make([]byte, base64.StdEncoding.EncodedLen(len(mac)))
+	base64.StdEncoding.Encode(peppered, mac)
+	return peppered
+}
+
+// pepperMAC returns the HMAC-SHA-256 of password under key.
+func pepperMAC(key, password []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(password)
+	return h.Sum(nil)
+}
+
+// validPepperID reports whether id can be encoded into a peppered hash.
+func validPepperID(id string) bool {
+	if id == "" || len(id) > maxPepperID {
+		return false
+	}
+	for _, c := range []byte(id) {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastBcrypt hashes at the minimum cost to keep the tests quick.
+func fastBcrypt(password string) (string, error) {
+	return HashWithCost(password, bcrypt.MinCost)
+}
+
+func testPepperKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, MinPepperKeyBytes)
+}
+
+func TestPepperApply(t *testing.T) {
+	key := testPepperKey(7)
+	p, err := NewPepper("v1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key[0] = 0 // The Pepper holds its own copy
+
+	h := hmac.New(sha256.New, testPepperKey(7))
+	h.Write([]byte("hunter2"))
+	if got := p.Apply([]byte("hunter2")); !bytes.Equal(got, h.Sum(nil)) {
+		t.Errorf("Apply() = %x, want HMAC-SHA-256 %x", got, h.Sum(nil))
+	}
+}
+
+func TestPepperRotation(t *testing.T) {
+	const password = "correct horse battery staple"
+	p, err := NewPepper("v1", testPepperKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := p.Hash(password, fastBcrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(v1, "$pepper-hmac-sha256$id=v1$2a$") {
+		t.Errorf("Hash() = %q, want the v1 key ID before a bcrypt hash", v1)
+	}
+
+	if err := p.Add("v2", testPepperKey(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetActive("v2"); err != nil {
+		t.Fatal(err)
+	}
+	v2, err := p.Hash(password, fastBcrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, encoded := range []string{v1, v2} {
+		if err := p.Verify(password, encoded); err != nil {
+			t.Errorf("Verify(%q) error = %v, want nil after rotation", encoded, err)
+		}
+		if err := p.Verify("wrong password", encoded); !errors.Is(err, ErrPasswordMismatch) {
+			t.Errorf("Verify(wrong, %q) error = %v, want ErrPasswordMismatch", encoded, err)
+		}
+	}
+	if id, err := PepperID(v2); id != "v2" || err != nil {
+		t.Errorf("PepperID() = %q, %v, want v2", id, err)
+	}
+
+	// A server without the retired key cannot verify its hashes.
+	retired, _ := NewPepper("v2", testPepperKey(2))
+	if err := retired.Verify(password, v1); !errors.Is(err, ErrUnknownPepper) {
+		t.Errorf("Verify() without v1 error = %v, want ErrUnknownPepper", err)
+	}
+	// Nor can one whose key of the same name differs.
+	other, _ := NewPepper("v1", testPepperKey(9))
+	if err := other.Verify(password, v1); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("Verify() with another v1 key error = %v, want ErrPasswordMismatch", err)
+	}
+	// Without the pepper the stored hash is of no use.
+	if _, err := VerifyAny(password, strings.TrimPrefix(v1, "$pepper-hmac-sha256$id=v1")); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("VerifyAny() of the unpeppered password error = %v, want ErrPasswordMismatch", err)
+	}
+}
+
+func TestPepperHashSchemes(t *testing.T) {
+	long := strings.Repeat("a", 2*MaxBcryptBytes)
+	p, _ := NewPepper("2024-01", testPepperKey(3))
+	argon2 := func(s string) (string, error) { return HashArgon2id(s, testArgon2Params) }
+	for _, password := range []string{"hunter2", long} {
+		for name, hash := range map[string]func(string) (string, error){"bcrypt": fastBcrypt, "argon2id": argon2} {
+			encoded, err := p.Hash(password, hash)
+			if err != nil {
+				t.Fatalf("Hash(%d bytes) with %s error = %v", len(password), name, err)
+			}
+			if err := VerifyWithPepper(password, encoded, p); err != nil {
+				t.Errorf("VerifyWithPepper(%d bytes) with %s error = %v", len(password), name, err)
+			}
+		}
+	}
+}
+
+func TestPepperErrors(t *testing.T) {
+	if _, err := NewPepper("v1", make([]byte, MinPepperKeyBytes-1)); err == nil {
+		t.Error("NewPepper() with a short key error = nil, want an error")
+	}
+	for _, id := range []string{"", "v$1", "v 1", strings.Repeat("v", maxPepperID+1)} {
+		if _, err := NewPepper(id, testPepperKey(1)); err == nil {
+			t.Errorf("NewPepper(%q) error = nil, want an error", id)
+		}
+	}
+
+	p, _ := NewPepper("v1", testPepperKey(1))
+	if err := p.Add("v1", testPepperKey(2)); err == nil {
+		t.Error("Add() of an existing ID error = nil, want an error")
+	}
+	if err := p.SetActive("v9"); !errors.Is(err, ErrUnknownPepper) {
+		t.Errorf("SetActive(v9) error = %v, want ErrUnknownPepper", err)
+	}
+	for _, encoded := range []string{"", "$2a$04$abc", "$pepper-hmac-sha256$id=v1", "$pepper-hmac-sha256$id=$2a$04$abc"} {
+		if err := p.Verify("hunter2", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrMalformedHash", encoded, err)
+		}
+	}
+}
+
+func TestPepperClose(t *testing.T) {
+	p, _ := NewPepper("v1", testPepperKey(1))
+	p.Add("v2", testPepperKey(2))
+	encoded, _ := p.Hash("hunter2", fastBcrypt)
+	keys := [][]byte{p.keys["v1"], p.keys["v2"]}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		if !bytes.Equal(key, make([]byte, len(key))) {
+			t.Errorf("key %d = %x after Close, want zeros", i+1, key)
+		}
+	}
+	if got := p.Apply([]byte("hunter2")); got != nil {
+		t.Errorf("Apply() after Close = %x, want nil", got)
+	}
+	if _, err := p.Hash("hunter2", fastBcrypt); !errors.Is(err, ErrPepperClosed) {
+		t.Errorf("Hash() after Close error = %v, want ErrPepperClosed", err)
+	}
+	if err := p.Verify("hunter2", encoded); !errors.Is(err, ErrPepperClosed) {
+		t.Errorf("Verify() after Close error = %v, want ErrPepperClosed", err)
+	}
+	if err := p.Add("v3", testPepperKey(3)); !errors.Is(err, ErrPepperClosed) {
+		t.Errorf("Add() after Close error = %v, want ErrPepperClosed", err)
+	}
+}
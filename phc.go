@@ -0,0 +1,246 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Limits ParsePHC enforces on each field, following the PHC string format specification for
+// identifiers and parameter names.
+const (
+	maxPHCName   = 32   // Function identifier and parameter names
+	maxPHCValue  = 64   // Parameter values
+	maxPHCBase64 = 1024 // Encoded salt and hash
+)
+
+// bcryptEncoding is the base64 alphabet bcrypt uses for its salt and hash.
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").
+	WithPadding(base64.NoPadding)
+
+// PHC is a hash in the PHC string format, "$<id>[$v=<version>][$<name>=<value>,...]$<salt>$<hash>".
+type PHC struct {
+	ID      string            // Function identifier, such as "argon2id" or "pbkdf2-sha256"
+	Version int               // The v= field, or 0 when absent
+	Params  map[string]string // Parameters by name, such as "m" and "t"
+	Salt    []byte            // Decoded salt
+	Hash    []byte            // Decoded hash
+
+	order []string // Parameter names in the order parsed, so String keeps it
+}
+
+// ParsePHC parses a PHC string such as the ones HashArgon2id, HashPBKDF2, and HashScrypt return.
+// bcrypt's "$2b$<cost>$<salt><hash>" is translated: ID is "2a", "2b", or "2y", Params holds "cost",
+// and Salt and Hash are decoded from bcrypt's base64 alphabet; String writes it back the same way.
+// Errors wrap ErrMalformedHash and one of ErrPHCMissingSegment, ErrPHCInvalidField,
+// ErrPHCBadBase64, or ErrPHCFieldTooLong.
+func ParsePHC(encoded string) (PHC, error) {
+	if !strings.HasPrefix(encoded, "$") {
+		return PHC{}, phcError(ErrPHCMissingSegment, "want a leading $")
+	}
+	fields := strings.Split(encoded[1:], "$")
+	id := fields[0]
+	if err := checkPHCName("identifier", id); err != nil {
+		return PHC{}, err
+	}
+	if id == "2a" || id == "2b" || id == "2y" {
+		return parseBcryptPHC(id, fields[1:])
+	}
+	if id == "1" || id == "5" || id == "6" || id == "apr1" {
+		return PHC{}, phcError(ErrPHCInvalidField, fmt.Sprintf("$%s$ is a crypt(3) hash, not a PHC string", id))
+	}
+
+	p := PHC{ID: id}
+	fields = fields[1:]
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "v=") {
+		v, err := strconv.Atoi(fields[0][2:])
+		if err != nil || strings.Trim(fields[0][2:], "0123456789") != "" || len(fields[0]) > maxPHCValue {
+			return PHC{}, phcError(ErrPHCInvalidField, fmt.Sprintf("version %q", fields[0]))
+		}
+		p.Version, fields = v, fields[1:]
+	}
+	switch len(fields) {
+	case 2:
+		// Base64 has "=" only as trailing padding, so a segment with one elsewhere is parameters
+		if strings.Contains(strings.TrimRight(fields[0], "="), "=") {
+			return PHC{}, phcError(ErrPHCMissingSegment, "want salt and hash after the parameters")
+		}
+	case 3:
+		if err := p.parseParams(fields[0]); err != nil {
+			return PHC{}, err
+		}
+		fields = fields[1:]
+	default:
+		if len(fields) < 2 {
+			return PHC{}, phcError(ErrPHCMissingSegment, "want salt and hash")
+		}
+		return PHC{}, phcError(ErrPHCInvalidField, "too many segments")
+	}
+	var err error
+	if p.Salt, err = decodePHCField("salt", fields[0]); err != nil {
+		return PHC{}, err
+	}
+	if p.Hash, err = decodePHCField("hash", fields[1]); err != nil {
+		return PHC{}, err
+	}
+	return p, nil
+}
+
+// parseParams parses the comma-separated name=value segment into p.
+func (p *PHC) parseParams(segment string) error {
+	p.Params = make(map[string]string)
+	for _, param := range strings.Split(segment, ",") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return phcError(ErrPHCInvalidField, fmt.Sprintf("parameter %q has no value", param))
+		}
+		if err := checkPHCName("parameter name", name); err != nil {
+			return err
+		}
+		if len(value) > maxPHCValue {
+			return phcError(ErrPHCFieldTooLong, fmt.Sprintf("parameter %s is %d bytes", name, len(value)))
+		}
+		if value == "" || strings.IndexFunc(value, func(r rune) bool { return !isPHCValueRune(r) }) >= 0 {
+			return phcError(ErrPHCInvalidField, fmt.Sprintf("parameter %s=%q", name, value))
+		}
+		if _, dup := p.Params[name]; dup {
+			return phcError(ErrPHCInvalidField, fmt.Sprintf("parameter %s repeated", name))
+		}
+		p.Params[name] = value
+		p.order = append(p.order, name)
+	}
+	return nil
+}
+
+// parseBcryptPHC translates the segments after a bcrypt "$2b$" into a PHC.
+func parseBcryptPHC(id string, fields []string) (PHC, error) {
+	if len(fields) < 2 || fields[1] == "" {
+		return PHC{}, phcError(ErrPHCMissingSegment, "want bcrypt cost, salt, and hash")
+	}
+	if len(fields) > 2 {
+		return PHC{}, phcError(ErrPHCInvalidField, "too many segments")
+	}
+	cost, err := strconv.Atoi(fields[0])
+	if err != nil || len(fields[0]) != 2 || cost < 4 || cost > 31 {
+		return PHC{}, phcError(ErrPHCInvalidField, fmt.Sprintf("bcrypt cost %q", fields[0]))
+	}
+	const saltChars, hashChars = 22, 31
+	rest := fields[1]
+	switch {
+	case len(rest) < saltChars+hashChars:
+		return PHC{}, phcError(ErrPHCMissingSegment, fmt.Sprintf("bcrypt salt and hash are %d characters, want 53", len(rest)))
+	case len(rest) > saltChars+hashChars:
+		return PHC{}, phcError(ErrPHCFieldTooLong, fmt.Sprintf("bcrypt salt and hash are %d characters, want 53", len(rest)))
+	}
+	salt, err := bcryptEncoding.DecodeString(rest[:saltChars])
+	if err != nil {
+		return PHC{}, phcError(ErrPHCBadBase64, "bcrypt salt")
+	}
+	hash, err := bcryptEncoding.DecodeString(rest[saltChars:])
+	if err != nil {
+		return PHC{}, phcError(ErrPHCBadBase64, "bcrypt hash")
+	}
+	return PHC{ID: id, Params: map[string]string{"cost": fields[0]}, Salt: salt, Hash: hash, order: []string{"cost"}}, nil
+}
+
+// String encodes p as a PHC string, or in bcrypt's own format for the bcrypt identifiers.
+// Parameters keep the order they were parsed in; ones added since follow in name order.
+func (p PHC) String() string {
+	if p.ID == "2a" || p.ID == "2b" || p.ID == "2y" {
+		cost, _ := strconv.Atoi(p.Params["cost"])
+		return fmt.Sprintf("$%s$%02d$%s%s", p.ID, cost, bcryptEncoding.EncodeToString(p.Salt), bcryptEncoding.EncodeToString(p.Hash))
+	}
+	var out strings.Builder
+	out.WriteString("$" + p.ID)
+	if p.Version != 0 {
+		fmt.Fprintf(&out, "$v=%d", p.Version)
+	}
+	for i, name := range p.paramNames() {
+		if i == 0 {
+			out.WriteByte('$')
+		} else {
+			out.WriteByte(',')
+		}
+		out.WriteString(name + "=" + p.Params[name])
+	}
+	out.WriteString("$" + encodePHCBase64(p.Salt) + "$" + encodePHCBase64(p.Hash))
+	return out.String()
+}
+
+// paramNames returns the names in p.Params, parsed ones first.
+func (p PHC) paramNames() []string {
+	names := make([]string, 0, len(p.Params))
+	for _, name := range p.order {
+		if _, ok := p.Params[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var added []string
+	for name := range p.Params {
+		if !slices.Contains(p.order, name) {
+			added = append(added, name)
+		}
+	}
+	slices.Sort(added)
+	return append(names, added...)
+}
+
+// checkPHCName checks an identifier or parameter name: 1 to 32 of a-z, 0-9, and "-".
+func checkPHCName(what, name string) error {
+	if name == "" {
+		return phcError(ErrPHCMissingSegment, "empty "+what)
+	}
+	if len(name) > maxPHCName {
+		return phcError(ErrPHCFieldTooLong, fmt.Sprintf("%s is %d bytes", what, len(name)))
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return phcError(ErrPHCInvalidField, fmt.Sprintf("%s %q", what, name))
+		}
+	}
+	return nil
+}
+
+// isPHCValueRune reports whether r may appear in a parameter value.
+func isPHCValueRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '/' || r == '+' || r == '.' || r == '-'
+}
+
+// decodePHCField decodes the salt or hash segment. Only the hash must be present; the scrypt RFC
+// 7914 test vectors, for one, have an empty salt.
+func decodePHCField(what, field string) ([]byte, error) {
+	if len(field) > maxPHCBase64 {
+		return nil, phcError(ErrPHCFieldTooLong, fmt.Sprintf("%s is %d bytes", what, len(field)))
+	}
+	b, err := decodePHCBase64(field)
+	if err != nil {
+		return nil, phcError(ErrPHCBadBase64, what)
+	}
+	if len(b) == 0 && what == "hash" {
+		return nil, phcError(ErrPHCMissingSegment, "empty hash")
+	}
+	return b, nil
+}
+
+// phcError wraps ErrMalformedHash and the specific PHC error with detail.
+func phcError(kind error, detail string) error {
+	return fmt.Errorf("%w: %w: %s", ErrMalformedHash, kind, detail)
+}
@@ -0,0 +1,160 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePHC(t *testing.T) {
+	tests := []struct {
+		encoded string
+		id      string
+		version int
+		params  map[string]string
+		salt    string
+	}{
+		{"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", "argon2id", 19, map[string]string{"m": "65536", "t": "2", "p": "1"}, "somesalt"},
+		{"$argon2id$m=256,t=2,p=1,keyid=abc$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", "argon2id", 0, map[string]string{"m": "256", "t": "2", "p": "1", "keyid": "abc"}, "somesalt"},
+		{"$pbkdf2-sha256$i=100000$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", "pbkdf2-sha256", 0, map[string]string{"i": "100000"}, "somesalt"},
+		{"$scrypt$ln=4,r=1,p=1$$dyPQ3ZtvO6z8KTBHw2kxr/8Ap3HV/P5V2ssGCEQWVXQf5f1vXvNZeKg/vtLsnii18G1juYTLmmsya3uEq9tINA", "scrypt", 0, map[string]string{"ln": "4", "r": "1", "p": "1"}, ""},
+		{"$custom$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", "custom", 0, nil, "somesalt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			p, err := ParsePHC(tt.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.ID != tt.id || p.Version != tt.version || !reflect.DeepEqual(p.Params, tt.params) || string(p.Salt) != tt.salt {
+				t.Errorf("ParsePHC() = %s v%d %v salt %q, want %s v%d %v salt %q", p.ID, p.Version, p.Params, p.Salt, tt.id, tt.version, tt.params, tt.salt)
+			}
+			if got := p.String(); got != tt.encoded {
+				t.Errorf("String() = %q, want %q", got, tt.encoded)
+			}
+		})
+	}
+}
+
+func TestParsePHCBcrypt(t *testing.T) {
+	const encoded = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+	p, err := ParsePHC(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != "2a" || p.Params["cost"] != "10" || len(p.Salt) != 16 || len(p.Hash) != 23 {
+		t.Errorf("ParsePHC() = %s cost %s, %d-byte salt, %d-byte hash, want 2a cost 10, 16 and 23 bytes", p.ID, p.Params["cost"], len(p.Salt), len(p.Hash))
+	}
+	if got := p.String(); got != encoded {
+		t.Errorf("String() = %q, want %q", got, encoded)
+	}
+}
+
+func TestPHCStringAddedParams(t *testing.T) {
+	p, err := ParsePHC("$argon2id$v=19$m=256,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Params["t"] = "3"
+	p.Params["keyid"] = "k1"
+	p.Params["data"] = "ZGF0YQ"
+	delete(p.Params, "p")
+	if got, want := p.String(), "$argon2id$v=19$m=256,t=3,data=ZGF0YQ,keyid=k1$"; !strings.HasPrefix(got, want) {
+		t.Errorf("String() = %q, want prefix %q", got, want)
+	}
+
+	built := PHC{ID: "pbkdf2-sha256", Params: map[string]string{"i": "600000"}, Salt: []byte("somesalt"), Hash: []byte{1, 2, 3}}
+	if got, want := built.String(), "$pbkdf2-sha256$i=600000$c29tZXNhbHQ$AQID"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestParsePHCErrors(t *testing.T) {
+	long := strings.Repeat("a", 2000)
+	tests := []struct {
+		name    string
+		encoded string
+		want    error
+	}{
+		{"Empty", "", ErrPHCMissingSegment},
+		{"No leading dollar", "argon2id$v=19$m=1$c2FsdA$aGFzaA", ErrPHCMissingSegment},
+		{"Empty identifier", "$$c2FsdA$aGFzaA", ErrPHCMissingSegment},
+		{"Missing hash", "$argon2id$v=19$m=256,t=2,p=1$c29tZXNhbHQ", ErrPHCMissingSegment},
+		{"Empty hash", "$argon2id$v=19$m=256,t=2,p=1$c29tZXNhbHQ$", ErrPHCMissingSegment},
+		{"Too many segments", "$argon2id$v=19$m=256$t=2$c29tZXNhbHQ$aGFzaA", ErrPHCInvalidField},
+		{"Uppercase identifier", "$Argon2id$c2FsdA$aGFzaA", ErrPHCInvalidField},
+		{"Bad version", "$argon2id$v=x$m=256$c2FsdA$aGFzaA", ErrPHCInvalidField},
+		{"Parameter without value", "$argon2id$m$c2FsdA$aGFzaA", ErrPHCInvalidField},
+		{"Empty parameter value", "$argon2id$m=$c2FsdA$aGFzaA", ErrPHCInvalidField},
+		{"Repeated parameter", "$argon2id$m=1,m=2$c2FsdA$aGFzaA", ErrPHCInvalidField},
+		{"Bad parameter value", "$argon2id$m=1;2$c2FsdA$aGFzaA", ErrPHCInvalidField},
+		{"crypt(3) format", "$6$saltsalt$qFmFH.bQmmtXzyBY0s9v7Oicd2z4XSIecDzlB5KiA2", ErrPHCInvalidField},
+		{"Bad salt base64", "$argon2id$m=1$c2Fs!A$aGFzaA", ErrPHCBadBase64},
+		{"Bad hash base64", "$argon2id$m=1$c2FsdA$aGF*aA", ErrPHCBadBase64},
+		{"Long identifier", "$" + long[:40] + "$c2FsdA$aGFzaA", ErrPHCFieldTooLong},
+		{"Long parameter name", "$argon2id$" + long[:40] + "=1$c2FsdA$aGFzaA", ErrPHCFieldTooLong},
+		{"Long parameter value", "$argon2id$m=" + long[:100] + "$c2FsdA$aGFzaA", ErrPHCFieldTooLong},
+		{"Long salt", "$argon2id$m=1$" + long + "$aGFzaA", ErrPHCFieldTooLong},
+		{"Long hash", "$argon2id$m=1$c2FsdA$" + long, ErrPHCFieldTooLong},
+		{"Short bcrypt", "$2b$10$N9qo8uLOickgx2ZMRZoMye", ErrPHCMissingSegment},
+		{"Missing bcrypt hash", "$2b$10", ErrPHCMissingSegment},
+		{"Long bcrypt", "$2b$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWyy", ErrPHCFieldTooLong},
+		{"Bad bcrypt cost", "$2b$3$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", ErrPHCInvalidField},
+		{"Bad bcrypt base64", "$2b$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lh+y", ErrPHCBadBase64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePHC(tt.encoded)
+			if !errors.Is(err, tt.want) || !errors.Is(err, ErrMalformedHash) {
+				t.Errorf("ParsePHC() error = %v, want %v and ErrMalformedHash", err, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzParsePHC(f *testing.F) {
+	for _, seed := range []string{
+		"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$scrypt$ln=4,r=1,p=1$$dyPQ3ZtvO6z8KTBHw2kxr",
+		"$2b$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+		"$pbkdf2-sha256$i=1$c2FsdA==$aGFzaA",
+		"$x$v=0$$AA", "$", "$$$$", "$a$b=c,d$e$f",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, encoded string) {
+		p, err := ParsePHC(encoded)
+		if err != nil {
+			if !errors.Is(err, ErrMalformedHash) {
+				t.Fatalf("ParsePHC(%q) error = %v, want ErrMalformedHash", encoded, err)
+			}
+			return
+		}
+		again, err := ParsePHC(p.String())
+		if err != nil {
+			t.Fatalf("ParsePHC(%q) failed on String() %q of its own result: %v", encoded, p.String(), err)
+		}
+		if again.ID != p.ID || again.Version != p.Version || !reflect.DeepEqual(again.Params, p.Params) ||
+			!bytes.Equal(again.Salt, p.Salt) || !bytes.Equal(again.Hash, p.Hash) {
+			t.Fatalf("ParsePHC(%q) = %+v, reparsed from %q = %+v", encoded, p, p.String(), again)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import "fmt"
+
+// Match kinds of the personal numbers reported in Result.Patterns. A personal number is never
+// copied into its Match: Token and Base are left empty and only the position is reported.
+const (
+	PatternCardNumber MatchKind = "card_number" // A run of 13 to 19 digits passing the Luhn check, optionally grouped
+	PatternSSN        MatchKind = "ssn"         // Nine digits shaped like a US Social Security number, as 078051120 or 078-05-1120
+)
+
+// Digit runs of these lengths passing the Luhn check are read as payment card numbers.
+const (
+	minCardDigits = 13
+	maxCardDigits = 19
+)
+
+// detectPII returns the card numbers and SSN-like numbers in pass. Like detectDates it allocates
+// nothing when there are none.
+func detectPII(pass string) []Match {
+	var patterns []Match
+	index := 0 // Position of pass[i], counted in runes
+	for i := 0; i < len(pass); {
+		if !isASCIIDigit(pass[i]) {
+			if pass[i] < 0x80 || pass[i] >= 0xC0 {
+				index++
+			}
+			i++
+			continue
+		}
+		end := numberEnd(pass, i)
+		if kind, ok := piiKind(pass[i:end]); ok {
+			patterns = append(patterns, Match{Kind: kind, Start: index, End: index + end - i})
+		}
+		index += end - i
+		i = end
+	}
+	return patterns
+}
+
+// piiKind returns the kind of personal number token is, and whether it is one.
+func piiKind(token string) (MatchKind, bool) {
+	digits := digitCount(token)
+	switch {
+	case digits >= minCardDigits && digits <= maxCardDigits && luhnValid(token):
+		return PatternCardNumber, true
+	case isSSN(token):
+		return PatternSSN, true
+	}
+	return "", false
+}
+
+// luhnValid reports whether the digits of token pass the Luhn checksum used by card numbers.
+func luhnValid(token string) bool {
+	sum, double := 0, false
+	for i := len(token) - 1; i >= 0; i-- {
+		if !isASCIIDigit(token[i]) {
+			continue
+		}
+		d := int(token[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// isSSN reports whether token is nine digits, or three, two, and four split by dashes, that could
+// be a Social Security number: no area 000, 666, or 900 and above, no group 00, and no serial 0000.
+func isSSN(token string) bool {
+	var area, group, serial string
+	switch {
+	case len(token) == 9 && digitCount(token) == 9:
+		area, group, serial = token[:3], token[3:5], token[5:]
+	case len(token) == 11 && token[3] == '-' && token[6] == '-' && digitCount(token) == 9:
+		area, group, serial = token[:3], token[4:6], token[7:]
+	default:
+		return false
+	}
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// redactPII clears the Token and Base of every match overlapping a personal number in pii, so
+// the digits never reach the report or an error through another detector.
+func redactPII(patterns, pii []Match) {
+	for i := range patterns {
+		for _, p := range pii {
+			if patterns[i].Start < p.End && p.Start < patterns[i].End {
+				patterns[i].Token, patterns[i].Base = "", ""
+				break
+			}
+		}
+	}
+}
+
+// patternError wraps err with the token and position of p, leaving out a token redactPII cleared.
+func patternError(err error, p Match) error {
+	if p.Token == "" {
+		return fmt.Errorf("%w at position %d", err, p.Start)
+	}
+	return fmt.Errorf("%w: %q at position %d", err, p.Token, p.Start)
+}
@@ -0,0 +1,140 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The card numbers below are the synthetic test numbers card networks publish for sandboxes,
+// and the SSNs are ones the Social Security Administration never issues to a person.
+func TestDetectPII(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     []Match
+	}{
+		{"Visa test number", "4111111111111111", []Match{{Kind: PatternCardNumber, End: 16}}},
+		{"Grouped with spaces", "pay 4111 1111 1111 1111!", []Match{{Kind: PatternCardNumber, Start: 4, End: 23}}},
+		{"Grouped with dashes", "5555-5555-5555-4444", []Match{{Kind: PatternCardNumber, End: 19}}},
+		{"Fifteen digits", "Ä378282246310005", []Match{{Kind: PatternCardNumber, Start: 1, End: 16}}},
+		{"Thirteen digits", "x4222222222222", []Match{{Kind: PatternCardNumber, Start: 1, End: 14}}},
+		{"Failing the Luhn check", "4111111111111112", nil},
+		{"Too short for a card", "411111111116", nil},
+		{"SSN with dashes", "me078-05-1120", []Match{{Kind: PatternSSN, Start: 2, End: 13}}},
+		{"SSN without dashes", "078051120", []Match{{Kind: PatternSSN, End: 9}}},
+		{"Area 666", "666-05-1120", nil},
+		{"Area 9xx", "900051120", nil},
+		{"Group 00", "078-00-1120", nil},
+		{"Serial 0000", "078-05-0000", nil},
+		{"Other grouping", "078.05.1120", nil},
+		{"No digits", "correct horse", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectPII(tt.password)
+			if len(got) != len(tt.want) {
+				t.Fatalf("detectPII(%q) = %+v, want %+v", tt.password, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("detectPII(%q)[%d] = %+v, want %+v", tt.password, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAuditRejectPII(t *testing.T) {
+	options := Options{RejectPII: true, RejectDates: true, MaxRepeatRun: 20}
+	for _, pass := range []string{"4111111111111111", "Card:5555-5555-5555-4444", "ssn078-05-1120", "078051120"} {
+		result := Audit(pass, options)
+		var verr *ValidationError
+		if !errors.Is(result.Err, ErrLooksLikePII) || !errors.As(result.Err, &verr) || verr.Code != CodePII {
+			t.Errorf("Audit(%q) error = %v, want %v with code %q", pass, result.Err, ErrLooksLikePII, CodePII)
+		}
+		if errors.Is(result.Err, ErrDate) {
+			t.Errorf("Audit(%q) reported the number as a date or phone number", pass)
+		}
+		for _, p := range result.Patterns {
+			if p.Token != "" || p.Base != "" {
+				t.Errorf("Audit(%q) Patterns has %+v, want no digits of the number", pass, p)
+			}
+		}
+		for _, v := range result.Violations {
+			if strings.Contains(digitsOf(v.Error()), digitsOf(pass)[:6]) {
+				t.Errorf("Audit(%q) violation %q has digits of the number", pass, v)
+			}
+		}
+	}
+
+	result := Audit("4111111111111111", Options{})
+	if result.Err != nil || len(result.Patterns) != 1 || result.Patterns[0].Kind != PatternCardNumber {
+		t.Errorf("Audit() without RejectPII = %v, %+v, want only a card number pattern", result.Err, result.Patterns)
+	}
+}
+
+func TestRedactPIIOverlapping(t *testing.T) {
+	result := Audit("x4111111111111111", Options{MaxRepeatRun: 3})
+	for _, p := range result.Patterns {
+		if p.Token != "" || p.Base != "" {
+			t.Errorf("Audit() Patterns has %+v, want the card number's digits redacted", p)
+		}
+	}
+	if len(result.Patterns) != 2 {
+		t.Errorf("Audit() Patterns = %+v, want the repeat and the card number", result.Patterns)
+	}
+}
+
+func TestAuditPIIErrorRedacted(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		want     error
+	}{
+		{"Repeat in a card number", "4111111111111111", Options{MaxRepeatRun: 2, RejectPII: true}, ErrRepeatedChars},
+		{"Repeat without RejectPII", "4111111111111111", Options{MaxRepeatRun: 2}, ErrRepeatedChars},
+		{"Sequence in a card number", "1234567812345670", Options{MaxSequenceLength: 3, RejectSequences: true}, ErrSequentialChars},
+		{"Keyboard walk in a card number", "1234567812345670", Options{KeyboardWalkLength: 4, RejectKeyboardWalks: true}, ErrKeyboardWalk},
+		{"Repeat in an SSN", "078-05-1120", Options{MaxRepeatRun: 1}, ErrRepeatedChars},
+		{"User input in an SSN", "078-05-1120", Options{UserInputs: []string{"078-05-1120"}}, ErrContainsUserInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if !errors.Is(result.Err, tt.want) {
+				t.Fatalf("Audit(%q) error = %v, want %v", tt.password, result.Err, tt.want)
+			}
+			if digits := digitsOf(result.Err.Error()); len(digits) > 2 {
+				t.Errorf("Audit(%q) error = %q, want no digits of the number", tt.password, result.Err)
+			}
+		})
+	}
+}
+
+// digitsOf returns the digits of s.
+func digitsOf(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,164 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/common_pins.txt
+var commonPINsData string
+
+var (
+	commonPINsOnce sync.Once
+	commonPINs     map[string]struct{}
+)
+
+// loadCommonPINs builds the lookup set for the embedded PIN list on first use.
+func loadCommonPINs() map[string]struct{} {
+	commonPINsOnce.Do(func() {
+		lines := strings.Fields(commonPINsData)
+		commonPINs = make(map[string]struct{}, len(lines))
+		for _, line := range lines {
+			commonPINs[line] = struct{}{}
+		}
+	})
+	return commonPINs
+}
+
+// Defaults applied by AuditPIN to zero PINOptions fields.
+const (
+	DefaultPINMinLength = 4
+	DefaultPINMaxLength = 8
+	DefaultPINMinYear   = 1900
+	DefaultPINMaxYear   = 2099
+)
+
+// PINOptions configures AuditPIN. Zero fields use the Default PIN constants.
+type PINOptions struct {
+	MinLength uint // Minimum number of digits
+	MaxLength uint // Maximum number of digits
+	MinYear   int  // Four-digit PINs from MinYear to MaxYear are rejected as years
+	MaxYear   int
+	FailFast  bool // Stop at the first failed requirement
+}
+
+// AuditPIN audits a numeric PIN. It requires MinLength to MaxLength ASCII digits and rejects PINs
+// made of one repeated digit or block ("1111", "1212"), ascending or descending runs ("1234",
+// "9876"), entries of the embedded list of common PINs, and four-digit years between MinYear and
+// MaxYear. Entropy starts at log2(10) per digit and drops to the size of the smallest weak family
+// the PIN belongs to, e.g. log2(10) bits for "1111".
+func AuditPIN(pin string, opts PINOptions) (audit Result) {
+	if opts.MinLength == 0 {
+		opts.MinLength = DefaultPINMinLength
+	}
+	if opts.MaxLength == 0 {
+		opts.MaxLength = DefaultPINMaxLength
+	}
+	if opts.MinYear == 0 && opts.MaxYear == 0 {
+		opts.MinYear, opts.MaxYear = DefaultPINMinYear, DefaultPINMaxYear
+	}
+
+	counts, length := classify(pin, "", false)
+	audit = Result{
+		Length:      int64(length),
+		LengthBytes: int64(len(pin)),
+		Counts:      counts,
+		Classes:     counts.Classes(),
+	}
+	audit.Complexity = complexityOf(audit.Classes)
+	defer func() {
+		audit.Score = scoreOf(audit.EffectiveEntropy, audit.Length, audit.Classes, false)
+		audit.Rating = RatingOf(audit.Score)
+		audit.CrackTimes = crackTimesOf(audit.EffectiveEntropy)
+		audit.Strong = audit.Err == nil
+	}()
+
+	if counts.Digits != length {
+		audit.violate(validationError(CodePINNotDigits, "", ErrPINNotDigits), true)
+		return audit
+	}
+	if length < int(opts.MinLength) {
+		audit.violate(validationError(CodeTooShort, "MinLength", fmt.Errorf("%w: %d digits, minimum is %d", ErrTooShort, length, opts.MinLength),
+			"length", length, "min_length", opts.MinLength), opts.FailFast)
+	}
+	if length > int(opts.MaxLength) {
+		audit.violate(validationError(CodeTooLong, "MaxLength", fmt.Errorf("%w: %d digits, maximum is %d", ErrTooLong, length, opts.MaxLength),
+			"length", length, "max_length", opts.MaxLength), opts.FailFast)
+	}
+	if length == 0 {
+		return audit
+	}
+
+	// guesses is the size of the smallest family of weak PINs this one belongs to.
+	guesses := math.Pow(10, float64(length))
+	// penalty is the entropy lost by a PIN that is one of only n guesses.
+	penalty := func(n float64) float64 {
+		return math.Log2(guesses) - math.Log2(n)
+	}
+	weaker := func(n float64) {
+		if n < guesses {
+			guesses = n
+		}
+	}
+
+	if block := repeatingBlock(pin); block != "" {
+		audit.Patterns = append(audit.Patterns, Match{Kind: PatternRepeatedBlock, Token: pin, End: length, Base: block, Penalty: penalty(math.Pow(10, float64(len(block))))})
+		if len(block) == 1 {
+			audit.Patterns[len(audit.Patterns)-1].Kind = PatternRepeat
+		}
+		weaker(math.Pow(10, float64(len(block))))
+		audit.violate(validationError(CodePINRepeated, "", fmt.Errorf("%w: a %d-digit block %d times", ErrPINRepeated, len(block), length/len(block))), opts.FailFast)
+	}
+	if length > 2 && len(detectSequences([]rune(pin), length-1)) == 1 {
+		audit.Patterns = append(audit.Patterns, Match{Kind: PatternSequence, Token: pin, End: length, Penalty: penalty(20)})
+		weaker(20) // ten starting digits in two directions
+		audit.violate(validationError(CodePINSequence, "", ErrPINSequence), opts.FailFast)
+	}
+	if _, ok := loadCommonPINs()[pin]; ok {
+		weaker(float64(len(loadCommonPINs())))
+		audit.violate(validationError(CodeCommonPIN, "", ErrCommonPIN), opts.FailFast)
+	}
+	if length == 4 {
+		if year, _ := strconv.Atoi(pin); year >= opts.MinYear && year <= opts.MaxYear {
+			weaker(float64(opts.MaxYear - opts.MinYear + 1))
+			audit.violate(validationError(CodePINYear, "MinYear", fmt.Errorf("%w between %d and %d", ErrPINYear, opts.MinYear, opts.MaxYear),
+				"min_year", opts.MinYear, "max_year", opts.MaxYear), opts.FailFast)
+		}
+	}
+
+	audit.CharsetEntropy = float64(length) * math.Log2(10)
+	audit.Entropy = audit.CharsetEntropy
+	audit.ShannonEntropy = shannonEntropy(pin, length)
+	audit.EffectiveEntropy = math.Log2(guesses)
+	return audit
+}
+
+// repeatingBlock returns the shortest block that pin repeats at least twice, or "" when it has none.
+func repeatingBlock(pin string) string {
+	for size := 1; size <= len(pin)/2; size++ {
+		if len(pin)%size == 0 && strings.Repeat(pin[:size], len(pin)/size) == pin {
+			return pin[:size]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAuditPIN(t *testing.T) {
+	tests := []struct {
+		name    string
+		pin     string
+		options PINOptions
+		want    error
+		entropy float64
+	}{
+		{name: "Random", pin: "4831", entropy: 4 * math.Log2(10)},
+		{name: "Random six digits", pin: "390417", entropy: 6 * math.Log2(10)},
+		{name: "Letters", pin: "12a4", want: ErrPINNotDigits},
+		{name: "Non-ASCII digits", pin: "١٢٣٤", want: ErrPINNotDigits},
+		{name: "Too short", pin: "482", want: ErrTooShort},
+		{name: "Too long", pin: "482019375", want: ErrTooLong},
+		{name: "Custom length", pin: "482", options: PINOptions{MinLength: 3}, entropy: 3 * math.Log2(10)},
+		{name: "Repeated digit", pin: "7777", want: ErrPINRepeated, entropy: math.Log2(10)},
+		{name: "Repeated block", pin: "4747", want: ErrPINRepeated, entropy: math.Log2(100)},
+		{name: "Repeated triple", pin: "385385", want: ErrPINRepeated, entropy: math.Log2(1000)},
+		{name: "Ascending", pin: "3456", want: ErrPINSequence, entropy: math.Log2(20)},
+		{name: "Descending", pin: "876543", want: ErrPINSequence, entropy: math.Log2(20)},
+		{name: "Partial sequence", pin: "34569", entropy: 5 * math.Log2(10)},
+		{name: "Common", pin: "2580", want: ErrCommonPIN},
+		{name: "Year", pin: "1987", want: ErrPINYear, entropy: math.Log2(200)},
+		{name: "Year outside range", pin: "1887", entropy: 4 * math.Log2(10)},
+		{name: "Custom year range", pin: "1887", options: PINOptions{MinYear: 1850, MaxYear: 1899}, want: ErrPINYear},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AuditPIN(tt.pin, tt.options)
+			if !errors.Is(result.Err, tt.want) || (tt.want == nil) != (result.Err == nil) {
+				t.Fatalf("AuditPIN(%q) error = %v, want %v", tt.pin, result.Err, tt.want)
+			}
+			if result.Strong != (result.Err == nil) {
+				t.Errorf("AuditPIN(%q) Strong = %v with error %v", tt.pin, result.Strong, result.Err)
+			}
+			if result.Err != nil && strings.Contains(result.Err.Error(), tt.pin) {
+				t.Errorf("AuditPIN(%q) error = %q, want the PIN left out", tt.pin, result.Err)
+			}
+			if tt.entropy != 0 && math.Abs(result.EffectiveEntropy-tt.entropy) > 1e-9 {
+				t.Errorf("AuditPIN(%q) EffectiveEntropy = %f, want %f", tt.pin, result.EffectiveEntropy, tt.entropy)
+			}
+		})
+	}
+}
+
+func TestAuditPINJoinsErrors(t *testing.T) {
+	result := AuditPIN("1111", PINOptions{})
+	for _, want := range []error{ErrPINRepeated, ErrCommonPIN} {
+		if !errors.Is(result.Err, want) {
+			t.Errorf("AuditPIN(%q) error = %v, want %v", "1111", result.Err, want)
+		}
+	}
+
+	result = AuditPIN("1111", PINOptions{FailFast: true})
+	if errors.Is(result.Err, ErrCommonPIN) {
+		t.Errorf("AuditPIN(%q) with FailFast error = %v, want only %v", "1111", result.Err, ErrPINRepeated)
+	}
+	if full := AuditPIN("1111", PINOptions{}); result.EffectiveEntropy != full.EffectiveEntropy || result.Score != full.Score {
+		t.Errorf("AuditPIN(%q) with FailFast EffectiveEntropy = %.2f, Score = %d, want %.2f and %d", "1111", result.EffectiveEntropy, result.Score, full.EffectiveEntropy, full.Score)
+	}
+}
+
+func TestCommonPINs(t *testing.T) {
+	pins := loadCommonPINs()
+	if len(pins) != 100 {
+		t.Fatalf("len(commonPINs) = %d, want 100", len(pins))
+	}
+	for pin := range pins {
+		if counts, n := classify(pin, "", false); counts.Digits != n || (n != 4 && n != 6) {
+			t.Errorf("common PIN %q is not four or six digits", pin)
+		}
+	}
+}
+
+func BenchmarkAuditPIN(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = AuditPIN("4831", PINOptions{})
+	}
+}
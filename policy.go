@@ -0,0 +1,68 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Policy names set by the preset constructors.
+const (
+	PolicyNIST   = "NIST SP 800-63B"
+	PolicyOWASP  = "OWASP ASVS 4.0"
+	PolicyPCIDSS = "PCI DSS 4.0"
+)
+
+// NISTOptions returns Options for memorized secrets under NIST SP 800-63B section 5.1.1: at least 8
+// characters, up to 64 accepted, no composition rules, and rejection of common, breached,
+// repetitive, and sequential passwords. Breached passwords are looked up with DefaultPwnedClient
+// and a failed lookup rejects the password; set BreachChecker to nil to audit offline. Add the
+// user's details to UserInputs for the context-specific check.
+func NISTOptions() Options {
+	return Options{
+		MinLength:         8,
+		MaxLength:         64,
+		RejectCommon:      true,
+		NormalizeLeet:     true,
+		BreachChecker:     DefaultPwnedClient,
+		MaxSequenceLength: 3,
+		RejectSequences:   true,
+		MaxRepeatRun:      3,
+		PolicyName:        PolicyNIST,
+	}
+}
+
+// OWASPOptions returns Options for OWASP ASVS 4.0 section 2.1: at least 12 characters, nothing
+// over 128, no composition rules, and rejection of common and breached passwords. Breached
+// passwords are looked up with DefaultPwnedClient as in NISTOptions.
+func OWASPOptions() Options {
+	return Options{
+		MinLength:     12,
+		MaxLength:     128,
+		RejectCommon:  true,
+		BreachChecker: DefaultPwnedClient,
+		PolicyName:    PolicyOWASP,
+	}
+}
+
+// PCIDSSOptions returns Options for PCI DSS 4.0 requirement 8.3.6: at least 12 characters with
+// both digits and letters. Letters are checked as lowercase letters. Requirement 8.3.7, which bars
+// the last four passwords, needs the account's hashes in History.
+func PCIDSSOptions() Options {
+	return Options{
+		MinLength:  12,
+		UseDigits:  true,
+		UseLower:   true,
+		PolicyName: PolicyPCIDSS,
+	}
+}
@@ -0,0 +1,83 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// breachedSample stands in for breach data so the presets can be tested offline.
+var breachedSample = BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+	if password == "Tr0ub4dor&3" || password == "ilovemyfamily2" {
+		return true, 42, nil
+	}
+	return false, 0, nil
+})
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		policy   string
+		password string
+		want     error
+	}{
+		{"NIST accepts a passphrase", NISTOptions(), PolicyNIST, "correct horse battery staple", nil},
+		{"NIST accepts no composition", NISTOptions(), PolicyNIST, "purplemonkeydishwasher", nil},
+		{"NIST rejects short", NISTOptions(), PolicyNIST, "Ab1!xyz", ErrTooShort},
+		{"NIST rejects over 64", NISTOptions(), PolicyNIST, strings.Repeat("cow horse ", 7), ErrTooLong},
+		{"NIST rejects dictionary words", NISTOptions(), PolicyNIST, "password", ErrCommonPassword},
+		{"NIST rejects disguised dictionary words", NISTOptions(), PolicyNIST, "p@ssw0rd", ErrCommonPassword},
+		{"NIST rejects repetitive", NISTOptions(), PolicyNIST, "aaaaaaaa", ErrRepeatedChars},
+		{"NIST rejects sequential", NISTOptions(), PolicyNIST, "1234abcd", ErrSequentialChars},
+		{"NIST rejects breached", NISTOptions(), PolicyNIST, "Tr0ub4dor&3", ErrPwnedPassword},
+		{"OWASP accepts a passphrase", OWASPOptions(), PolicyOWASP, "correct horse battery staple", nil},
+		{"OWASP accepts 128 characters", OWASPOptions(), PolicyOWASP, strings.Repeat("ab c", 32), nil},
+		{"OWASP rejects under 12", OWASPOptions(), PolicyOWASP, "Password1!", ErrTooShort},
+		{"OWASP rejects over 128", OWASPOptions(), PolicyOWASP, strings.Repeat("ab c", 32) + "d", ErrTooLong},
+		{"OWASP rejects breached", OWASPOptions(), PolicyOWASP, "ilovemyfamily2", ErrPwnedPassword},
+		{"PCI accepts letters and digits", PCIDSSOptions(), PolicyPCIDSS, "winter2024lights", nil},
+		{"PCI rejects under 12", PCIDSSOptions(), PolicyPCIDSS, "winter2024", ErrTooShort},
+		{"PCI rejects letters only", PCIDSSOptions(), PolicyPCIDSS, "winterlightsfall", ErrMissingDigits},
+		{"PCI rejects digits only", PCIDSSOptions(), PolicyPCIDSS, "202420242024", ErrMissingLower},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.options.BreachChecker != DefaultPwnedClient && tt.policy != PolicyPCIDSS {
+				t.Fatalf("%s preset does not check breaches with DefaultPwnedClient", tt.policy)
+			}
+			if tt.options.BreachChecker != nil {
+				tt.options.BreachChecker = breachedSample
+			}
+			if _, err := NewValidator(tt.options); err != nil {
+				t.Fatalf("NewValidator() error = %v", err)
+			}
+
+			result := Audit(tt.password, tt.options)
+			if !errors.Is(result.Err, tt.want) || (tt.want == nil) != (result.Err == nil) {
+				t.Errorf("Audit(%q) error = %v, want %v", tt.password, result.Err, tt.want)
+			}
+			if result.Policy != tt.policy {
+				t.Errorf("Audit(%q) Policy = %q, want %q", tt.password, result.Policy, tt.policy)
+			}
+		})
+	}
+}
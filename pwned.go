@@ -0,0 +1,238 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// BreachChecker reports whether a password appears in a compromised-password source and how many
+// times it was seen. Implementations must not log or retain the password.
+type BreachChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, int, error)
+}
+
+// BreachCheckerFunc adapts an ordinary function to the BreachChecker interface.
+type BreachCheckerFunc func(ctx context.Context, password string) (bool, int, error)
+
+// IsBreached calls f(ctx, password).
+func (f BreachCheckerFunc) IsBreached(ctx context.Context, password string) (bool, int, error) {
+	return f(ctx, password)
+}
+
+// PwnedRangeURL is the Have I Been Pwned range endpoint queried by PwnedClient.
+const PwnedRangeURL = "https://api.pwnedpasswords.com/range/"
+
+// PwnedClient queries the Have I Been Pwned range API using k-anonymity: only the first five hex
+// characters of the password's SHA-1 hash leave the process.
+type PwnedClient struct {
+	HTTPClient *http.Client // Defaults to http.DefaultClient
+	BaseURL    string       // Defaults to PwnedRangeURL
+}
+
+// DefaultPwnedClient is used by CheckPwned.
+var DefaultPwnedClient = &PwnedClient{}
+
+// CheckPwned returns how many times password appears in the Have I Been Pwned corpus using
+// DefaultPwnedClient.
+func CheckPwned(ctx context.Context, password string) (int, error) {
+	return DefaultPwnedClient.CheckPwned(ctx, password)
+}
+
+// IsBreached implements BreachChecker using CheckPwned.
+func (c *PwnedClient) IsBreached(ctx context.Context, password string) (bool, int, error) {
+	count, err := c.CheckPwned(ctx, password)
+	return count > 0, count, err
+}
+
+// CheckPwned returns how many times password appears in the Have I Been Pwned corpus. A count of
+// zero means the password was not found.
+func (c *PwnedClient) CheckPwned(ctx context.Context, password string) (int, error) {
+	prefix, suffix := pwnedHash(password)
+	body, err := c.fetchRange(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	return rangeCount(body, suffix)
+}
+
+// DefaultPwnedConcurrency is the number of range requests CheckPwnedBatch keeps in flight when
+// concurrency is zero.
+const DefaultPwnedConcurrency = 8
+
+// CheckPwnedBatch is like PwnedClient.CheckPwnedBatch using DefaultPwnedClient.
+func CheckPwnedBatch(ctx context.Context, passwords []string, concurrency int) (map[string]int, error) {
+	return DefaultPwnedClient.CheckPwnedBatch(ctx, passwords, concurrency)
+}
+
+// PwnedKey returns the key CheckPwnedBatch reports password under: the uppercase hex SHA-1 hash.
+// The hash is unsalted and cracks like the password, so treat it as the password itself.
+func PwnedKey(password string) string {
+	prefix, suffix := pwnedHash(password)
+	return prefix + suffix
+}
+
+// CheckPwnedBatch returns how many times each of passwords appears in the Have I Been Pwned
+// corpus, keyed by PwnedKey. The keys are as sensitive as the passwords, so never log or store
+// the map. It hashes every password first and requests each five character prefix once, at most
+// concurrency requests at a time (DefaultPwnedConcurrency when zero or negative). When a request
+// fails or ctx is done it returns the counts resolved so far with the error, or ctx.Err(); a key
+// absent from the map was not checked.
+func (c *PwnedClient) CheckPwnedBatch(ctx context.Context, passwords []string, concurrency int) (map[string]int, error) {
+	buckets := make(map[string][]string)
+	seen := make(map[string]bool, len(passwords))
+	for _, password := range passwords {
+		prefix, suffix := pwnedHash(password)
+		if !seen[prefix+suffix] {
+			seen[prefix+suffix] = true
+			buckets[prefix] = append(buckets[prefix], suffix)
+		}
+	}
+	prefixes := slices.Sorted(maps.Keys(buckets))
+	if concurrency <= 0 {
+		concurrency = DefaultPwnedConcurrency
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		next     atomic.Int64
+		firstErr error
+		counts   = make(map[string]int, len(seen))
+	)
+	for range min(concurrency, len(prefixes)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= len(prefixes) {
+					return
+				}
+				prefix := prefixes[i]
+				found, err := c.bucketCounts(ctx, prefix, buckets[prefix])
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				for suffix, n := range found {
+					counts[prefix+suffix] = n
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := parent.Err(); err != nil {
+		return counts, err
+	}
+	return counts, firstErr
+}
+
+// bucketCounts fetches the range for prefix and returns the count of each of suffixes, or nothing
+// and the error when the range cannot be read.
+func (c *PwnedClient) bucketCounts(ctx context.Context, prefix string, suffixes []string) (map[string]int, error) {
+	body, err := c.fetchRange(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]int, len(suffixes))
+	for _, suffix := range suffixes {
+		if found[suffix], err = rangeCount(body, suffix); err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+// maxRangeBytes bounds the range response PwnedClient reads; padded responses are about 40 KB.
+const maxRangeBytes = 1 << 20
+
+// pwnedHash splits the uppercase hex SHA-1 hash of password into the five character prefix sent
+// to the range API and the suffix looked up in its response.
+func pwnedHash(password string) (prefix, suffix string) {
+	p := []byte(password)
+	sum := sha1.Sum(p)
+	wipe(p)
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:5], hash[5:]
+}
+
+// fetchRange returns the range response for prefix, one "SUFFIX:count" line per hash.
+func (c *PwnedClient) fetchRange(ctx context.Context, prefix string) ([]byte, error) {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = PwnedRangeURL
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pwned passwords range request failed: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRangeBytes))
+}
+
+// rangeCount returns the count of suffix in a range response, zero when it is absent.
+func rangeCount(body []byte, suffix string) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, count, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("pwned passwords range response has an invalid count: %w", err)
+		}
+		return n, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,231 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPwnedServer serves a range response for the SHA-1 prefix of "password" (5BAA6).
+func newPwnedServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/5BAA6" {
+			fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n")
+			return
+		}
+		fmt.Fprint(w, "1D2DA4053E34E76F6576ED1DA63134B5E2A:2\r\n")
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n")
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD9:0\r\n")
+	}))
+	t.Cleanup(server.Close)
+	return server, &paths
+}
+
+func TestPwnedClientCheckPwned(t *testing.T) {
+	server, paths := newPwnedServer(t)
+	client := &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}
+
+	count, err := client.CheckPwned(context.Background(), "password")
+	if err != nil {
+		t.Fatalf("CheckPwned() error = %v", err)
+	}
+	if count != 9545824 {
+		t.Errorf("CheckPwned() = %d, want 9545824", count)
+	}
+	if len(*paths) != 1 || (*paths)[0] != "/5BAA6" {
+		t.Errorf("requested paths = %v, want only the five character prefix", *paths)
+	}
+
+	count, err = client.CheckPwned(context.Background(), "Xq7#mB2vLp9!")
+	if err != nil || count != 0 {
+		t.Errorf("CheckPwned() = %d, %v, want 0, nil", count, err)
+	}
+}
+
+func TestPwnedClientContextCancelled(t *testing.T) {
+	server, _ := newPwnedServer(t)
+	client := &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.CheckPwned(ctx, "password"); !errors.Is(err, context.Canceled) {
+		t.Errorf("CheckPwned() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPwnedClientBadStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}
+	if _, err := client.CheckPwned(context.Background(), "password"); err == nil {
+		t.Errorf("CheckPwned() error = nil, want an error for a non-200 response")
+	}
+}
+
+func TestAuditBreachChecker(t *testing.T) {
+	server, _ := newPwnedServer(t)
+	client := &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}
+
+	result := Audit("password", Options{BreachChecker: client})
+	if !errors.Is(result.Err, ErrPwnedPassword) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrPwnedPassword)
+	}
+	if result.PwnedCount != 9545824 {
+		t.Errorf("Audit() pwned count = %d, want 9545824", result.PwnedCount)
+	}
+
+	failing := BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+		return false, 0, errors.New("network unreachable")
+	})
+
+	result = Audit("Xq7#mB2vLp9!", Options{BreachChecker: failing})
+	if !errors.Is(result.Err, ErrBreachCheckFailed) {
+		t.Errorf("Audit() error = %v, want %v when failing closed", result.Err, ErrBreachCheckFailed)
+	}
+
+	result = Audit("Xq7#mB2vLp9!", Options{BreachChecker: failing, BreachFailOpen: true})
+	if result.Err != nil {
+		t.Errorf("Audit() error = %v, want nil when failing open", result.Err)
+	}
+}
+
+func TestAuditBreachCheckerSkippedAfterFailure(t *testing.T) {
+	called := false
+	checker := BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+		called = true
+		return false, 0, nil
+	})
+
+	Audit("short", Options{MinLength: 8, BreachChecker: checker})
+	if called {
+		t.Errorf("BreachChecker called for a password that already failed the cheap checks")
+	}
+}
+
+// newBatchPwnedServer serves the range response of newPwnedServer, holding requests for other
+// prefixes until release is closed when block is set. It counts requests and the most in flight.
+func newBatchPwnedServer(t *testing.T, block bool) (server *httptest.Server, requests, peak *atomic.Int32) {
+	t.Helper()
+	requests, peak = new(atomic.Int32), new(atomic.Int32)
+	var inFlight atomic.Int32
+	release := make(chan struct{})
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		if r.URL.Path != "/5BAA6" {
+			if block {
+				<-release
+			}
+			fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n")
+			return
+		}
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n")
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return server, requests, peak
+}
+
+func TestPwnedClientCheckPwnedBatch(t *testing.T) {
+	passwords := []string{"password", "bucket-543162", "password", "Xq7#mB2vLp9!"}
+	for i := range 500 {
+		passwords = append(passwords, fmt.Sprintf("batch-%d", i))
+	}
+	prefixes := make(map[string]bool)
+	for _, password := range passwords {
+		prefixes[PwnedKey(password)[:5]] = true
+	}
+
+	server, requests, peak := newBatchPwnedServer(t, false)
+	client := &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}
+	counts, err := client.CheckPwnedBatch(context.Background(), passwords, 4)
+	if err != nil {
+		t.Fatalf("CheckPwnedBatch() error = %v", err)
+	}
+	if n := int(requests.Load()); n != len(prefixes) {
+		t.Errorf("CheckPwnedBatch() made %d requests, want one per unique prefix (%d)", n, len(prefixes))
+	}
+	if n := peak.Load(); n > 4 {
+		t.Errorf("CheckPwnedBatch() had %d requests in flight, want at most 4", n)
+	}
+	if len(counts) != len(passwords)-1 {
+		t.Errorf("CheckPwnedBatch() returned %d counts, want %d distinct passwords", len(counts), len(passwords)-1)
+	}
+	if n := counts[PwnedKey("password")]; n != 9545824 {
+		t.Errorf("count of password = %d, want 9545824", n)
+	}
+	if n, ok := counts[PwnedKey("bucket-543162")]; !ok || n != 0 {
+		t.Errorf("count of bucket-543162 = %d, %v, want 0 from the shared range", n, ok)
+	}
+	for key := range counts {
+		if len(key) != hashHexLen || strings.ToUpper(key) != key {
+			t.Errorf("CheckPwnedBatch() key %q, want an uppercase SHA-1 hash", key)
+		}
+	}
+}
+
+func TestPwnedClientCheckPwnedBatchPartial(t *testing.T) {
+	server, _, _ := newBatchPwnedServer(t, true)
+	client := &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	counts, err := client.CheckPwnedBatch(ctx, []string{"password", "Xq7#mB2vLp9!", "batch-1"}, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CheckPwnedBatch() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	want := map[string]int{PwnedKey("password"): 9545824}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("CheckPwnedBatch() = %v, want only the answered bucket %v", counts, want)
+	}
+}
+
+func TestPwnedClientCheckPwnedBatchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &PwnedClient{HTTPClient: server.Client(), BaseURL: server.URL + "/"}
+	counts, err := client.CheckPwnedBatch(context.Background(), []string{"password", "Xq7#mB2vLp9!"}, 2)
+	if err == nil || len(counts) != 0 {
+		t.Errorf("CheckPwnedBatch() = %v, %v, want no counts and an error", counts, err)
+	}
+	if err != nil && strings.Contains(err.Error(), "Xq7#mB2vLp9!") {
+		t.Errorf("CheckPwnedBatch() error = %q, want no plaintext", err)
+	}
+}
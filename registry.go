@@ -0,0 +1,113 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Registry holds a compiled Validator per named policy, such as one per tenant, so policies are
+// built once rather than on every request. It is safe for concurrent use, and the zero Registry is
+// empty and ready to use.
+type Registry struct {
+	mu       sync.RWMutex
+	policies map[string]*Validator
+}
+
+// Register compiles opts with NewValidator and stores it under name. It fails with
+// ErrPolicyExists when name is taken, and with the error from Options.Validate when opts can
+// never be satisfied. An empty PolicyName is set to name, so Result.Policy names the policy.
+func (r *Registry) Register(name string, opts Options) error {
+	v, err := compilePolicy(name, opts)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.policies[name]; ok {
+		return fmt.Errorf("%w: %q", ErrPolicyExists, name)
+	}
+	if r.policies == nil {
+		r.policies = make(map[string]*Validator)
+	}
+	r.policies[name] = v
+	return nil
+}
+
+// Update compiles opts and swaps it in for the policy registered under name, failing with
+// ErrUnknownPolicy when there is none. Audits already running finish against the Validator they
+// started with; later calls to Get and Audit see the new one. An invalid opts leaves the policy
+// unchanged.
+func (r *Registry) Update(name string, opts Options) error {
+	v, err := compilePolicy(name, opts)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.policies[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownPolicy, name)
+	}
+	r.policies[name] = v
+	return nil
+}
+
+// Get returns the Validator registered under name and whether there is one. The Validator stays
+// usable after an Update, auditing against the policy as it was.
+func (r *Registry) Get(name string) (*Validator, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.policies[name]
+	return v, ok
+}
+
+// Audit audits password against the policy registered under name, failing with ErrUnknownPolicy
+// when there is none. Whether the password passes is reported in the Result, as by
+// Validator.Audit.
+func (r *Registry) Audit(name, password string) (Result, error) {
+	v, ok := r.Get(name)
+	if !ok {
+		return Result{}, fmt.Errorf("%w: %q", ErrUnknownPolicy, name)
+	}
+	return v.Audit(password), nil
+}
+
+// Names returns the names of the registered policies, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.policies))
+	for name := range r.policies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// compilePolicy builds the Validator a Registry stores under name.
+func compilePolicy(name string, opts Options) (*Validator, error) {
+	if name == "" {
+		return nil, errors.New("policy name must not be empty")
+	}
+	if opts.PolicyName == "" {
+		opts.PolicyName = name
+	}
+	return NewValidator(opts)
+}
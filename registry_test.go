@@ -0,0 +1,132 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var r Registry
+	if err := r.Register("acme", Options{MinLength: 12}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("globex", Options{MinLength: 8, PolicyName: "Globex staff"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := r.Audit("acme", "Tr0ub4dor&3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(result.Err, ErrTooShort) || result.Policy != "acme" {
+		t.Errorf("Audit(acme) = %v for policy %q, want %v for policy %q", result.Err, result.Policy, ErrTooShort, "acme")
+	}
+	if result, err := r.Audit("globex", "Tr0ub4dor&3"); err != nil || result.Err != nil || result.Policy != "Globex staff" {
+		t.Errorf("Audit(globex) = %v, %v for policy %q, want a pass for policy %q", result.Err, err, result.Policy, "Globex staff")
+	}
+	if got, want := r.Names(), []string{"acme", "globex"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+
+	before, _ := r.Get("acme")
+	if err := r.Update("acme", Options{MinLength: 8}); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := r.Audit("acme", "Tr0ub4dor&3"); result.Err != nil {
+		t.Errorf("Audit(acme) after Update error = %v, want nil", result.Err)
+	}
+	if result := before.Audit("Tr0ub4dor&3"); !errors.Is(result.Err, ErrTooShort) {
+		t.Errorf("Validator from before Update error = %v, want the old policy's %v", result.Err, ErrTooShort)
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	var r Registry
+	if _, err := r.Audit("missing", "Tr0ub4dor&3"); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("Audit() of an unknown policy error = %v, want %v", err, ErrUnknownPolicy)
+	}
+	if _, ok := r.Get("missing"); ok {
+		t.Error("Get() of an unknown policy found one")
+	}
+	if err := r.Update("missing", Options{}); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("Update() of an unknown policy error = %v, want %v", err, ErrUnknownPolicy)
+	}
+
+	if err := r.Register("acme", Options{MinLength: 12}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("acme", Options{}); !errors.Is(err, ErrPolicyExists) {
+		t.Errorf("Register() of a taken name error = %v, want %v", err, ErrPolicyExists)
+	}
+	if err := r.Register("", Options{}); err == nil {
+		t.Error("Register() with an empty name succeeded")
+	}
+	invalid := Options{MinLength: 20, MaxLength: 10}
+	if err := r.Register("broken", invalid); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Register() of invalid options error = %v, want %v", err, ErrInvalidOptions)
+	}
+	if err := r.Update("acme", invalid); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Update() to invalid options error = %v, want %v", err, ErrInvalidOptions)
+	}
+	if v, _ := r.Get("acme"); v.Options().MinLength != 12 {
+		t.Errorf("failed Update() changed the policy to %+v", v.Options())
+	}
+}
+
+func TestRegistryConcurrentUpdate(t *testing.T) {
+	var r Registry
+	short := Options{MinLength: 8, PolicyName: "short"}
+	long := Options{MinLength: 12, PolicyName: "long"}
+	if err := r.Register("tenant", short); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				v, ok := r.Get("tenant")
+				if !ok {
+					t.Error("Get() lost the policy during an Update")
+					return
+				}
+				// Every audit runs wholly against one version of the policy.
+				result := v.Audit("Tr0ub4dor&3")
+				if tooShort := errors.Is(result.Err, ErrTooShort); tooShort != (result.Policy == "long") {
+					t.Errorf("Audit() against %q error = %v", result.Policy, result.Err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		opts := short
+		if i%2 == 0 {
+			opts = long
+		}
+		if err := r.Update("tenant", opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
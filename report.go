@@ -0,0 +1,314 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Entropy histogram layout of a ReportSummary: ReportEntropyBuckets buckets of
+// ReportEntropyBucketBits bits of EffectiveEntropy each, the last one open-ended.
+const (
+	ReportEntropyBucketBits = 10
+	ReportEntropyBuckets    = 13
+)
+
+// DefaultReportTopPatterns is how many pattern kinds a ReportSummary lists when Report.TopPatterns
+// is zero.
+const DefaultReportTopPatterns = 10
+
+// Report accumulates statistics over many audited passwords for reviewing a corpus such as a
+// credential dump. It keeps counts only, never passwords or pattern tokens, so its memory does
+// not grow with the corpus. Set Options.Report to have AuditAll and AuditReader add every Result.
+// A Report is safe for concurrent use; the zero value is ready to use.
+type Report struct {
+	TopPatterns int // Pattern kinds listed in the summary, DefaultReportTopPatterns when zero
+
+	mu         sync.Mutex
+	total      int
+	strong     int
+	complexity map[Complexity]int
+	ratings    [RatingStrong + 1]int
+	entropy    [ReportEntropyBuckets]int
+	failures   map[string]int    // Passwords failing each violation code
+	patterns   map[MatchKind]int // Passwords with each pattern kind
+	lengths    map[int64]int     // Passwords of each length, for an exact median
+}
+
+// Add records res.
+func (r *Report) Add(res Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.complexity == nil {
+		r.complexity = make(map[Complexity]int)
+		r.failures = make(map[string]int)
+		r.patterns = make(map[MatchKind]int)
+		r.lengths = make(map[int64]int)
+	}
+
+	r.total++
+	if res.Strong {
+		r.strong++
+	}
+	r.complexity[res.Complexity]++
+	if res.Rating >= RatingWeak && res.Rating <= RatingStrong {
+		r.ratings[res.Rating]++
+	}
+	bucket := int(math.Max(res.EffectiveEntropy, 0)) / ReportEntropyBucketBits
+	r.entropy[min(bucket, ReportEntropyBuckets-1)]++
+	r.lengths[res.Length]++
+
+	// Each password counts once per code and kind, however often it repeats them
+	for _, code := range violationCodes(res.Violations) {
+		r.failures[code]++
+	}
+	var kinds []MatchKind
+	for _, p := range res.Patterns {
+		if !slices.Contains(kinds, p.Kind) {
+			kinds = append(kinds, p.Kind)
+			r.patterns[p.Kind]++
+		}
+	}
+}
+
+// ReportSummary is a snapshot of a Report.
+type ReportSummary struct {
+	Total        int                // Passwords added
+	Strong       int                // Passwords that passed the policy
+	Complexity   map[Complexity]int // Passwords at each complexity level
+	Ratings      map[Rating]int     // Passwords in each rating
+	Entropy      []EntropyBucket    // Histogram of EffectiveEntropy, ReportEntropyBuckets long
+	Failures     []RuleFailure      // Violation codes by how many passwords failed them, most first
+	Patterns     []PatternCount     // The most common pattern kinds, most first
+	MinLength    int64
+	MedianLength int64 // The lower median for an even count
+	MaxLength    int64
+}
+
+// EntropyBucket counts the passwords whose EffectiveEntropy is at least MinBits and below MaxBits.
+// The last bucket has an infinite MaxBits.
+type EntropyBucket struct {
+	MinBits int
+	MaxBits float64
+	Count   int
+}
+
+// RuleFailure is how many passwords failed the requirement with violation code Code.
+type RuleFailure struct {
+	Code    string
+	Count   int
+	Percent float64 // Count as a percentage of ReportSummary.Total
+}
+
+// PatternCount is how many passwords contained at least one pattern of Kind.
+type PatternCount struct {
+	Kind  MatchKind
+	Count int
+}
+
+// Summary returns the statistics of the Results added so far.
+func (r *Report) Summary() ReportSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := ReportSummary{
+		Total:      r.total,
+		Strong:     r.strong,
+		Complexity: make(map[Complexity]int, len(r.complexity)),
+		Ratings:    make(map[Rating]int),
+		Entropy:    make([]EntropyBucket, ReportEntropyBuckets),
+	}
+	for c, n := range r.complexity {
+		s.Complexity[c] = n
+	}
+	for rating, n := range r.ratings {
+		if n > 0 {
+			s.Ratings[Rating(rating)] = n
+		}
+	}
+	for i, n := range r.entropy {
+		s.Entropy[i] = EntropyBucket{MinBits: i * ReportEntropyBucketBits, MaxBits: float64((i + 1) * ReportEntropyBucketBits), Count: n}
+	}
+	s.Entropy[ReportEntropyBuckets-1].MaxBits = math.Inf(1)
+
+	for code, n := range r.failures {
+		s.Failures = append(s.Failures, RuleFailure{Code: code, Count: n, Percent: 100 * float64(n) / float64(r.total)})
+	}
+	slices.SortFunc(s.Failures, func(a, b RuleFailure) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Code, b.Code))
+	})
+	for kind, n := range r.patterns {
+		s.Patterns = append(s.Patterns, PatternCount{Kind: kind, Count: n})
+	}
+	slices.SortFunc(s.Patterns, func(a, b PatternCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Kind, b.Kind))
+	})
+	top := r.TopPatterns
+	if top <= 0 {
+		top = DefaultReportTopPatterns
+	}
+	s.Patterns = s.Patterns[:min(top, len(s.Patterns))]
+
+	lengths := make([]int64, 0, len(r.lengths))
+	for length := range r.lengths {
+		lengths = append(lengths, length)
+	}
+	slices.Sort(lengths)
+	if len(lengths) > 0 {
+		s.MinLength, s.MaxLength = lengths[0], lengths[len(lengths)-1]
+		seen, middle := 0, (r.total+1)/2
+		for _, length := range lengths {
+			if seen += r.lengths[length]; seen >= middle {
+				s.MedianLength = length
+				break
+			}
+		}
+	}
+	return s
+}
+
+// reportJSON is the wire representation of a ReportSummary, with levels and ratings by name.
+type reportJSON struct {
+	Total        int                 `json:"total"`
+	Strong       int                 `json:"strong"`
+	Complexity   map[string]int      `json:"complexity"`
+	Ratings      map[string]int      `json:"ratings"`
+	Entropy      []entropyBucketJSON `json:"entropy"`
+	Failures     []ruleFailureJSON   `json:"failures"`
+	Patterns     []patternCountJSON  `json:"patterns"`
+	MinLength    int64               `json:"min_length"`
+	MedianLength int64               `json:"median_length"`
+	MaxLength    int64               `json:"max_length"`
+}
+
+type entropyBucketJSON struct {
+	MinBits int  `json:"min_bits"`
+	MaxBits *int `json:"max_bits"` // null for the open-ended bucket
+	Count   int  `json:"count"`
+}
+
+type ruleFailureJSON struct {
+	Code    string  `json:"code"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+type patternCountJSON struct {
+	Kind  MatchKind `json:"kind"`
+	Count int       `json:"count"`
+}
+
+// MarshalJSON encodes the summary with snake_case keys, complexity levels and ratings by name,
+// and a null max_bits for the open-ended entropy bucket.
+func (s ReportSummary) MarshalJSON() ([]byte, error) {
+	out := reportJSON{
+		Total:        s.Total,
+		Strong:       s.Strong,
+		Complexity:   make(map[string]int, len(s.Complexity)),
+		Ratings:      make(map[string]int, len(s.Ratings)),
+		Entropy:      make([]entropyBucketJSON, len(s.Entropy)),
+		Failures:     make([]ruleFailureJSON, len(s.Failures)),
+		Patterns:     make([]patternCountJSON, len(s.Patterns)),
+		MinLength:    s.MinLength,
+		MedianLength: s.MedianLength,
+		MaxLength:    s.MaxLength,
+	}
+	for c, n := range s.Complexity {
+		out.Complexity[c.snakeName()] = n
+	}
+	for rating, n := range s.Ratings {
+		out.Ratings[rating.String()] = n
+	}
+	for i, b := range s.Entropy {
+		out.Entropy[i] = entropyBucketJSON{MinBits: b.MinBits, Count: b.Count}
+		if !math.IsInf(b.MaxBits, 1) {
+			maxBits := int(b.MaxBits)
+			out.Entropy[i].MaxBits = &maxBits
+		}
+	}
+	for i, f := range s.Failures {
+		out.Failures[i] = ruleFailureJSON(f)
+	}
+	for i, p := range s.Patterns {
+		out.Patterns[i] = patternCountJSON(p)
+	}
+	return json.Marshal(out)
+}
+
+// WriteTo renders the summary as plain text for a terminal.
+func (s ReportSummary) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "passwords: %d, strong: %d (%.1f%%)\n", s.Total, s.Strong, percentOf(s.Strong, s.Total))
+	fmt.Fprintf(&b, "length: min %d, median %d, max %d\n", s.MinLength, s.MedianLength, s.MaxLength)
+
+	b.WriteString("ratings:\n")
+	for rating := RatingWeak; rating <= RatingStrong; rating++ {
+		fmt.Fprintf(&b, "  %-8s %8d  %5.1f%%\n", rating, s.Ratings[rating], percentOf(s.Ratings[rating], s.Total))
+	}
+
+	b.WriteString("complexity:\n")
+	levels := make([]Complexity, 0, len(s.Complexity))
+	for c := range s.Complexity {
+		levels = append(levels, c)
+	}
+	slices.SortFunc(levels, func(a, b Complexity) int { return cmp.Compare(ComplexityStrength(a), ComplexityStrength(b)) })
+	for _, c := range levels {
+		fmt.Fprintf(&b, "  %-22s %8d  %5.1f%%\n", c.snakeName(), s.Complexity[c], percentOf(s.Complexity[c], s.Total))
+	}
+
+	b.WriteString("effective entropy:\n")
+	peak := 1
+	for _, bucket := range s.Entropy {
+		peak = max(peak, bucket.Count)
+	}
+	for _, bucket := range s.Entropy {
+		label := fmt.Sprintf("%d-%d bits", bucket.MinBits, int(bucket.MaxBits)-1)
+		if math.IsInf(bucket.MaxBits, 1) {
+			label = fmt.Sprintf("%d+ bits", bucket.MinBits)
+		}
+		fmt.Fprintf(&b, "  %-12s %8d  %s\n", label, bucket.Count, strings.Repeat("#", bucket.Count*40/peak))
+	}
+
+	if len(s.Failures) > 0 {
+		b.WriteString("failing rules:\n")
+		for _, f := range s.Failures {
+			fmt.Fprintf(&b, "  %-22s %8d  %5.1f%%\n", f.Code, f.Count, f.Percent)
+		}
+	}
+	if len(s.Patterns) > 0 {
+		b.WriteString("top patterns:\n")
+		for _, p := range s.Patterns {
+			fmt.Fprintf(&b, "  %-22s %8d  %5.1f%%\n", p.Kind, p.Count, percentOf(p.Count, s.Total))
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// percentOf returns n as a percentage of total, zero when total is.
+func percentOf(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
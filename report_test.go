@@ -0,0 +1,197 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syntheticCorpus returns 10000 passwords in four equal groups: six digits, eight lowercase
+// letters, a word with a year and a symbol, and sixteen random characters of every class.
+func syntheticCorpus() []string {
+	rng := rand.New(rand.NewPCG(1, 2))
+	const lower, all = "abcdefghijklmnopqrstuvwxyz", "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*"
+	random := func(charset string, n int) string {
+		var b strings.Builder
+		for range n {
+			b.WriteByte(charset[rng.IntN(len(charset))])
+		}
+		return b.String()
+	}
+	corpus := make([]string, 0, 10000)
+	for i := range 2500 {
+		corpus = append(corpus,
+			fmt.Sprintf("%06d", i*397%1000000),
+			random(lower, 8),
+			fmt.Sprintf("Summer%d!", 1950+i%70),
+			"Qz7#"+random(all, 12))
+	}
+	return corpus
+}
+
+func TestReportCorpus(t *testing.T) {
+	report := &Report{TopPatterns: 3}
+	opts := Options{MinLength: 10, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true, Report: report}
+	if _, err := AuditAll(context.Background(), syntheticCorpus(), opts, 4); err != nil {
+		t.Fatal(err)
+	}
+	s := report.Summary()
+
+	if s.Total != 10000 || s.Strong != 5000 {
+		t.Errorf("Total = %d, Strong = %d, want 10000 and 5000", s.Total, s.Strong)
+	}
+	if s.MinLength != 6 || s.MedianLength != 8 || s.MaxLength != 16 {
+		t.Errorf("lengths = %d/%d/%d, want 6/8/16", s.MinLength, s.MedianLength, s.MaxLength)
+	}
+	if s.Complexity[PwComplexityDigitsOnly] != 2500 || s.Complexity[PwComplexityLowerOnly] != 2500 {
+		t.Errorf("Complexity = %v, want 2500 digits-only and 2500 lower-only", s.Complexity)
+	}
+	failures := make(map[string]float64)
+	for _, f := range s.Failures {
+		failures[f.Code] = f.Percent
+	}
+	for code, want := range map[string]float64{CodeTooShort: 50, CodeMissingUpper: 50, CodeMissingSymbol: 50, CodeMissingDigit: 25, CodeMissingLower: 25} {
+		if failures[code] != want {
+			t.Errorf("%s fails %.1f%% of passwords, want %.1f%%", code, failures[code], want)
+		}
+	}
+	for i := 1; i < len(s.Failures); i++ {
+		if s.Failures[i].Count > s.Failures[i-1].Count {
+			t.Errorf("Failures not sorted by count: %v", s.Failures)
+		}
+	}
+
+	var bucketed, ratings int
+	for _, b := range s.Entropy {
+		bucketed += b.Count
+	}
+	for _, n := range s.Ratings {
+		ratings += n
+	}
+	if len(s.Entropy) != ReportEntropyBuckets || bucketed != 10000 || ratings != 10000 {
+		t.Errorf("%d entropy buckets holding %d, ratings holding %d, want %d buckets and 10000 in each", len(s.Entropy), bucketed, ratings, ReportEntropyBuckets)
+	}
+	if !math.IsInf(s.Entropy[ReportEntropyBuckets-1].MaxBits, 1) {
+		t.Errorf("last entropy bucket ends at %v, want +Inf", s.Entropy[ReportEntropyBuckets-1].MaxBits)
+	}
+	// Sixteen characters from the 95-rune printable pool land at about 105 bits, the
+	// Summer passwords near 55, the letters near 38, and digits in the bottom two buckets
+	for bucket, want := range map[int]int{3: 2500, 5: 2500, 10: 2500} {
+		if n := s.Entropy[bucket].Count; n != want {
+			t.Errorf("entropy bucket %d holds %d passwords, want %d", bucket, n, want)
+		}
+	}
+	if n := s.Entropy[0].Count + s.Entropy[1].Count; n != 2500 {
+		t.Errorf("entropy below 20 bits holds %d passwords, want the 2500 digit runs", n)
+	}
+
+	if len(s.Patterns) == 0 || len(s.Patterns) > 3 {
+		t.Fatalf("Patterns = %v, want 1 to 3 kinds", s.Patterns)
+	}
+	years := 0
+	for _, p := range s.Patterns {
+		if p.Kind == PatternYear {
+			years = p.Count
+		}
+	}
+	if years < 2500 {
+		t.Errorf("Patterns = %v, want the year of every Summer password counted", s.Patterns)
+	}
+}
+
+func TestReportKeepsNoPasswords(t *testing.T) {
+	report := &Report{}
+	opts := Options{MinLength: 8, UserInputs: []string{"alice@example.com"}, Report: report}
+	if err := AuditReader(context.Background(), strings.NewReader("alice@example.com1\nCorrect-Horse-9\n"), opts, nil); err != nil {
+		t.Fatal(err)
+	}
+	s := report.Summary()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text bytes.Buffer
+	if _, err := s.WriteTo(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range []string{string(data), text.String(), fmt.Sprintf("%+v", report)} {
+		if strings.Contains(out, "alice") || strings.Contains(out, "Horse") {
+			t.Errorf("report output contains a password: %s", out)
+		}
+	}
+	if s.Total != 2 || s.Failures[0].Code != CodeUserInput {
+		t.Errorf("Summary() = %d passwords, failures %v", s.Total, s.Failures)
+	}
+}
+
+func TestReportSummaryJSON(t *testing.T) {
+	var report Report
+	report.Add(Audit("123456", Options{MinLength: 8}))
+	report.Add(Audit("Tr0ub4dor&3xyz", Options{MinLength: 8}))
+	data, err := json.Marshal(report.Summary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["total"] != 2.0 || got["median_length"] != 6.0 || got["max_length"] != 14.0 {
+		t.Errorf("MarshalJSON() = %s", data)
+	}
+	if complexity := got["complexity"].(map[string]any); complexity["digits_only"] != 1.0 {
+		t.Errorf("complexity = %v, want digits_only counted by name", complexity)
+	}
+	entropy := got["entropy"].([]any)
+	if last := entropy[len(entropy)-1].(map[string]any); last["max_bits"] != nil || last["min_bits"] != 120.0 {
+		t.Errorf("last entropy bucket = %v, want min_bits 120 and a null max_bits", last)
+	}
+	failures := got["failures"].([]any)
+	if f := failures[0].(map[string]any); f["code"] != CodeTooShort || f["percent"] != 50.0 {
+		t.Errorf("failures = %v, want too_short at 50%%", failures)
+	}
+}
+
+func TestReportConcurrentAdd(t *testing.T) {
+	var report Report
+	result := Audit("password", Options{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				report.Add(result)
+			}
+		}()
+	}
+	wg.Wait()
+	if s := report.Summary(); s.Total != 800 || s.MedianLength != 8 {
+		t.Errorf("Summary() = %d passwords, median %d, want 800 and 8", s.Total, s.MedianLength)
+	}
+	if s := new(Report).Summary(); s.Total != 0 || len(s.Entropy) != ReportEntropyBuckets {
+		t.Errorf("empty Summary() = %+v", s)
+	}
+}
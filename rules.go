@@ -0,0 +1,137 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// MaxPatternBytes caps how much of a password RequiredPatterns are matched against. RE2 runs in
+// time linear in its input, so the cap bounds their cost. ForbiddenPatterns see the whole
+// password, so a forbidden match cannot hide past the cap.
+const MaxPatternBytes = 1024
+
+// Rule is a custom requirement checked after the built-in ones. Check returns nil when the
+// password passes, an error to fail it, or an error built with Warn to report a concern without
+// failing it.
+type Rule interface {
+	Name() string
+	Check(password string) error
+}
+
+// NewRule adapts an ordinary function to the Rule interface.
+func NewRule(name string, check func(password string) error) Rule {
+	return funcRule{name: name, check: check}
+}
+
+type funcRule struct {
+	name  string
+	check func(password string) error
+}
+
+func (r funcRule) Name() string { return r.name }
+
+func (r funcRule) Check(password string) error { return r.check(password) }
+
+// Warning marks a Rule failure as advisory: Audit records it in Result.Warnings and the password
+// still passes. Detect it with errors.As.
+type Warning struct {
+	Err error
+}
+
+func (w *Warning) Error() string { return "warning: " + w.Err.Error() }
+
+func (w *Warning) Unwrap() error { return w.Err }
+
+// Warn wraps err in a *Warning, or returns nil when err is nil.
+func Warn(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Warning{Err: err}
+}
+
+// RuleError tags the error a Rule returned with the rule's name.
+type RuleError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleError) Error() string { return fmt.Sprintf("rule %s: %v", e.Rule, e.Err) }
+
+func (e *RuleError) Unwrap() error { return e.Err }
+
+// checkRule runs rule against pass, converting a panic into an error wrapping ErrRulePanicked.
+func checkRule(rule Rule, pass string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrRulePanicked, r)
+		}
+	}()
+	return rule.Check(pass)
+}
+
+// runRules appends the failures of opts.CustomRules to audit, stopping at the first with FailFast.
+func runRules(audit *Result, opts *Options, pass string) {
+	for _, rule := range opts.CustomRules {
+		err := checkRule(rule, pass)
+		if err == nil {
+			continue
+		}
+		name := rule.Name()
+		err = validationError(CodeCustomRule, "CustomRules", &RuleError{Rule: name, Err: err}, "rule", name)
+		var warning *Warning
+		if errors.As(err, &warning) {
+			audit.Warnings = append(audit.Warnings, err)
+			continue
+		}
+		if audit.violate(err, opts.FailFast) {
+			return
+		}
+	}
+}
+
+// matchPatterns checks the first MaxPatternBytes of pass against the compiled RequiredPatterns and
+// all of it against the ForbiddenPatterns, stopping at the first failure with FailFast.
+func (v *Validator) matchPatterns(audit *Result, pass string) {
+	head := pass
+	if len(head) > MaxPatternBytes {
+		cut := MaxPatternBytes
+		for cut > 0 && !utf8.RuneStart(head[cut]) {
+			cut--
+		}
+		head = head[:cut]
+	}
+	for _, re := range v.required {
+		if !re.MatchString(head) {
+			if audit.violate(validationError(CodeMissingPattern, "RequiredPatterns", fmt.Errorf("%w: %s", ErrMissingPattern, re),
+				"pattern", re.String()), v.opts.FailFast) {
+				return
+			}
+		}
+	}
+	for _, re := range v.forbidden {
+		if re.MatchString(pass) {
+			if audit.violate(validationError(CodeForbiddenPattern, "ForbiddenPatterns", fmt.Errorf("%w: %s", ErrForbiddenPattern, re),
+				"pattern", re.String()), v.opts.FailFast) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errQuarter = errors.New("contains the current quarter")
+
+// quarterRule fails passwords that contain "q3".
+var quarterRule = NewRule("quarter", func(password string) error {
+	if strings.Contains(strings.ToLower(password), "q3") {
+		return errQuarter
+	}
+	return nil
+})
+
+func TestAuditCustomRules(t *testing.T) {
+	errShort := errors.New("shorter than 16 characters")
+	advisory := NewRule("length-advice", func(password string) error {
+		if len(password) < 16 {
+			return Warn(errShort)
+		}
+		return nil
+	})
+	panicky := NewRule("panicky", func(string) error { panic("boom") })
+
+	tests := []struct {
+		name       string
+		password   string
+		rules      []Rule
+		violations []error
+		warnings   []error
+	}{
+		{"No rules", "Acme-Q3-2026!", nil, nil, nil},
+		{"Passing rule", "Xq7mB2vLp9!", []Rule{quarterRule}, nil, nil},
+		{"Failing rule", "Acme-Q3-2026!", []Rule{quarterRule}, []error{errQuarter}, nil},
+		{"Warning only", "Xq7mB2vLp9!", []Rule{advisory}, nil, []error{errShort}},
+		{"Failure and warning", "Acme-Q3-2026!", []Rule{quarterRule, advisory}, []error{errQuarter}, []error{errShort}},
+		{"Panic", "Xq7mB2vLp9!", []Rule{panicky}, []error{ErrRulePanicked}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, Options{CustomRules: tt.rules})
+			if len(result.Violations) != len(tt.violations) {
+				t.Fatalf("Audit() violations = %v, want %v", result.Violations, tt.violations)
+			}
+			for i, want := range tt.violations {
+				var ruleErr *RuleError
+				if !errors.Is(result.Violations[i], want) || !errors.As(result.Violations[i], &ruleErr) {
+					t.Errorf("Audit() violations[%d] = %v, want a *RuleError wrapping %v", i, result.Violations[i], want)
+				}
+			}
+			if len(result.Warnings) != len(tt.warnings) {
+				t.Fatalf("Audit() warnings = %v, want %v", result.Warnings, tt.warnings)
+			}
+			for i, want := range tt.warnings {
+				var warning *Warning
+				if !errors.Is(result.Warnings[i], want) || !errors.As(result.Warnings[i], &warning) {
+					t.Errorf("Audit() warnings[%d] = %v, want a *Warning wrapping %v", i, result.Warnings[i], want)
+				}
+			}
+			if passed := result.Err == nil; passed != (len(tt.violations) == 0) {
+				t.Errorf("Audit() error = %v, want failure %v", result.Err, len(tt.violations) > 0)
+			}
+		})
+	}
+}
+
+func TestRuleErrorMessage(t *testing.T) {
+	result := Audit("Acme-Q3-2026!", Options{CustomRules: []Rule{quarterRule}})
+	if want := "rule quarter: contains the current quarter"; result.Err == nil || result.Err.Error() != want {
+		t.Errorf("Audit() error = %v, want %q", result.Err, want)
+	}
+
+	advisory := NewRule("advice", func(string) error { return Warn(errQuarter) })
+	result = Audit("Acme-Q3-2026!", Options{CustomRules: []Rule{advisory}})
+	if want := "rule advice: warning: contains the current quarter"; len(result.Warnings) != 1 || result.Warnings[0].Error() != want {
+		t.Errorf("Audit() warnings = %v, want %q", result.Warnings, want)
+	}
+}
+
+func TestCustomRulesRunAfterBuiltins(t *testing.T) {
+	var calls int
+	counting := NewRule("counting", func(string) error { calls++; return errQuarter })
+
+	result := Audit("short", Options{MinLength: 8, CustomRules: []Rule{counting}})
+	if len(result.Violations) != 2 || !errors.Is(result.Violations[0], ErrTooShort) || !errors.Is(result.Violations[1], errQuarter) {
+		t.Errorf("Audit() violations = %v, want the length failure before the rule", result.Violations)
+	}
+
+	calls = 0
+	Audit("short", Options{MinLength: 8, FailFast: true, CustomRules: []Rule{counting}})
+	if calls != 0 {
+		t.Errorf("rule called %d times after FailFast stopped the audit, want 0", calls)
+	}
+	Audit("Xq7mB2vLp9!", Options{FailFast: true, CustomRules: []Rule{counting, counting}})
+	if calls != 1 {
+		t.Errorf("rule called %d times with FailFast, want 1", calls)
+	}
+}
+
+func TestWarn(t *testing.T) {
+	if err := Warn(nil); err != nil {
+		t.Errorf("Warn(nil) = %v, want nil", err)
+	}
+}
+
+func TestCustomRulesOptions(t *testing.T) {
+	if err := (Options{CustomRules: []Rule{nil}}).Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrInvalidOptions)
+	}
+	if _, err := New(WithRules(quarterRule, nil)); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("New() error = %v, want %v", err, ErrInvalidOptions)
+	}
+
+	base := Options{CustomRules: make([]Rule, 1, 4)}
+	base.CustomRules[0] = quarterRule
+	v, err := New(WithOptions(base), WithRules(quarterRule))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if n := len(v.Options().CustomRules); n != 2 {
+		t.Errorf("New() CustomRules has %d rules, want 2", n)
+	}
+	if base.CustomRules[:2][1] != nil {
+		t.Errorf("WithRules() wrote into the backing array of the base Options")
+	}
+}
+
+func TestAuditPatterns(t *testing.T) {
+	options := Options{
+		RequiredPatterns:  []string{`^[A-Za-z]`, `[0-9]$`},
+		ForbiddenPatterns: []string{`(?i)acme`, `(19|20)[0-9]{2}`},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []error
+	}{
+		{"Matches every rule", "Xq7mB-vLp9", nil},
+		{"Misses a required pattern", "7mB-vLp9", []error{ErrMissingPattern}},
+		{"Misses both required patterns", "-mBvLp", []error{ErrMissingPattern, ErrMissingPattern}},
+		{"Matches a forbidden pattern", "AcmeRocks9", []error{ErrForbiddenPattern}},
+		{"Mixed", "ACME-2026-x", []error{ErrMissingPattern, ErrForbiddenPattern, ErrForbiddenPattern}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, options)
+			if len(result.Violations) != len(tt.want) {
+				t.Fatalf("Audit() violations = %v, want %v", result.Violations, tt.want)
+			}
+			for i, want := range tt.want {
+				if !errors.Is(result.Violations[i], want) {
+					t.Errorf("Audit() violations[%d] = %v, want %v", i, result.Violations[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditPatternErrorDetail(t *testing.T) {
+	result := Audit("Acme9", Options{ForbiddenPatterns: []string{`(?i)acme`}})
+	if want := "password matches a forbidden pattern: (?i)acme"; result.Err == nil || result.Err.Error() != want {
+		t.Errorf("Audit() error = %v, want %q", result.Err, want)
+	}
+
+	result = Audit("Acme9", Options{RequiredPatterns: []string{`[a-z`}})
+	if !errors.Is(result.Err, ErrInvalidOptions) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrInvalidOptions)
+	}
+	if _, err := New(WithOptions(Options{ForbiddenPatterns: []string{`(`}})); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("New() error = %v, want %v", err, ErrInvalidOptions)
+	}
+}
+
+func TestAuditPatternLengthCap(t *testing.T) {
+	options := Options{ForbiddenPatterns: []string{`secret`}}
+
+	// Forbidden patterns see the whole password, so padding cannot push a match past the cap.
+	long := strings.Repeat("é", MaxPatternBytes) + "secret"
+	if result := Audit(long, options); !errors.Is(result.Err, ErrForbiddenPattern) {
+		t.Errorf("Audit() error = %v, want %v beyond the cap", result.Err, ErrForbiddenPattern)
+	}
+	if result := Audit("secret"+long, options); !errors.Is(result.Err, ErrForbiddenPattern) {
+		t.Errorf("Audit() error = %v, want %v", result.Err, ErrForbiddenPattern)
+	}
+
+	// Required patterns only see the first MaxPatternBytes, cut on a rune boundary.
+	if result := Audit(long, Options{RequiredPatterns: []string{`secret`}}); !errors.Is(result.Err, ErrMissingPattern) {
+		t.Errorf("Audit() error = %v, want the required pattern beyond the cap unmatched", result.Err)
+	}
+
+	// The cap falls inside an é; cutting there would leave an invalid byte the pattern rejects.
+	odd := "a" + strings.Repeat("é", MaxPatternBytes)
+	if result := Audit(odd, Options{RequiredPatterns: []string{`^aé+$`}}); result.Err != nil {
+		t.Errorf("Audit() error = %v, want the input cut on a rune boundary", result.Err)
+	}
+}
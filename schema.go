@@ -0,0 +1,197 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDialect is the JSON Schema draft JSONSchema writes.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// passwordSchema is the document JSONSchema writes, in a fixed key order.
+type passwordSchema struct {
+	Schema      string          `json:"$schema"`
+	Title       string          `json:"title,omitempty"`
+	Type        string          `json:"type"`
+	MinLength   uint            `json:"minLength,omitempty"`
+	MaxLength   uint            `json:"maxLength,omitempty"`
+	AllOf       []schemaPattern `json:"allOf,omitempty"`
+	Not         *schemaAnyOf    `json:"not,omitempty"`
+	Version     string          `json:"x-policy-version"`
+	Forbidden   []string        `json:"x-forbidden-substrings,omitempty"`
+	Description []string        `json:"x-description"`
+}
+
+// schemaPattern is a subschema matching strings containing a regular expression, or, for the
+// class combinations of MinClasses, matching any or all of its subschemas.
+type schemaPattern struct {
+	Pattern string          `json:"pattern,omitempty"`
+	AllOf   []schemaPattern `json:"allOf,omitempty"`
+	AnyOf   []schemaPattern `json:"anyOf,omitempty"`
+}
+
+// schemaAnyOf is a subschema matching strings that match any of its patterns.
+type schemaAnyOf struct {
+	AnyOf []schemaPattern `json:"anyOf"`
+}
+
+// JSONSchema returns the policy as a JSON Schema for a string, so a browser form can check a
+// password against the same policy before sending it. minLength and maxLength carry the length
+// bounds, "allOf" one pattern per required character class and RequiredPatterns, or under
+// MinClasses an "anyOf" over every combination of that many enabled classes, and "not" the
+// ForbiddenPatterns and the characters outside AllowedChars or inside DisallowedChars.
+// "x-forbidden-substrings" lists the lowercased UserInputs a password must not contain, ignoring
+// case, and "x-description" is Describe, which also covers the checks a schema cannot express,
+// such as dictionaries and breach checks; the server stays the final judge.
+//
+// "x-policy-version" is a SHA-256 of the policy fields MarshalJSON encodes and the description,
+// so any change to an enforced rule changes it and a form can tell when its cached copy is stale.
+// Patterns are written for the "u" flag of ECMAScript regular expressions; RequiredPatterns and
+// ForbiddenPatterns are copied as written, so use the RE2 syntax both engines share.
+func (opts Options) JSONSchema() ([]byte, error) {
+	policy, err := opts.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	schema := passwordSchema{
+		Schema:      jsonSchemaDialect,
+		Title:       opts.PolicyName,
+		Type:        "string",
+		MinLength:   opts.MinLength,
+		MaxLength:   opts.MaxLength,
+		Description: opts.Describe(),
+	}
+	if schema.Description == nil {
+		schema.Description = []string{}
+	}
+
+	lower, upper, digits := "[a-z]", "[A-Z]", "[0-9]"
+	if opts.UnicodeClasses {
+		lower, upper, digits = `\p{Ll}`, `\p{Lu}`, `\p{Nd}`
+	}
+	classes := []struct {
+		pattern string
+		min     uint
+	}{
+		{digits, minimumCount(opts.UseDigits, opts.MinDigits)},
+		{lower, minimumCount(opts.UseLower, opts.MinLower)},
+		{upper, minimumCount(opts.UseUpper, opts.MinUpper)},
+		{"[" + classEscape(symbolSetOf(&opts)) + "]", minimumCount(opts.UseSymbols, opts.MinSymbols)},
+		{`[^\x00-\x7F\P{L}]`, minimumCount(opts.UseExtended, opts.MinExtended)}, // Letters beyond ASCII
+		{`[^\x00-\x7F\p{L}\p{N}\s]`, minimumCount(opts.UseEmoji, 0)},            // Emoji and symbols beyond ASCII
+	}
+	var required []string
+	for _, class := range classes {
+		if class.min > 0 {
+			required = append(required, atLeast(class.pattern, class.min))
+		}
+	}
+	if opts.MinClasses > 0 && len(required) == 0 {
+		for _, class := range classes[:defaultClassCount] {
+			required = append(required, class.pattern)
+		}
+	}
+	if k := int(opts.MinClasses); k > 0 && k < len(required) {
+		schema.AllOf = append(schema.AllOf, schemaPattern{AnyOf: classCombinations(required, k)})
+	} else {
+		for _, pattern := range required {
+			schema.AllOf = append(schema.AllOf, schemaPattern{Pattern: pattern})
+		}
+	}
+	for _, pattern := range opts.RequiredPatterns {
+		schema.AllOf = append(schema.AllOf, schemaPattern{Pattern: pattern})
+	}
+
+	var forbidden []schemaPattern
+	for _, pattern := range opts.ForbiddenPatterns {
+		forbidden = append(forbidden, schemaPattern{Pattern: pattern})
+	}
+	if opts.AllowedChars != "" {
+		forbidden = append(forbidden, schemaPattern{Pattern: "[^" + classEscape(opts.AllowedChars) + "]"})
+	}
+	if opts.DisallowedChars != "" {
+		forbidden = append(forbidden, schemaPattern{Pattern: "[" + classEscape(opts.DisallowedChars) + "]"})
+	}
+	if len(forbidden) > 0 {
+		schema.Not = &schemaAnyOf{AnyOf: forbidden}
+	}
+	for _, input := range opts.UserInputs {
+		schema.Forbidden = append(schema.Forbidden, userInputNeedles(input)...)
+	}
+
+	hash := sha256.New()
+	hash.Write(policy)
+	for _, line := range schema.Description {
+		hash.Write([]byte("\n" + line))
+	}
+	schema.Version = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false) // Keep "<" and "&" readable in the patterns
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// classCombinations returns a subschema for every way of picking k of patterns, each matching
+// strings that contain all k.
+func classCombinations(patterns []string, k int) []schemaPattern {
+	var combinations []schemaPattern
+	var pick func(start int, chosen []schemaPattern)
+	pick = func(start int, chosen []schemaPattern) {
+		if len(chosen) == k {
+			combinations = append(combinations, schemaPattern{AllOf: slices.Clone(chosen)})
+			return
+		}
+		for i := start; i <= len(patterns)-k+len(chosen); i++ {
+			pick(i+1, append(chosen, schemaPattern{Pattern: patterns[i]}))
+		}
+	}
+	pick(0, nil)
+	return combinations
+}
+
+// atLeast returns a pattern matching strings with min or more runes matching class.
+func atLeast(class string, min uint) string {
+	if min == 1 {
+		return class
+	}
+	// Any runes, newlines included, may sit between the matches.
+	return `(?:[\s\S]*?` + class + "){" + strconv.FormatUint(uint64(min), 10) + "}"
+}
+
+// classEscape escapes the runes of chars that are special inside a bracketed character class.
+func classEscape(chars string) string {
+	var b strings.Builder
+	for _, r := range chars {
+		if strings.ContainsRune(`\]^-[`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,191 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestJSONSchemaGolden(t *testing.T) {
+	options := Options{
+		PolicyName:        "signup",
+		MinLength:         12,
+		MaxLength:         64,
+		UseDigits:         true,
+		MinUpper:          2,
+		UseSymbols:        true,
+		UseExtended:       true,
+		ForbiddenPatterns: []string{"acme"},
+		DisallowedChars:   `\"]`,
+		UserInputs:        []string{"jsmith@example.com"},
+		RejectCommon:      true,
+	}
+	got, err := options.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "schema_signup.golden", got)
+}
+
+func TestJSONSchemaPatterns(t *testing.T) {
+	options := Options{MinDigits: 2, UseLower: true, UseSymbols: true, UseExtended: true, UseEmoji: true, AllowedChars: "abcé12!🎉"}
+	data, err := options.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema passwordSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.AllOf) != 5 || schema.Not == nil || len(schema.Not.AnyOf) != 1 {
+		t.Fatalf("JSONSchema() = %s, want five required patterns and one forbidden", data)
+	}
+
+	// Each password below satisfies every pattern but the one at its index.
+	failing := []string{"ab1!é🎉", "12!é🎉", "ab12é🎉", "ab12!🎉", "ab12!é"}
+	for i, p := range schema.AllOf {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			t.Fatalf("pattern %q: %v", p.Pattern, err)
+		}
+		if !re.MatchString("ab12!é🎉") {
+			t.Errorf("pattern %q does not match a compliant password", p.Pattern)
+		}
+		if re.MatchString(failing[i]) {
+			t.Errorf("pattern %q matches %q", p.Pattern, failing[i])
+		}
+	}
+	forbidden := regexp.MustCompile(schema.Not.AnyOf[0].Pattern)
+	if forbidden.MatchString("ab12!é🎉") || !forbidden.MatchString("abd") {
+		t.Errorf("forbidden pattern %q does not match exactly the runes outside AllowedChars", forbidden)
+	}
+}
+
+func TestJSONSchemaMinClasses(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		accepted []string
+		rejected []string
+	}{
+		{"Three of four", Options{UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true, MinClasses: 3},
+			[]string{"abcdefG12x", "ABC!12", "abc!DEF", "a1B!"}, []string{"abcdefghij", "abc123", "ABC!!!"}},
+		{"Default classes", Options{MinClasses: 2}, []string{"abc1", "ABC!"}, []string{"abc", "1234"}},
+		{"All enabled", Options{UseDigits: true, UseLower: true, MinClasses: 2}, []string{"abc1"}, []string{"abc", "123"}},
+		{"Counted minimums", Options{MinDigits: 2, UseLower: true, UseUpper: true, MinClasses: 2}, []string{"ab12", "aB"}, []string{"ab1", "AB1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.options.JSONSchema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var schema passwordSchema
+			if err := json.Unmarshal(data, &schema); err != nil {
+				t.Fatal(err)
+			}
+			top := schemaPattern{AllOf: schema.AllOf}
+			for _, pass := range tt.accepted {
+				if !schemaMatches(t, top, pass) {
+					t.Errorf("JSONSchema() = %s, rejects %q", data, pass)
+				}
+				if res := Audit(pass, tt.options); res.Err != nil {
+					t.Errorf("Audit(%q) error = %v, want the server to agree with the schema", pass, res.Err)
+				}
+			}
+			for _, pass := range tt.rejected {
+				if schemaMatches(t, top, pass) {
+					t.Errorf("JSONSchema() = %s, accepts %q", data, pass)
+				}
+				if res := Audit(pass, tt.options); res.Err == nil {
+					t.Errorf("Audit(%q) error = nil, want the server to agree with the schema", pass)
+				}
+			}
+		})
+	}
+}
+
+// schemaMatches reports whether pass satisfies the pattern, allOf, and anyOf of s.
+func schemaMatches(t *testing.T, s schemaPattern, pass string) bool {
+	t.Helper()
+	if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(pass) {
+		return false
+	}
+	for _, sub := range s.AllOf {
+		if !schemaMatches(t, sub, pass) {
+			return false
+		}
+	}
+	if len(s.AnyOf) == 0 {
+		return true
+	}
+	for _, sub := range s.AnyOf {
+		if schemaMatches(t, sub, pass) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaExempt lists the Options fields that are not part of the policy a JSONSchema describes.
+var schemaExempt = map[string]string{
+	"Report":      "collects statistics",
+	"Metrics":     "exports counters",
+	"Logger":      "logs outcomes",
+	"LogLevel":    "logs outcomes",
+	"MarkovModel": "a model only the server can evaluate",
+	"Matchers":    "matchers only the server can run",
+}
+
+func TestJSONSchemaChangesWithEveryOption(t *testing.T) {
+	values := optionTestValues()
+	values[reflect.TypeOf(Complexity(0))] = reflect.ValueOf(PwComplexitySymbolsDigits)
+	values[reflect.TypeOf(time.Duration(0))] = reflect.ValueOf(time.Second)
+	values[reflect.TypeOf(BreachCheckMode(0))] = reflect.ValueOf(BreachAdvisory)
+	values[reflect.TypeOf(Form(0))] = reflect.ValueOf(NormalizeNFC)
+	base, err := Options{}.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := reflect.TypeOf(Options{})
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		t.Run(field.Name, func(t *testing.T) {
+			if reason, ok := schemaExempt[field.Name]; ok {
+				t.Skip(reason)
+			}
+			value, ok := values[field.Type]
+			if !ok {
+				t.Fatalf("no test value for %s of type %s; add one or exempt the field", field.Name, field.Type)
+			}
+			var set Options
+			reflect.ValueOf(&set).Elem().Field(i).Set(value)
+			got, err := set.JSONSchema()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.DeepEqual(got, base) {
+				t.Errorf("JSONSchema() does not change with %s", field.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Rating is a qualitative bucket for a Score.
+type Rating int
+
+const (
+	RatingWeak   Rating = iota // Score 0-39
+	RatingFair                 // Score 40-59
+	RatingGood                 // Score 60-79
+	RatingStrong               // Score 80-100
+)
+
+var ratingNames = [...]string{"weak", "fair", "good", "strong"}
+
+// String returns the lowercase name of the rating.
+func (r Rating) String() string {
+	if r < RatingWeak || r > RatingStrong {
+		return "unknown"
+	}
+	return ratingNames[r]
+}
+
+// parseRating is the inverse of Rating.String.
+func parseRating(name string) Rating {
+	for i, n := range ratingNames {
+		if n == name {
+			return Rating(i)
+		}
+	}
+	return RatingWeak
+}
+
+// RatingOf returns the bucket a score falls into.
+func RatingOf(score int) Rating {
+	switch {
+	case score >= 80:
+		return RatingStrong
+	case score >= 60:
+		return RatingGood
+	case score >= 40:
+		return RatingFair
+	default:
+		return RatingWeak
+	}
+}
+
+// Score weights. The mapping is stable; changing it changes every pinned score.
+const (
+	scoreEntropyPoints   = 60  // Awarded linearly up to scoreEntropyCeiling bits
+	scoreEntropyCeiling  = 100 // Bits of entropy that earn every entropy point
+	scoreLengthPoints    = 20  // One point per rune up to 20 runes
+	scoreDiversityPoints = 20  // Five points per character class, capped
+	scoreCompromisedCap  = 10  // Maximum score of a password found in a dictionary or breach
+)
+
+// scoreOf combines entropy, length and class diversity into a 0-100 score:
+//
+//	entropy:   60 * min(entropy, 100) / 100
+//	length:    min(length, 20)
+//	diversity: min(5 * classes present, 20)
+//
+// A password found in a dictionary or breach source is capped at 10.
+func scoreOf(entropy float64, length int64, classes Class, compromised bool) int {
+	points := scoreEntropyPoints * math.Min(math.Max(entropy, 0), scoreEntropyCeiling) / scoreEntropyCeiling
+	points += math.Min(float64(length), scoreLengthPoints)
+	points += math.Min(float64(5*bits.OnesCount8(uint8(classes))), scoreDiversityPoints)
+
+	score := int(math.Round(points))
+	if compromised && score > scoreCompromisedCap {
+		score = scoreCompromisedCap
+	}
+	return score
+}
+
+// ScoreOf returns the 0-100 score of pass without enforcing any policy. The embedded common
+// password list is consulted so well-known passwords score low.
+func ScoreOf(pass string) int {
+	return Audit(pass, Options{RejectCommon: true}).Score
+}
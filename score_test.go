@@ -0,0 +1,99 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"testing"
+)
+
+func TestScoreOf(t *testing.T) {
+	// Pinned so accidental scoring changes are noticed.
+	tests := []struct {
+		password string
+		score    int
+		rating   Rating
+	}{
+		{"", 0, RatingWeak},
+		{"password", 10, RatingWeak},
+		{"Password123!", 10, RatingWeak},
+		{"aaaaaaaa", 10, RatingWeak},
+		{"12345678", 10, RatingWeak},
+		{"qwerty", 10, RatingWeak},
+		{"🔒🔑🚀🚀", 34, RatingWeak},
+		{"73920584617309256184", 65, RatingGood},
+		{"Øversættelse", 69, RatingGood},
+		{"Tr0ub4dor&3", 74, RatingGood},
+		{"Xq7#mB2vLp9!", 79, RatingGood},
+		{"correcthorsebatterystaple", 85, RatingStrong},
+		{"kV9$wQ2!zR7@mN4#pL8^", 100, RatingStrong},
+	}
+
+	for _, tt := range tests {
+		if got := ScoreOf(tt.password); got != tt.score {
+			t.Errorf("ScoreOf(%q) = %d, want %d", tt.password, got, tt.score)
+		}
+		if got := RatingOf(tt.score); got != tt.rating {
+			t.Errorf("RatingOf(%d) = %v, want %v", tt.score, got, tt.rating)
+		}
+	}
+}
+
+func TestScoreIgnoresPolicy(t *testing.T) {
+	result := Audit("Xq7#mB2vLp9!", Options{MinLength: 20})
+	if result.Err == nil {
+		t.Fatalf("Audit() error = nil, want the policy to fail")
+	}
+	if result.Score != ScoreOf("Xq7#mB2vLp9!") || result.Rating != RatingGood {
+		t.Errorf("Audit() score = %d rating = %v, want the policy-free score", result.Score, result.Rating)
+	}
+}
+
+func TestRatingBoundaries(t *testing.T) {
+	tests := []struct {
+		score int
+		want  Rating
+	}{
+		{0, RatingWeak},
+		{39, RatingWeak},
+		{40, RatingFair},
+		{59, RatingFair},
+		{60, RatingGood},
+		{79, RatingGood},
+		{80, RatingStrong},
+		{100, RatingStrong},
+	}
+
+	for _, tt := range tests {
+		if got := RatingOf(tt.score); got != tt.want {
+			t.Errorf("RatingOf(%d) = %v, want %v", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestRatingString(t *testing.T) {
+	for rating, want := range map[Rating]string{
+		RatingWeak:   "weak",
+		RatingFair:   "fair",
+		RatingGood:   "good",
+		RatingStrong: "strong",
+		Rating(9):    "unknown",
+	} {
+		if got := rating.String(); got != want {
+			t.Errorf("Rating(%d).String() = %q, want %q", rating, got, want)
+		}
+	}
+}
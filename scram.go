@@ -0,0 +1,233 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultScramIterations is the iteration count PostgreSQL uses by default.
+const DefaultScramIterations = 4096
+
+// scramSaltLength matches the salt PostgreSQL generates.
+const scramSaltLength = 16
+
+// scramPrefix starts every credential in pg_authid.rolpassword.
+const scramPrefix = "SCRAM-SHA-256$"
+
+// ScramSHA256Credential returns the SCRAM-SHA-256 verifier PostgreSQL stores for password, in the
+// form "SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>", so it can be set with CREATE
+// ROLE ... PASSWORD '<credential>' without the server seeing the password. Zero iterations uses
+// DefaultScramIterations. The password is normalized with SASLprep as PostgreSQL does, and used
+// unchanged when SASLprep rejects it.
+func ScramSHA256Credential(password string, iterations int) (string, error) {
+	if iterations == 0 {
+		iterations = DefaultScramIterations
+	}
+	if iterations < 1 || iterations > MaxPBKDF2Iterations {
+		return "", fmt.Errorf("scram iterations %d must be between 1 and %d", iterations, MaxPBKDF2Iterations)
+	}
+	salt, err := randomSalt(scramSaltLength)
+	if err != nil {
+		return "", err
+	}
+	storedKey, serverKey, err := scramKeys(password, salt, iterations)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%d:%s$%s:%s", scramPrefix, iterations, base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(storedKey), base64.StdEncoding.EncodeToString(serverKey)), nil
+}
+
+// VerifyScram checks password against a SCRAM-SHA-256 credential from ScramSHA256Credential or
+// pg_authid. It returns nil on a match, ErrPasswordMismatch for a wrong password, and
+// ErrMalformedHash when credential cannot be parsed or has more than MaxPBKDF2Iterations.
+func VerifyScram(password, credential string) error {
+	if !strings.HasPrefix(credential, scramPrefix) {
+		return fmt.Errorf("%w: want prefix %q", ErrMalformedHash, scramPrefix)
+	}
+	params, keys, ok := strings.Cut(strings.TrimPrefix(credential, scramPrefix), "$")
+	iter, salt64, ok1 := strings.Cut(params, ":")
+	stored64, server64, ok2 := strings.Cut(keys, ":")
+	if !ok || !ok1 || !ok2 {
+		return fmt.Errorf("%w: want iterations:salt$StoredKey:ServerKey", ErrMalformedHash)
+	}
+	iterations, err := strconv.Atoi(iter)
+	if err != nil || iterations < 1 || iterations > MaxPBKDF2Iterations {
+		return fmt.Errorf("%w: bad iterations %q", ErrMalformedHash, iter)
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil || len(salt) == 0 {
+		return fmt.Errorf("%w: bad salt", ErrMalformedHash)
+	}
+	stored, err1 := base64.StdEncoding.DecodeString(stored64)
+	server, err2 := base64.StdEncoding.DecodeString(server64)
+	if err1 != nil || err2 != nil || len(stored) != sha256.Size || len(server) != sha256.Size {
+		return fmt.Errorf("%w: bad keys", ErrMalformedHash)
+	}
+
+	storedKey, serverKey, err := scramKeys(password, salt, iterations)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(stored, storedKey)&subtle.ConstantTimeCompare(server, serverKey) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+// scramKeys derives the StoredKey and ServerKey of RFC 5802 from password and salt.
+func scramKeys(password string, salt []byte, iterations int) (storedKey, serverKey []byte, err error) {
+	if prepared, ok := saslPrep(password); ok {
+		password = prepared
+	}
+	salted, err := pbkdf2.Key(sha256.New, password, salt, iterations, sha256.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer wipe(salted)
+	clientKey := scramHMAC(salted, "Client Key")
+	defer wipe(clientKey)
+	sum := sha256.Sum256(clientKey)
+	return sum[:], scramHMAC(salted, "Server Key"), nil
+}
+
+func scramHMAC(key []byte, message string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// saslPrep applies the SASLprep profile of RFC 4013 for stored strings. It reports false for
+// invalid UTF-8, prohibited or unassigned code points, and mixed-direction text, which PostgreSQL
+// answers by using the password as is. Unassigned code points are judged by the Unicode version of
+// the unicode package rather than the 3.2 tables of RFC 3454.
+func saslPrep(s string) (string, bool) {
+	if !utf8.ValidString(s) {
+		return s, false
+	}
+	mapped := make([]rune, 0, len(s))
+	for _, r := range s {
+		switch {
+		case unicode.Is(saslNonASCIISpaces, r):
+			mapped = append(mapped, ' ')
+		case !unicode.Is(saslMappedToNothing, r):
+			mapped = append(mapped, r)
+		}
+	}
+	prepared := norm.NFKC.String(string(mapped))
+
+	var randAL, leftToRight bool
+	var first, last bidi.Class
+	for i, r := range []rune(prepared) {
+		if unicode.Is(saslProhibited, r) || !unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Z, unicode.C) {
+			return s, false
+		}
+		props, _ := bidi.LookupRune(r)
+		class := props.Class()
+		randAL = randAL || class == bidi.R || class == bidi.AL
+		leftToRight = leftToRight || class == bidi.L
+		if i == 0 {
+			first = class
+		}
+		last = class
+	}
+	if randAL && (leftToRight || first != bidi.R && first != bidi.AL || last != bidi.R && last != bidi.AL) {
+		return s, false
+	}
+	return prepared, true
+}
+
+// saslNonASCIISpaces is RFC 3454 table C.1.2, mapped to U+0020.
+var saslNonASCIISpaces = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a0, Hi: 0x00a0, Stride: 1},
+		{Lo: 0x1680, Hi: 0x1680, Stride: 1},
+		{Lo: 0x2000, Hi: 0x200b, Stride: 1},
+		{Lo: 0x202f, Hi: 0x202f, Stride: 1},
+		{Lo: 0x205f, Hi: 0x205f, Stride: 1},
+		{Lo: 0x3000, Hi: 0x3000, Stride: 1},
+	},
+}
+
+// saslMappedToNothing is RFC 3454 table B.1, removed before normalizing.
+var saslMappedToNothing = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00ad, Hi: 0x00ad, Stride: 1},
+		{Lo: 0x034f, Hi: 0x034f, Stride: 1},
+		{Lo: 0x1806, Hi: 0x1806, Stride: 1},
+		{Lo: 0x180b, Hi: 0x180d, Stride: 1},
+		{Lo: 0x200b, Hi: 0x200d, Stride: 1},
+		{Lo: 0x2060, Hi: 0x2060, Stride: 1},
+		{Lo: 0xfe00, Hi: 0xfe0f, Stride: 1},
+		{Lo: 0xfeff, Hi: 0xfeff, Stride: 1},
+	},
+}
+
+// saslProhibited merges RFC 3454 tables C.2.1 to C.9, the code points SASLprep rejects after
+// normalizing: controls, private use, non-characters, surrogates, and display and tagging
+// characters.
+var saslProhibited = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0000, Hi: 0x001f, Stride: 1},
+		{Lo: 0x007f, Hi: 0x009f, Stride: 1},
+		{Lo: 0x0340, Hi: 0x0341, Stride: 1},
+		{Lo: 0x06dd, Hi: 0x06dd, Stride: 1},
+		{Lo: 0x070f, Hi: 0x070f, Stride: 1},
+		{Lo: 0x180e, Hi: 0x180e, Stride: 1},
+		{Lo: 0x200c, Hi: 0x200f, Stride: 1},
+		{Lo: 0x2028, Hi: 0x202e, Stride: 1},
+		{Lo: 0x2060, Hi: 0x2063, Stride: 1},
+		{Lo: 0x206a, Hi: 0x206f, Stride: 1},
+		{Lo: 0x2ff0, Hi: 0x2ffb, Stride: 1},
+		{Lo: 0xd800, Hi: 0xf8ff, Stride: 1},
+		{Lo: 0xfdd0, Hi: 0xfdef, Stride: 1},
+		{Lo: 0xfeff, Hi: 0xfeff, Stride: 1},
+		{Lo: 0xfff9, Hi: 0xffff, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1d173, Hi: 0x1d17a, Stride: 1},
+		{Lo: 0x1fffe, Hi: 0x1ffff, Stride: 1},
+		{Lo: 0x2fffe, Hi: 0x2ffff, Stride: 1},
+		{Lo: 0x3fffe, Hi: 0x3ffff, Stride: 1},
+		{Lo: 0x4fffe, Hi: 0x4ffff, Stride: 1},
+		{Lo: 0x5fffe, Hi: 0x5ffff, Stride: 1},
+		{Lo: 0x6fffe, Hi: 0x6ffff, Stride: 1},
+		{Lo: 0x7fffe, Hi: 0x7ffff, Stride: 1},
+		{Lo: 0x8fffe, Hi: 0x8ffff, Stride: 1},
+		{Lo: 0x9fffe, Hi: 0x9ffff, Stride: 1},
+		{Lo: 0xafffe, Hi: 0xaffff, Stride: 1},
+		{Lo: 0xbfffe, Hi: 0xbffff, Stride: 1},
+		{Lo: 0xcfffe, Hi: 0xcffff, Stride: 1},
+		{Lo: 0xdfffe, Hi: 0xdffff, Stride: 1},
+		{Lo: 0xe0001, Hi: 0xe0001, Stride: 1},
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+		{Lo: 0xefffe, Hi: 0x10ffff, Stride: 1},
+	},
+}
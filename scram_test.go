@@ -0,0 +1,147 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readScramCredential(t *testing.T) (password, credential string) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/scram.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if password, credential, ok := strings.Cut(line, "\t"); ok && !strings.HasPrefix(line, "#") {
+			return password, credential
+		}
+	}
+	t.Fatal("testdata/scram.txt has no credential")
+	return "", ""
+}
+
+func TestScramRFC7677(t *testing.T) {
+	password, credential := readScramCredential(t)
+	if err := VerifyScram(password, credential); err != nil {
+		t.Fatalf("VerifyScram() = %v", err)
+	}
+	if err := VerifyScram("pencils", credential); !errors.Is(err, ErrPasswordMismatch) {
+		t.Errorf("VerifyScram(wrong) = %v, want ErrPasswordMismatch", err)
+	}
+
+	salt, _ := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	storedKey, serverKey, err := scramKeys(password, salt, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const authMessage = "n=user,r=rOprNGfwEbeRWgbNEkqO,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+		"s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096,c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+	salted, _ := pbkdf2.Key(sha256.New, password, salt, 4096, sha256.Size)
+	proof := scramHMAC(salted, "Client Key")
+	for i, b := range scramHMAC(storedKey, authMessage) {
+		proof[i] ^= b
+	}
+	if got, want := base64.StdEncoding.EncodeToString(proof), "dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="; got != want {
+		t.Errorf("ClientProof = %s, want %s", got, want)
+	}
+	signature := hmac.New(sha256.New, serverKey)
+	signature.Write([]byte(authMessage))
+	if got, want := base64.StdEncoding.EncodeToString(signature.Sum(nil)), "6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="; got != want {
+		t.Errorf("ServerSignature = %s, want %s", got, want)
+	}
+}
+
+func TestScramSHA256Credential(t *testing.T) {
+	credential, err := ScramSHA256Credential("Pässwørd-密码", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(credential, "SCRAM-SHA-256$4096:") {
+		t.Errorf("ScramSHA256Credential() = %q, want the default 4096 iterations", credential)
+	}
+	if err := VerifyScram("Pässwørd-密码", credential); err != nil {
+		t.Errorf("VerifyScram() = %v", err)
+	}
+	// SASLprep removes the soft hyphen and folds the full-width letters
+	if err := VerifyScram("Pä\u00adsswørd-密码", credential); err != nil {
+		t.Errorf("VerifyScram() with a soft hyphen = %v", err)
+	}
+	if err := VerifyScram("Ｐässwørd-密码", credential); err != nil {
+		t.Errorf("VerifyScram() with a full-width letter = %v", err)
+	}
+	if _, err := ScramSHA256Credential("pencil", -1); err == nil {
+		t.Error("ScramSHA256Credential() with negative iterations succeeded")
+	}
+}
+
+func TestSASLPrep(t *testing.T) {
+	// The examples of RFC 4013 section 3
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"I\u00adX", "IX", true},
+		{"user", "user", true},
+		{"USER", "USER", true},
+		{"ª", "a", true},
+		{"Ⅸ", "IX", true},
+		{"a\u00a0b", "a b", true},
+		{"\u0007", "\u0007", false},
+		{"\u06271", "\u06271", false},
+		{"\u06271\u0628", "\u06271\u0628", true},
+		{"pass\xffword", "pass\xffword", false},
+		{"\ue000", "\ue000", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got, ok := saslPrep(tt.in); got != tt.want || ok != tt.ok {
+				t.Errorf("saslPrep(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVerifyScramMalformed(t *testing.T) {
+	for _, credential := range []string{
+		"md5c0b0b3e1f1d0e1b1",
+		"SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==",
+		"SCRAM-SHA-256$x:W22ZaJ0SNY7soEsUEjb6gQ==$WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=:wfPLwcE6nTWhTAmQ7tl2KeoiWGPlZqQxSrmfPwDl2dU=",
+		"SCRAM-SHA-256$4096:!!$WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=:wfPLwcE6nTWhTAmQ7tl2KeoiWGPlZqQxSrmfPwDl2dU=",
+		"SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$WG5d8oPm:wfPLwcE6",
+		"SCRAM-SHA-256$10000001:W22ZaJ0SNY7soEsUEjb6gQ==$WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=:wfPLwcE6nTWhTAmQ7tl2KeoiWGPlZqQxSrmfPwDl2dU=",
+		"SCRAM-SHA-256$2147483647:W22ZaJ0SNY7soEsUEjb6gQ==$WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=:wfPLwcE6nTWhTAmQ7tl2KeoiWGPlZqQxSrmfPwDl2dU=",
+	} {
+		if err := VerifyScram("pencil", credential); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("VerifyScram(%q) = %v, want ErrMalformedHash", credential, err)
+		}
+	}
+}
+
+func TestScramSHA256CredentialIterationLimit(t *testing.T) {
+	if _, err := ScramSHA256Credential("pencil", MaxPBKDF2Iterations+1); err == nil {
+		t.Errorf("ScramSHA256Credential() with %d iterations succeeded", MaxPBKDF2Iterations+1)
+	}
+}
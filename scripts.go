@@ -0,0 +1,120 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math/bits"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// unknownScriptSize is the charset size credited to extended letters outside every script in
+// scriptTable, the flat estimate Audit used before it recognized scripts.
+const unknownScriptSize = 100
+
+// scriptTable lists the scripts Audit recognizes in extended letters and the alphabet an attacker
+// must try for each. Cased scripts credit their size once per letter case present, like the ASCII
+// lowercase and uppercase classes; the Han and Hangul sizes count the characters in everyday use
+// rather than every code point.
+var scriptTable = [...]struct {
+	name  string
+	table *unicode.RangeTable
+	size  int
+	cased bool
+}{
+	{"Latin", unicode.Latin, 32, true}, // Letters of the Latin-1 Supplement, per case
+	{"Greek", unicode.Greek, 24, true},
+	{"Cyrillic", unicode.Cyrillic, 33, true},
+	{"Armenian", unicode.Armenian, 38, true},
+	{"Hebrew", unicode.Hebrew, 27, false},
+	{"Arabic", unicode.Arabic, 28, false},
+	{"Devanagari", unicode.Devanagari, 48, false},
+	{"Thai", unicode.Thai, 44, false},
+	{"Hiragana", unicode.Hiragana, 46, false},
+	{"Katakana", unicode.Katakana, 46, false},
+	{"Hangul", unicode.Hangul, 2350, false},
+	{"Han", unicode.Han, 3500, false},
+}
+
+// maxScriptsSize is the largest charset extended letters can be credited, with every script present
+// in both cases along with an unrecognized letter.
+var maxScriptsSize = func() int {
+	size := unknownScriptSize
+	for _, script := range scriptTable {
+		if script.cased {
+			size += 2 * script.size
+		} else {
+			size += script.size
+		}
+	}
+	return size
+}()
+
+// scriptsOf returns the scripts of the extended letters in pass and the charset size they imply.
+// Runes in a non-empty symbols are counted as symbols, as in classify, and skipped.
+func scriptsOf(pass, symbols string) ([]string, int) {
+	var upper, lower uint16 // Bit i is set when scriptTable[i] is present in that case
+	unknown := false
+	for _, r := range pass {
+		if r < utf8.RuneSelf || !unicode.IsLetter(r) || (symbols != "" && strings.ContainsRune(symbols, r)) {
+			continue
+		}
+		i := scriptIndex(r)
+		switch {
+		case i < 0:
+			unknown = true
+		case scriptTable[i].cased && unicode.IsUpper(r):
+			upper |= 1 << i
+		default:
+			lower |= 1 << i
+		}
+	}
+
+	// Each Result gets its own slice, so a caller changing it cannot corrupt another's.
+	var scripts []string
+	if present := bits.OnesCount16(upper | lower); present > 0 {
+		scripts = make([]string, 0, present)
+	}
+	size := 0
+	for i, script := range scriptTable {
+		if (upper|lower)&(1<<i) == 0 {
+			continue
+		}
+		scripts = append(scripts, script.name)
+		if upper&(1<<i) != 0 {
+			size += script.size
+		}
+		if lower&(1<<i) != 0 {
+			size += script.size
+		}
+	}
+	if unknown {
+		size += unknownScriptSize
+	}
+	return scripts, size
+}
+
+// scriptIndex returns the index of the script of r in scriptTable, or -1 if it is not listed.
+func scriptIndex(r rune) int {
+	for i, script := range scriptTable {
+		if unicode.Is(script.table, r) {
+			return i
+		}
+	}
+	return -1
+}
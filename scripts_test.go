@@ -0,0 +1,82 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestAuditScripts(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		wantScripts []string
+		wantSize    int
+	}{
+		{"ASCII", "password", nil, 26},
+		{"Latin lowercase", "crème", []string{"Latin"}, 26 + 32},
+		{"Latin both cases", "Øversættelse", []string{"Latin"}, 26 + 32 + 32},
+		{"Cyrillic lowercase", "пароль", []string{"Cyrillic"}, 33},
+		{"Cyrillic both cases", "Пароль", []string{"Cyrillic"}, 33 + 33},
+		{"Greek", "κωδικός", []string{"Greek"}, 24},
+		{"Han", "密码", []string{"Han"}, 3500},
+		{"Mixed scripts", "пароль密码", []string{"Cyrillic", "Han"}, 33 + 3500},
+		{"Unrecognized script", "ᚠᚢᚦ", nil, unknownScriptSize},
+		{"Emoji are not letters", "🔒🔑", nil, emojiCharsetSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, Options{})
+			if !slices.Equal(result.Scripts, tt.wantScripts) {
+				t.Errorf("Audit() scripts = %q, want %q", result.Scripts, tt.wantScripts)
+			}
+			want := float64(result.Length) * math.Log2(float64(tt.wantSize))
+			if math.Abs(result.Entropy-want) > 1e-9 {
+				t.Errorf("Audit() entropy = %v, want %v", result.Entropy, want)
+			}
+		})
+	}
+}
+
+func TestAuditScriptEntropyOrdering(t *testing.T) {
+	// Eight lowercase letters in each script: CJK draws from the largest alphabet and Cyrillic
+	// from one only slightly larger than ASCII.
+	ascii := Audit("password", Options{}).Entropy
+	cyrillic := Audit("парольщи", Options{}).Entropy
+	cjk := Audit("密码安全保护账户", Options{}).Entropy
+	if !(ascii < cyrillic && cyrillic < cjk) {
+		t.Errorf("entropy ASCII = %.2f, Cyrillic = %.2f, CJK = %.2f, want increasing", ascii, cyrillic, cjk)
+	}
+	if cyrillic > 1.5*ascii {
+		t.Errorf("Cyrillic entropy = %.2f, want close to ASCII entropy %.2f", cyrillic, ascii)
+	}
+}
+
+func TestAuditScriptsSymbolSet(t *testing.T) {
+	result := Audit("abcß", Options{SymbolSet: "ß"})
+	if result.Scripts != nil {
+		t.Errorf("Audit() scripts = %q, want none for a letter in SymbolSet", result.Scripts)
+	}
+}
+
+func TestScriptTableFitsMasks(t *testing.T) {
+	if len(scriptTable) > 16 {
+		t.Errorf("scriptTable has %d scripts, scriptsOf tracks at most 16", len(scriptTable))
+	}
+}
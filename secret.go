@@ -0,0 +1,121 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// redacted replaces a Secret wherever it is printed, logged, or serialized.
+const redacted = "[REDACTED]"
+
+// Secret holds a password that redacts itself: fmt verbs, String, GoString, JSON, and slog all
+// show "[REDACTED]". Copies share the same bytes, so Zero wipes every copy. The zero value is an
+// empty secret.
+type Secret struct {
+	data *secretData // Behind a pointer so printing a struct with an unexported Secret field shows an address, not the bytes
+}
+
+type secretData struct {
+	b []byte
+}
+
+// NewSecret returns a Secret holding a copy of s.
+func NewSecret(s string) Secret {
+	return Secret{&secretData{[]byte(s)}}
+}
+
+// NewSecretBytes returns a Secret that takes ownership of b without copying it, so Zero wipes the
+// caller's buffer, such as the one term.ReadPassword returns. The caller must not modify b after.
+func NewSecretBytes(b []byte) Secret {
+	return Secret{&secretData{b}}
+}
+
+// bytes returns the secret's bytes, nil for the zero value.
+func (s Secret) bytes() []byte {
+	if s.data == nil {
+		return nil
+	}
+	return s.data.b
+}
+
+// Reveal returns the plaintext. The string is a copy that Zero cannot wipe, so call it only where
+// the plaintext is truly needed, such as when hashing.
+func (s Secret) Reveal() string {
+	return string(s.bytes())
+}
+
+// Len returns the length of the secret in bytes.
+func (s Secret) Len() int {
+	return len(s.bytes())
+}
+
+// Zero overwrites the secret's bytes with zeros and empties it.
+func (s Secret) Zero() {
+	if s.data != nil {
+		Zero(s.data.b)
+		s.data.b = nil
+	}
+}
+
+// String returns "[REDACTED]".
+func (s Secret) String() string { return redacted }
+
+// GoString returns "[REDACTED]" for the %#v verb.
+func (s Secret) GoString() string { return redacted }
+
+// Format writes "[REDACTED]" for every verb, so %x or %q cannot expose the bytes either.
+func (s Secret) Format(f fmt.State, _ rune) {
+	io.WriteString(f, redacted)
+}
+
+// MarshalJSON encodes the secret as the string "[REDACTED]".
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// UnmarshalJSON decodes a JSON string into the secret, so request bodies can carry one.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var plain []byte
+	if string(data) != "null" {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		plain = []byte(str)
+	}
+	*s = NewSecretBytes(plain)
+	return nil
+}
+
+// LogValue makes slog record the secret as "[REDACTED]".
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// AuditSecret audits s like Audit without revealing it as a string.
+func AuditSecret(s Secret, opts Options) Result {
+	return AuditBytes(s.bytes(), opts)
+}
+
+// AuditSecret audits s like (*Validator).Audit without revealing it as a string.
+func (v *Validator) AuditSecret(s Secret) Result {
+	return v.AuditBytes(s.bytes())
+}
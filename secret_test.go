@@ -0,0 +1,126 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+const secretPlaintext = "hunter2-Tr0ub4dor"
+
+func TestSecretRedacts(t *testing.T) {
+	s := NewSecret(secretPlaintext)
+	type wrapper struct {
+		Exported Secret
+		hidden   Secret
+	}
+	w := wrapper{s, s}
+	var out []string
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%d", "%10s"} {
+		out = append(out, fmt.Sprintf(verb, s), fmt.Sprintf(verb, w), fmt.Sprintf(verb, &w))
+	}
+	out = append(out, s.String(), s.GoString(), fmt.Sprint(s), fmt.Sprintln(s, w))
+	for _, v := range []any{s, w, map[string]Secret{"password": s}, []Secret{s}} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, string(data))
+	}
+	var logs bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logs, nil)).Info("login", "password", s)
+	out = append(out, logs.String())
+
+	hexed := fmt.Sprintf("%x", secretPlaintext)
+	for _, got := range out {
+		if strings.Contains(got, secretPlaintext) || strings.Contains(got, "hunter2") || strings.Contains(strings.ToLower(got), hexed) {
+			t.Errorf("output %q contains the plaintext", got)
+		}
+	}
+	if got := fmt.Sprintf("%v", s); got != "[REDACTED]" {
+		t.Errorf("%%v = %q, want [REDACTED]", got)
+	}
+	if data, _ := json.Marshal(s); string(data) != `"[REDACTED]"` {
+		t.Errorf("json.Marshal() = %s, want \"[REDACTED]\"", data)
+	}
+}
+
+func TestSecretRevealAndZero(t *testing.T) {
+	buf := []byte(secretPlaintext)
+	s := NewSecretBytes(buf)
+	clone := s
+	if s.Reveal() != secretPlaintext || s.Len() != len(secretPlaintext) {
+		t.Fatalf("Reveal() = %q, Len() = %d", s.Reveal(), s.Len())
+	}
+	s.Zero()
+	if !bytes.Equal(buf, make([]byte, len(buf))) {
+		t.Errorf("Zero() left %q in the caller's buffer", buf)
+	}
+	if clone.Reveal() != "" || clone.Len() != 0 {
+		t.Errorf("a copy still reveals %q after Zero()", clone.Reveal())
+	}
+
+	var zero Secret
+	zero.Zero()
+	if zero.Reveal() != "" || fmt.Sprint(zero) != "[REDACTED]" {
+		t.Errorf("zero Secret = %q, %v", zero.Reveal(), zero)
+	}
+	if NewSecret(secretPlaintext).Reveal() != secretPlaintext {
+		t.Error("NewSecret() did not keep the plaintext")
+	}
+}
+
+func TestSecretUnmarshalJSON(t *testing.T) {
+	var req struct {
+		Password Secret `json:"password"`
+	}
+	if err := json.Unmarshal([]byte(`{"password": "päss\"word"}`), &req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Password.Reveal(); got != `päss"word` {
+		t.Errorf("Reveal() = %q, want %q", got, `päss"word`)
+	}
+	if err := json.Unmarshal([]byte(`{"password": 42}`), &req); err == nil {
+		t.Error("json.Unmarshal() of a number into a Secret succeeded")
+	}
+	if err := json.Unmarshal([]byte(`{"password": null}`), &req); err != nil || req.Password.Len() != 0 {
+		t.Errorf("json.Unmarshal(null) = %v, Len() = %d", err, req.Password.Len())
+	}
+}
+
+func TestAuditSecret(t *testing.T) {
+	opts := Options{MinLength: 8, UseDigits: true, UseUpper: true, RejectCommon: true}
+	for _, password := range []string{"password", secretPlaintext, ""} {
+		want := Audit(password, opts)
+		s := NewSecret(password)
+		if got := AuditSecret(s, opts); got.Strong != want.Strong || got.Score != want.Score || len(got.Violations) != len(want.Violations) {
+			t.Errorf("AuditSecret(%q) = %v score %d, Audit = %v score %d", password, got.Strong, got.Score, want.Strong, want.Score)
+		}
+		v, err := NewValidator(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.AuditSecret(s); got.Strong != want.Strong || got.Score != want.Score {
+			t.Errorf("(*Validator).AuditSecret(%q) = %v score %d, want %v score %d", password, got.Strong, got.Score, want.Strong, want.Score)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/subtle"
+	"runtime"
+	"unsafe"
+)
+
+// Equal reports whether a and b hold the same bytes in time that depends only on their lengths,
+// never on their contents.
+func Equal(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Zero overwrites b with zeros. The KeepAlive keeps the compiler from treating the stores as dead.
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	runtime.KeepAlive(b)
+}
+
+// zeroRunes overwrites r with zeros like Zero.
+func zeroRunes(r []rune) {
+	for i := range r {
+		r[i] = 0
+	}
+	runtime.KeepAlive(r)
+}
+
+// wipe is called on every internal copy of a password before the hashing helpers return. Tests
+// replace it to observe the copies.
+var wipe = Zero
+
+// AuditBytes audits pass like Audit without copying it into a string, so the caller can Zero the
+// buffer afterwards. pass is read, never modified, and must not change during the call.
+// BreachChecker and DictionaryChecker implementations see a string sharing pass's memory and must
+// not retain it.
+func AuditBytes(pass []byte, opts Options) Result {
+	required, forbidden, err := opts.validate()
+	if err != nil {
+		return invalidOptionsResult(&opts, err)
+	}
+	v := newValidator(opts, required, forbidden)
+	return v.Audit(bytesView(pass))
+}
+
+// AuditBytes audits pass like (*Validator).Audit without copying it into a string.
+func (v *Validator) AuditBytes(pass []byte) Result {
+	return v.Audit(bytesView(pass))
+}
+
+// bytesView returns a string sharing b's memory. It is only valid while b is unchanged.
+func bytesView(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
+}
@@ -0,0 +1,153 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b []byte
+		want bool
+	}{
+		{[]byte("secret"), []byte("secret"), true},
+		{[]byte("secret"), []byte("Secret"), false},
+		{[]byte("secret"), []byte("secret!"), false},
+		{nil, []byte{}, true},
+	}
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestZero(t *testing.T) {
+	b := []byte("correct horse battery staple")
+	Zero(b)
+	if !bytes.Equal(b, make([]byte, len(b))) {
+		t.Errorf("Zero() left %q", b)
+	}
+	Zero(nil)
+}
+
+func TestAuditBytesLeavesInputIntact(t *testing.T) {
+	pass := []byte("Tr0ub4dor&3-ñ")
+	original := append([]byte(nil), pass...)
+	options := Options{MinLength: 8, UseDigits: true, RejectCommon: true, MaxSequenceLength: 3}
+
+	got := AuditBytes(pass, options)
+	if !bytes.Equal(pass, original) {
+		t.Errorf("AuditBytes() modified its input to %q", pass)
+	}
+	if want := Audit(string(original), options); !reflect.DeepEqual(got, want) {
+		t.Errorf("AuditBytes() = %+v, want %+v", got, want)
+	}
+	if got := AuditBytes(nil, Options{MinLength: 1}); got.Err == nil {
+		t.Error("AuditBytes(nil) error = nil, want ErrTooShort")
+	}
+}
+
+func TestAuditBytesResultSurvivesZero(t *testing.T) {
+	tests := []struct {
+		name    string
+		pass    string
+		options Options
+	}{
+		{"Date", "07041999", Options{RejectDates: true}},
+		{"Year and phone", "abc1999x5551234567", Options{RejectDates: true}},
+		{"Confusable", "pаssword9!", Options{RejectConfusables: true}},
+		{"One edit from a dictionary word", "dragonx", Options{Dictionary: NewDictionary([]string{"dragons"})}},
+		{"Common password", "password1!", Options{RejectCommon: true, MaxSequenceLength: 3, MaxRepeatRun: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pass := []byte(tt.pass)
+			got := AuditBytes(pass, tt.options)
+			Zero(pass)
+			if want := Audit(tt.pass, tt.options); !reflect.DeepEqual(got, want) {
+				t.Errorf("AuditBytes() after Zero = %+v, want %+v", got, want)
+			}
+			for _, p := range got.Patterns {
+				if strings.ContainsRune(p.Token+p.Base, 0) {
+					t.Errorf("AuditBytes() pattern %+v shares the zeroed buffer", p)
+				}
+			}
+		})
+	}
+}
+
+// recordWipes replaces wipe for the duration of the test and returns the buffers it was given.
+func recordWipes(t *testing.T) *[][]byte {
+	t.Helper()
+	var wiped [][]byte
+	saved := wipe
+	wipe = func(b []byte) {
+		saved(b)
+		wiped = append(wiped, b)
+	}
+	t.Cleanup(func() { wipe = saved })
+	return &wiped
+}
+
+func TestHashingHelpersZeroPasswordCopies(t *testing.T) {
+	const password = "correct horse battery staple"
+	helpers := map[string]func(){
+		"HashWithCost": func() { _, _ = HashWithCost(password, bcrypt.MinCost) },
+		"HashArgon2id": func() { _, _ = HashArgon2id(password, testArgon2Params) },
+		"HashScrypt":   func() { _, _ = HashScrypt(password, testScryptParams) },
+		"CryptSHA512":  func() { _, _ = CryptSHA512(password, "saltstring", 0) },
+		"APR1":         func() { _, _ = NewHtpasswdEntry("user", password, HtpasswdAPR1) },
+		"Pepper.Hash": func() {
+			p, _ := NewPepper("v1", bytes.Repeat([]byte{1}, MinPepperKeyBytes))
+			_, _ = p.Hash(password, func(s string) (string, error) { return HashWithCost(s, bcrypt.MinCost) })
+		},
+	}
+
+	for name, helper := range helpers {
+		t.Run(name, func(t *testing.T) {
+			wiped := recordWipes(t)
+			helper()
+			found := false
+			for _, b := range *wiped {
+				if !bytes.Equal(b, make([]byte, len(b))) {
+					t.Errorf("buffer of %d bytes was not zeroed", len(b))
+				}
+				found = found || len(b) == len(password)
+			}
+			if !found {
+				t.Errorf("%s did not wipe its copy of the password", name)
+			}
+		})
+	}
+}
+
+func BenchmarkAuditBytes(b *testing.B) {
+	pass := []byte("Tr0ub4dor&3")
+	options := Options{MinLength: 8, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		AuditBytes(pass, options)
+	}
+}
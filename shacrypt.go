@@ -0,0 +1,221 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sha512-crypt parameters from the "Unix crypt using SHA-256 and SHA-512" specification.
+const (
+	sha512CryptPrefix        = "$6$"
+	sha512CryptRoundsPrefix  = "rounds="
+	sha512CryptDefaultRounds = 5000
+	sha512CryptMinRounds     = 1000
+	sha512CryptMaxRounds     = 999999999
+	sha512CryptMaxSalt       = 16
+)
+
+// MaxSHA512CryptRounds is the highest rounds CryptSHA512 and VerifySHA512Crypt accept, well below
+// the 999999999 the specification allows. A stored hash above it fails with ErrMalformedHash
+// rather than tying up a CPU for minutes.
+const MaxSHA512CryptRounds = 10000000
+
+// cryptAlphabet is the base64 variant used by crypt(3).
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// sha512CryptOrder is the byte permutation sha512-crypt applies before encoding the digest.
+var sha512CryptOrder = [...]int{
+	0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4, 47, 5, 26, 6, 27, 48,
+	28, 49, 7, 50, 8, 29, 9, 30, 51, 31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13,
+	56, 14, 35, 15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19, 62, 20, 41,
+}
+
+// CryptSHA512 returns the glibc-compatible sha512-crypt string of password, as stored in
+// /etc/shadow. salt is at most 16 characters without "$", ":", or newlines; GenerateShadowSalt
+// returns a suitable one. rounds is between 1000 and MaxSHA512CryptRounds, or zero for the default
+// of 5000 with the rounds= field omitted.
+func CryptSHA512(password, salt string, rounds int) (string, error) {
+	if len(salt) > sha512CryptMaxSalt || strings.ContainsAny(salt, "$:\n") {
+		return "", fmt.Errorf("invalid sha512-crypt salt %q", salt)
+	}
+	if rounds == 0 {
+		return sha512Crypt(password, salt, sha512CryptDefaultRounds, false), nil
+	}
+	if rounds < sha512CryptMinRounds || rounds > MaxSHA512CryptRounds {
+		return "", fmt.Errorf("sha512-crypt rounds %d is outside %d to %d", rounds, sha512CryptMinRounds, MaxSHA512CryptRounds)
+	}
+	return sha512Crypt(password, salt, rounds, true), nil
+}
+
+// GenerateShadowSalt returns a random 16 character salt in the crypt(3) alphabet from crypto/rand.
+func GenerateShadowSalt() (string, error) {
+	return randomCryptSalt(sha512CryptMaxSalt)
+}
+
+// sha512Crypt returns the full "$6$" string for password. The salt is cut to 16 bytes and rounds
+// are clamped to the range glibc accepts; rounds= is only written when explicitRounds is set.
+func sha512Crypt(password, salt string, rounds int, explicitRounds bool) string {
+	if len(salt) > sha512CryptMaxSalt {
+		salt = salt[:sha512CryptMaxSalt]
+	}
+	if rounds < sha512CryptMinRounds {
+		rounds = sha512CryptMinRounds
+	}
+	if rounds > sha512CryptMaxRounds {
+		rounds = sha512CryptMaxRounds
+	}
+	p, s := []byte(password), []byte(salt)
+	defer wipe(p)
+
+	alt := sha512.New()
+	alt.Write(p)
+	alt.Write(s)
+	alt.Write(p)
+	altSum := alt.Sum(nil)
+
+	a := sha512.New()
+	a.Write(p)
+	a.Write(s)
+	n := len(p)
+	for ; n > sha512.Size; n -= sha512.Size {
+		a.Write(altSum)
+	}
+	a.Write(altSum[:n])
+	for n = len(p); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			a.Write(altSum)
+		} else {
+			a.Write(p)
+		}
+	}
+	c := a.Sum(nil)
+
+	dp := sha512.New()
+	for i := 0; i < len(p); i++ {
+		dp.Write(p)
+	}
+	pSeq := repeatBytes(dp.Sum(nil), len(p))
+	defer wipe(pSeq)
+
+	ds := sha512.New()
+	for i := 0; i < 16+int(c[0]); i++ {
+		ds.Write(s)
+	}
+	sSeq := repeatBytes(ds.Sum(nil), len(s))
+
+	h := sha512.New()
+	for r := 0; r < rounds; r++ {
+		h.Reset()
+		if r&1 != 0 {
+			h.Write(pSeq)
+		} else {
+			h.Write(c)
+		}
+		if r%3 != 0 {
+			h.Write(sSeq)
+		}
+		if r%7 != 0 {
+			h.Write(pSeq)
+		}
+		if r&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(pSeq)
+		}
+		c = h.Sum(c[:0])
+	}
+
+	var out strings.Builder
+	out.WriteString(sha512CryptPrefix)
+	if explicitRounds {
+		out.WriteString(sha512CryptRoundsPrefix + strconv.Itoa(rounds) + "$")
+	}
+	out.WriteString(salt)
+	out.WriteByte('$')
+	for i := 0; i < len(sha512CryptOrder); i += 3 {
+		o := sha512CryptOrder[i:]
+		writeCrypt64(&out, uint(c[o[0]])<<16|uint(c[o[1]])<<8|uint(c[o[2]]), 4)
+	}
+	writeCrypt64(&out, uint(c[63]), 2)
+	return out.String()
+}
+
+// repeatBytes repeats b until it is n bytes long.
+func repeatBytes(b []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, b[:min(len(b), n-len(out))]...)
+	}
+	return out
+}
+
+// writeCrypt64 writes the low 6*n bits of v in the crypt(3) alphabet, least significant first.
+func writeCrypt64(out *strings.Builder, v uint, n int) {
+	for ; n > 0; n-- {
+		out.WriteByte(cryptAlphabet[v&0x3f])
+		v >>= 6
+	}
+}
+
+// parseSHA512Crypt splits a "$6$[rounds=N$]salt$hash" string into its salt and rounds.
+func parseSHA512Crypt(encoded string) (salt string, rounds int, explicitRounds bool, err error) {
+	if !strings.HasPrefix(encoded, sha512CryptPrefix) {
+		return "", 0, false, fmt.Errorf("%w: not a sha512-crypt hash", ErrMalformedHash)
+	}
+	rest := strings.TrimPrefix(encoded, sha512CryptPrefix)
+	rounds = sha512CryptDefaultRounds
+	if strings.HasPrefix(rest, sha512CryptRoundsPrefix) {
+		value, tail, ok := strings.Cut(strings.TrimPrefix(rest, sha512CryptRoundsPrefix), "$")
+		n, convErr := strconv.Atoi(value)
+		if !ok || convErr != nil || n < 1 {
+			return "", 0, false, fmt.Errorf("%w: bad rounds %q", ErrMalformedHash, value)
+		}
+		if n > MaxSHA512CryptRounds {
+			return "", 0, false, fmt.Errorf("%w: rounds %d above %d", ErrMalformedHash, n, MaxSHA512CryptRounds)
+		}
+		rounds, explicitRounds, rest = n, true, tail
+	}
+	salt, hash, ok := strings.Cut(rest, "$")
+	if !ok || len(hash) != 86 || strings.Trim(hash, cryptAlphabet) != "" {
+		return "", 0, false, fmt.Errorf("%w: want salt and an 86 character hash", ErrMalformedHash)
+	}
+	if len(salt) > sha512CryptMaxSalt || strings.ContainsAny(salt, ":\n") {
+		return "", 0, false, fmt.Errorf("%w: bad salt", ErrMalformedHash)
+	}
+	return salt, rounds, explicitRounds, nil
+}
+
+// VerifySHA512Crypt compares password with a "$6$[rounds=N$]salt$hash" string in constant time.
+// It returns nil on a match, ErrPasswordMismatch for a wrong password, and ErrMalformedHash when
+// encoded cannot be parsed or its rounds exceed MaxSHA512CryptRounds.
+func VerifySHA512Crypt(password, encoded string) error {
+	salt, rounds, explicitRounds, err := parseSHA512Crypt(encoded)
+	if err != nil {
+		return err
+	}
+	got := sha512Crypt(password, salt, rounds, explicitRounds)
+	// Compare only the hash so "rounds=10" and its clamped "rounds=1000" form both verify.
+	if subtle.ConstantTimeCompare([]byte(got[strings.LastIndexByte(got, '$'):]), []byte(encoded[strings.LastIndexByte(encoded, '$'):])) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// sha512CryptVectors are the test vectors of the SHA-crypt specification, matching glibc.
+var sha512CryptVectors = []struct {
+	password string
+	encoded  string
+}{
+	{"Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+	{"Hello world!", "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+	{"This is just a test", "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+	{"a very much longer text to encrypt.  This one even stretches over morethan one line.", "$6$rounds=1400$anotherlongsalts$POfYwTEok97VWcjxIiSOjiykti.o/pQs.wPvMxQ6Fm7I6IoYN3CmLs66x9t0oSwbtEW7o7UmJEiDwGqd8p4ur1"},
+	{"we have a short salt string but not a short password", "$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0"},
+	{"a short string", "$6$rounds=123456$asaltof16chars..$BtCwjqMJGx5hrJhZywWvt0RLE8uZ4oPwcelCjmw2kSYu.Ec6ycULevoBK25fs2xXgMNrCzIMVcgEJAstJeonj1"},
+	{"the minimum number is still observed", "$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."},
+}
+
+func TestSHA512CryptVectors(t *testing.T) {
+	for _, tt := range sha512CryptVectors {
+		t.Run(tt.encoded[:20], func(t *testing.T) {
+			salt, rounds, explicit, err := parseSHA512Crypt(tt.encoded)
+			if err != nil {
+				t.Fatalf("parseSHA512Crypt() error = %v", err)
+			}
+			if got := sha512Crypt(tt.password, salt, rounds, explicit); got != tt.encoded {
+				t.Errorf("sha512Crypt() = %q, want %q", got, tt.encoded)
+			}
+			if err := VerifySHA512Crypt(tt.password, tt.encoded); err != nil {
+				t.Errorf("VerifySHA512Crypt() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestSHA512CryptClampsRounds(t *testing.T) {
+	want := "$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."
+	if got := sha512Crypt("the minimum number is still observed", "roundstoolow", 10, true); got != want {
+		t.Errorf("sha512Crypt() = %q, want %q", got, want)
+	}
+	encoded := "$6$rounds=10$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."
+	if err := VerifySHA512Crypt("the minimum number is still observed", encoded); err != nil {
+		t.Errorf("VerifySHA512Crypt() error = %v, want nil", err)
+	}
+}
+
+func TestParseSHA512CryptMalformed(t *testing.T) {
+	for _, encoded := range []string{
+		"",
+		"$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZF4vbBUI8",
+		"$6$saltstring",
+		"$6$saltstring$tooshort",
+		"$6$rounds=abc$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		"$6$rounds=10000001$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		"$6$rounds=999999999$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35in!1",
+	} {
+		if _, _, _, err := parseSHA512Crypt(encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("parseSHA512Crypt(%q) error = %v, want %v", encoded, err, ErrMalformedHash)
+		}
+	}
+}
+
+func TestVerifySHA512CryptRoundsLimit(t *testing.T) {
+	const hash = "$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"
+	if _, rounds, _, err := parseSHA512Crypt(fmt.Sprintf("$6$rounds=%d%s", MaxSHA512CryptRounds, hash)); err != nil || rounds != MaxSHA512CryptRounds {
+		t.Errorf("parseSHA512Crypt(rounds=%d) = %d, %v, want the limit itself accepted", MaxSHA512CryptRounds, rounds, err)
+	}
+	over := fmt.Sprintf("$6$rounds=%d%s", MaxSHA512CryptRounds+1, hash)
+	if err := VerifySHA512Crypt("Hello world!", over); !errors.Is(err, ErrMalformedHash) {
+		t.Errorf("VerifySHA512Crypt(rounds=%d) error = %v, want %v", MaxSHA512CryptRounds+1, err, ErrMalformedHash)
+	}
+	if _, err := VerifyAny("Hello world!", over); !errors.Is(err, ErrMalformedHash) {
+		t.Errorf("VerifyAny(rounds=%d) error = %v, want %v", MaxSHA512CryptRounds+1, err, ErrMalformedHash)
+	}
+}
+
+// FuzzVerifySHA512CryptRounds checks that rounds above MaxSHA512CryptRounds are rejected without
+// being run. Rounds within the limit are skipped, as running them is the slow path it bounds.
+func FuzzVerifySHA512CryptRounds(f *testing.F) {
+	f.Add(MaxSHA512CryptRounds + 1)
+	f.Add(999999999)
+	f.Add(1<<63 - 1)
+	f.Fuzz(func(t *testing.T, rounds int) {
+		if rounds <= MaxSHA512CryptRounds {
+			t.Skip("within the limit")
+		}
+		encoded := fmt.Sprintf("$6$rounds=%d$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", rounds)
+		if err := VerifySHA512Crypt("Hello world!", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("VerifySHA512Crypt(%q) error = %v, want %v", encoded, err, ErrMalformedHash)
+		}
+	})
+}
+
+func TestCryptSHA512(t *testing.T) {
+	got, err := CryptSHA512("Hello world!", "saltstring", 0)
+	if err != nil || got != sha512CryptVectors[0].encoded {
+		t.Errorf("CryptSHA512() = %q, %v, want %q", got, err, sha512CryptVectors[0].encoded)
+	}
+	got, err = CryptSHA512("a short string", "asaltof16chars..", 123456)
+	if err != nil || got != sha512CryptVectors[5].encoded {
+		t.Errorf("CryptSHA512() = %q, %v, want %q", got, err, sha512CryptVectors[5].encoded)
+	}
+
+	for _, tt := range []struct {
+		salt   string
+		rounds int
+	}{
+		{"toolongsaltstring", 5000},
+		{"dollar$", 5000},
+		{"saltstring", 999},
+		{"saltstring", MaxSHA512CryptRounds + 1},
+		{"saltstring", 1000000000},
+	} {
+		if _, err := CryptSHA512("password", tt.salt, tt.rounds); err == nil {
+			t.Errorf("CryptSHA512(salt %q, rounds %d) error = nil, want error", tt.salt, tt.rounds)
+		}
+	}
+}
+
+func TestGenerateShadowSalt(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		salt, err := GenerateShadowSalt()
+		if err != nil {
+			t.Fatalf("GenerateShadowSalt() error = %v", err)
+		}
+		if len(salt) != 16 || strings.Trim(salt, cryptAlphabet) != "" {
+			t.Fatalf("GenerateShadowSalt() = %q, want 16 characters of the crypt alphabet", salt)
+		}
+		if seen[salt] {
+			t.Fatalf("GenerateShadowSalt() repeated %q", salt)
+		}
+		seen[salt] = true
+	}
+
+	salt, _ := GenerateShadowSalt()
+	encoded, err := CryptSHA512("correct horse", salt, 0)
+	if err != nil {
+		t.Fatalf("CryptSHA512() error = %v", err)
+	}
+	if err := VerifySHA512Crypt("correct horse", encoded); err != nil {
+		t.Errorf("VerifySHA512Crypt() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,80 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"strings"
+)
+
+// DefaultMaxSimilarity is the threshold used when Options.PreviousPasswords is set and
+// Options.MaxSimilarity is zero. It rejects single-character edits of passwords up to ten runes.
+const DefaultMaxSimilarity = 0.7
+
+// Similarity returns how alike two passwords are, from 0 for nothing in common to 1 for the same
+// password ignoring case. It is one minus the Levenshtein distance between the case-folded runes,
+// divided by the longer length. Two empty strings are identical.
+func Similarity(old, new string) float64 {
+	a := []rune(strings.ToLower(old))
+	b := []rune(strings.ToLower(new))
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein returns the number of single-rune insertions, deletions, and substitutions needed
+// to turn a into b, keeping a single row of the distance matrix.
+func levenshtein(a, b []rune) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			next := diagonal + cost
+			if row[j]+1 < next {
+				next = row[j] + 1
+			}
+			if row[j-1]+1 < next {
+				next = row[j-1] + 1
+			}
+			diagonal, row[j] = row[j], next
+		}
+	}
+	return row[len(b)]
+}
+
+// mostSimilar returns the index and similarity of the previous password closest to pass.
+func mostSimilar(pass string, previous []string) (int, float64) {
+	best, score := -1, -1.0
+	for i, old := range previous {
+		if s := Similarity(old, pass); s > score {
+			best, score = i, s
+		}
+	}
+	return best, score
+}
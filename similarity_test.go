@@ -0,0 +1,91 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want float64
+	}{
+		{"Both empty", "", "", 1},
+		{"One empty", "", "secret", 0},
+		{"Identical", "Winter2024!", "Winter2024!", 1},
+		{"Case only", "Winter2024!", "wINTER2024!", 1},
+		{"One digit changed", "Winter2024!", "Winter2025!", 1 - 1.0/11},
+		{"Appended character", "Winter2024!", "Winter2024!!", 1 - 1.0/12},
+		{"Prepended character", "Winter2024!", "#Winter2024!", 1 - 1.0/12},
+		{"Unicode runes", "Ünïcødé", "Ünïcode", 1 - 2.0/7},
+		{"Nothing in common", "abcd", "wxyz", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.old, tt.new); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditPreviousPasswords(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		wantErr  bool
+	}{
+		{
+			name:     "Rotated year",
+			password: "Winter2025!",
+			options:  Options{PreviousPasswords: []string{"Summer2019?", "Winter2024!"}},
+			wantErr:  true,
+		},
+		{
+			name:     "Unrelated password",
+			password: "correct horse battery",
+			options:  Options{PreviousPasswords: []string{"Winter2024!"}},
+		},
+		{
+			name:     "Custom threshold allows the edit",
+			password: "Winter2025!",
+			options:  Options{PreviousPasswords: []string{"Winter2024!"}, MaxSimilarity: 0.95},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if got := errors.Is(result.Err, ErrTooSimilar); got != tt.wantErr {
+				t.Errorf("Audit(%q) error = %v, want ErrTooSimilar %v", tt.password, result.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkSimilarity(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Similarity("Correct-Horse-Battery-Staple-2024", "correct-horse-battery-staple-2025!")
+	}
+}
@@ -0,0 +1,146 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTemplateLength is the longest template, in runes, GenerateFromTemplate accepts.
+const MaxTemplateLength = 1024
+
+// templateSets maps each template placeholder to the characters it is filled from.
+var templateSets = map[rune]string{
+	'U': upperChars,
+	'l': lowerChars,
+	'd': digitChars,
+	's': symbolChars,
+	'x': digitChars + lowerChars + upperChars + symbolChars,
+}
+
+// TemplateError describes why a template could not be parsed.
+type TemplateError struct {
+	Position int // Rune index in the template
+	Reason   string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template position %d: %s", e.Position, e.Reason)
+}
+
+// GenerateFromTemplate returns a password shaped like tmpl, filling each placeholder with a
+// character from crypto/rand: U uppercase, l lowercase, d digit, s symbol, and x any of those.
+// Any other character must be escaped with a backslash to appear literally, so "Ulllllldd\-s"
+// yields something like "Kqzvbad42-!". Invalid templates return a *TemplateError.
+func GenerateFromTemplate(tmpl string) (string, error) {
+	var g Generator
+	return g.GenerateFromTemplate(tmpl)
+}
+
+// GenerateFromTemplate is like the package-level GenerateFromTemplate but leaves out the characters
+// g excludes. A placeholder with no characters left returns a *TemplateError.
+func (g *Generator) GenerateFromTemplate(tmpl string) (string, error) {
+	parts, err := g.parseTemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+	out := make([]rune, len(parts))
+	for i, part := range parts {
+		if part.set == nil {
+			out[i] = part.literal
+			continue
+		}
+		if out[i], err = randomRune(g.random(), part.set); err != nil {
+			return "", err
+		}
+	}
+	password := string(out)
+	zeroRunes(out)
+	return password, nil
+}
+
+// templatePart is a literal rune or a set to draw from.
+type templatePart struct {
+	literal rune
+	set     []rune
+}
+
+// parseTemplate splits tmpl into its parts, drawing placeholders from the characters g allows.
+func (g *Generator) parseTemplate(tmpl string) ([]templatePart, error) {
+	if tmpl == "" {
+		return nil, &TemplateError{Position: 0, Reason: "template is empty"}
+	}
+	if !utf8.ValidString(tmpl) {
+		return nil, &TemplateError{Position: 0, Reason: "template is not valid UTF-8"}
+	}
+	runes := []rune(tmpl)
+	if len(runes) > MaxTemplateLength {
+		return nil, &TemplateError{Position: MaxTemplateLength, Reason: fmt.Sprintf("template is longer than %d characters", MaxTemplateLength)}
+	}
+
+	parts := make([]templatePart, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\\' {
+			if i+1 == len(runes) {
+				return nil, &TemplateError{Position: i, Reason: "trailing backslash escapes nothing"}
+			}
+			i++
+			parts = append(parts, templatePart{literal: runes[i]})
+			continue
+		}
+		chars, ok := templateSets[r]
+		if !ok {
+			return nil, &TemplateError{Position: i, Reason: fmt.Sprintf("unknown placeholder %q, escape literals with a backslash", r)}
+		}
+		set := g.charset(chars)
+		if len(set) == 0 {
+			return nil, &TemplateError{Position: i, Reason: fmt.Sprintf("placeholder %q has no characters left after excluding ambiguous characters", r)}
+		}
+		parts = append(parts, templatePart{set: set})
+	}
+	return parts, nil
+}
+
+// TemplateOf returns the template describing the shape of pass: U, l, d, and s for ASCII
+// uppercase, lowercase, digits, and symbols, and every other character escaped as a literal.
+// GenerateFromTemplate accepts the result.
+func TemplateOf(pass string) string {
+	var b strings.Builder
+	for _, r := range pass {
+		class := ClassOther
+		if r < utf8.RuneSelf {
+			class = asciiClasses[r]
+		}
+		switch class {
+		case ClassUpper:
+			b.WriteByte('U')
+		case ClassLower:
+			b.WriteByte('l')
+		case ClassDigits:
+			b.WriteByte('d')
+		case ClassSymbols:
+			b.WriteByte('s')
+		default:
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGenerateFromTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl  string
+		shape string // TemplateOf of every output
+	}{
+		{"Ulllllldds", "Ulllllldds"},
+		{"dddd", "dddd"},
+		{`Ulll\-dd\ø`, `Ulll` + "s" + `dd\ø`},
+		{`\\U`, `\\U`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				password, err := GenerateFromTemplate(tt.tmpl)
+				if err != nil {
+					t.Fatalf("GenerateFromTemplate() error = %v", err)
+				}
+				if got := TemplateOf(password); got != tt.shape {
+					t.Fatalf("TemplateOf(%q) = %q, want %q", password, got, tt.shape)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFromTemplateAny(t *testing.T) {
+	password, err := GenerateFromTemplate(strings.Repeat("x", 64))
+	if err != nil {
+		t.Fatalf("GenerateFromTemplate() error = %v", err)
+	}
+	if strings.Contains(TemplateOf(password), `\`) {
+		t.Errorf("GenerateFromTemplate(x...) = %q, want only ASCII classes", password)
+	}
+}
+
+func TestGenerateFromTemplateErrors(t *testing.T) {
+	tests := []struct {
+		tmpl     string
+		position int
+	}{
+		{"", 0},
+		{"UllA", 3},
+		{`Ull\`, 3},
+		{"dd-dd", 2},
+		{"ddé", 2},
+		{"\xff", 0},
+		{strings.Repeat("d", MaxTemplateLength+1), MaxTemplateLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			_, err := GenerateFromTemplate(tt.tmpl)
+			var tmplErr *TemplateError
+			if !errors.As(err, &tmplErr) {
+				t.Fatalf("GenerateFromTemplate() error = %v, want *TemplateError", err)
+			}
+			if tmplErr.Position != tt.position {
+				t.Errorf("TemplateError.Position = %d, want %d (%v)", tmplErr.Position, tt.position, err)
+			}
+		})
+	}
+}
+
+func TestTemplateOf(t *testing.T) {
+	tests := []struct{ pass, want string }{
+		{"Password1!", "Ulllllllds"},
+		{"", ""},
+		{"pä ss", `l\ä\ ll`},
+		{`a\b`, `l\\l`},
+	}
+	for _, tt := range tests {
+		if got := TemplateOf(tt.pass); got != tt.want {
+			t.Errorf("TemplateOf(%q) = %q, want %q", tt.pass, got, tt.want)
+		}
+	}
+}
+
+func FuzzGenerateFromTemplate(f *testing.F) {
+	for _, seed := range []string{"Ulllllldds", `\`, `\\`, "x", "", "Ud\\ü", "\xff\xfe", "zzz"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, tmpl string) {
+		password, err := GenerateFromTemplate(tmpl)
+		if err != nil {
+			var tmplErr *TemplateError
+			if !errors.As(err, &tmplErr) {
+				t.Fatalf("GenerateFromTemplate(%q) error = %v, want *TemplateError", tmpl, err)
+			}
+			return
+		}
+		if _, err := GenerateFromTemplate(TemplateOf(password)); err != nil {
+			t.Fatalf("TemplateOf(%q) = %q does not parse: %v", password, TemplateOf(password), err)
+		}
+		if utf8.RuneCountInString(password) > MaxTemplateLength {
+			t.Fatalf("GenerateFromTemplate(%q) returned %d runes", tmpl, utf8.RuneCountInString(password))
+		}
+	})
+}
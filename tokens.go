@@ -0,0 +1,162 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// RecoveryCodeAlphabet is the unambiguous alphabet recovery codes are drawn from. It leaves out
+// 0, O, 1 and I so codes can be read back over the phone or copied from paper.
+const RecoveryCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// MinTokenBytes is the smallest amount of randomness GenerateToken accepts.
+const MinTokenBytes = 16
+
+// tokenChecksumLength is the number of base62 characters holding a token's CRC32.
+const tokenChecksumLength = 6
+
+const base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// GenerateRecoveryCodes returns n distinct one-time recovery codes of groups blocks of groupLen
+// characters joined by "-", such as "7F3K-9QZ2-MMQ1", drawn with crypto/rand from
+// RecoveryCodeAlphabet.
+func GenerateRecoveryCodes(n, groups, groupLen int) ([]string, error) {
+	var g Generator
+	return g.GenerateRecoveryCodes(n, groups, groupLen)
+}
+
+// GenerateRecoveryCodes is like the package-level GenerateRecoveryCodes but also leaves out the
+// characters g excludes from RecoveryCodeAlphabet.
+func (g *Generator) GenerateRecoveryCodes(n, groups, groupLen int) ([]string, error) {
+	alphabet := g.charset(RecoveryCodeAlphabet)
+	if len(alphabet) == 0 {
+		return nil, errors.New("no recovery code characters are left after excluding ambiguous characters")
+	}
+	if n < 1 || groups < 1 || groupLen < 1 {
+		return nil, errors.New("recovery code count, groups and group length must be positive")
+	}
+	if bits := float64(groups*groupLen) * math.Log2(float64(len(alphabet))); bits < 62 && float64(n) > math.Exp2(bits) {
+		return nil, fmt.Errorf("cannot generate %d distinct codes of %d characters", n, groups*groupLen)
+	}
+
+	codes := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	for len(codes) < n {
+		code, err := recoveryCode(g.random(), alphabet, groups, groupLen)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[code]; dup {
+			continue
+		}
+		seen[code] = struct{}{}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// recoveryCode returns one recovery code drawn from alphabet using random.
+func recoveryCode(random io.Reader, alphabet []rune, groups, groupLen int) (string, error) {
+	var b strings.Builder
+	b.Grow(groups*(groupLen+1) - 1)
+	for g := 0; g < groups; g++ {
+		if g > 0 {
+			b.WriteByte('-')
+		}
+		for i := 0; i < groupLen; i++ {
+			r, err := randomRune(random, alphabet)
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
+
+// GenerateToken returns an API token of the form prefix_<body><checksum>. The body is bytes of
+// crypto/rand output in base62 and the checksum is the CRC32 of everything before it as six base62
+// characters, so secret scanners can recognise leaked tokens without false positives. The prefix
+// may only contain ASCII letters and digits.
+func GenerateToken(prefix string, bytes int) (string, error) {
+	var g Generator
+	return g.GenerateToken(prefix, bytes)
+}
+
+// GenerateToken is like the package-level GenerateToken but reads its random bytes from g.Rand.
+func (g *Generator) GenerateToken(prefix string, bytes int) (string, error) {
+	if bytes < MinTokenBytes {
+		return "", fmt.Errorf("token needs at least %d random bytes, got %d", MinTokenBytes, bytes)
+	}
+	if prefix == "" {
+		return "", errors.New("token prefix is empty")
+	}
+	for _, r := range prefix {
+		if r > 127 || !strings.ContainsRune(base62Chars, r) {
+			return "", fmt.Errorf("token prefix %q must contain only ASCII letters and digits", prefix)
+		}
+	}
+
+	secret := make([]byte, bytes)
+	defer wipe(secret)
+	if _, err := io.ReadFull(g.random(), secret); err != nil {
+		return "", err
+	}
+
+	width := int(math.Ceil(float64(bytes*8) / math.Log2(62)))
+	token := prefix + "_" + base62(new(big.Int).SetBytes(secret), width)
+	return token + base62(big.NewInt(int64(crc32.ChecksumIEEE([]byte(token)))), tokenChecksumLength), nil
+}
+
+// ValidTokenChecksum reports whether token has the shape produced by GenerateToken and its CRC32
+// checksum matches. It does not say whether the token was ever issued.
+func ValidTokenChecksum(token string) bool {
+	prefix, rest, ok := strings.Cut(token, "_")
+	if !ok || prefix == "" || len(rest) <= tokenChecksumLength {
+		return false
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] != '_' && strings.IndexByte(base62Chars, token[i]) < 0 {
+			return false
+		}
+	}
+	split := len(token) - tokenChecksumLength
+	want := base62(big.NewInt(int64(crc32.ChecksumIEEE([]byte(token[:split])))), tokenChecksumLength)
+	return token[split:] == want
+}
+
+// base62 encodes n in base62, left-padded with zeros to width characters.
+func base62(n *big.Int, width int) string {
+	out := make([]byte, width)
+	for i := range out {
+		out[i] = base62Chars[0]
+	}
+	n = new(big.Int).Set(n)
+	radix, mod := big.NewInt(62), new(big.Int)
+	for i := width - 1; i >= 0 && n.Sign() > 0; i-- {
+		n.DivMod(n, radix, mod)
+		out[i] = base62Chars[mod.Int64()]
+	}
+	return string(out)
+}
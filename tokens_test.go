@@ -0,0 +1,195 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		groups   int
+		groupLen int
+		pattern  string
+	}{
+		{name: "Three groups of four", n: 10, groups: 3, groupLen: 4, pattern: `^[2-9A-HJ-NP-Z]{4}-[2-9A-HJ-NP-Z]{4}-[2-9A-HJ-NP-Z]{4}$`},
+		{name: "Single group", n: 16, groups: 1, groupLen: 10, pattern: `^[2-9A-HJ-NP-Z]{10}$`},
+		{name: "Every possible code", n: 32, groups: 1, groupLen: 1, pattern: `^[2-9A-HJ-NP-Z]$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes, err := GenerateRecoveryCodes(tt.n, tt.groups, tt.groupLen)
+			if err != nil {
+				t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+			}
+			if len(codes) != tt.n {
+				t.Fatalf("len(codes) = %d, want %d", len(codes), tt.n)
+			}
+			re := regexp.MustCompile(tt.pattern)
+			seen := make(map[string]bool)
+			for _, code := range codes {
+				if !re.MatchString(code) {
+					t.Errorf("code %q does not match %s", code, tt.pattern)
+				}
+				if seen[code] {
+					t.Errorf("code %q generated twice", code)
+				}
+				seen[code] = true
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCodesNoCollisions(t *testing.T) {
+	// 1024 possible codes, so duplicates are near certain unless they are retried.
+	codes, err := GenerateRecoveryCodes(1000, 2, 1)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if seen[code] {
+			t.Fatalf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestGenerateRecoveryCodesErrors(t *testing.T) {
+	tests := []struct {
+		name               string
+		n, groups, groupLn int
+	}{
+		{name: "No codes", n: 0, groups: 2, groupLn: 4},
+		{name: "No groups", n: 5, groups: 0, groupLn: 4},
+		{name: "Empty groups", n: 5, groups: 2, groupLn: 0},
+		{name: "More codes than combinations", n: 33, groups: 1, groupLn: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if codes, err := GenerateRecoveryCodes(tt.n, tt.groups, tt.groupLn); err == nil {
+				t.Errorf("GenerateRecoveryCodes() = %v, want error", codes)
+			}
+		})
+	}
+}
+
+func TestGenerateToken(t *testing.T) {
+	re := regexp.MustCompile(`^ghp_[0-9A-Za-z]{49}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		token, err := GenerateToken("ghp", 32)
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		if !re.MatchString(token) {
+			t.Fatalf("GenerateToken() = %q, want match for %s", token, re)
+		}
+		if !ValidTokenChecksum(token) {
+			t.Fatalf("ValidTokenChecksum(%q) = false", token)
+		}
+		if seen[token] {
+			t.Fatalf("token %q generated twice", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestGenerateTokenErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		bytes  int
+	}{
+		{name: "Too few bytes", prefix: "tok", bytes: MinTokenBytes - 1},
+		{name: "Empty prefix", prefix: "", bytes: 32},
+		{name: "Underscore in prefix", prefix: "my_app", bytes: 32},
+		{name: "Non-ASCII prefix", prefix: "tök", bytes: 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if token, err := GenerateToken(tt.prefix, tt.bytes); err == nil {
+				t.Errorf("GenerateToken(%q, %d) = %q, want error", tt.prefix, tt.bytes, token)
+			}
+		})
+	}
+}
+
+func TestValidTokenChecksum(t *testing.T) {
+	token, err := GenerateToken("sk", MinTokenBytes)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	flip := func(s string, i int) string {
+		c := byte('a')
+		if s[i] == 'a' {
+			c = 'b'
+		}
+		return s[:i] + string(c) + s[i+1:]
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{name: "Generated", token: token, want: true},
+		{name: "Body changed", token: flip(token, 5), want: false},
+		{name: "Checksum changed", token: flip(token, len(token)-1), want: false},
+		{name: "Prefix changed", token: "ak" + token[2:], want: false},
+		{name: "Truncated", token: token[:len(token)-1], want: false},
+		{name: "No separator", token: strings.Replace(token, "_", "", 1), want: false},
+		{name: "Only checksum", token: "sk_" + token[len(token)-6:], want: false},
+		{name: "Invalid character", token: token[:4] + "!" + token[5:], want: false},
+		{name: "Empty", token: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidTokenChecksum(tt.token); got != tt.want {
+				t.Errorf("ValidTokenChecksum(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBase62(t *testing.T) {
+	tests := []struct {
+		n     int64
+		width int
+		want  string
+	}{
+		{n: 0, width: 3, want: "000"},
+		{n: 61, width: 2, want: "0z"},
+		{n: 62, width: 2, want: "10"},
+		{n: 4294967295, width: 6, want: "4gfFC3"},
+	}
+
+	for _, tt := range tests {
+		if got := base62(big.NewInt(tt.n), tt.width); got != tt.want {
+			t.Errorf("base62(%d, %d) = %q, want %q", tt.n, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"slices"
+	"unicode"
+)
+
+// minSubstringWord is the shortest word, in runes, Dictionary.ContainsSubstring reports, as
+// shorter words turn up in almost any password by chance.
+const minSubstringWord = 4
+
+// Dictionary is a DictionaryChecker built once from a list of banned words into a trie, so it can
+// also find the words hidden inside a password or one edit away from it. Words are compared
+// ignoring case. When it is Options.Dictionary, Audit also rejects passwords within one edit of a
+// word, such as "passw0rd", and passwords at least half made of one, such as "mypassword2024". It
+// is safe for concurrent use.
+type Dictionary struct {
+	nodes []trieNode // nodes[0] is the root
+	words []string
+}
+
+// trieNode is a node of a Dictionary trie.
+type trieNode struct {
+	edges []trieEdge // Sorted by rune
+	word  int32      // Index in Dictionary.words of the word ending here, or -1
+}
+
+// trieEdge links a node to the child reached by r.
+type trieEdge struct {
+	r    rune
+	next int32
+}
+
+// NewDictionary builds a Dictionary of words, skipping empty ones.
+func NewDictionary(words []string) *Dictionary {
+	d := &Dictionary{nodes: []trieNode{{word: -1}}}
+	for _, word := range words {
+		runes := lowerRunes(word)
+		if len(runes) == 0 {
+			continue
+		}
+		n := int32(0)
+		for _, r := range runes {
+			n = d.insertChild(n, r)
+		}
+		if d.nodes[n].word < 0 {
+			d.nodes[n].word = int32(len(d.words))
+			d.words = append(d.words, string(runes))
+		}
+	}
+	return d
+}
+
+// insertChild returns the child of node n reached by r, adding it when missing.
+func (d *Dictionary) insertChild(n int32, r rune) int32 {
+	edges := d.nodes[n].edges
+	i, found := slices.BinarySearchFunc(edges, r, func(e trieEdge, r rune) int { return int(e.r - r) })
+	if found {
+		return edges[i].next
+	}
+	child := int32(len(d.nodes))
+	d.nodes = append(d.nodes, trieNode{word: -1})
+	d.nodes[n].edges = slices.Insert(edges, i, trieEdge{r, child})
+	return child
+}
+
+// child returns the child of node n reached by r, or -1.
+func (d *Dictionary) child(n int32, r rune) int32 {
+	edges := d.nodes[n].edges
+	if i, found := slices.BinarySearchFunc(edges, r, func(e trieEdge, r rune) int { return int(e.r - r) }); found {
+		return edges[i].next
+	}
+	return -1
+}
+
+// Len returns the number of distinct words in the dictionary.
+func (d *Dictionary) Len() int {
+	return len(d.words)
+}
+
+// Contains reports whether word is in the dictionary, ignoring case.
+func (d *Dictionary) Contains(word string) bool {
+	n := int32(0)
+	for _, r := range word {
+		if n = d.child(n, unicode.ToLower(r)); n < 0 {
+			return false
+		}
+	}
+	return d.nodes[n].word >= 0
+}
+
+// ContainsSubstring returns the longest word of at least four runes found anywhere in s, as a
+// PatternDictionary Match locating it in s, and whether there is one.
+func (d *Dictionary) ContainsSubstring(s string) (Match, bool) {
+	runes := lowerRunes(s)
+	best, start, end := int32(-1), 0, 0
+	for i := range runes {
+		n := int32(0)
+		for j := i; j < len(runes); j++ {
+			if n = d.child(n, runes[j]); n < 0 {
+				break
+			}
+			if w := d.nodes[n].word; w >= 0 && j+1-i >= minSubstringWord && j+1-i > end-start {
+				best, start, end = w, i, j+1
+			}
+		}
+	}
+	if best < 0 {
+		return Match{}, false
+	}
+	token := []rune(s)[start:end]
+	return Match{Kind: PatternDictionary, Token: string(token), Start: start, End: end, Base: d.words[best]}, true
+}
+
+// Closest returns the word of the dictionary within one edit of s, a rune substituted, inserted,
+// or deleted, preferring s itself, and whether there is one.
+func (d *Dictionary) Closest(s string) (string, bool) {
+	runes := lowerRunes(s)
+	w := d.withinOne(0, runes, false)
+	if w < 0 {
+		return "", false
+	}
+	return d.words[w], true
+}
+
+// withinOne returns a word of the subtrie at node n matching runes with at most one edit, or one
+// fewer when edited is set, trying the exact match first. It returns -1 when there is none.
+func (d *Dictionary) withinOne(n int32, runes []rune, edited bool) int32 {
+	if len(runes) == 0 {
+		if w := d.nodes[n].word; w >= 0 {
+			return w
+		}
+	} else if c := d.child(n, runes[0]); c >= 0 {
+		if w := d.withinOne(c, runes[1:], edited); w >= 0 {
+			return w
+		}
+	}
+	if edited {
+		return -1
+	}
+	if len(runes) > 0 {
+		if w := d.withinOne(n, runes[1:], true); w >= 0 { // Deletion
+			return w
+		}
+	}
+	for _, e := range d.nodes[n].edges {
+		if len(runes) > 0 && e.r != runes[0] {
+			if w := d.withinOne(e.next, runes[1:], true); w >= 0 { // Substitution
+				return w
+			}
+		}
+		if w := d.withinOne(e.next, runes, true); w >= 0 { // Insertion
+			return w
+		}
+	}
+	return -1
+}
+
+// lowerRunes returns the runes of s lowercased one by one, so positions in the result match s.
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
@@ -0,0 +1,158 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestDictionaryContains(t *testing.T) {
+	d := NewDictionary([]string{"password", "Dragon", "", "dragon", "straße"})
+	if d.Len() != 3 {
+		t.Errorf("Len() = %d, want 3 distinct words", d.Len())
+	}
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"password", true},
+		{"PASSWORD", true},
+		{"dragon", true},
+		{"STRASSE", false},
+		{"Straße", true},
+		{"pass", false},
+		{"passwords", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := d.Contains(tt.word); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestDictionaryContainsSubstring(t *testing.T) {
+	d := NewDictionary([]string{"password", "pass", "word", "dragon", "cat"})
+	tests := []struct {
+		s           string
+		found       bool
+		base, token string
+		start, end  int
+	}{
+		{"mypassword2024", true, "password", "password", 2, 10},
+		{"MyPassWord", true, "password", "PassWord", 2, 10},
+		{"xßdragon!", true, "dragon", "dragon", 2, 8},
+		{"wordy", true, "word", "word", 0, 4},
+		{"concatenate", false, "", "", 0, 0}, // "cat" is too short
+		{"Tr0ub4dor&3", false, "", "", 0, 0},
+	}
+	for _, tt := range tests {
+		m, ok := d.ContainsSubstring(tt.s)
+		if ok != tt.found {
+			t.Errorf("ContainsSubstring(%q) found = %v, want %v", tt.s, ok, tt.found)
+			continue
+		}
+		if ok && (m.Kind != PatternDictionary || m.Base != tt.base || m.Token != tt.token || m.Start != tt.start || m.End != tt.end) {
+			t.Errorf("ContainsSubstring(%q) = %+v, want %q as %q at [%d, %d)", tt.s, m, tt.base, tt.token, tt.start, tt.end)
+		}
+	}
+}
+
+func TestDictionaryClosest(t *testing.T) {
+	d := NewDictionary([]string{"password", "passwords", "dragon", "monkey"})
+	tests := []struct {
+		s, want string
+	}{
+		{"password", "password"}, // Exact match preferred over "passwords"
+		{"passw0rd", "password"}, // Substitution
+		{"pasword", "password"},  // Insertion
+		{"dragoon", "dragon"},    // Deletion
+		{"xdragon", "dragon"},    // Deletion at the start
+		{"dragonx", "dragon"},    // Deletion at the end
+		{"ragon", "dragon"},      // Insertion at the start
+		{"MONKEE", "monkey"},     // Case is ignored
+		{"mnokey", ""},           // A transposition is two edits
+		{"passw0rd1", ""},        // Two edits
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, ok := d.Closest(tt.s)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Closest(%q) = %q, %v, want %q", tt.s, got, ok, tt.want)
+		}
+	}
+}
+
+func TestAuditDictionaryTrie(t *testing.T) {
+	opts := Options{Dictionary: NewDictionary([]string{"password", "dragon"})}
+	tests := []struct {
+		pass string
+		fail bool
+	}{
+		{"password", true},
+		{"passw0rd", true},
+		{"mypassword2024", true},
+		{"dragon-1", true},
+		{"correct horse dragon staple", false}, // The word is less than half the password
+		{"Tr0ub4dor&3", false},
+	}
+	for _, tt := range tests {
+		result := Audit(tt.pass, opts)
+		if got := errors.Is(result.Err, ErrCommonPassword); got != tt.fail {
+			t.Errorf("Audit(%q) error = %v, want ErrCommonPassword %v", tt.pass, result.Err, tt.fail)
+		}
+		if tt.fail && (len(result.Patterns) == 0 || result.Patterns[len(result.Patterns)-1].Kind != PatternDictionary) {
+			t.Errorf("Audit(%q) Patterns = %v, want a dictionary match", tt.pass, result.Patterns)
+		}
+	}
+}
+
+// benchmarkDictionary returns a Dictionary of n random lowercase words of 4 to 12 letters.
+func benchmarkDictionary(n int) *Dictionary {
+	rng := rand.New(rand.NewPCG(1, 2))
+	words := make([]string, n)
+	for i := range words {
+		var b strings.Builder
+		for range 4 + rng.IntN(9) {
+			b.WriteByte(byte('a' + rng.IntN(26)))
+		}
+		words[i] = b.String()
+	}
+	return NewDictionary(words)
+}
+
+func BenchmarkDictionary(b *testing.B) {
+	d := benchmarkDictionary(100_000)
+	password := strings.Repeat("correcthorsebatterystaple", 3)[:64]
+	for _, bench := range []struct {
+		name string
+		f    func(string)
+	}{
+		{"ContainsSubstring", func(s string) { d.ContainsSubstring(s) }},
+		{"Closest", func(s string) { d.Closest(s) }},
+	} {
+		b.Run(fmt.Sprintf("%s/64", bench.name), func(b *testing.B) {
+			for b.Loop() {
+				bench.f(password)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// uncasedChars are the letters without case Generate draws from when UseExtended and
+// UnicodeClasses are set, since under UnicodeClasses accented letters count as lower or upper.
+const uncasedChars = "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわをん"
+
+// unicodeClassOf returns the class of the non-ASCII rune r under Options.UnicodeClasses: digits,
+// cased letters, and punctuation and symbols of every script count as their ASCII counterparts,
+// and letters without case, such as Han or Arabic, as extended letters.
+func unicodeClassOf(r rune, symbols string) Class {
+	switch {
+	case unicode.IsDigit(r):
+		return ClassDigits
+	case unicode.IsUpper(r) || unicode.IsTitle(r):
+		return ClassUpper
+	case unicode.IsLower(r):
+		return ClassLower
+	case unicode.IsLetter(r):
+		return ClassExtended
+	case unicode.Is(emojiRanges, r):
+		return ClassEmoji
+	case symbols == "" && (unicode.IsPunct(r) || unicode.IsSymbol(r)):
+		return ClassSymbols
+	}
+	return ClassOther
+}
+
+// unicodeCharset splits the charset of pass under Options.UnicodeClasses. It returns the classes
+// of its ASCII runes, credited the ASCII charset sizes, and the size credited to its other digits
+// and symbols: 10 for the digits of each script and otherCharsetSize for punctuation and symbols
+// beyond ASCII. Letters beyond ASCII are credited per script by scriptsOf.
+func unicodeCharset(pass, symbols string) (ascii Class, size int) {
+	var zeros []rune // The zero of each run of ten digits found
+	other := false
+	for _, r := range pass {
+		class := classOf(r, symbols, true)
+		if r < utf8.RuneSelf || class == ClassSymbols && symbols != "" {
+			ascii |= class
+			continue
+		}
+		switch class {
+		case ClassDigits:
+			if zero := digitZero(r); !slices.Contains(zeros, zero) {
+				zeros = append(zeros, zero)
+				size += 10
+			}
+		case ClassSymbols:
+			if !other {
+				other = true
+				size += otherCharsetSize
+			}
+		}
+	}
+	return ascii, size
+}
+
+// digitZero returns the zero of the run of ten decimal digits holding r, so that digits of the
+// same script share it.
+func digitZero(r rune) rune {
+	for _, rng := range unicode.Nd.R16 {
+		if lo, hi := rune(rng.Lo), rune(rng.Hi); r >= lo && r <= hi {
+			return lo + (r-lo)/10*10
+		}
+	}
+	for _, rng := range unicode.Nd.R32 {
+		if lo, hi := rune(rng.Lo), rune(rng.Hi); r >= lo && r <= hi {
+			return lo + (r-lo)/10*10
+		}
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestUnicodeClasses(t *testing.T) {
+	tests := []struct {
+		name        string
+		pass        string
+		ascii, want Class
+		charset     int
+	}{
+		{"Cyrillic", "Пароль123", ClassExtended | ClassDigits, ClassUpper | ClassLower | ClassDigits, 10 + 33 + 33},
+		{"Arabic-Indic digits", "٠١٢٣٤٥", ClassOther, ClassDigits, 10},
+		{"Devanagari digits beside ASCII digits", "१२३456", ClassOther | ClassDigits, ClassDigits, 10 + 10},
+		{"Accented Latin", "Ñandú", ClassExtended | ClassLower, ClassUpper | ClassLower, 26 + 32 + 32},
+		{"Letters without case stay extended", "كلمة", ClassExtended, ClassExtended, 28},
+		{"Punctuation beyond ASCII", "pass¿word", ClassLower | ClassEmoji, ClassLower | ClassSymbols, 26 + otherCharsetSize},
+		{"Emoji", "pass🔒", ClassLower | ClassEmoji, ClassLower | ClassEmoji, 26 + emojiCharsetSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Audit(tt.pass, Options{}).Classes; got != tt.ascii {
+				t.Errorf("Audit(%q) Classes = %s, want %s", tt.pass, got, tt.ascii)
+			}
+			result := Audit(tt.pass, Options{UnicodeClasses: true})
+			if result.Classes != tt.want {
+				t.Errorf("Audit(%q) with UnicodeClasses Classes = %s, want %s", tt.pass, result.Classes, tt.want)
+			}
+			want := float64(utf8.RuneCountInString(tt.pass)) * math.Log2(float64(tt.charset))
+			if math.Abs(result.CharsetEntropy-want) > 1e-9 {
+				t.Errorf("Audit(%q) with UnicodeClasses CharsetEntropy = %.2f, want %.2f", tt.pass, result.CharsetEntropy, want)
+			}
+		})
+	}
+}
+
+func TestUnicodeClassesRequirements(t *testing.T) {
+	opts := Options{UseDigits: true, UseLower: true, UseUpper: true}
+	if result := Audit("Пароль123", opts); result.Err == nil {
+		t.Errorf("Audit() error = nil, want missing lowercase and uppercase letters")
+	}
+	opts.UnicodeClasses = true
+	if result := Audit("Пароль123", opts); result.Err != nil {
+		t.Errorf("Audit() with UnicodeClasses error = %v, want nil", result.Err)
+	}
+	if result := Audit("Пароль١٢٣", opts); result.Err != nil {
+		t.Errorf("Audit() with UnicodeClasses and Arabic-Indic digits error = %v, want nil", result.Err)
+	}
+}
+
+func TestUnicodeClassesConfusables(t *testing.T) {
+	result := Audit("pаsswоrd", Options{UnicodeClasses: true, RejectConfusables: true}) // Cyrillic а and о
+	if len(result.ConfusableRunes) != 2 || result.Err == nil {
+		t.Errorf("Audit() ConfusableRunes = %v, error = %v, want 2 and ErrConfusable", result.ConfusableRunes, result.Err)
+	}
+}
+
+func TestGenerateUnicodeClasses(t *testing.T) {
+	opts := Options{MinLength: 12, MaxLength: 12, UseLower: true, UseExtended: true, UnicodeClasses: true}
+	password, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result := Audit(password, opts); result.Err != nil {
+		t.Errorf("Audit(%q) error = %v, want nil", password, result.Err)
+	}
+	if !strings.ContainsAny(password, uncasedChars) {
+		t.Errorf("Generate() = %q, want a letter of %q", password, uncasedChars)
+	}
+}
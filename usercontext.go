@@ -0,0 +1,119 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// UserContext is the user record a password is chosen for.
+type UserContext struct {
+	Username       string
+	Email          string
+	DisplayName    string
+	PreviousHashes []string // Hashes of earlier passwords in any format VerifyAny accepts, most recent first
+}
+
+// Inputs returns the personal details AuditForUser rejects in a password: the username, the
+// email address, its local part, the domain without its top-level label, and the display name,
+// each whole and split into tokens at whitespace, ".", "_", "-", "+", and "@". Tokens shorter
+// than MinUserInputLength runes are dropped, and duplicates are dropped regardless of case, so
+// "Mary-Jane.Watson@dailybugle.com" yields the address, "Mary-Jane.Watson", "Mary", "Jane",
+// "Watson", and "dailybugle".
+func (u UserContext) Inputs() []string {
+	var inputs []string
+	seen := make(map[string]bool)
+	add := func(s string) {
+		key := strings.ToLower(strings.TrimSpace(s))
+		if utf8.RuneCountInString(key) < MinUserInputLength || seen[key] {
+			return
+		}
+		seen[key] = true
+		inputs = append(inputs, strings.TrimSpace(s))
+	}
+	addTokens := func(s string) {
+		add(s)
+		for _, token := range splitUserInput(s) {
+			add(token)
+		}
+	}
+
+	addTokens(u.Username)
+	if local, domain, ok := strings.Cut(u.Email, "@"); ok {
+		add(u.Email)
+		addTokens(local)
+		if labels := strings.Split(domain, "."); len(labels) > 1 {
+			for _, label := range labels[:len(labels)-1] {
+				add(label)
+			}
+		}
+	} else {
+		addTokens(u.Email)
+	}
+	addTokens(u.DisplayName)
+	return inputs
+}
+
+// splitUserInput splits s at whitespace and the separators usernames and addresses use.
+func splitUserInput(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("._-+@", r)
+	})
+}
+
+// AuditForUser audits password against opts with the details of user added: Inputs joins
+// UserInputs, and PreviousHashes are checked for reuse with VerifyAny. When opts.History is set,
+// its Verify, Workers, and Timeout are used instead and its Hashes are checked after them. ctx bounds the breach
+// and history checks, which fail the audit when it is done.
+func AuditForUser(ctx context.Context, password string, user UserContext, opts Options) Result {
+	if inputs := user.Inputs(); len(inputs) > 0 {
+		opts.UserInputs = slices.Concat(opts.UserInputs, inputs)
+	}
+	if len(user.PreviousHashes) > 0 {
+		history := HistoryChecker{Verify: verifyAnyHash}
+		if opts.History != nil {
+			history = *opts.History
+			history.Hashes = slices.Concat(user.PreviousHashes, opts.History.Hashes)
+			if history.Verify == nil {
+				history.Verify = verifyAnyHash
+			}
+		} else {
+			history.Hashes = user.PreviousHashes
+		}
+		opts.History = &history
+	}
+	required, forbidden, err := opts.validate()
+	if err != nil {
+		return invalidOptionsResult(&opts, err)
+	}
+	v := newValidator(opts, required, forbidden)
+	return v.auditContext(ctx, password)
+}
+
+// verifyAnyHash is the VerifyFunc AuditForUser checks PreviousHashes with.
+func verifyAnyHash(password, encoded string) (bool, error) {
+	_, err := VerifyAny(password, encoded)
+	if errors.Is(err, ErrPasswordMismatch) {
+		return false, nil
+	}
+	return err == nil, err
+}
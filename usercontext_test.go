@@ -0,0 +1,126 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserContextInputs(t *testing.T) {
+	tests := []struct {
+		name string
+		user UserContext
+		want []string
+	}{
+		{"Email", UserContext{Email: "Mary-Jane.Watson@dailybugle.com"},
+			[]string{"Mary-Jane.Watson@dailybugle.com", "Mary-Jane.Watson", "Mary", "Jane", "Watson", "dailybugle"}},
+		{"Username", UserContext{Username: "peter_parker+web"}, []string{"peter_parker+web", "peter", "parker"}},
+		{"Display name", UserContext{DisplayName: "  Peter  Benjamin Parker "}, []string{"Peter  Benjamin Parker", "Peter", "Benjamin", "Parker"}},
+		{"Short tokens dropped", UserContext{Username: "al.b.jr", DisplayName: "Al B"}, []string{"al.b.jr", "Al B"}},
+		{"Duplicates across fields", UserContext{Username: "parker", Email: "PARKER@mail.co.uk", DisplayName: "Peter Parker"},
+			[]string{"parker", "PARKER@mail.co.uk", "mail", "Peter Parker", "Peter"}},
+		{"Address without @", UserContext{Email: "peter.parker"}, []string{"peter.parker", "peter", "parker"}},
+		{"Empty", UserContext{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.user.Inputs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Inputs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditForUser(t *testing.T) {
+	previous, err := HashWithCost("Sp1der-Sense!Tingling", bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := UserContext{Username: "spidey", Email: "peter.parker@dailybugle.com", DisplayName: "Peter Parker", PreviousHashes: []string{previous}}
+	opts := Options{MinLength: 8}
+
+	tests := []struct {
+		password string
+		want     error
+	}{
+		{"Parker#2024!xyz", ErrContainsUserInput},
+		{"xyz!Bugle-dailybugle", ErrContainsUserInput},
+		{"r3kraP-Orchid-Lamp", ErrContainsUserInput},
+		{"Sp1der-Sense!Tingling", ErrPasswordReused},
+		{"Orchid-Lamp-Voyage", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			result := AuditForUser(context.Background(), tt.password, user, opts)
+			if tt.want == nil && result.Err != nil || tt.want != nil && !errors.Is(result.Err, tt.want) {
+				t.Errorf("AuditForUser() error = %v, want %v", result.Err, tt.want)
+			}
+		})
+	}
+	if opts.UserInputs != nil || opts.History != nil {
+		t.Error("AuditForUser() modified the caller's Options")
+	}
+}
+
+func TestAuditForUserHistoryOptions(t *testing.T) {
+	var checked []string
+	history := &HistoryChecker{
+		Hashes: []string{"shared"},
+		Verify: func(password, encoded string) (bool, error) {
+			checked = append(checked, encoded)
+			return false, nil
+		},
+		Workers: 1,
+	}
+	user := UserContext{PreviousHashes: []string{"mine"}}
+	if result := AuditForUser(context.Background(), "Orchid-Lamp-Voyage", user, Options{History: history}); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if !reflect.DeepEqual(checked, []string{"mine", "shared"}) {
+		t.Errorf("checked %q, want the user's hashes then the Options'", checked)
+	}
+	if len(history.Hashes) != 1 {
+		t.Errorf("AuditForUser() changed opts.History.Hashes to %q", history.Hashes)
+	}
+}
+
+func TestAuditForUserCancelled(t *testing.T) {
+	slow := BreachCheckerFunc(func(ctx context.Context, password string) (bool, int, error) {
+		select {
+		case <-ctx.Done():
+			return false, 0, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return false, 0, nil
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := AuditForUser(ctx, "Orchid-Lamp-Voyage", UserContext{}, Options{BreachChecker: slow})
+	if !errors.Is(result.Err, ErrBreachCheckUnavailable) || !errors.Is(result.Err, context.DeadlineExceeded) {
+		t.Errorf("AuditForUser() error = %v, want ErrBreachCheckUnavailable from the deadline", result.Err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("AuditForUser() took %v after its context was done", elapsed)
+	}
+}
@@ -0,0 +1,86 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// PatternUserInput is the Pattern kind of a personal detail from Options.UserInputs found in the
+// password. Base holds the user input as given.
+const PatternUserInput MatchKind = "user_input"
+
+// MinUserInputLength is the shortest user input, in runes, that is looked for in a password.
+// Shorter inputs such as initials would match too many unrelated passwords.
+const MinUserInputLength = 4
+
+// detectUserInputs returns the first occurrence of each user input in the password, compared
+// case-insensitively, reversed, and with leet substitutions decoded. The local part of an email
+// address is looked for on its own as well.
+func detectUserInputs(pass string, inputs []string) []Match {
+	lower := strings.ToLower(pass)
+	spellings := append([]string{lower}, leetCandidates(lower, MaxLeetCandidates)...)
+	runes := []rune(pass)
+
+	var patterns []Match
+	for _, input := range inputs {
+		for _, needle := range userInputNeedles(input) {
+			index, n := -1, utf8.RuneCountInString(needle)
+			for _, spelling := range spellings {
+				if i := strings.Index(spelling, needle); i >= 0 {
+					index = utf8.RuneCountInString(spelling[:i])
+					break
+				}
+			}
+			if index >= 0 && index+n <= len(runes) {
+				patterns = append(patterns, Match{Kind: PatternUserInput, Token: string(runes[index : index+n]), Start: index, End: index + n, Base: input})
+				break
+			}
+		}
+	}
+	return patterns
+}
+
+// userInputNeedles returns the lowercased forms of input to search for, longest first.
+func userInputNeedles(input string) []string {
+	lower := strings.ToLower(strings.TrimSpace(input))
+	var needles []string
+	add := func(s string) {
+		if utf8.RuneCountInString(s) < MinUserInputLength {
+			return
+		}
+		needles = append(needles, s)
+		if reversed := reverseString(s); reversed != s {
+			needles = append(needles, reversed)
+		}
+	}
+	add(lower)
+	if at := strings.LastIndexByte(lower, '@'); at > 0 {
+		add(lower[:at])
+	}
+	return needles
+}
+
+// reverseString reverses s rune by rune.
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
@@ -0,0 +1,96 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDetectUserInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		inputs   []string
+		want     []Match
+	}{
+		{
+			name:     "Username ignoring case",
+			password: "JSmith2024!",
+			inputs:   []string{"jsmith"},
+			want:     []Match{{Kind: PatternUserInput, Token: "JSmith", Start: 0, End: 6, Base: "jsmith"}},
+		},
+		{
+			name:     "Email local part",
+			password: "x-jsmith-99",
+			inputs:   []string{"jsmith@example.com"},
+			want:     []Match{{Kind: PatternUserInput, Token: "jsmith", Start: 2, End: 8, Base: "jsmith@example.com"}},
+		},
+		{
+			name:     "Reversed",
+			password: "htimsj#1",
+			inputs:   []string{"jsmith"},
+			want:     []Match{{Kind: PatternUserInput, Token: "htimsj", Start: 0, End: 6, Base: "jsmith"}},
+		},
+		{
+			name:     "Leet spelling",
+			password: "Acm3C0rp!",
+			inputs:   []string{"AcmeCorp"},
+			want:     []Match{{Kind: PatternUserInput, Token: "Acm3C0rp", Start: 0, End: 8, Base: "AcmeCorp"}},
+		},
+		{
+			name:     "Positions count runes",
+			password: "ñañaJosé",
+			inputs:   []string{"josé"},
+			want:     []Match{{Kind: PatternUserInput, Token: "José", Start: 4, End: 8, Base: "josé"}},
+		},
+		{
+			name:     "Short inputs are ignored",
+			password: "bobcat-crossing",
+			inputs:   []string{"bob", "jo"},
+		},
+		{
+			name:     "No match",
+			password: "correct horse",
+			inputs:   []string{"jsmith", "Acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectUserInputs(tt.password, tt.inputs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectUserInputs(%q) = %+v, want %+v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditUserInputs(t *testing.T) {
+	options := Options{UserInputs: []string{"jsmith", "jsmith@example.com", "Smith"}}
+	result := Audit("jsmith2024!", options)
+	if !errors.Is(result.Err, ErrContainsUserInput) {
+		t.Fatalf("Audit() error = %v, want %v", result.Err, ErrContainsUserInput)
+	}
+	if len(result.Patterns) != 4 || result.Patterns[3].Kind != PatternYear {
+		t.Errorf("Audit() Patterns = %+v, want one match per input and the year", result.Patterns)
+	}
+
+	if result := Audit("tr0ub4dor&3", options); result.Err != nil {
+		t.Errorf("Audit() error = %v, want nil", result.Err)
+	}
+}
@@ -0,0 +1,266 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Validator audits passwords against Options that were checked and compiled once. A Validator is
+// safe for concurrent use by multiple goroutines and immutable after construction, except for
+// its banned words; see AddBannedWords.
+type Validator struct {
+	opts    Options
+	extra   WordList                     // ExtraDictionary compiled for O(1) lookups
+	banned  *atomic.Pointer[bannedWords] // Replaces extra once the banned words change, nil outside NewValidator
+	symbols int                          // Size of the symbol set credited for entropy
+	allowed int                          // Runes in AllowedChars and not in DisallowedChars, zero when unrestricted
+
+	required  []*regexp.Regexp // RequiredPatterns compiled
+	forbidden []*regexp.Regexp // ForbiddenPatterns compiled
+
+	metrics Metrics // Options.Metrics, or NopMetrics when nil
+}
+
+// maxCharsetSize returns the largest charset Audit credits a password meant for opts. Every ASCII
+// class counts, but extended letters are held to the flat unknownScriptSize and emoji to nothing
+// unless opts enables them, so a Han or emoji alphabet does not make every entropy floor look
+// reachable. AllowedChars caps it, as it caps the charset Audit credits.
+func maxCharsetSize(opts *Options) int {
+	size := 10 + 26 + 26 + utf8.RuneCountInString(symbolSetOf(opts)) + otherCharsetSize + unknownScriptSize
+	if minimumCount(opts.UseExtended, opts.MinExtended) > 0 || opts.UnicodeClasses {
+		size += maxScriptsSize - unknownScriptSize
+	}
+	if opts.UseEmoji {
+		size += emojiCharsetSize
+	}
+	if opts.AllowedChars != "" {
+		size = min(size, utf8.RuneCountInString(opts.AllowedChars))
+	}
+	return size
+}
+
+// Validate reports Options that no password can satisfy or that are out of range. Every problem
+// found is wrapped with ErrInvalidOptions and joined with errors.Join.
+func (opts Options) Validate() error {
+	_, _, err := opts.validate()
+	return err
+}
+
+// validate is Validate that also returns the RequiredPatterns and ForbiddenPatterns it compiled,
+// so callers building a Validator need not compile them again.
+func (opts Options) validate() (requiredPatterns, forbiddenPatterns []*regexp.Regexp, err error) {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOptions}, args...)...))
+	}
+
+	if opts.MaxLength > 0 && opts.MinLength > opts.MaxLength {
+		invalid("minimum length %d exceeds maximum length %d", opts.MinLength, opts.MaxLength)
+	}
+
+	if opts.MaxBytes > 0 && opts.MinLength > opts.MaxBytes {
+		invalid("minimum length %d exceeds maximum bytes %d", opts.MinLength, opts.MaxBytes)
+	}
+
+	required := minimumCount(opts.UseDigits, opts.MinDigits) +
+		minimumCount(opts.UseLower, opts.MinLower) +
+		minimumCount(opts.UseUpper, opts.MinUpper) +
+		minimumCount(opts.UseSymbols, opts.MinSymbols) +
+		minimumCount(opts.UseExtended, opts.MinExtended) +
+		minimumCount(opts.UseEmoji, 0)
+	if opts.MaxLength > 0 && required > opts.MaxLength {
+		invalid("required characters %d exceed maximum length %d", required, opts.MaxLength)
+	}
+
+	if opts.MinClasses > 0 {
+		enabled := 0
+		for _, n := range []uint{
+			minimumCount(opts.UseDigits, opts.MinDigits), minimumCount(opts.UseLower, opts.MinLower),
+			minimumCount(opts.UseUpper, opts.MinUpper), minimumCount(opts.UseSymbols, opts.MinSymbols),
+			minimumCount(opts.UseExtended, opts.MinExtended), minimumCount(opts.UseEmoji, 0),
+		} {
+			if n > 0 {
+				enabled++
+			}
+		}
+		if enabled == 0 {
+			enabled = defaultClassCount
+		}
+		if int(opts.MinClasses) > enabled {
+			invalid("minimum classes %d exceed the %d classes enabled", opts.MinClasses, enabled)
+		}
+	}
+
+	if opts.Normalize < NormalizeNone || opts.Normalize > NormalizeNFKC {
+		invalid("unknown normalization form %d", opts.Normalize)
+	}
+
+	if opts.BreachCheckMode < BreachEnforce || opts.BreachCheckMode > BreachSkip {
+		invalid("unknown breach check mode %d", opts.BreachCheckMode)
+	}
+
+	if opts.BreachCheckTimeout < 0 {
+		invalid("breach check timeout %v cannot be negative", opts.BreachCheckTimeout)
+	}
+
+	if ComplexityStrength(opts.MinimumComplexity) < 0 {
+		invalid("unknown minimum complexity %d", opts.MinimumComplexity)
+	}
+
+	switch {
+	case opts.MinEntropy < 0:
+		invalid("minimum entropy %.2f cannot be negative", opts.MinEntropy)
+	case opts.MaxLength > 0 && opts.MinEntropy > float64(opts.MaxLength)*math.Log2(float64(maxCharsetSize(&opts))):
+		invalid("minimum entropy %.2f bits is unreachable in %d characters", opts.MinEntropy, opts.MaxLength)
+	}
+
+	if opts.MaxLength > 0 && opts.MinUniqueChars > opts.MaxLength {
+		invalid("minimum unique characters %d exceed maximum length %d", opts.MinUniqueChars, opts.MaxLength)
+	}
+	if !(opts.MaxClassRatio >= 0 && opts.MaxClassRatio <= 1) {
+		invalid("maximum class ratio %.2f is outside 0 to 1", opts.MaxClassRatio)
+	}
+
+	if opts.YearRange.Min > opts.YearRange.Max {
+		invalid("year range starts at %d after it ends at %d", opts.YearRange.Min, opts.YearRange.Max)
+	}
+
+	if opts.MaxSimilarity < 0 || opts.MaxSimilarity > 1 {
+		invalid("maximum similarity %.2f is outside 0 to 1", opts.MaxSimilarity)
+	}
+
+	for _, set := range []struct{ name, chars string }{
+		{"symbol set", opts.SymbolSet},
+		{"allowed characters", opts.AllowedChars},
+		{"disallowed characters", opts.DisallowedChars},
+	} {
+		if !utf8.ValidString(set.chars) {
+			invalid("%s are not valid UTF-8", set.name)
+		}
+	}
+	if strings.IndexFunc(opts.SymbolSet, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+		invalid("symbol set %q contains letters or digits", opts.SymbolSet)
+	}
+	if opts.AllowedChars != "" || opts.DisallowedChars != "" {
+		// Every required class needs at least one rune that Audit permits.
+		pool := opts.AllowedChars
+		if pool == "" {
+			pool = digitChars + lowerChars + upperChars + symbolSetOf(&opts) + extendedSetOf(&opts) + emojiChars
+		}
+		permitted, _ := classify(permittedChars(&opts, pool), opts.SymbolSet, opts.UnicodeClasses)
+		for _, class := range []struct {
+			name     string
+			required uint
+			count    int
+		}{
+			{"digits", minimumCount(opts.UseDigits, opts.MinDigits), permitted.Digits},
+			{"lowercase letters", minimumCount(opts.UseLower, opts.MinLower), permitted.Lower},
+			{"uppercase letters", minimumCount(opts.UseUpper, opts.MinUpper), permitted.Upper},
+			{"symbols", minimumCount(opts.UseSymbols, opts.MinSymbols), permitted.Symbols},
+			{"extended letters", minimumCount(opts.UseExtended, opts.MinExtended), permitted.Extended},
+			{"emoji", minimumCount(opts.UseEmoji, 0), permitted.Emoji},
+		} {
+			if class.required > 0 && class.count == 0 {
+				invalid("%s are required but none are allowed", class.name)
+			}
+		}
+	}
+
+	compile := func(name string, patterns []string) []*regexp.Regexp {
+		var compiled []*regexp.Regexp
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				invalid("%s pattern %q: %v", name, pattern, err)
+				continue
+			}
+			compiled = append(compiled, re)
+		}
+		return compiled
+	}
+	requiredPatterns = compile("required", opts.RequiredPatterns)
+	forbiddenPatterns = compile("forbidden", opts.ForbiddenPatterns)
+
+	for i, rule := range opts.CustomRules {
+		if rule == nil {
+			invalid("custom rule %d is nil", i)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return requiredPatterns, forbiddenPatterns, nil
+}
+
+// NewValidator validates opts and precomputes the lookup tables used by Audit. It returns the
+// error from Options.Validate when the Options can never be satisfied. The Validator keeps a
+// Clone of opts, so the caller may change or reuse opts and its slices afterwards.
+func NewValidator(opts Options) (*Validator, error) {
+	required, forbidden, err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+	opts = opts.Clone()
+
+	if opts.RejectCommon {
+		loadCommonPasswords()
+	}
+
+	v := newValidator(opts, required, forbidden)
+	v.banned = new(atomic.Pointer[bannedWords])
+	return &v, nil
+}
+
+// newValidator builds a Validator from opts and the patterns validate compiled from them, without
+// validating opts. It returns a value so throwaway Validators built by Audit stay on the stack.
+func newValidator(opts Options, required, forbidden []*regexp.Regexp) Validator {
+	v := Validator{opts: opts, symbols: utf8.RuneCountInString(symbolSetOf(&opts))}
+	for i, r := range opts.AllowedChars {
+		if strings.IndexRune(opts.AllowedChars, r) == i && !strings.ContainsRune(opts.DisallowedChars, r) {
+			v.allowed++
+		}
+	}
+	if len(opts.ExtraDictionary) > 0 {
+		v.extra = NewWordList(opts.ExtraDictionary...)
+	}
+	v.required, v.forbidden = required, forbidden
+	v.metrics = opts.Metrics
+	if v.metrics == nil {
+		v.metrics = NopMetrics{}
+	}
+	return v
+}
+
+// Options returns a Clone of the Options the Validator was built from, with ExtraDictionary
+// reflecting AddBannedWords and RemoveBannedWords.
+func (v *Validator) Options() Options {
+	opts := v.opts.Clone()
+	if banned := v.bannedWords(); banned != nil {
+		opts.ExtraDictionary = slices.Clone(banned.words)
+	}
+	return opts
+}
@@ -0,0 +1,297 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestNewValidatorRejectsImpossibleOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{"Minimum length exceeds maximum", Options{MinLength: 16, MaxLength: 8}},
+		{"Required classes exceed maximum length", Options{MaxLength: 3, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true}},
+		{"Per-class minimums exceed maximum length", Options{MaxLength: 5, MinDigits: 3, MinSymbols: 3}},
+		{"Negative entropy floor", Options{MinEntropy: -1}},
+		{"Similarity above one", Options{MaxSimilarity: 1.5}},
+		{"Unknown minimum complexity", Options{MinimumComplexity: 99}},
+		{"Entropy unreachable at maximum length", Options{MaxLength: 8, MinEntropy: 80}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, err := NewValidator(tt.options); err == nil {
+				t.Errorf("NewValidator() = %v, want error", v)
+			}
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		problems int
+	}{
+		{"Zero value", Options{}, 0},
+		{"Every class fits", Options{MinLength: 8, MaxLength: 8, MinDigits: 2, MinLower: 2, MinUpper: 2, MinSymbols: 2}, 0},
+		{"Entropy reachable at maximum length", Options{MaxLength: 12, MinEntropy: 80}, 0},
+		{"Entropy without maximum length", Options{MinEntropy: 500}, 0},
+		{"Strongest complexity", Options{MinimumComplexity: PwComplexityExtendedMixed}, 0},
+		{"Minimum length exceeds maximum", Options{MinLength: 16, MaxLength: 8}, 1},
+		{"Required classes exceed maximum length", Options{MaxLength: 3, UseDigits: true, UseLower: true, UseUpper: true, UseSymbols: true}, 1},
+		{"Negative complexity", Options{MinimumComplexity: -1}, 1},
+		{"Entropy unreachable at maximum length", Options{MaxLength: 8, MinEntropy: 80}, 1},
+		{"Entropy reachable with extended letters", Options{MaxLength: 8, MinEntropy: 80, UseExtended: true}, 0},
+		{"Entropy unreachable in AllowedChars", Options{MaxLength: 20, MinEntropy: 70, AllowedChars: "0123456789"}, 1},
+		{"Custom symbol set", Options{UseSymbols: true, SymbolSet: "€£!", AllowedChars: "abc€£!"}, 0},
+		{"Symbol set with letters", Options{SymbolSet: "!a"}, 1},
+		{"Required class with nothing allowed", Options{UseDigits: true, AllowedChars: "abc"}, 1},
+		{"Required symbols all disallowed", Options{UseSymbols: true, SymbolSet: "!?", DisallowedChars: "!?"}, 1},
+		{"Valid patterns", Options{RequiredPatterns: []string{`^[A-Z]`}, ForbiddenPatterns: []string{`(?i)acme`}}, 0},
+		{"Patterns that do not compile", Options{RequiredPatterns: []string{`[a-z`}, ForbiddenPatterns: []string{`(?P<x`, `ok`}}, 2},
+		{"Invalid UTF-8 in character sets", Options{SymbolSet: "\xff", DisallowedChars: "\xfe"}, 2},
+		{"Several problems", Options{MinLength: 9, MaxLength: 2, MinDigits: 3, MinimumComplexity: 42, MinEntropy: -1, MaxSimilarity: 2}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.problems == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Fatalf("Validate() error = %v, want %v", err, ErrInvalidOptions)
+			}
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok || len(joined.Unwrap()) != tt.problems {
+				t.Errorf("Validate() error = %v, want %d problems", err, tt.problems)
+			}
+		})
+	}
+}
+
+func TestAuditInvalidOptions(t *testing.T) {
+	options := Options{MinLength: 16, MaxLength: 8, PolicyName: "broken"}
+	result := Audit("Xq7mB2vLp9!", options)
+	if !errors.Is(result.Err, ErrInvalidOptions) || len(result.Violations) != 1 || result.Strong {
+		t.Errorf("Audit() = %+v, want an ErrInvalidOptions failure", result)
+	}
+	if result.Policy != "broken" {
+		t.Errorf("Audit() Policy = %q, want %q", result.Policy, "broken")
+	}
+	if _, err := NewValidator(options); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("NewValidator() error = %v, want %v", err, ErrInvalidOptions)
+	}
+}
+
+func TestValidatorAuditMatchesAudit(t *testing.T) {
+	options := Options{
+		MinLength:       8,
+		UseDigits:       true,
+		UseUpper:        true,
+		RejectCommon:    true,
+		ExtraDictionary: []string{"acmecorp"},
+	}
+	v, err := NewValidator(options)
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	for _, password := range []string{"short", "Password1", "AcmeCorp2024", "Xq7mB2vLp9", "øøøøøøøø"} {
+		got, want := v.Audit(password), Audit(password, options)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Validator.Audit(%q) = %+v, want %+v", password, got, want)
+		}
+	}
+}
+
+func TestAuditCompilesPatternsOnce(t *testing.T) {
+	pattern := `(?i)(acme|initech|globex)[0-9]{2,4}`
+	compile := testing.AllocsPerRun(20, func() { regexp.MustCompile(pattern) })
+	without := testing.AllocsPerRun(20, func() { Audit("Xq7#mB2vLp9!", Options{}) })
+	with := testing.AllocsPerRun(20, func() { Audit("Xq7#mB2vLp9!", Options{ForbiddenPatterns: []string{pattern}}) })
+	if extra := with - without; extra >= 2*compile {
+		t.Errorf("Audit() with a pattern allocates %v more per call, want under %v for one compile", extra, 2*compile)
+	}
+}
+
+func TestValidatorConcurrentAudit(t *testing.T) {
+	v, err := NewValidator(Options{MinLength: 8, RejectCommon: true, ExtraDictionary: []string{"acmecorp"}})
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if result := v.Audit("AcmeCorp1!"); !errors.Is(result.Err, ErrCommonPassword) {
+					t.Errorf("Validator.Audit() error = %v, want %v", result.Err, ErrCommonPassword)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewValidatorIgnoresLaterChanges(t *testing.T) {
+	opts := Options{
+		RejectCommon:      true,
+		ExtraDictionary:   []string{"acmecorp"},
+		ForbiddenPatterns: []string{`^admin`},
+		CustomRules:       []Rule{NewRule("no-zz", func(p string) error { return nil })},
+		UserInputs:        []string{"alice"},
+		PreviousPasswords: []string{"Winter-Sky-2024!"},
+		History:           &HistoryChecker{Hashes: []string{"plain:Old-Value-2023!"}, Verify: plainVerify},
+	}
+	v, err := NewValidator(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts.ExtraDictionary[0] = "unrelated"
+	opts.ForbiddenPatterns[0] = `^nothing$`
+	opts.CustomRules[0] = NewRule("reject-all", func(string) error { return errors.New("rejected") })
+	opts.UserInputs[0] = "bob"
+	opts.PreviousPasswords[0] = "x"
+	opts.History.Hashes[0] = "garbage"
+	opts.History.Verify = nil
+
+	tests := []struct {
+		password string
+		want     error
+	}{
+		{"AcmeCorp", ErrCommonPassword},
+		{"admin-Blue-Rocket-7", ErrForbiddenPattern},
+		{"alice-Blue-Rocket-7", ErrContainsUserInput},
+		{"Winter-Sky-2024?", ErrTooSimilar},
+		{"Old-Value-2023!", ErrPasswordReused},
+		{"Blue-Rocket-Lamp-7", nil},
+	}
+	for _, tt := range tests {
+		if res := v.Audit(tt.password); !errors.Is(res.Err, tt.want) || (tt.want == nil && res.Err != nil) {
+			t.Errorf("Audit(%q) error = %v, want %v", tt.password, res.Err, tt.want)
+		}
+	}
+
+	copied := v.Options()
+	copied.UserInputs[0] = "carol"
+	if !errors.Is(v.Audit("alice-Blue-Rocket-7").Err, ErrContainsUserInput) {
+		t.Error("changing Options() changed the Validator")
+	}
+}
+
+func TestOptionsCloneCopiesEveryReference(t *testing.T) {
+	opts := Options{History: &HistoryChecker{Hashes: []string{"h"}}}
+	value := reflect.ValueOf(&opts).Elem()
+	for i := range value.NumField() {
+		if field := value.Field(i); field.Kind() == reflect.Slice {
+			field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+		}
+	}
+	clone := opts.Clone()
+	cloned := reflect.ValueOf(clone)
+	for i := range value.NumField() {
+		name := value.Type().Field(i).Name
+		switch field := value.Field(i); field.Kind() {
+		case reflect.Slice:
+			if field.Pointer() == cloned.Field(i).Pointer() {
+				t.Errorf("Clone() shares Options.%s", name)
+			}
+		case reflect.Map:
+			t.Errorf("Options.%s is a map; copy it in Clone and cover it here", name)
+		}
+	}
+	if clone.History == opts.History || &clone.History.Hashes[0] == &opts.History.Hashes[0] {
+		t.Error("Clone() shares History")
+	}
+	if zero := (Options{}).Clone(); !reflect.DeepEqual(zero, Options{}) {
+		t.Errorf("Options{}.Clone() = %+v, want the zero Options", zero)
+	}
+}
+
+func TestAuditSharedOptionsConcurrent(t *testing.T) {
+	opts := Options{
+		MinLength:         10,
+		RejectCommon:      true,
+		ExtraDictionary:   []string{"acmecorp"},
+		UserInputs:        []string{"alice"},
+		PreviousPasswords: []string{"Winter-Sky-2024!"},
+		RequiredPatterns:  []string{`\d`},
+	}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if res := Audit("alice-Blue-Rocket-7", opts); !errors.Is(res.Err, ErrContainsUserInput) {
+					t.Errorf("Audit() error = %v, want %v", res.Err, ErrContainsUserInput)
+					return
+				}
+				AuditForUser(context.Background(), "Blue-Rocket-Lamp-7", UserContext{Username: "bob"}, opts)
+				if _, err := NewValidator(opts); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// largeDictionaryOptions exercises the cost of compiling ExtraDictionary.
+func largeDictionaryOptions() Options {
+	words := make([]string, 5000)
+	for i := range words {
+		words[i] = fmt.Sprintf("banned%d", i)
+	}
+	return Options{MinLength: 8, RejectCommon: true, ExtraDictionary: words}
+}
+
+func BenchmarkAuditThrowawayValidator(b *testing.B) {
+	options := largeDictionaryOptions()
+	for i := 0; i < b.N; i++ {
+		Audit("Xq7#mB2vLp9!", options)
+	}
+}
+
+func BenchmarkValidatorAudit(b *testing.B) {
+	v, err := NewValidator(largeDictionaryOptions())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Audit("Xq7#mB2vLp9!")
+	}
+}
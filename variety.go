@@ -0,0 +1,66 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// uniqueRunes returns the number of distinct runes in pass. Case is significant, so 'A' and 'a'
+// are two runes, and runes below U+0100 are counted without allocating.
+func uniqueRunes(pass string) int {
+	var latin [256]bool
+	var others map[rune]bool
+	unique := 0
+	for _, r := range pass {
+		if r < 256 {
+			if !latin[r] {
+				latin[r] = true
+				unique++
+			}
+			continue
+		}
+		if others == nil {
+			others = make(map[rune]bool)
+		}
+		if !others[r] {
+			others[r] = true
+			unique++
+		}
+	}
+	return unique
+}
+
+// largestClass returns the name of the class with the most characters in counts, as in its JSON
+// encoding, and that number of characters.
+func largestClass(counts Counts) (string, int) {
+	classes := [...]struct {
+		name  string
+		count int
+	}{
+		{"digits", counts.Digits},
+		{"lower", counts.Lower},
+		{"upper", counts.Upper},
+		{"symbols", counts.Symbols},
+		{"extended", counts.Extended},
+		{"other", counts.Other},
+		{"emoji", counts.Emoji},
+	}
+	largest := classes[0]
+	for _, class := range classes[1:] {
+		if class.count > largest.count {
+			largest = class
+		}
+	}
+	return largest.name, largest.count
+}
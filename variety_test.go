@@ -0,0 +1,113 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUniqueRunes(t *testing.T) {
+	tests := []struct {
+		pass string
+		want int
+	}{
+		{"", 0},
+		{"aaaa", 1},
+		{"Aa", 2},
+		{"ßẞss", 3},
+		{"пароль", 6},
+		{"🔑🔑a", 2},
+	}
+	for _, tt := range tests {
+		if got := uniqueRunes(tt.pass); got != tt.want {
+			t.Errorf("uniqueRunes(%q) = %d, want %d", tt.pass, got, tt.want)
+		}
+	}
+}
+
+func TestAuditMinUniqueChars(t *testing.T) {
+	tests := []struct {
+		name  string
+		pass  string
+		min   uint
+		valid bool
+	}{
+		{"Below the minimum", "aaaaaaaaaaaA1!", 5, false},
+		{"At the minimum", "aaaaaaaaaaaA1!", 4, true},
+		{"Case is significant", "aAaAaAaA", 2, true},
+		{"Above the minimum", "Tr0ub4dor&3", 8, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.pass, Options{MinUniqueChars: tt.min})
+			if result.UniqueChars != uniqueRunes(tt.pass) {
+				t.Errorf("Audit(%q) UniqueChars = %d, want %d", tt.pass, result.UniqueChars, uniqueRunes(tt.pass))
+			}
+			if valid := result.Err == nil; valid != tt.valid {
+				t.Fatalf("Audit(%q) with MinUniqueChars %d error = %v, want valid %v", tt.pass, tt.min, result.Err, tt.valid)
+			}
+			var verr *ValidationError
+			if !tt.valid && (!errors.As(result.Err, &verr) || verr.Code != CodeTooFewUnique || verr.Params["unique_chars"] != result.UniqueChars) {
+				t.Errorf("Audit(%q) error = %#v, want %s", tt.pass, result.Err, CodeTooFewUnique)
+			}
+		})
+	}
+}
+
+func TestAuditMaxClassRatio(t *testing.T) {
+	tests := []struct {
+		name  string
+		pass  string
+		ratio float64
+		class string
+	}{
+		{"Mostly lowercase", "aaaaaaaaaaaA1!", 0.75, "lower"},
+		{"Exactly at the ratio", "aaaaaaaaA1", 0.8, ""},
+		{"One over the ratio", "aaaaaaaaaA1", 0.8, "lower"},
+		{"Mostly digits", "12345678Ab", 0.75, "digits"},
+		{"Balanced", "Ab1!Cd2@", 0.25, ""},
+		{"Single class at the maximum ratio", "abcdef", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.pass, Options{MaxClassRatio: tt.ratio})
+			if tt.class == "" {
+				if result.Err != nil {
+					t.Errorf("Audit(%q) with MaxClassRatio %v error = %v, want nil", tt.pass, tt.ratio, result.Err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.Is(result.Err, ErrClassRatio) || !errors.As(result.Err, &verr) || verr.Params["class"] != tt.class {
+				t.Errorf("Audit(%q) with MaxClassRatio %v error = %v, want %v for %s", tt.pass, tt.ratio, result.Err, ErrClassRatio, tt.class)
+			}
+		})
+	}
+}
+
+func TestValidateVariety(t *testing.T) {
+	for _, opts := range []Options{
+		{MaxLength: 8, MinUniqueChars: 9},
+		{MaxClassRatio: -0.1},
+		{MaxClassRatio: 1.5},
+	} {
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Validate(%+v) = %v, want %v", opts, err, ErrInvalidOptions)
+		}
+	}
+}
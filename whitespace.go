@@ -0,0 +1,73 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// whitespaceCharsetSize is the charset size credited to whitespace: a space, and rarely a tab or
+// a space from another script.
+const whitespaceCharsetSize = 2
+
+// isWhitespace reports whether r is a space, tab, or other Unicode white space.
+func isWhitespace(r rune) bool {
+	return unicode.IsSpace(r)
+}
+
+// countWhitespace returns the number of whitespace runes in pass that classify counts as other
+// characters rather than as runes of symbols.
+func countWhitespace(pass, symbols string, unicodeClasses bool) int {
+	n := 0
+	for _, r := range pass {
+		if isWhitespace(r) && classOf(r, symbols, unicodeClasses) == ClassOther {
+			n++
+		}
+	}
+	return n
+}
+
+// edgeWhitespace returns the position in runes of whitespace leading or trailing pass, or -1 when
+// there is none.
+func edgeWhitespace(pass string) int {
+	if r, _ := utf8.DecodeRuneInString(pass); isWhitespace(r) {
+		return 0
+	}
+	if r, _ := utf8.DecodeLastRuneInString(pass); isWhitespace(r) {
+		return utf8.RuneCountInString(pass) - 1
+	}
+	return -1
+}
+
+// boundaryClasses returns the classes of the first and last characters of pass and the position
+// in runes where the last one starts. An emoji sequence is one character, as in classify.
+func boundaryClasses(pass, symbols string, unicodeClasses bool) (first, last Class, lastIndex int) {
+	var joiner emojiJoiner
+	i := 0
+	for _, r := range pass {
+		class := classOf(r, symbols, unicodeClasses)
+		if !joiner.extends(r, class) {
+			if i == 0 {
+				first = class
+			}
+			last, lastIndex = class, i
+		}
+		i++
+	}
+	return first, last, lastIndex
+}
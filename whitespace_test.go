@@ -0,0 +1,135 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAuditWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		pass string
+		opts Options
+		code string
+		pos  int
+	}{
+		{"Internal spaces pass", "correct horse battery staple", Options{ForbidLeadingTrailingSpace: true}, "", 0},
+		{"Leading space", " correct horse battery staple", Options{ForbidLeadingTrailingSpace: true}, CodeEdgeWhitespace, 0},
+		{"Trailing space", "correct horse battery staple ", Options{ForbidLeadingTrailingSpace: true}, CodeEdgeWhitespace, 28},
+		{"Spaces allowed by default", " correct horse battery staple ", Options{}, "", 0},
+		{"Internal space rejected", "correct horse", Options{RejectWhitespace: true}, CodeWhitespace, 7},
+		{"Ideographic space rejected", "ßüñ　horse", Options{RejectWhitespace: true}, CodeWhitespace, 3},
+		{"No whitespace", "correct-horse", Options{RejectWhitespace: true}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.pass, tt.opts)
+			if tt.code == "" {
+				if result.Err != nil {
+					t.Fatalf("Audit(%q) error = %v, want nil", tt.pass, result.Err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(result.Err, &verr) || verr.Code != tt.code {
+				t.Fatalf("Audit(%q) error = %#v, want %s", tt.pass, result.Err, tt.code)
+			}
+			if verr.Params["position"] != tt.pos {
+				t.Errorf("Audit(%q) position = %v, want %d", tt.pass, verr.Params["position"], tt.pos)
+			}
+		})
+	}
+}
+
+func TestAuditBoundaryClasses(t *testing.T) {
+	letters := ClassLower | ClassUpper
+	tests := []struct {
+		name        string
+		pass        string
+		first, last Class
+		code        string
+		class       string
+	}{
+		{"Letter first", "Tr0ub4dor&3", letters, 0, "", ""},
+		{"Digit first", "3Tr0ub4dor&", letters, 0, CodeFirstChar, "digits"},
+		{"Symbol last", "Tr0ub4dor3&", 0, ClassDigits | letters, CodeLastChar, "symbols"},
+		{"Emoji sequence last", "Tr0ub4dor3👨‍👩‍👧", 0, ClassEmoji, "", ""},
+		{"Extended letter first", "ßTr0ub4dor3", ClassExtended, 0, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.pass, Options{FirstCharClasses: tt.first, LastCharClasses: tt.last})
+			if tt.code == "" {
+				if result.Err != nil {
+					t.Fatalf("Audit(%q) error = %v, want nil", tt.pass, result.Err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(result.Err, &verr) || verr.Code != tt.code || verr.Params["class"] != tt.class {
+				t.Errorf("Audit(%q) error = %#v, want %s for %s", tt.pass, result.Err, tt.code, tt.class)
+			}
+		})
+	}
+}
+
+func TestWhitespaceCharset(t *testing.T) {
+	tests := []struct {
+		name    string
+		pass    string
+		charset int
+	}{
+		{"Letters", "correcthorsebatterystaple", 26},
+		{"Letters and spaces", "correct horse battery staple", 26 + whitespaceCharsetSize},
+		{"Spaces and another character", "correct horse battery staple٣", 26 + whitespaceCharsetSize + otherCharsetSize},
+		{"Space in the symbol set", "correct horse battery staple", 26 + 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{}
+			if tt.name == "Space in the symbol set" {
+				opts.SymbolSet = " -_"
+			}
+			result := Audit(tt.pass, opts)
+			want := float64(utf8.RuneCountInString(tt.pass)) * math.Log2(float64(tt.charset))
+			if math.Abs(result.CharsetEntropy-want) > 1e-9 {
+				t.Errorf("Audit(%q) CharsetEntropy = %.2f, want %.2f", tt.pass, result.CharsetEntropy, want)
+			}
+		})
+	}
+}
+
+func TestClassString(t *testing.T) {
+	tests := []struct {
+		classes Class
+		want    string
+	}{
+		{0, "none"},
+		{ClassDigits, "digits"},
+		{ClassLower | ClassUpper, "lower|upper"},
+		{ClassSymbols | ClassEmoji, "symbols|emoji"},
+	}
+	for _, tt := range tests {
+		if got := tt.classes.String(); got != tt.want {
+			t.Errorf("Class(%d).String() = %q, want %q", tt.classes, got, tt.want)
+		}
+	}
+}
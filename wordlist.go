@@ -0,0 +1,94 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultWordlistMinLength is the shortest word, in characters, LoadWordlist accepts. Shorter
+// entries would reject any password containing them as a word and are almost always mistakes.
+const DefaultWordlistMinLength = 3
+
+// WordlistLoader reads banned-word lists maintained outside the code, such as product names,
+// office locations, and people's names.
+type WordlistLoader struct {
+	// MinLength is the shortest word in characters that Load accepts, DefaultWordlistMinLength
+	// when zero.
+	MinLength int
+}
+
+// LoadWordlist reads a wordlist with a WordlistLoader using the default minimum length.
+func LoadWordlist(r io.Reader) ([]string, error) {
+	var l WordlistLoader
+	return l.Load(r)
+}
+
+// Load reads one word per line, ignoring a leading byte order mark. Each line is trimmed and
+// lowercased; blank lines and lines starting with "#" are skipped and duplicates are dropped, so
+// the words are returned in first-seen order. When any line is not valid UTF-8, is shorter than
+// MinLength, or has an invisible or control character, Load returns no words and an error
+// wrapping ErrInvalidWordlist for each such line.
+func (l *WordlistLoader) Load(r io.Reader) ([]string, error) {
+	minLength := l.MinLength
+	if minLength == 0 {
+		minLength = DefaultWordlistMinLength
+	}
+	var (
+		words []string
+		errs  []error
+	)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		word := strings.TrimSpace(text)
+		switch {
+		case word == "" || strings.HasPrefix(word, "#"):
+			continue
+		case !utf8.ValidString(word):
+			errs = append(errs, fmt.Errorf("%w: line %d is not valid UTF-8", ErrInvalidWordlist, line))
+			continue
+		case strings.ContainsFunc(word, func(r rune) bool { return isControl(r) || isInvisible(r) }):
+			errs = append(errs, fmt.Errorf("%w: line %d: %q contains an invisible or control character", ErrInvalidWordlist, line, word))
+			continue
+		}
+		word = strings.ToLower(word)
+		switch {
+		case utf8.RuneCountInString(word) < minLength:
+			errs = append(errs, fmt.Errorf("%w: line %d: %q is shorter than %d characters", ErrInvalidWordlist, line, word, minLength))
+		case !seen[word]:
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return words, nil
+}
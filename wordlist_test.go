@@ -0,0 +1,97 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadWordlistFixture(t *testing.T) {
+	f, err := os.Open("testdata/wordlist.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	words, err := LoadWordlist(f)
+	if err != nil {
+		t.Fatalf("LoadWordlist() error = %v", err)
+	}
+	want := []string{"acmecorp", "springfield", "burns", "smithers"}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("LoadWordlist() = %q, want %q", words, want)
+	}
+}
+
+func TestWordlistLoader(t *testing.T) {
+	tests := []struct {
+		name      string
+		minLength int
+		input     string
+		want      []string
+		wantLines []string
+	}{
+		{"Empty", 0, "", nil, nil},
+		{"Only comments", 0, "# one\n  # two\n\n", nil, nil},
+		{"Comment marker inside a word", 0, "c#sharp\n", []string{"c#sharp"}, nil},
+		{"Byte order mark only on the first line", 0, "\ufeffacme\n\ufeffcorp\n", nil, []string{"line 2"}},
+		{"Default minimum", 0, "acme\nab\nxyz\n", nil, []string{"line 2"}},
+		{"Every invalid line reported", 0, "a\nacme\nb\n", nil, []string{"line 1", "line 3"}},
+		{"Custom minimum", 5, "acme\nspringfield\n", nil, []string{"line 1"}},
+		{"Minimum counts characters", 4, "żółw\n", []string{"żółw"}, nil},
+		{"Invalid UTF-8", 0, "acme\n\xff\xfe\n", nil, []string{"line 2", "UTF-8"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := WordlistLoader{MinLength: tt.minLength}
+			words, err := l.Load(strings.NewReader(tt.input))
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("Load() = %q, want %q", words, tt.want)
+			}
+			if (err != nil) != (tt.wantLines != nil) {
+				t.Fatalf("Load() error = %v, want error %v", err, tt.wantLines != nil)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, ErrInvalidWordlist) {
+				t.Errorf("Load() error = %v, want ErrInvalidWordlist", err)
+			}
+			for _, line := range tt.wantLines {
+				if !strings.Contains(err.Error(), line) {
+					t.Errorf("Load() error = %q, want it to mention %q", err, line)
+				}
+			}
+		})
+	}
+}
+
+func TestWordlistMergesWithCommonPasswords(t *testing.T) {
+	v, err := New(WithWordlist(strings.NewReader("# Product names\r\nAcmeCorp\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pass := range []string{"acmecorp2024!", "password1"} {
+		if err := v.Audit(pass).Err; !errors.Is(err, ErrCommonPassword) {
+			t.Errorf("Audit(%q) error = %v, want ErrCommonPassword", pass, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Pattern kinds of a dictionary word disguised by repeating or reversing it. Base holds the word.
+const (
+	PatternRepeatedWord MatchKind = "repeated_word" // A word typed twice such as "dragondragon" or "dragon1dragon"
+	PatternReversedWord MatchKind = "reversed_word" // A word typed backwards such as "nogard"
+)
+
+// minVariantWord is the shortest word, in runes, looked for reversed or repeated within a longer
+// password, as shorter words turn up by chance.
+const minVariantWord = 5
+
+// maxVariantWord bounds the substrings tried so long passwords stay cheap.
+const maxVariantWord = 32
+
+// wordVariantMatch returns the doubled or reversed word of an enabled dictionary the password is
+// made of, and whether there is one. The whole password, with trailing digits and symbols
+// stripped as for the dictionary itself, is tried first, then substrings making up at least half
+// of it, so "dragon1dragon" and "nogard2024" are still caught.
+func (v *Validator) wordVariantMatch(pass string) (Match, bool) {
+	if !v.opts.RejectCommon && v.opts.Dictionary == nil {
+		return Match{}, false
+	}
+	for _, candidate := range dictionaryCandidates(pass) {
+		if word := reverseString(candidate); word != candidate && v.containsWord(word) {
+			return prefixMatch(PatternReversedWord, pass, word), true
+		}
+		if half, ok := doubledHalf(candidate); ok && v.containsWord(half) {
+			m := prefixMatch(PatternRepeatedWord, pass, candidate)
+			m.Base = half
+			return m, true
+		}
+	}
+	return v.wordVariantSubstring(pass)
+}
+
+// doubledHalf returns the first half of s when s is the same string twice.
+func doubledHalf(s string) (string, bool) {
+	runes := []rune(s)
+	half := len(runes) / 2
+	if half == 0 || len(runes)%2 != 0 || !equalRunes(runes[:half], runes[half:]) {
+		return "", false
+	}
+	return string(runes[:half]), true
+}
+
+// wordVariantSubstring returns the longest word of at least minVariantWord runes found reversed,
+// or twice without overlapping, in the password, as long as the match covers at least half of it.
+// Words are compared rune by rune, so accented words reverse correctly.
+func (v *Validator) wordVariantSubstring(pass string) (Match, bool) {
+	lowered := lowerRunes(pass)
+	n := len(lowered)
+	if n < minVariantWord {
+		return Match{}, false
+	}
+	lower := string(lowered)
+	reversed := reverseString(lower)
+	at, reversedAt := runeOffsets(lower, n), runeOffsets(reversed, n)
+
+	var best Match
+	consider := func(kind MatchKind, start, end int, word string) {
+		if 2*(end-start) >= n && end-start > best.Len() {
+			best = Match{Kind: kind, Start: start, End: end, Base: word}
+		}
+	}
+	first := make(map[string][2]int) // Where each word was first found
+	for i := 0; i+minVariantWord <= n; i++ {
+		for j := min(n, i+maxVariantWord); j-i >= minVariantWord; j-- {
+			word := lower[at[i]:at[j]]
+			if v.containsWord(word) {
+				if span, ok := first[word]; !ok {
+					first[word] = [2]int{i, j}
+				} else if span[1] <= i {
+					consider(PatternRepeatedWord, span[0], j, word)
+				}
+			}
+			// The runes i to j of the password, read backwards.
+			if backwards := reversed[reversedAt[n-j]:reversedAt[n-i]]; backwards != word && v.containsWord(backwards) {
+				consider(PatternReversedWord, i, j, backwards)
+			}
+		}
+	}
+	if best.Kind == "" {
+		return Match{}, false
+	}
+	best.Token = string([]rune(pass)[best.Start:best.End])
+	return best, true
+}
+
+// runeOffsets returns the byte offset of each of the n runes of s, followed by len(s).
+func runeOffsets(s string, n int) []int {
+	offsets := make([]int, 0, n+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(s))
+}
+
+// variantName describes how kind disguises its word in an error.
+func variantName(kind MatchKind) string {
+	if kind == PatternReversedWord {
+		return "reversed"
+	}
+	return "doubled"
+}
@@ -0,0 +1,134 @@
+package go_passwd
+
+/*
+   Copyright 2024 Andrei Merlescu
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAuditWordVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+		want     Match
+	}{
+		{
+			name:     "Doubled word",
+			password: "dragondragon",
+			options:  Options{RejectCommon: true},
+			want:     Match{Kind: PatternRepeatedWord, Token: "dragondragon", End: 12, Base: "dragon"},
+		},
+		{
+			name:     "Doubled word with trailing digits",
+			password: "DragonDragon99",
+			options:  Options{RejectCommon: true},
+			want:     Match{Kind: PatternRepeatedWord, Token: "DragonDragon", End: 12, Base: "dragon"},
+		},
+		{
+			name:     "Doubled word split by a digit",
+			password: "dragon1dragon",
+			options:  Options{RejectCommon: true},
+			want:     Match{Kind: PatternRepeatedWord, Token: "dragon1dragon", End: 13, Base: "dragon"},
+		},
+		{
+			name:     "Reversed word",
+			password: "Yeknom",
+			options:  Options{RejectCommon: true},
+			want:     Match{Kind: PatternReversedWord, Token: "Yeknom", End: 6, Base: "monkey"},
+		},
+		{
+			name:     "Reversed substring",
+			password: "x!nogard7",
+			options:  Options{RejectCommon: true},
+			want:     Match{Kind: PatternReversedWord, Token: "nogard", Start: 2, End: 8, Base: "dragon"},
+		},
+		{
+			name:     "Reversed accented word",
+			password: "éfacéfac",
+			options:  Options{Dictionary: NewWordList("cafécafé")},
+			want:     Match{Kind: PatternReversedWord, Token: "éfacéfac", End: 8, Base: "cafécafé"},
+		},
+		{
+			name:     "Custom dictionary",
+			password: "tigrestigres",
+			options:  Options{Dictionary: NewWordList("tigres")},
+			want:     Match{Kind: PatternRepeatedWord, Token: "tigrestigres", End: 12, Base: "tigres"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if !errors.Is(result.Err, ErrCommonPassword) {
+				t.Fatalf("Audit(%q) error = %v, want %v", tt.password, result.Err, ErrCommonPassword)
+			}
+			tt.want.Penalty = result.CharsetEntropy - compromisedEntropy
+			if len(result.Patterns) != 1 || result.Patterns[0] != tt.want {
+				t.Errorf("Audit(%q) Patterns = %+v, want [%+v]", tt.password, result.Patterns, tt.want)
+			}
+			if result.EffectiveEntropy != compromisedEntropy {
+				t.Errorf("Audit(%q) EffectiveEntropy = %v, want %v", tt.password, result.EffectiveEntropy, compromisedEntropy)
+			}
+		})
+	}
+}
+
+func TestAuditWordVariantsAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		options  Options
+	}{
+		{"Dictionary disabled", "dragondragon", Options{}},
+		{"Short reversed word", "kq7#nogardWv3!pLx9", Options{RejectCommon: true}},
+		{"Palindrome", "racecar", Options{Dictionary: NewWordList("racecars")}},
+		{"Overlapping halves", "dragonagon", Options{Dictionary: NewWordList("dragon", "onagon")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Audit(tt.password, tt.options)
+			if errors.Is(result.Err, ErrCommonPassword) {
+				t.Errorf("Audit(%q) error = %v, want no dictionary match", tt.password, result.Err)
+			}
+			for _, p := range result.Patterns {
+				if p.Kind == PatternRepeatedWord || p.Kind == PatternReversedWord {
+					t.Errorf("Audit(%q) Patterns has %+v", tt.password, p)
+				}
+			}
+		})
+	}
+}
+
+func TestDoubledHalf(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+		ok   bool
+	}{
+		{"abcabc", "abc", true},
+		{"ééé", "", false},
+		{"éaéa", "éa", true},
+		{"", "", false},
+		{"abcabd", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := doubledHalf(tt.s); got != tt.want || ok != tt.ok {
+			t.Errorf("doubledHalf(%q) = %q, %v, want %q, %v", tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+}